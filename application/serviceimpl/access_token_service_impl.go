@@ -0,0 +1,232 @@
+package serviceimpl
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gofiber-template/domain/dto"
+	"gofiber-template/domain/models"
+	"gofiber-template/domain/repositories"
+	"gofiber-template/domain/services"
+	"gofiber-template/pkg/utils"
+)
+
+const (
+	accessTokenBytes         = 32                  // Entropy of a personal access token
+	accessTokenDefaultTTL    = 90 * 24 * time.Hour // Used when the request doesn't pick an expiry
+	accessTokenTouchInterval = time.Minute         // Last-used time is only written this often
+	accessTokenRetention     = 30 * 24 * time.Hour // Expired and revoked tokens are kept this long
+	maxAccessTokensPerUser   = 25
+)
+
+type AccessTokenServiceImpl struct {
+	tokenRepo   repositories.PersonalAccessTokenRepository
+	userRepo    repositories.UserRepository
+	authService services.AuthService
+}
+
+func NewAccessTokenService(
+	tokenRepo repositories.PersonalAccessTokenRepository,
+	userRepo repositories.UserRepository,
+	authService services.AuthService,
+) services.AccessTokenService {
+	return &AccessTokenServiceImpl{
+		tokenRepo:   tokenRepo,
+		userRepo:    userRepo,
+		authService: authService,
+	}
+}
+
+func (s *AccessTokenServiceImpl) CreateToken(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, mfaVerified bool, req *dto.CreateAccessTokenRequest) (*dto.CreatedAccessTokenResponse, error) {
+	if err := s.authService.RequireRecentAuth(ctx, userID, sessionID); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	scopes, err := normalizeTokenScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	if slices.Contains(scopes, models.TokenScopeAdmin) {
+		if len(models.RolePermissions(user.Role)) == 0 {
+			return nil, errors.New("only moderators and admins can create tokens with the admin scope")
+		}
+		if models.RoleRequiresMFA(user.Role) && !mfaVerified {
+			return nil, errors.New("sign in with two-factor authentication to create tokens with the admin scope")
+		}
+	}
+
+	now := time.Now()
+	count, err := s.tokenRepo.CountActiveByUser(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxAccessTokensPerUser {
+		return nil, errors.New("too many access tokens, revoke one first")
+	}
+
+	ttl := accessTokenDefaultTTL
+	if req.ExpiresInDays > 0 {
+		ttl = time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	}
+
+	rawToken, err := newAccessToken()
+	if err != nil {
+		return nil, err
+	}
+
+	token := &models.PersonalAccessToken{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Name:        strings.TrimSpace(req.Name),
+		TokenHash:   hashAccessToken(rawToken),
+		TokenPrefix: rawToken[:len(models.PersonalAccessTokenPrefix)+8],
+		Scopes:      strings.Join(scopes, " "),
+		MFAVerified: mfaVerified,
+		ExpiresAt:   now.Add(ttl),
+	}
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return nil, err
+	}
+
+	log.Printf("🔑 Personal access token %s created for user %s (scopes: %s)", token.ID, userID, token.Scopes)
+
+	return &dto.CreatedAccessTokenResponse{
+		AccessTokenResponse: accessTokenResponse(token),
+		Token:               rawToken,
+	}, nil
+}
+
+func (s *AccessTokenServiceImpl) ListTokens(ctx context.Context, userID uuid.UUID) ([]dto.AccessTokenResponse, error) {
+	tokens, err := s.tokenRepo.ListActiveByUser(ctx, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.AccessTokenResponse, len(tokens))
+	for i, token := range tokens {
+		responses[i] = accessTokenResponse(token)
+	}
+	return responses, nil
+}
+
+func (s *AccessTokenServiceImpl) RevokeToken(ctx context.Context, userID uuid.UUID, tokenID uuid.UUID) error {
+	revoked, err := s.tokenRepo.Revoke(ctx, userID, tokenID, time.Now())
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return errors.New("access token not found")
+	}
+
+	log.Printf("🔒 Personal access token %s of user %s revoked", tokenID, userID)
+	return nil
+}
+
+func (s *AccessTokenServiceImpl) Authenticate(ctx context.Context, rawToken string, ip string) (*dto.AccessTokenPrincipal, error) {
+	if !strings.HasPrefix(rawToken, models.PersonalAccessTokenPrefix) {
+		return nil, utils.ErrInvalidToken
+	}
+
+	token, err := s.tokenRepo.GetByHash(ctx, hashAccessToken(rawToken))
+	if err != nil {
+		return nil, utils.ErrInvalidToken
+	}
+
+	now := time.Now()
+	if token.RevokedAt != nil {
+		return nil, utils.ErrInvalidToken
+	}
+	if !token.IsActive(now) {
+		return nil, utils.ErrExpiredToken
+	}
+
+	// Role, email and account state are read fresh, so changes apply to tokens immediately
+	user, err := s.userRepo.GetByID(ctx, token.UserID)
+	if err != nil || !user.IsActive {
+		return nil, utils.ErrInvalidToken
+	}
+
+	ip = truncateString(ip, 64)
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= accessTokenTouchInterval || token.LastUsedIP != ip {
+		if err := s.tokenRepo.Touch(ctx, token.ID, ip, now); err != nil {
+			log.Printf("Failed to record use of access token %s: %v", token.ID, err)
+		}
+	}
+
+	return &dto.AccessTokenPrincipal{
+		TokenID:       token.ID,
+		UserID:        user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		Role:          user.Role,
+		Scopes:        token.ScopeList(),
+		EmailVerified: user.EmailVerified,
+		MFAVerified:   token.MFAVerified,
+	}, nil
+}
+
+func (s *AccessTokenServiceImpl) PurgeInactiveTokens(ctx context.Context) (int64, error) {
+	return s.tokenRepo.DeleteInactive(ctx, time.Now().Add(-accessTokenRetention))
+}
+
+// normalizeTokenScopes checks requested scopes and returns them deduplicated in display order
+func normalizeTokenScopes(requested []string) ([]string, error) {
+	for _, scope := range requested {
+		if !models.IsValidTokenScope(scope) {
+			return nil, errors.New("unknown scope: " + scope)
+		}
+	}
+
+	scopes := make([]string, 0, len(requested))
+	for _, scope := range models.TokenScopes {
+		if slices.Contains(requested, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
+
+// newAccessToken returns a random token carrying the personal access token prefix
+func newAccessToken() (string, error) {
+	b := make([]byte, accessTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return models.PersonalAccessTokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashAccessToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func accessTokenResponse(token *models.PersonalAccessToken) dto.AccessTokenResponse {
+	return dto.AccessTokenResponse{
+		ID:          token.ID,
+		Name:        token.Name,
+		TokenPrefix: token.TokenPrefix,
+		Scopes:      token.ScopeList(),
+		ExpiresAt:   token.ExpiresAt,
+		LastUsedAt:  token.LastUsedAt,
+		LastUsedIP:  token.LastUsedIP,
+		CreatedAt:   token.CreatedAt,
+	}
+}
+
+// Ensure interface compliance
+var _ services.AccessTokenService = (*AccessTokenServiceImpl)(nil)
@@ -0,0 +1,254 @@
+package serviceimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gofiber-template/domain/dto"
+	"gofiber-template/domain/models"
+	"gofiber-template/domain/repositories"
+	"gofiber-template/domain/services"
+	"gofiber-template/infrastructure/redis"
+	"gofiber-template/pkg/config"
+	"gofiber-template/pkg/mailer"
+	"gofiber-template/pkg/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const accountMailCooldown = time.Minute // Minimum gap between account emails of the same kind
+
+type AccountServiceImpl struct {
+	userRepo        repositories.UserRepository
+	authService     services.AuthService
+	redisService    *redis.RedisService
+	mailer          mailer.Mailer
+	secret          string
+	appName         string
+	frontendURL     string
+	verificationTTL time.Duration
+	resetTTL        time.Duration
+}
+
+func NewAccountService(
+	userRepo repositories.UserRepository,
+	authService services.AuthService,
+	redisService *redis.RedisService,
+	mail mailer.Mailer,
+	cfg *config.Config,
+) services.AccountService {
+	return &AccountServiceImpl{
+		userRepo:        userRepo,
+		authService:     authService,
+		redisService:    redisService,
+		mailer:          mail,
+		secret:          cfg.JWT.Secret,
+		appName:         cfg.App.Name,
+		frontendURL:     strings.TrimRight(cfg.App.FrontendURL, "/"),
+		verificationTTL: time.Duration(cfg.Account.EmailVerificationTTL) * time.Hour,
+		resetTTL:        time.Duration(cfg.Account.PasswordResetTTL) * time.Minute,
+	}
+}
+
+func (s *AccountServiceImpl) SendVerificationEmail(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	if user.HasConfirmedEmail() {
+		return errors.New("email already verified")
+	}
+
+	allowed, err := s.redisService.AcquireMailCooldown(ctx, utils.ActionEmailVerification, user.ID, accountMailCooldown)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return errors.New("verification email sent recently, please wait before requesting another")
+	}
+
+	// Bound to the address, so changing it invalidates links sent to the old one
+	token, _, err := utils.GenerateActionToken(utils.ActionEmailVerification, user.ID, utils.ActionTokenBinding(user.Email), s.verificationTTL, s.secret)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: fmt.Sprintf("Verify your %s email address", s.appName),
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThe link expires in %s. If you didn't create an account, you can ignore this email.",
+			user.DisplayName, s.link("/verify-email", token), formatTTL(s.verificationTTL),
+		),
+	})
+}
+
+func (s *AccountServiceImpl) VerifyEmail(ctx context.Context, token string) error {
+	claims, err := utils.ParseActionToken(token, utils.ActionEmailVerification, s.secret)
+	if err != nil {
+		if errors.Is(err, utils.ErrExpiredToken) {
+			return errors.New("verification link has expired")
+		}
+		return errors.New("invalid verification link")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return errors.New("invalid verification link")
+	}
+
+	// Opening the link twice is harmless
+	if user.HasConfirmedEmail() {
+		return nil
+	}
+
+	if claims.Binding != utils.ActionTokenBinding(user.Email) {
+		return errors.New("invalid verification link")
+	}
+
+	if err := s.consumeToken(ctx, claims); err != nil {
+		return err
+	}
+
+	return s.userRepo.ConfirmEmail(ctx, user.ID, time.Now())
+}
+
+func (s *AccountServiceImpl) NotifyAccountLocked(ctx context.Context, userID uuid.UUID, lockedFor time.Duration) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	return s.mailer.Send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: fmt.Sprintf("Sign-in to your %s account was paused", s.appName),
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nThere were several failed attempts to sign in to your account, so we have paused sign-in for %s.\n\nIf this was you, wait and try again. If it wasn't, someone may be guessing your password; we recommend choosing a new one:\n\n%s",
+			user.DisplayName, formatTTL(lockedFor), s.frontendURL+"/forgot-password",
+		),
+	})
+}
+
+func (s *AccountServiceImpl) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil || !user.IsActive {
+		// Same response as for a real account, so addresses can't be probed
+		return nil
+	}
+
+	allowed, err := s.redisService.AcquireMailCooldown(ctx, utils.ActionPasswordReset, user.ID, accountMailCooldown)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return nil
+	}
+
+	// Bound to the current password hash: once any new password is set, every
+	// outstanding reset link stops working
+	token, _, err := utils.GenerateActionToken(utils.ActionPasswordReset, user.ID, utils.ActionTokenBinding(user.Password), s.resetTTL, s.secret)
+	if err != nil {
+		return err
+	}
+
+	err = s.mailer.Send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: fmt.Sprintf("Reset your %s password", s.appName),
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nSomeone asked to reset the password of your account. To choose a new password, open the link below:\n\n%s\n\nThe link expires in %s. If it wasn't you, you can ignore this email; your password stays the same.",
+			user.DisplayName, s.link("/reset-password", token), formatTTL(s.resetTTL),
+		),
+	})
+	if err != nil {
+		// Not reported to the caller, that would confirm the account exists
+		log.Printf("Failed to send password reset email to user %s: %v", user.ID, err)
+	}
+
+	return nil
+}
+
+func (s *AccountServiceImpl) ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) error {
+	claims, err := utils.ParseActionToken(req.Token, utils.ActionPasswordReset, s.secret)
+	if err != nil {
+		if errors.Is(err, utils.ErrExpiredToken) {
+			return errors.New("reset link has expired")
+		}
+		return errors.New("invalid reset link")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil || claims.Binding != utils.ActionTokenBinding(user.Password) {
+		return errors.New("invalid reset link")
+	}
+
+	if !user.IsActive {
+		return errors.New("account is disabled")
+	}
+
+	if err := s.consumeToken(ctx, claims); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	user.Password = string(hashedPassword)
+	user.UpdatedAt = now
+
+	if err := s.userRepo.Update(ctx, user.ID, user); err != nil {
+		return err
+	}
+
+	// The link arrived by email, which proves the address as well
+	if !user.HasConfirmedEmail() {
+		if err := s.userRepo.ConfirmEmail(ctx, user.ID, now); err != nil {
+			return err
+		}
+	}
+
+	// Whoever knew the old password must not stay signed in anywhere
+	_, err = s.authService.RevokeOtherSessions(ctx, user.ID, uuid.Nil, models.SessionRevokeReasonPasswordReset)
+	return err
+}
+
+// consumeToken enforces that an emailed link works only once
+func (s *AccountServiceImpl) consumeToken(ctx context.Context, claims *utils.ActionTokenClaims) error {
+	ttl := max(time.Until(time.Unix(claims.ExpiresAt, 0)), time.Second)
+
+	fresh, err := s.redisService.ConsumeActionToken(ctx, claims.Nonce, ttl)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return errors.New("link has already been used")
+	}
+	return nil
+}
+
+func (s *AccountServiceImpl) link(path string, token string) string {
+	return s.frontendURL + path + "?token=" + url.QueryEscape(token)
+}
+
+// formatTTL renders a link lifetime for email copy, e.g. "24 hours" or "60 minutes"
+func formatTTL(d time.Duration) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		hours := int(d / time.Hour)
+		if hours == 1 {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", hours)
+	}
+	return fmt.Sprintf("%d minutes", int(d/time.Minute))
+}
+
+// Ensure interface compliance
+var _ services.AccountService = (*AccountServiceImpl)(nil)
@@ -0,0 +1,357 @@
+package serviceimpl
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gofiber-template/domain/dto"
+	"gofiber-template/domain/models"
+	"gofiber-template/domain/repositories"
+	"gofiber-template/domain/services"
+	"gofiber-template/infrastructure/redis"
+	"gofiber-template/pkg/config"
+	"gofiber-template/pkg/utils"
+)
+
+const (
+	refreshTokenBytes = 32               // Entropy of an opaque refresh token
+	refreshReuseGrace = 10 * time.Second // A client racing itself (two tabs) isn't treated as theft
+	revocationSkew    = time.Minute      // Revocation markers outlive access tokens by this much
+	recentAuthWindow  = 10 * time.Minute // Sensitive account changes need a sign-in or re-authentication this recent
+)
+
+var errRefreshTokenRaced = errors.New("refresh token already used")
+
+type AuthServiceImpl struct {
+	sessionRepo      repositories.SessionRepository
+	refreshTokenRepo repositories.RefreshTokenRepository
+	userRepo         repositories.UserRepository
+	redisService     *redis.RedisService
+	txManager        repositories.TransactionManager
+	jwtSecret        string
+	accessTokenTTL   time.Duration
+	refreshTokenTTL  time.Duration
+}
+
+func NewAuthService(
+	sessionRepo repositories.SessionRepository,
+	refreshTokenRepo repositories.RefreshTokenRepository,
+	userRepo repositories.UserRepository,
+	redisService *redis.RedisService,
+	txManager repositories.TransactionManager,
+	cfg *config.Config,
+) services.AuthService {
+	return &AuthServiceImpl{
+		sessionRepo:      sessionRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		userRepo:         userRepo,
+		redisService:     redisService,
+		txManager:        txManager,
+		jwtSecret:        cfg.JWT.Secret,
+		accessTokenTTL:   time.Duration(cfg.JWT.AccessTokenTTL) * time.Minute,
+		refreshTokenTTL:  time.Duration(cfg.JWT.RefreshTokenTTL) * 24 * time.Hour,
+	}
+}
+
+func (s *AuthServiceImpl) IssueTokens(ctx context.Context, user *models.User, client *dto.ClientInfo, mfaVerified bool) (*dto.TokenPair, error) {
+	now := time.Now()
+	userAgent, ipAddress := clientDetails(client)
+
+	session := &models.Session{
+		ID:           uuid.New(),
+		UserID:       user.ID,
+		UserAgent:    userAgent,
+		IPAddress:    ipAddress,
+		LastActiveAt: now,
+		ExpiresAt:    now.Add(s.refreshTokenTTL),
+		MFAVerified:  mfaVerified,
+	}
+
+	rawToken, token, err := newRefreshToken(session)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.sessionRepo.Create(ctx, session); err != nil {
+			return err
+		}
+		return s.refreshTokenRepo.Create(ctx, token)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.tokenPair(user, session, rawToken)
+}
+
+func (s *AuthServiceImpl) Refresh(ctx context.Context, refreshToken string, client *dto.ClientInfo) (*dto.TokenPair, error) {
+	token, err := s.refreshTokenRepo.GetByHash(ctx, hashRefreshToken(refreshToken))
+	if err != nil {
+		return nil, errors.New("invalid refresh token")
+	}
+
+	session, err := s.sessionRepo.GetByID(ctx, token.SessionID)
+	if err != nil {
+		return nil, errors.New("invalid refresh token")
+	}
+
+	now := time.Now()
+
+	if session.RevokedAt != nil {
+		return nil, errors.New("session has been revoked")
+	}
+
+	if token.IsRotated() {
+		// Same token presented again shortly after rotation: most likely the client retrying
+		if now.Sub(*token.RevokedAt) < refreshReuseGrace {
+			return nil, errRefreshTokenRaced
+		}
+
+		// A rotated token coming back means it was copied; end the session for both holders
+		log.Printf("⚠️ Refresh token reuse detected for user %s, revoking session %s", session.UserID, session.ID)
+		s.revokeSessions(ctx, session.UserID, []uuid.UUID{session.ID}, models.SessionRevokeReasonTokenReuse, now)
+		return nil, errors.New("refresh token reuse detected")
+	}
+
+	if !session.IsActive(now) || !now.Before(token.ExpiresAt) {
+		return nil, errors.New("refresh token has expired")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, session.UserID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if !user.IsActive {
+		s.revokeSessions(ctx, user.ID, []uuid.UUID{session.ID}, models.SessionRevokeReasonAccountDisabled, now)
+		return nil, errors.New("account is disabled")
+	}
+
+	// Sliding expiry: an active device stays signed in
+	userAgent, ipAddress := clientDetails(client)
+	session.ExpiresAt = now.Add(s.refreshTokenTTL)
+
+	rawToken, next, err := newRefreshToken(session)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		rotated, err := s.refreshTokenRepo.Rotate(ctx, token.ID, next.ID, now)
+		if err != nil {
+			return err
+		}
+		if !rotated {
+			return errRefreshTokenRaced
+		}
+		if err := s.refreshTokenRepo.Create(ctx, next); err != nil {
+			return err
+		}
+		return s.sessionRepo.Touch(ctx, session.ID, userAgent, ipAddress, now, session.ExpiresAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.tokenPair(user, session, rawToken)
+}
+
+func (s *AuthServiceImpl) Logout(ctx context.Context, refreshToken string) error {
+	token, err := s.refreshTokenRepo.GetByHash(ctx, hashRefreshToken(refreshToken))
+	if err != nil {
+		return errors.New("invalid refresh token")
+	}
+
+	s.revokeSessions(ctx, token.UserID, []uuid.UUID{token.SessionID}, models.SessionRevokeReasonLogout, time.Now())
+	return nil
+}
+
+func (s *AuthServiceImpl) IsSessionActive(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	revoked, err := s.redisService.IsSessionRevoked(ctx, sessionID)
+	if err != nil {
+		return false, err
+	}
+	return !revoked, nil
+}
+
+func (s *AuthServiceImpl) MarkSessionMFAVerified(ctx context.Context, sessionID uuid.UUID) error {
+	return s.sessionRepo.MarkMFAVerified(ctx, sessionID)
+}
+
+func (s *AuthServiceImpl) MarkSessionReauthenticated(ctx context.Context, sessionID uuid.UUID) error {
+	return s.sessionRepo.MarkReauthenticated(ctx, sessionID, time.Now())
+}
+
+func (s *AuthServiceImpl) RequireRecentAuth(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil || session.UserID != userID || !session.IsActive(time.Now()) {
+		return services.ErrReauthenticationRequired
+	}
+
+	if time.Since(session.AuthenticatedAt()) > recentAuthWindow {
+		return services.ErrReauthenticationRequired
+	}
+	return nil
+}
+
+func (s *AuthServiceImpl) ListSessions(ctx context.Context, userID uuid.UUID, currentSessionID uuid.UUID) ([]dto.SessionResponse, error) {
+	sessions, err := s.sessionRepo.ListActiveByUser(ctx, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		responses[i] = dto.SessionResponse{
+			ID:           session.ID,
+			Device:       utils.DescribeUserAgent(session.UserAgent),
+			UserAgent:    session.UserAgent,
+			IPAddress:    session.IPAddress,
+			IsCurrent:    session.ID == currentSessionID,
+			LastActiveAt: session.LastActiveAt,
+			CreatedAt:    session.CreatedAt,
+		}
+	}
+
+	return responses, nil
+}
+
+func (s *AuthServiceImpl) RevokeSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil || session.UserID != userID || !session.IsActive(time.Now()) {
+		return errors.New("session not found")
+	}
+
+	s.revokeSessions(ctx, userID, []uuid.UUID{sessionID}, models.SessionRevokeReasonUser, time.Now())
+	return nil
+}
+
+func (s *AuthServiceImpl) RevokeOtherSessions(ctx context.Context, userID uuid.UUID, currentSessionID uuid.UUID, reason string) (int, error) {
+	now := time.Now()
+
+	revoked, err := s.sessionRepo.RevokeAllByUser(ctx, userID, currentSessionID, reason, now)
+	if err != nil {
+		return 0, err
+	}
+
+	// Already revoked in the database; only the access token side is left
+	s.blockSessions(ctx, userID, revoked)
+
+	log.Printf("🔒 Revoked %d other session(s) of user %s (%s)", len(revoked), userID, reason)
+	return len(revoked), nil
+}
+
+func (s *AuthServiceImpl) PurgeExpiredSessions(ctx context.Context) (int64, error) {
+	now := time.Now()
+
+	// Deleting a session cascades to its refresh tokens
+	sessions, err := s.sessionRepo.DeleteExpired(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	// Rotated tokens of live sessions are only kept for reuse detection until they expire
+	tokens, err := s.refreshTokenRepo.DeleteExpired(ctx, now)
+	if err != nil {
+		return sessions, err
+	}
+
+	return sessions + tokens, nil
+}
+
+// revokeSessions revokes sessions in the database and blocks their outstanding access tokens
+func (s *AuthServiceImpl) revokeSessions(ctx context.Context, userID uuid.UUID, sessionIDs []uuid.UUID, reason string, now time.Time) {
+	for _, sessionID := range sessionIDs {
+		if _, err := s.sessionRepo.Revoke(ctx, sessionID, reason, now); err != nil {
+			log.Printf("Failed to revoke session %s: %v", sessionID, err)
+		}
+	}
+
+	s.blockSessions(ctx, userID, sessionIDs)
+}
+
+// blockSessions rejects the sessions' access tokens until they expire and closes their
+// chat connections on every instance
+func (s *AuthServiceImpl) blockSessions(ctx context.Context, userID uuid.UUID, sessionIDs []uuid.UUID) {
+	for _, sessionID := range sessionIDs {
+		if err := s.redisService.RevokeSession(ctx, sessionID, s.accessTokenTTL+revocationSkew); err != nil {
+			log.Printf("Failed to mark session %s as revoked: %v", sessionID, err)
+		}
+
+		if err := s.redisService.PublishSessionRevoked(ctx, userID, sessionID); err != nil {
+			log.Printf("Failed to publish revocation of session %s: %v", sessionID, err)
+		}
+	}
+}
+
+func (s *AuthServiceImpl) tokenPair(user *models.User, session *models.Session, refreshToken string) (*dto.TokenPair, error) {
+	accessToken, err := utils.GenerateAccessToken(&utils.UserContext{
+		ID:        user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		Role:      user.Role,
+		SessionID: session.ID,
+
+		EmailVerified: user.EmailVerified,
+		MFAVerified:   session.MFAVerified,
+	}, s.accessTokenTTL, s.jwtSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.TokenPair{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.accessTokenTTL.Seconds()),
+	}, nil
+}
+
+// newRefreshToken creates an opaque token for the session and the row that stores its hash
+func newRefreshToken(session *models.Session) (string, *models.RefreshToken, error) {
+	b := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+	rawToken := base64.RawURLEncoding.EncodeToString(b)
+
+	return rawToken, &models.RefreshToken{
+		ID:        uuid.New(),
+		SessionID: session.ID,
+		UserID:    session.UserID,
+		TokenHash: hashRefreshToken(rawToken),
+		ExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// clientDetails returns the user agent and IP trimmed to their column sizes
+func clientDetails(client *dto.ClientInfo) (string, string) {
+	if client == nil {
+		return "", ""
+	}
+	return truncateString(client.UserAgent, 512), truncateString(client.IPAddress, 64)
+}
+
+func truncateString(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	// Cut on a rune boundary; Postgres rejects invalid UTF-8
+	return strings.ToValidUTF8(s[:max], "")
+}
+
+// Ensure interface compliance
+var _ services.AuthService = (*AuthServiceImpl)(nil)
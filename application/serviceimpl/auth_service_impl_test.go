@@ -0,0 +1,192 @@
+package serviceimpl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gofiber-template/domain/dto"
+	"gofiber-template/domain/models"
+	"gofiber-template/domain/repositories"
+	"gofiber-template/pkg/config"
+)
+
+// memSessionRepo keeps sessions in memory
+type memSessionRepo struct {
+	repositories.SessionRepository
+	sessions map[uuid.UUID]*models.Session
+}
+
+func (r *memSessionRepo) Create(ctx context.Context, session *models.Session) error {
+	copied := *session
+	r.sessions[session.ID] = &copied
+	return nil
+}
+
+func (r *memSessionRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Session, error) {
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	copied := *session
+	return &copied, nil
+}
+
+func (r *memSessionRepo) Touch(ctx context.Context, id uuid.UUID, userAgent string, ipAddress string, at time.Time, expiresAt time.Time) error {
+	r.sessions[id].LastActiveAt = at
+	r.sessions[id].ExpiresAt = expiresAt
+	return nil
+}
+
+func (r *memSessionRepo) Revoke(ctx context.Context, id uuid.UUID, reason string, at time.Time) (bool, error) {
+	session := r.sessions[id]
+	if session.RevokedAt != nil {
+		return false, nil
+	}
+	session.RevokedAt = &at
+	session.RevokeReason = reason
+	return true, nil
+}
+
+// memRefreshTokenRepo keeps refresh tokens in memory, indexed by hash
+type memRefreshTokenRepo struct {
+	repositories.RefreshTokenRepository
+	tokens map[string]*models.RefreshToken
+}
+
+func (r *memRefreshTokenRepo) Create(ctx context.Context, token *models.RefreshToken) error {
+	copied := *token
+	r.tokens[token.TokenHash] = &copied
+	return nil
+}
+
+func (r *memRefreshTokenRepo) GetByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	token, ok := r.tokens[tokenHash]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	copied := *token
+	return &copied, nil
+}
+
+func (r *memRefreshTokenRepo) Rotate(ctx context.Context, id uuid.UUID, replacedByID uuid.UUID, at time.Time) (bool, error) {
+	for _, token := range r.tokens {
+		if token.ID == id {
+			if token.RevokedAt != nil {
+				return false, nil
+			}
+			token.RevokedAt = &at
+			token.ReplacedByID = &replacedByID
+			return true, nil
+		}
+	}
+	return false, errors.New("record not found")
+}
+
+// age moves a rotated token's rotation time back past the retry grace period
+func (r *memRefreshTokenRepo) age(rawToken string) {
+	rotatedAt := time.Now().Add(-2 * refreshReuseGrace)
+	r.tokens[hashRefreshToken(rawToken)].RevokedAt = &rotatedAt
+}
+
+type authFixture struct {
+	service  *AuthServiceImpl
+	user     *models.User
+	sessions *memSessionRepo
+	tokens   *memRefreshTokenRepo
+}
+
+func newAuthFixture(t *testing.T) *authFixture {
+	t.Helper()
+	_, redisService := newTestRedis(t)
+	cfg := &config.Config{JWT: config.JWTConfig{Secret: "test-secret", AccessTokenTTL: 15, RefreshTokenTTL: 30}}
+
+	user := &models.User{ID: uuid.New(), Email: "owner@example.com", IsActive: true, Role: models.RoleUser}
+	sessions := &memSessionRepo{sessions: map[uuid.UUID]*models.Session{}}
+	tokens := &memRefreshTokenRepo{tokens: map[string]*models.RefreshToken{}}
+
+	service := NewAuthService(sessions, tokens, &stubUserRepo{user: user}, redisService, &fakeTxManager{}, cfg)
+	return &authFixture{service: service.(*AuthServiceImpl), user: user, sessions: sessions, tokens: tokens}
+}
+
+func (f *authFixture) signIn(t *testing.T) (*dto.TokenPair, uuid.UUID) {
+	t.Helper()
+	pair, err := f.service.IssueTokens(context.Background(), f.user, &dto.ClientInfo{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _ := f.tokens.GetByHash(context.Background(), hashRefreshToken(pair.RefreshToken))
+	return pair, token.SessionID
+}
+
+func TestRefreshRotatesToken(t *testing.T) {
+	f := newAuthFixture(t)
+	ctx := context.Background()
+	first, _ := f.signIn(t)
+
+	second, err := f.service.Refresh(ctx, first.RefreshToken, &dto.ClientInfo{})
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("refresh token was not rotated")
+	}
+	if _, err := f.service.Refresh(ctx, second.RefreshToken, &dto.ClientInfo{}); err != nil {
+		t.Fatalf("refresh with the successor: %v", err)
+	}
+}
+
+func TestRefreshRetryWithinGraceKeepsSession(t *testing.T) {
+	f := newAuthFixture(t)
+	ctx := context.Background()
+	first, sessionID := f.signIn(t)
+
+	if _, err := f.service.Refresh(ctx, first.RefreshToken, &dto.ClientInfo{}); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if _, err := f.service.Refresh(ctx, first.RefreshToken, &dto.ClientInfo{}); !errors.Is(err, errRefreshTokenRaced) {
+		t.Fatalf("immediate retry: got %v, want %v", err, errRefreshTokenRaced)
+	}
+
+	if f.sessions.sessions[sessionID].RevokedAt != nil {
+		t.Fatal("a client racing itself revoked its session")
+	}
+}
+
+func TestRefreshTokenReuseRevokesSession(t *testing.T) {
+	f := newAuthFixture(t)
+	ctx := context.Background()
+	first, sessionID := f.signIn(t)
+	other, otherSessionID := f.signIn(t)
+
+	second, err := f.service.Refresh(ctx, first.RefreshToken, &dto.ClientInfo{})
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	f.tokens.age(first.RefreshToken)
+
+	// The stolen copy comes back after the legitimate client rotated it
+	if _, err := f.service.Refresh(ctx, first.RefreshToken, &dto.ClientInfo{}); err == nil {
+		t.Fatal("rotated token accepted")
+	}
+
+	session := f.sessions.sessions[sessionID]
+	if session.RevokedAt == nil || session.RevokeReason != models.SessionRevokeReasonTokenReuse {
+		t.Fatalf("session not revoked for token reuse: %+v", session)
+	}
+	if active, err := f.service.IsSessionActive(ctx, sessionID); err != nil || active {
+		t.Fatalf("access tokens of the session still accepted (active %v, err %v)", active, err)
+	}
+
+	// Every holder of the session is signed out, including the one with the newest token
+	if _, err := f.service.Refresh(ctx, second.RefreshToken, &dto.ClientInfo{}); err == nil {
+		t.Fatal("successor token still works after reuse was detected")
+	}
+
+	// Other sessions of the user are not affected
+	if _, err := f.service.Refresh(ctx, other.RefreshToken, &dto.ClientInfo{}); err != nil {
+		t.Fatalf("unrelated session %s revoked: %v", otherSessionID, err)
+	}
+}
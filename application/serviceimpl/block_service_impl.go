@@ -103,6 +103,15 @@ func (s *BlockServiceImpl) GetBlockStatus(ctx context.Context, userID uuid.UUID,
 	}, nil
 }
 
+func (s *BlockServiceImpl) IsBlockedBetween(ctx context.Context, userID uuid.UUID, otherUserID uuid.UUID) (bool, error) {
+	isBlocked, isBlockedBy, err := s.blockRepo.GetBlockStatus(ctx, userID, otherUserID)
+	if err != nil {
+		return false, err
+	}
+
+	return isBlocked || isBlockedBy, nil
+}
+
 func (s *BlockServiceImpl) ListBlockedUsers(ctx context.Context, userID uuid.UUID, offset, limit int) (*dto.BlockedUsersResponse, error) {
 	// Set default pagination
 	if limit <= 0 || limit > 100 {
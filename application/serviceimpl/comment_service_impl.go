@@ -3,6 +3,7 @@ package serviceimpl
 import (
 	"context"
 	"errors"
+	"log"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,6 +18,7 @@ type CommentServiceImpl struct {
 	postRepo    repositories.PostRepository
 	voteRepo    repositories.VoteRepository
 	notifService services.NotificationService
+	txManager   repositories.TransactionManager
 }
 
 func NewCommentService(
@@ -24,12 +26,14 @@ func NewCommentService(
 	postRepo repositories.PostRepository,
 	voteRepo repositories.VoteRepository,
 	notifService services.NotificationService,
+	txManager repositories.TransactionManager,
 ) services.CommentService {
 	return &CommentServiceImpl{
 		commentRepo:  commentRepo,
 		postRepo:     postRepo,
 		voteRepo:     voteRepo,
 		notifService: notifService,
+		txManager:    txManager,
 	}
 }
 
@@ -72,41 +76,50 @@ func (s *CommentServiceImpl) CreateComment(ctx context.Context, userID uuid.UUID
 		UpdatedAt: time.Now(),
 	}
 
-	err = s.commentRepo.Create(ctx, comment)
-	if err != nil {
-		return nil, err
-	}
+	// Comment, counter and notification outbox are committed atomically
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.commentRepo.Create(ctx, comment); err != nil {
+			return err
+		}
 
-	// Increment post comment count
-	_ = s.postRepo.IncrementCommentCount(ctx, req.PostID)
-
-	// Send notification to post author or parent comment author
-	if req.ParentID != nil && parentComment != nil {
-		// Reply notification
-		if parentComment.AuthorID != userID {
-			_ = s.notifService.CreateNotification(
-				ctx,
-				parentComment.AuthorID,
-				userID,
-				"reply",
-				"ตอบกลับความคิดเห็นของคุณ",
-				&req.PostID,
-				&comment.ID,
-			)
+		// Increment post comment count
+		if err := s.postRepo.IncrementCommentCount(ctx, req.PostID); err != nil {
+			return err
 		}
-	} else {
-		// Comment notification to post author
-		if post.AuthorID != userID {
-			_ = s.notifService.CreateNotification(
-				ctx,
-				post.AuthorID,
-				userID,
-				"reply",
-				"แสดงความคิดเห็นในโพสต์ของคุณ",
-				&req.PostID,
-				&comment.ID,
-			)
+
+		// Send notification to post author or parent comment author
+		if req.ParentID != nil && parentComment != nil {
+			// Reply notification
+			if parentComment.AuthorID != userID {
+				return s.notifService.CreateNotification(
+					ctx,
+					parentComment.AuthorID,
+					userID,
+					"reply",
+					"ตอบกลับความคิดเห็นของคุณ",
+					&req.PostID,
+					&comment.ID,
+				)
+			}
+		} else {
+			// Comment notification to post author
+			if post.AuthorID != userID {
+				return s.notifService.CreateNotification(
+					ctx,
+					post.AuthorID,
+					userID,
+					"reply",
+					"แสดงความคิดเห็นในโพสต์ของคุณ",
+					&req.PostID,
+					&comment.ID,
+				)
+			}
 		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return s.GetComment(ctx, comment.ID, &userID)
@@ -161,7 +174,7 @@ func (s *CommentServiceImpl) UpdateComment(ctx context.Context, commentID uuid.U
 	return s.GetComment(ctx, commentID, &userID)
 }
 
-func (s *CommentServiceImpl) DeleteComment(ctx context.Context, commentID uuid.UUID, userID uuid.UUID) error {
+func (s *CommentServiceImpl) DeleteComment(ctx context.Context, commentID uuid.UUID, actor *dto.Actor) error {
 	// Get comment
 	comment, err := s.commentRepo.GetByID(ctx, commentID)
 	if err != nil {
@@ -169,8 +182,11 @@ func (s *CommentServiceImpl) DeleteComment(ctx context.Context, commentID uuid.U
 	}
 
 	// Check ownership
-	if comment.AuthorID != userID {
-		return errors.New("unauthorized: not comment owner")
+	if comment.AuthorID != actor.UserID {
+		if !actor.Can(models.PermissionCommentRemoveAny) {
+			return errors.New("unauthorized: not comment owner")
+		}
+		log.Printf("🛡️ Comment %s of user %s removed by %s", commentID, comment.AuthorID, actor.UserID)
 	}
 
 	// Soft delete
@@ -21,8 +21,20 @@ type ConversationServiceImpl struct {
 	userRepo         repositories.UserRepository
 	followRepo       repositories.FollowRepository
 	redisService     *redisInfra.RedisService
+	txManager        repositories.TransactionManager
+	receiptRepo      repositories.MessageReceiptRepository
+	chatSettingsRepo repositories.ChatSettingsRepository
+	reportRepo       repositories.ConversationReportRepository
 }
 
+const (
+	// maxGroupParticipants caps group size (including the owner)
+	maxGroupParticipants = 256
+
+	// maxPinnedConversations caps how many conversations a user can pin
+	maxPinnedConversations = 5
+)
+
 func NewConversationService(
 	conversationRepo repositories.ConversationRepository,
 	messageRepo repositories.MessageRepository,
@@ -30,6 +42,10 @@ func NewConversationService(
 	userRepo repositories.UserRepository,
 	followRepo repositories.FollowRepository,
 	redisService *redisInfra.RedisService,
+	txManager repositories.TransactionManager,
+	receiptRepo repositories.MessageReceiptRepository,
+	chatSettingsRepo repositories.ChatSettingsRepository,
+	reportRepo repositories.ConversationReportRepository,
 ) services.ConversationService {
 	return &ConversationServiceImpl{
 		conversationRepo: conversationRepo,
@@ -38,6 +54,10 @@ func NewConversationService(
 		userRepo:         userRepo,
 		followRepo:       followRepo,
 		redisService:     redisService,
+		txManager:        txManager,
+		receiptRepo:      receiptRepo,
+		chatSettingsRepo: chatSettingsRepo,
+		reportRepo:       reportRepo,
 	}
 }
 
@@ -63,8 +83,18 @@ func (s *ConversationServiceImpl) GetOrCreateConversation(ctx context.Context, u
 		return nil, errors.New("cannot start conversation: user is blocked")
 	}
 
+	// Existing conversations keep their state; a new one is checked against
+	// the other user's DM policy and may start as a message request
+	var requesterID *uuid.UUID
+	if _, err := s.conversationRepo.GetByUsers(ctx, userID, otherUser.ID); err != nil {
+		requesterID, err = s.checkDMPolicy(ctx, userID, otherUser.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Get or create conversation
-	conversation, _, err := s.conversationRepo.GetOrCreateByUsers(ctx, userID, otherUser.ID)
+	conversation, _, err := s.conversationRepo.GetOrCreateByUsers(ctx, userID, otherUser.ID, requesterID)
 	if err != nil {
 		return nil, err
 	}
@@ -73,12 +103,7 @@ func (s *ConversationServiceImpl) GetOrCreateConversation(ctx context.Context, u
 	resp := dto.ConversationToConversationResponse(conversation, userID)
 
 	// Load last message if exists
-	if conversation.LastMessageID != nil {
-		lastMsg, err := s.messageRepo.GetByID(ctx, *conversation.LastMessageID)
-		if err == nil {
-			resp.LastMessage = dto.MessageToMessageResponse(lastMsg)
-		}
-	}
+	resp.LastMessage = s.lastVisibleMessage(ctx, conversation, userID)
 
 	return resp, nil
 }
@@ -90,7 +115,7 @@ func (s *ConversationServiceImpl) GetConversation(ctx context.Context, conversat
 	}
 
 	// Check if user is participant
-	if conversation.User1ID != userID && conversation.User2ID != userID {
+	if !conversation.HasParticipant(userID) {
 		return nil, errors.New("access denied: not a participant")
 	}
 
@@ -98,17 +123,35 @@ func (s *ConversationServiceImpl) GetConversation(ctx context.Context, conversat
 	resp := dto.ConversationToConversationResponse(conversation, userID)
 
 	// Load last message if exists
-	if conversation.LastMessageID != nil {
-		lastMsg, err := s.messageRepo.GetByID(ctx, *conversation.LastMessageID)
-		if err == nil {
-			resp.LastMessage = dto.MessageToMessageResponse(lastMsg)
+	resp.LastMessage = s.lastVisibleMessage(ctx, conversation, userID)
+
+	return resp, nil
+}
+
+func (s *ConversationServiceImpl) ListConversations(ctx context.Context, userID uuid.UUID, cursorStr *string, limit int) (*dto.ConversationListResponse, error) {
+	resp, err := s.listConversations(ctx, userID, models.ConversationFolderInbox, cursorStr, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pinned conversations lead the first page and are not paginated
+	if cursorStr == nil || *cursorStr == "" {
+		pinned, err := s.conversationRepo.ListByUser(ctx, userID, models.ConversationFolderPinned, nil, maxPinnedConversations)
+		if err != nil {
+			return nil, err
 		}
+		resp.Conversations = append(s.toConversationResponses(ctx, userID, pinned), resp.Conversations...)
 	}
 
 	return resp, nil
 }
 
-func (s *ConversationServiceImpl) ListConversations(ctx context.Context, userID uuid.UUID, cursorStr *string, limit int) (*dto.ConversationListResponse, error) {
+func (s *ConversationServiceImpl) ListArchivedConversations(ctx context.Context, userID uuid.UUID, cursorStr *string, limit int) (*dto.ConversationListResponse, error) {
+	return s.listConversations(ctx, userID, models.ConversationFolderArchived, cursorStr, limit)
+}
+
+// listConversations pages through one folder ordered by latest message
+func (s *ConversationServiceImpl) listConversations(ctx context.Context, userID uuid.UUID, folder models.ConversationFolder, cursorStr *string, limit int) (*dto.ConversationListResponse, error) {
 	// Decode cursor if provided
 	var cursor *time.Time
 	if cursorStr != nil && *cursorStr != "" {
@@ -125,7 +168,7 @@ func (s *ConversationServiceImpl) ListConversations(ctx context.Context, userID
 	}
 
 	// Fetch conversations (limit + 1 to check for more)
-	conversations, err := s.conversationRepo.ListByUser(ctx, userID, cursor, limit+1)
+	conversations, err := s.conversationRepo.ListByUser(ctx, userID, folder, cursor, limit+1)
 	if err != nil {
 		return nil, err
 	}
@@ -136,26 +179,6 @@ func (s *ConversationServiceImpl) ListConversations(ctx context.Context, userID
 		conversations = conversations[:limit]
 	}
 
-	// Convert to DTOs
-	conversationResponses := make([]dto.ConversationResponse, len(conversations))
-	for i, conv := range conversations {
-		resp := dto.ConversationToConversationResponse(conv, userID)
-
-		// Fetch last message from database (always use DB for complete data)
-		if conv.LastMessageID != nil {
-			lastMsg, err := s.messageRepo.GetByID(ctx, *conv.LastMessageID)
-			if err == nil {
-				resp.LastMessage = dto.MessageToMessageResponse(lastMsg)
-			}
-		}
-
-		// Get unread count from Redis
-		unreadCount, _ := s.redisService.GetConversationUnreadCount(ctx, userID, conv.ID)
-		resp.UnreadCount = unreadCount
-
-		conversationResponses[i] = *resp
-	}
-
 	// Generate next cursor
 	var nextCursor *string
 	if hasMore && len(conversations) > 0 {
@@ -167,12 +190,44 @@ func (s *ConversationServiceImpl) ListConversations(ctx context.Context, userID
 	}
 
 	return &dto.ConversationListResponse{
-		Conversations: conversationResponses,
+		Conversations: s.toConversationResponses(ctx, userID, conversations),
 		NextCursor:    nextCursor,
 		HasMore:       hasMore,
 	}, nil
 }
 
+// lastVisibleMessage returns the newest message the viewer hasn't deleted for themselves,
+// which is not necessarily the conversation's last message
+func (s *ConversationServiceImpl) lastVisibleMessage(ctx context.Context, conversation *models.Conversation, viewerID uuid.UUID) *dto.MessageResponse {
+	if conversation.LastMessageID == nil {
+		return nil
+	}
+
+	messages, err := s.messageRepo.ListByConversation(ctx, conversation.ID, viewerID, nil, 1)
+	if err != nil || len(messages) == 0 {
+		return nil
+	}
+	return dto.MessageToMessageResponse(messages[0])
+}
+
+// toConversationResponses converts conversations to DTOs with their last message and cached unread count
+func (s *ConversationServiceImpl) toConversationResponses(ctx context.Context, userID uuid.UUID, conversations []*models.Conversation) []dto.ConversationResponse {
+	conversationResponses := make([]dto.ConversationResponse, len(conversations))
+	for i, conv := range conversations {
+		resp := dto.ConversationToConversationResponse(conv, userID)
+
+		// Fetch last message from database (always use DB for complete data)
+		resp.LastMessage = s.lastVisibleMessage(ctx, conv, userID)
+
+		// Get unread count from Redis
+		unreadCount, _ := s.redisService.GetConversationUnreadCount(ctx, userID, conv.ID)
+		resp.UnreadCount = unreadCount
+
+		conversationResponses[i] = *resp
+	}
+	return conversationResponses
+}
+
 func (s *ConversationServiceImpl) GetUnreadCount(ctx context.Context, userID uuid.UUID) (*dto.UnreadCountResponse, error) {
 	// Try Redis first
 	count, err := s.redisService.GetTotalUnreadCount(ctx, userID)
@@ -196,39 +251,150 @@ func (s *ConversationServiceImpl) GetUnreadCount(ctx context.Context, userID uui
 	}, nil
 }
 
-func (s *ConversationServiceImpl) MarkAsRead(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) error {
+func (s *ConversationServiceImpl) MarkAsRead(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, lastReadMessageID *uuid.UUID) (*dto.ReadReceiptUpdate, error) {
 	// Verify user is participant
 	conversation, err := s.conversationRepo.GetByID(ctx, conversationID)
 	if err != nil {
-		return errors.New("conversation not found")
+		return nil, errors.New("conversation not found")
 	}
 
-	if conversation.User1ID != userID && conversation.User2ID != userID {
-		return errors.New("access denied: not a participant")
+	if !conversation.HasParticipant(userID) {
+		return nil, errors.New("access denied: not a participant")
 	}
 
-	// Get current unread count from Redis before marking
-	unreadCount, _ := s.redisService.GetConversationUnreadCount(ctx, userID, conversationID)
+	update := &dto.ReadReceiptUpdate{
+		ConversationID: conversationID,
+		ReadBy:         userID,
+		ReadAt:         time.Now(),
+	}
 
-	// Mark all messages as read
-	if err := s.messageRepo.MarkAllAsRead(ctx, conversationID, userID); err != nil {
-		return err
+	// Default read position is the latest message
+	targetID := lastReadMessageID
+	if targetID == nil {
+		targetID = conversation.LastMessageID
+	}
+	if targetID == nil {
+		// Nothing to read yet
+		return update, nil
 	}
 
-	// Reset unread count in conversation
-	if err := s.conversationRepo.ResetUnreadCount(ctx, conversationID, userID); err != nil {
-		return err
+	target, err := s.messageRepo.GetByID(ctx, *targetID)
+	if err != nil || target.ConversationID != conversationID {
+		return nil, errors.New("message not found")
 	}
 
-	// Update Redis
-	if unreadCount > 0 {
-		// Decrement total unread
-		_ = s.redisService.DecrementTotalUnread(ctx, userID, unreadCount)
-		// Reset conversation unread (returns count but we ignore it)
-		_, _ = s.redisService.ResetConversationUnread(ctx, userID, conversationID)
+	// Never move the read position backwards
+	var after *time.Time
+	if participant := conversation.Participant(userID); participant != nil && participant.LastReadMessageID != nil {
+		if previous, err := s.messageRepo.GetByID(ctx, *participant.LastReadMessageID); err == nil {
+			after = &previous.CreatedAt
+			if !target.CreatedAt.After(previous.CreatedAt) {
+				target = previous
+			}
+		}
 	}
 
-	return nil
+	var newlyRead []*models.Message
+	var unreadCount int64
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		newlyRead, err = s.receiptRepo.MarkRead(ctx, conversationID, userID, after, target.CreatedAt, update.ReadAt)
+		if err != nil {
+			return err
+		}
+
+		if err := s.messageRepo.MarkReadUntil(ctx, conversationID, userID, target.CreatedAt); err != nil {
+			return err
+		}
+
+		unreadCount, err = s.messageRepo.CountFromOthersAfter(ctx, conversationID, userID, target.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		return s.conversationRepo.UpdateReadPosition(ctx, conversationID, userID, target.ID, int(unreadCount))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Update Redis (received message requests are not part of the total)
+	previous, _ := s.redisService.SetConversationUnread(ctx, userID, conversationID, int(unreadCount))
+	if !conversation.IsRequestFor(userID) {
+		_ = s.redisService.DecrementTotalUnread(ctx, userID, previous-int(unreadCount))
+	}
+
+	update.LastReadMessageID = &target.ID
+	update.UnreadCount = int(unreadCount)
+	update.NotifyUserIDs = s.readReceiptRecipients(ctx, userID, newlyRead)
+
+	return update, nil
+}
+
+// readReceiptRecipients returns the senders allowed to see that readerID read their messages.
+// Receipts are reciprocal: nobody is told when the reader has them off, and senders
+// with them off are not told either.
+func (s *ConversationServiceImpl) readReceiptRecipients(ctx context.Context, readerID uuid.UUID, messages []*models.Message) []uuid.UUID {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	userIDs := []uuid.UUID{readerID}
+	for _, message := range messages {
+		if !seen[message.SenderID] {
+			seen[message.SenderID] = true
+			userIDs = append(userIDs, message.SenderID)
+		}
+	}
+
+	disabled, err := s.chatSettingsRepo.ListReadReceiptsDisabled(ctx, userIDs)
+	if err != nil || disabled[readerID] {
+		return nil
+	}
+
+	recipients := make([]uuid.UUID, 0, len(userIDs)-1)
+	for _, senderID := range userIDs[1:] {
+		if !disabled[senderID] {
+			recipients = append(recipients, senderID)
+		}
+	}
+	return recipients
+}
+
+func (s *ConversationServiceImpl) GetChatSettings(ctx context.Context, userID uuid.UUID) (*dto.ChatSettingsResponse, error) {
+	settings, err := s.chatSettingsRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		// Not saved yet: use defaults
+		return dto.ChatSettingsToResponse(models.DefaultChatSettings(userID)), nil
+	}
+
+	return dto.ChatSettingsToResponse(settings), nil
+}
+
+func (s *ConversationServiceImpl) UpdateChatSettings(ctx context.Context, userID uuid.UUID, req *dto.UpdateChatSettingsRequest) (*dto.ChatSettingsResponse, error) {
+	settings, err := s.chatSettingsRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		settings = models.DefaultChatSettings(userID)
+	}
+
+	if req.ReadReceipts != nil {
+		settings.ReadReceipts = *req.ReadReceipts
+	}
+	if req.DMPolicy != nil {
+		switch *req.DMPolicy {
+		case models.DMPolicyEveryone, models.DMPolicyFollowers, models.DMPolicyNobody:
+			settings.DMPolicy = *req.DMPolicy
+		default:
+			return nil, errors.New("invalid dm policy")
+		}
+	}
+
+	if err := s.chatSettingsRepo.Upsert(ctx, settings); err != nil {
+		return nil, err
+	}
+
+	return dto.ChatSettingsToResponse(settings), nil
 }
 
 func (s *ConversationServiceImpl) SearchUsersForChat(ctx context.Context, userID uuid.UUID, query string, limit int) (*dto.ChatUserSearchResponse, error) {
@@ -322,5 +488,616 @@ func (s *ConversationServiceImpl) SearchUsersForChat(ctx context.Context, userID
 	}, nil
 }
 
+// ==================== Group Conversations ====================
+
+func (s *ConversationServiceImpl) CreateGroup(ctx context.Context, userID uuid.UUID, req *dto.CreateGroupRequest) (*dto.ConversationResponse, error) {
+	memberIDs, err := s.resolveNewMembers(ctx, userID, req.Usernames)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(memberIDs)+1 > maxGroupParticipants {
+		return nil, errors.New("group is full")
+	}
+
+	now := time.Now()
+	title := req.Title
+	conversation := &models.Conversation{
+		Type:          models.ConversationTypeGroup,
+		Title:         &title,
+		Avatar:        req.Avatar,
+		OwnerID:       &userID,
+		User1ID:       userID,
+		User2ID:       userID,
+		LastMessageAt: now,
+	}
+
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.conversationRepo.Create(ctx, conversation); err != nil {
+			return err
+		}
+		if err := s.conversationRepo.AddParticipants(ctx, conversation.ID, []uuid.UUID{userID}, models.ParticipantRoleOwner); err != nil {
+			return err
+		}
+		return s.conversationRepo.AddParticipants(ctx, conversation.ID, memberIDs, models.ParticipantRoleMember)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetConversation(ctx, conversation.ID, userID)
+}
+
+func (s *ConversationServiceImpl) UpdateGroup(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, req *dto.UpdateGroupRequest) (*dto.ConversationResponse, error) {
+	conversation, actor, err := s.getGroupAsParticipant(ctx, conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !actor.CanManage() {
+		return nil, errors.New("access denied: only group admins can update the group")
+	}
+
+	updates := &models.Conversation{}
+	if req.Title != nil {
+		updates.Title = req.Title
+	}
+	if req.Avatar != nil {
+		updates.Avatar = req.Avatar
+	}
+
+	if err := s.conversationRepo.Update(ctx, conversation.ID, updates); err != nil {
+		return nil, err
+	}
+
+	return s.GetConversation(ctx, conversation.ID, userID)
+}
+
+func (s *ConversationServiceImpl) ListParticipants(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) (*dto.ConversationParticipantListResponse, error) {
+	conversation, err := s.conversationRepo.GetByID(ctx, conversationID)
+	if err != nil {
+		return nil, errors.New("conversation not found")
+	}
+
+	if !conversation.HasParticipant(userID) {
+		return nil, errors.New("access denied: not a participant")
+	}
+
+	participants := make([]dto.ConversationParticipantResponse, len(conversation.Participants))
+	for i := range conversation.Participants {
+		participants[i] = *dto.ConversationParticipantToResponse(&conversation.Participants[i])
+	}
+
+	return &dto.ConversationParticipantListResponse{
+		Participants: participants,
+	}, nil
+}
+
+func (s *ConversationServiceImpl) AddParticipants(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, req *dto.AddParticipantsRequest) (*dto.ConversationResponse, error) {
+	conversation, actor, err := s.getGroupAsParticipant(ctx, conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !actor.CanManage() {
+		return nil, errors.New("access denied: only group admins can add members")
+	}
+
+	memberIDs, err := s.resolveNewMembers(ctx, userID, req.Usernames)
+	if err != nil {
+		return nil, err
+	}
+
+	// Skip users who are already members
+	newMemberIDs := make([]uuid.UUID, 0, len(memberIDs))
+	for _, memberID := range memberIDs {
+		if conversation.Participant(memberID) == nil {
+			newMemberIDs = append(newMemberIDs, memberID)
+		}
+	}
+
+	if len(conversation.Participants)+len(newMemberIDs) > maxGroupParticipants {
+		return nil, errors.New("group is full")
+	}
+
+	if err := s.conversationRepo.AddParticipants(ctx, conversationID, newMemberIDs, models.ParticipantRoleMember); err != nil {
+		return nil, err
+	}
+
+	return s.GetConversation(ctx, conversationID, userID)
+}
+
+func (s *ConversationServiceImpl) RemoveParticipant(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, targetUserID uuid.UUID) error {
+	if userID == targetUserID {
+		return s.LeaveGroup(ctx, conversationID, userID)
+	}
+
+	conversation, actor, err := s.getGroupAsParticipant(ctx, conversationID, userID)
+	if err != nil {
+		return err
+	}
+
+	target := conversation.Participant(targetUserID)
+	if target == nil {
+		return errors.New("user is not a participant")
+	}
+
+	// Admins remove members; only the owner removes admins; nobody removes the owner
+	switch {
+	case !actor.CanManage():
+		return errors.New("access denied: only group admins can remove members")
+	case target.Role == models.ParticipantRoleOwner:
+		return errors.New("cannot remove the group owner")
+	case target.Role == models.ParticipantRoleAdmin && actor.Role != models.ParticipantRoleOwner:
+		return errors.New("access denied: only the owner can remove admins")
+	}
+
+	if err := s.conversationRepo.RemoveParticipant(ctx, conversationID, targetUserID); err != nil {
+		return err
+	}
+
+	s.clearUnread(ctx, targetUserID, conversationID)
+	return nil
+}
+
+func (s *ConversationServiceImpl) UpdateParticipantRole(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, targetUserID uuid.UUID, role string) (*dto.ConversationResponse, error) {
+	conversation, actor, err := s.getGroupAsParticipant(ctx, conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if actor.Role != models.ParticipantRoleOwner {
+		return nil, errors.New("access denied: only the owner can change roles")
+	}
+
+	target := conversation.Participant(targetUserID)
+	if target == nil {
+		return nil, errors.New("user is not a participant")
+	}
+
+	if targetUserID == userID {
+		return nil, errors.New("cannot change your own role")
+	}
+
+	newRole := models.ParticipantRole(role)
+	switch newRole {
+	case models.ParticipantRoleOwner:
+		// Transfer ownership: the current owner becomes an admin
+		err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+			if err := s.conversationRepo.UpdateParticipantRole(ctx, conversationID, targetUserID, models.ParticipantRoleOwner); err != nil {
+				return err
+			}
+			if err := s.conversationRepo.UpdateParticipantRole(ctx, conversationID, userID, models.ParticipantRoleAdmin); err != nil {
+				return err
+			}
+			return s.conversationRepo.Update(ctx, conversationID, &models.Conversation{OwnerID: &targetUserID})
+		})
+	case models.ParticipantRoleAdmin, models.ParticipantRoleMember:
+		err = s.conversationRepo.UpdateParticipantRole(ctx, conversationID, targetUserID, newRole)
+	default:
+		return nil, errors.New("invalid role")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetConversation(ctx, conversationID, userID)
+}
+
+func (s *ConversationServiceImpl) LeaveGroup(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) error {
+	conversation, actor, err := s.getGroupAsParticipant(ctx, conversationID, userID)
+	if err != nil {
+		return err
+	}
+
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.conversationRepo.RemoveParticipant(ctx, conversationID, userID); err != nil {
+			return err
+		}
+
+		if actor.Role != models.ParticipantRoleOwner {
+			return nil
+		}
+
+		// Hand ownership to the longest-serving admin, otherwise the longest-serving member
+		successor := s.pickSuccessor(conversation, userID)
+		if successor == nil {
+			return nil
+		}
+		if err := s.conversationRepo.UpdateParticipantRole(ctx, conversationID, successor.UserID, models.ParticipantRoleOwner); err != nil {
+			return err
+		}
+		return s.conversationRepo.Update(ctx, conversationID, &models.Conversation{OwnerID: &successor.UserID})
+	})
+	if err != nil {
+		return err
+	}
+
+	s.clearUnread(ctx, userID, conversationID)
+	return nil
+}
+
+// ==================== Conversation Management ====================
+
+func (s *ConversationServiceImpl) PinConversation(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, pinned bool) (*dto.ConversationResponse, error) {
+	_, participant, err := s.getAsParticipant(ctx, conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var pinnedAt *time.Time
+	if pinned {
+		if participant.PinnedAt != nil {
+			return s.GetConversation(ctx, conversationID, userID)
+		}
+
+		count, err := s.conversationRepo.CountPinnedByUser(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if count >= maxPinnedConversations {
+			return nil, errors.New("pinned conversation limit reached")
+		}
+
+		now := time.Now()
+		pinnedAt = &now
+	}
+
+	if err := s.conversationRepo.SetPinned(ctx, conversationID, userID, pinnedAt); err != nil {
+		return nil, err
+	}
+
+	return s.GetConversation(ctx, conversationID, userID)
+}
+
+func (s *ConversationServiceImpl) ArchiveConversation(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, archived bool) (*dto.ConversationResponse, error) {
+	if _, _, err := s.getAsParticipant(ctx, conversationID, userID); err != nil {
+		return nil, err
+	}
+
+	var archivedAt *time.Time
+	if archived {
+		now := time.Now()
+		archivedAt = &now
+	}
+
+	if err := s.conversationRepo.SetArchived(ctx, conversationID, userID, archivedAt); err != nil {
+		return nil, err
+	}
+
+	return s.GetConversation(ctx, conversationID, userID)
+}
+
+func (s *ConversationServiceImpl) MuteConversation(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, req *dto.MuteConversationRequest) (*dto.ConversationResponse, error) {
+	if _, _, err := s.getAsParticipant(ctx, conversationID, userID); err != nil {
+		return nil, err
+	}
+
+	// No duration mutes until the user unmutes
+	var mutedUntil *time.Time
+	if req.DurationMinutes != nil {
+		until := time.Now().Add(time.Duration(*req.DurationMinutes) * time.Minute)
+		mutedUntil = &until
+	}
+
+	if err := s.conversationRepo.SetMuted(ctx, conversationID, userID, true, mutedUntil); err != nil {
+		return nil, err
+	}
+
+	return s.GetConversation(ctx, conversationID, userID)
+}
+
+func (s *ConversationServiceImpl) UnmuteConversation(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) (*dto.ConversationResponse, error) {
+	if _, _, err := s.getAsParticipant(ctx, conversationID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.conversationRepo.SetMuted(ctx, conversationID, userID, false, nil); err != nil {
+		return nil, err
+	}
+
+	return s.GetConversation(ctx, conversationID, userID)
+}
+
+func (s *ConversationServiceImpl) DeleteConversationForMe(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) error {
+	if _, _, err := s.getAsParticipant(ctx, conversationID, userID); err != nil {
+		return err
+	}
+
+	// Other participants keep their history; the conversation comes back with the next message
+	if err := s.conversationRepo.ClearHistory(ctx, conversationID, userID, time.Now()); err != nil {
+		return err
+	}
+
+	s.clearUnread(ctx, userID, conversationID)
+	return nil
+}
+
+// ==================== Message Requests ====================
+
+func (s *ConversationServiceImpl) ListMessageRequests(ctx context.Context, userID uuid.UUID, cursorStr *string, limit int) (*dto.ConversationListResponse, error) {
+	// Decode cursor if provided
+	var cursor *time.Time
+	if cursorStr != nil && *cursorStr != "" {
+		decoded, err := utils.DecodeCursor(*cursorStr)
+		if err != nil {
+			return nil, errors.New("invalid cursor")
+		}
+		cursor = decoded
+	}
+
+	// Set default limit
+	if limit <= 0 || limit > 50 {
+		limit = 20
+	}
+
+	// Fetch requests (limit + 1 to check for more)
+	conversations, err := s.conversationRepo.ListRequestsByUser(ctx, userID, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	hasMore := len(conversations) > limit
+	if hasMore {
+		conversations = conversations[:limit]
+	}
+
+	conversationResponses := make([]dto.ConversationResponse, len(conversations))
+	for i, conv := range conversations {
+		resp := dto.ConversationToConversationResponse(conv, userID)
+
+		resp.LastMessage = s.lastVisibleMessage(ctx, conv, userID)
+
+		conversationResponses[i] = *resp
+	}
+
+	// Generate next cursor
+	var nextCursor *string
+	if hasMore && len(conversations) > 0 {
+		encoded, err := utils.EncodeCursor(conversations[len(conversations)-1].LastMessageAt)
+		if err == nil {
+			nextCursor = &encoded
+		}
+	}
+
+	return &dto.ConversationListResponse{
+		Conversations: conversationResponses,
+		NextCursor:    nextCursor,
+		HasMore:       hasMore,
+	}, nil
+}
+
+func (s *ConversationServiceImpl) CountMessageRequests(ctx context.Context, userID uuid.UUID) (*dto.MessageRequestCountResponse, error) {
+	count, err := s.conversationRepo.CountRequestsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.MessageRequestCountResponse{Count: count}, nil
+}
+
+func (s *ConversationServiceImpl) AcceptMessageRequest(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) (*dto.ConversationResponse, error) {
+	conversation, err := s.getReceivedRequest(ctx, conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.conversationRepo.UpdateRequestStatus(ctx, conversationID, models.ConversationRequestAccepted); err != nil {
+		return nil, err
+	}
+
+	// Its unread messages now count toward the inbox total
+	unreadCount, _ := s.redisService.GetConversationUnreadCount(ctx, userID, conversationID)
+	_ = s.redisService.AddTotalUnread(ctx, userID, unreadCount)
+
+	conversation.RequestStatus = models.ConversationRequestAccepted
+	return dto.ConversationToConversationResponse(conversation, userID), nil
+}
+
+func (s *ConversationServiceImpl) DeclineMessageRequest(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) error {
+	if _, err := s.getReceivedRequest(ctx, conversationID, userID); err != nil {
+		return err
+	}
+
+	if err := s.conversationRepo.UpdateRequestStatus(ctx, conversationID, models.ConversationRequestDeclined); err != nil {
+		return err
+	}
+
+	// Requests are not part of the total, so only the conversation count is dropped
+	_ = s.conversationRepo.ResetUnreadCount(ctx, conversationID, userID)
+	_, _ = s.redisService.ResetConversationUnread(ctx, userID, conversationID)
+	return nil
+}
+
+func (s *ConversationServiceImpl) ReportMessageRequest(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, req *dto.ReportConversationRequest) error {
+	conversation, err := s.getReceivedRequest(ctx, conversationID, userID)
+	if err != nil {
+		return err
+	}
+
+	report := &models.ConversationReport{
+		ConversationID: conversationID,
+		ReporterID:     userID,
+		ReportedUserID: *conversation.RequesterID,
+		Reason:         req.Reason,
+		Details:        req.Details,
+		CreatedAt:      time.Now(),
+	}
+	if err := s.reportRepo.Create(ctx, report); err != nil {
+		return err
+	}
+
+	if conversation.RequestStatus == models.ConversationRequestPending {
+		if err := s.DeclineMessageRequest(ctx, conversationID, userID); err != nil {
+			return err
+		}
+	}
+
+	if req.Block {
+		isBlocked, err := s.blockRepo.IsBlocked(ctx, userID, *conversation.RequesterID)
+		if err != nil {
+			return err
+		}
+		if !isBlocked {
+			return s.blockRepo.Create(ctx, &models.Block{
+				BlockerID: userID,
+				BlockedID: *conversation.RequesterID,
+				CreatedAt: time.Now(),
+			})
+		}
+	}
+
+	return nil
+}
+
+// getReceivedRequest loads a message request sent to userID
+func (s *ConversationServiceImpl) getReceivedRequest(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) (*models.Conversation, error) {
+	conversation, err := s.conversationRepo.GetByID(ctx, conversationID)
+	if err != nil {
+		return nil, errors.New("conversation not found")
+	}
+
+	if !conversation.HasParticipant(userID) {
+		return nil, errors.New("access denied: not a participant")
+	}
+
+	if !conversation.IsRequestFor(userID) {
+		return nil, errors.New("conversation is not a message request")
+	}
+
+	return conversation, nil
+}
+
+// checkDMPolicy decides whether senderID may start a conversation with recipientID.
+// Returns the requester to record when it must go through the requests inbox.
+func (s *ConversationServiceImpl) checkDMPolicy(ctx context.Context, senderID uuid.UUID, recipientID uuid.UUID) (*uuid.UUID, error) {
+	// People the recipient follows go straight to the inbox
+	followed, err := s.followRepo.IsFollowing(ctx, recipientID, senderID)
+	if err != nil {
+		return nil, err
+	}
+	if followed {
+		return nil, nil
+	}
+
+	policy := models.DMPolicyEveryone
+	if settings, err := s.chatSettingsRepo.GetByUserID(ctx, recipientID); err == nil {
+		policy = settings.DMPolicy
+	}
+
+	switch policy {
+	case models.DMPolicyNobody:
+		return nil, errors.New("this user is not accepting messages")
+	case models.DMPolicyFollowers:
+		follower, err := s.followRepo.IsFollowing(ctx, senderID, recipientID)
+		if err != nil {
+			return nil, err
+		}
+		if !follower {
+			return nil, errors.New("this user only accepts messages from followers")
+		}
+	}
+
+	return &senderID, nil
+}
+
+// getAsParticipant loads a conversation in the caller's inbox and their participant row
+func (s *ConversationServiceImpl) getAsParticipant(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) (*models.Conversation, *models.ConversationParticipant, error) {
+	conversation, err := s.conversationRepo.GetByID(ctx, conversationID)
+	if err != nil {
+		return nil, nil, errors.New("conversation not found")
+	}
+
+	participant := conversation.Participant(userID)
+	if participant == nil {
+		return nil, nil, errors.New("access denied: not a participant")
+	}
+
+	// Received message requests are managed from the requests inbox
+	if conversation.IsRequestFor(userID) {
+		return nil, nil, errors.New("message request has not been accepted")
+	}
+
+	return conversation, participant, nil
+}
+
+// getGroupAsParticipant loads a group conversation and the caller's participant row
+func (s *ConversationServiceImpl) getGroupAsParticipant(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) (*models.Conversation, *models.ConversationParticipant, error) {
+	conversation, err := s.conversationRepo.GetByID(ctx, conversationID)
+	if err != nil {
+		return nil, nil, errors.New("conversation not found")
+	}
+
+	if !conversation.IsGroup() {
+		return nil, nil, errors.New("conversation is not a group")
+	}
+
+	participant := conversation.Participant(userID)
+	if participant == nil {
+		return nil, nil, errors.New("access denied: not a participant")
+	}
+
+	return conversation, participant, nil
+}
+
+// resolveNewMembers looks up usernames and rejects users blocked by or blocking the caller
+func (s *ConversationServiceImpl) resolveNewMembers(ctx context.Context, userID uuid.UUID, usernames []string) ([]uuid.UUID, error) {
+	seen := make(map[uuid.UUID]bool)
+	memberIDs := make([]uuid.UUID, 0, len(usernames))
+
+	for _, username := range usernames {
+		user, err := s.userRepo.GetByUsername(ctx, username)
+		if err != nil {
+			return nil, errors.New("user not found: " + username)
+		}
+
+		if user.ID == userID || seen[user.ID] {
+			continue
+		}
+
+		blocked, blockedBy, err := s.blockRepo.GetBlockStatus(ctx, userID, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if blocked || blockedBy {
+			return nil, errors.New("cannot add user: user is blocked")
+		}
+
+		seen[user.ID] = true
+		memberIDs = append(memberIDs, user.ID)
+	}
+
+	return memberIDs, nil
+}
+
+// pickSuccessor chooses the next owner when the owner leaves
+func (s *ConversationServiceImpl) pickSuccessor(conversation *models.Conversation, leavingUserID uuid.UUID) *models.ConversationParticipant {
+	var successor *models.ConversationParticipant
+	for i := range conversation.Participants {
+		candidate := &conversation.Participants[i]
+		if candidate.UserID == leavingUserID || candidate.LeftAt != nil {
+			continue
+		}
+
+		switch {
+		case successor == nil:
+			successor = candidate
+		case candidate.Role == models.ParticipantRoleAdmin && successor.Role != models.ParticipantRoleAdmin:
+			successor = candidate
+		case candidate.Role == successor.Role && candidate.JoinedAt.Before(successor.JoinedAt):
+			successor = candidate
+		}
+	}
+	return successor
+}
+
+// clearUnread drops a participant's cached unread count (after leaving or deleting the conversation)
+func (s *ConversationServiceImpl) clearUnread(ctx context.Context, userID uuid.UUID, conversationID uuid.UUID) {
+	unreadCount, _ := s.redisService.ResetConversationUnread(ctx, userID, conversationID)
+	if unreadCount > 0 {
+		_ = s.redisService.DecrementTotalUnread(ctx, userID, unreadCount)
+	}
+}
+
 // Ensure interface compliance
 var _ services.ConversationService = (*ConversationServiceImpl)(nil)
@@ -15,17 +15,20 @@ type FollowServiceImpl struct {
 	followRepo   repositories.FollowRepository
 	userRepo     repositories.UserRepository
 	notifService services.NotificationService
+	txManager    repositories.TransactionManager
 }
 
 func NewFollowService(
 	followRepo repositories.FollowRepository,
 	userRepo repositories.UserRepository,
 	notifService services.NotificationService,
+	txManager repositories.TransactionManager,
 ) services.FollowService {
 	return &FollowServiceImpl{
 		followRepo:   followRepo,
 		userRepo:     userRepo,
 		notifService: notifService,
+		txManager:    txManager,
 	}
 }
 
@@ -47,27 +50,36 @@ func (s *FollowServiceImpl) Follow(ctx context.Context, followerID uuid.UUID, fo
 		return nil, errors.New("already following")
 	}
 
-	// Create follow relationship
-	err = s.followRepo.Follow(ctx, followerID, followingID)
+	// Follow, counters and notification outbox are committed atomically
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Create follow relationship
+		if err := s.followRepo.Follow(ctx, followerID, followingID); err != nil {
+			return err
+		}
+
+		// Update follower/following counts
+		if err := s.followRepo.UpdateFollowerCount(ctx, followingID, 1); err != nil {
+			return err
+		}
+		if err := s.followRepo.UpdateFollowingCount(ctx, followerID, 1); err != nil {
+			return err
+		}
+
+		// Send notification
+		return s.notifService.CreateNotification(
+			ctx,
+			followingID,
+			followerID,
+			"follow",
+			"เริ่มติดตามคุณ",
+			nil,
+			nil,
+		)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Update follower/following counts
-	_ = s.followRepo.UpdateFollowerCount(ctx, followingID, 1)
-	_ = s.followRepo.UpdateFollowingCount(ctx, followerID, 1)
-
-	// Send notification
-	_ = s.notifService.CreateNotification(
-		ctx,
-		followingID,
-		followerID,
-		"follow",
-		"เริ่มติดตามคุณ",
-		nil,
-		nil,
-	)
-
 	return &dto.FollowResponse{
 		FollowerID:  followerID,
 		FollowingID: followingID,
@@ -0,0 +1,146 @@
+package serviceimpl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gofiber-template/domain/models"
+	"gofiber-template/domain/repositories"
+	"gofiber-template/domain/services"
+	"gofiber-template/infrastructure/redis"
+	"gofiber-template/pkg/linkpreview"
+)
+
+const (
+	linkPreviewTTL       = 7 * 24 * time.Hour // Successful previews are refetched after a week
+	linkPreviewFailedTTL = 1 * time.Hour      // Failed URLs are retried after an hour
+	linkPreviewRedisTTL  = 24 * time.Hour     // Upper bound for the Redis copy
+	linkPreviewTimeout   = 10 * time.Second   // Background unfurling of a message or post
+)
+
+type LinkPreviewServiceImpl struct {
+	previewRepo  repositories.LinkPreviewRepository
+	redisService *redis.RedisService
+	fetcher      *linkpreview.Fetcher
+}
+
+func NewLinkPreviewService(
+	previewRepo repositories.LinkPreviewRepository,
+	redisService *redis.RedisService,
+	fetcher *linkpreview.Fetcher,
+) services.LinkPreviewService {
+	return &LinkPreviewServiceImpl{
+		previewRepo:  previewRepo,
+		redisService: redisService,
+		fetcher:      fetcher,
+	}
+}
+
+func (s *LinkPreviewServiceImpl) PreviewForText(ctx context.Context, text string) (*models.LinkPreview, error) {
+	rawURL := linkpreview.FirstURL(text)
+	if rawURL == "" {
+		return nil, nil
+	}
+
+	url, err := linkpreview.NormalizeURL(rawURL)
+	if err != nil {
+		return nil, nil
+	}
+
+	preview, err := s.getPreview(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	if preview.Status != models.LinkPreviewStatusOK {
+		return nil, nil
+	}
+	return preview, nil
+}
+
+// getPreview looks the URL up in Redis, then Postgres, and fetches it when neither is fresh
+func (s *LinkPreviewServiceImpl) getPreview(ctx context.Context, url string) (*models.LinkPreview, error) {
+	now := time.Now()
+
+	if data, err := s.redisService.GetLinkPreview(ctx, url); err == nil && data != nil {
+		var cached models.LinkPreview
+		if err := json.Unmarshal(data, &cached); err == nil {
+			return &cached, nil
+		}
+	}
+
+	stored, err := s.previewRepo.GetByURL(ctx, url)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if stored != nil && stored.IsFresh(now, linkPreviewTTL, linkPreviewFailedTTL) {
+		s.cachePreview(ctx, stored, now)
+		return stored, nil
+	}
+
+	preview := &models.LinkPreview{
+		URL:       url,
+		Status:    models.LinkPreviewStatusOK,
+		FetchedAt: now,
+	}
+
+	metadata, err := s.fetcher.Fetch(ctx, url)
+	if err != nil {
+		log.Printf("⚠️  Link preview for %s failed: %v", url, err)
+		preview.Status = models.LinkPreviewStatusFailed
+	} else {
+		preview.CanonicalURL = metadata.URL
+		preview.Title = metadata.Title
+		preview.Description = metadata.Description
+		preview.SiteName = metadata.SiteName
+		preview.ImageURL = metadata.ImageURL
+	}
+
+	saved, err := s.previewRepo.Upsert(ctx, preview)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cachePreview(ctx, saved, now)
+	return saved, nil
+}
+
+// cachePreview stores the preview in Redis until it goes stale
+func (s *LinkPreviewServiceImpl) cachePreview(ctx context.Context, preview *models.LinkPreview, now time.Time) {
+	ttl := linkPreviewTTL
+	if preview.Status == models.LinkPreviewStatusFailed {
+		ttl = linkPreviewFailedTTL
+	}
+	ttl = preview.FetchedAt.Add(ttl).Sub(now)
+	if ttl > linkPreviewRedisTTL {
+		ttl = linkPreviewRedisTTL
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	data, err := json.Marshal(preview)
+	if err != nil {
+		return
+	}
+	_ = s.redisService.SetLinkPreview(ctx, preview.URL, data, ttl)
+}
+
+// linkPreviewChanged reports whether edited text links somewhere other than the current preview
+func linkPreviewChanged(current *models.LinkPreview, text string) (changed bool, hasLink bool) {
+	nextURL, _ := linkpreview.NormalizeURL(linkpreview.FirstURL(text))
+
+	currentURL := ""
+	if current != nil {
+		currentURL = current.URL
+	}
+
+	return nextURL != currentURL, nextURL != ""
+}
+
+// Ensure interface compliance
+var _ services.LinkPreviewService = (*LinkPreviewServiceImpl)(nil)
@@ -0,0 +1,30 @@
+package serviceimpl
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gofiber-template/domain/repositories"
+)
+
+var errLastLoginMethod = errors.New("this is your only way to sign in, add a password or link another account first")
+
+// ensureLoginMethodLeft fails when, once a login method is removed, the user would have
+// neither a password nor a linked identity. Call it in the same transaction as the
+// removal, after locking the user row with GetByIDForUpdate, so two removals at once
+// can't both pass.
+func ensureLoginMethodLeft(ctx context.Context, identityRepo repositories.UserIdentityRepository, userID uuid.UUID, hasPassword bool) error {
+	if hasPassword {
+		return nil
+	}
+
+	identities, err := identityRepo.CountByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if identities == 0 {
+		return errLastLoginMethod
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package serviceimpl
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gofiber-template/domain/services"
+	"gofiber-template/infrastructure/redis"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	loginFailureWindow     = time.Hour // Failures are forgotten after this long without another
+	loginFreeAttempts      = 3         // Failures per account before backoff starts
+	loginBackoffBase       = time.Second
+	loginBackoffMax        = 5 * time.Minute
+	accountLockoutAttempts = 10 // Failures that lock the account
+	accountLockoutDuration = 15 * time.Minute
+	ipFreeAttempts         = 20 // Failures per IP address (across accounts) before backoff starts
+)
+
+// dummyPasswordHash is compared against when there is no real hash, so unknown emails
+// take as long as wrong passwords and don't reveal which accounts exist
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy-password-for-constant-timing"), bcrypt.DefaultCost)
+	return hash
+})
+
+// loginThrottle slows down password and second factor guessing with per-account and
+// per-IP failure counters in Redis. Redis errors fail open: logins keep working, only
+// unthrottled.
+type loginThrottle struct {
+	redisService   *redis.RedisService
+	accountService services.AccountService
+}
+
+func newLoginThrottle(redisService *redis.RedisService, accountService services.AccountService) *loginThrottle {
+	return &loginThrottle{
+		redisService:   redisService,
+		accountService: accountService,
+	}
+}
+
+// check returns a LoginThrottledError while the account or IP address has to wait
+func (t *loginThrottle) check(ctx context.Context, email string, ip string) error {
+	remaining, err := t.redisService.LoginBlockRemaining(ctx, t.subjects(email, ip)...)
+	if err != nil {
+		log.Printf("⚠️ Login throttle check failed: %v", err)
+		return nil
+	}
+
+	if remaining > 0 {
+		return &services.LoginThrottledError{RetryAfter: remaining}
+	}
+	return nil
+}
+
+// recordFailure counts a failed attempt and blocks further attempts with exponential
+// backoff. userID is the account the email belongs to, if any; its owner is notified
+// when the account gets locked.
+func (t *loginThrottle) recordFailure(ctx context.Context, email string, ip string, userID *uuid.UUID) {
+	account := accountSubject(email)
+
+	failures, err := t.redisService.RecordLoginFailure(ctx, account, loginFailureWindow)
+	if err != nil {
+		log.Printf("⚠️ Failed to record login failure: %v", err)
+		return
+	}
+
+	delay := backoffDelay(failures, loginFreeAttempts)
+	locked := failures%accountLockoutAttempts == 0
+	if locked {
+		delay = accountLockoutDuration
+	}
+	if delay > 0 {
+		if err := t.redisService.BlockLogin(ctx, account, delay); err != nil {
+			log.Printf("⚠️ Failed to block login: %v", err)
+		}
+	}
+
+	if locked && userID != nil {
+		log.Printf("🔒 Login locked for user %s after %d failed attempts", *userID, failures)
+
+		// In the background so the response time doesn't reveal that the account exists
+		lockedUserID := *userID
+		go func() {
+			if err := t.accountService.NotifyAccountLocked(context.Background(), lockedUserID, accountLockoutDuration); err != nil {
+				log.Printf("Failed to send lockout notice to user %s: %v", lockedUserID, err)
+			}
+		}()
+	}
+
+	if ip == "" {
+		return
+	}
+
+	ipFailures, err := t.redisService.RecordLoginFailure(ctx, ipSubject(ip), loginFailureWindow)
+	if err != nil {
+		log.Printf("⚠️ Failed to record login failure: %v", err)
+		return
+	}
+	if delay := backoffDelay(ipFailures, ipFreeAttempts); delay > 0 {
+		if err := t.redisService.BlockLogin(ctx, ipSubject(ip), delay); err != nil {
+			log.Printf("⚠️ Failed to block login: %v", err)
+		}
+	}
+}
+
+// recordSuccess resets the account's counter. The IP counter is left to expire, so
+// logging into one account can't be used to keep guessing others.
+func (t *loginThrottle) recordSuccess(ctx context.Context, email string) {
+	if err := t.redisService.ClearLoginFailures(ctx, accountSubject(email)); err != nil {
+		log.Printf("⚠️ Failed to clear login failures: %v", err)
+	}
+}
+
+func (t *loginThrottle) subjects(email string, ip string) []string {
+	subjects := []string{accountSubject(email)}
+	if ip != "" {
+		subjects = append(subjects, ipSubject(ip))
+	}
+	return subjects
+}
+
+// backoffDelay doubles the wait with every failure past the free ones
+func backoffDelay(failures int64, free int64) time.Duration {
+	if failures <= free {
+		return 0
+	}
+
+	delay := loginBackoffBase
+	for i := free + 1; i < failures && delay < loginBackoffMax; i++ {
+		delay *= 2
+	}
+	return min(delay, loginBackoffMax)
+}
+
+// accountSubject keys counters by a hash of the normalized email, so unknown emails
+// are throttled exactly like real ones and no addresses end up in Redis
+func accountSubject(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return "acct:" + hex.EncodeToString(sum[:16])
+}
+
+func ipSubject(ip string) string {
+	return "ip:" + ip
+}
@@ -0,0 +1,139 @@
+package serviceimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"gofiber-template/domain/dto"
+	"gofiber-template/domain/models"
+	"gofiber-template/domain/repositories"
+	"gofiber-template/domain/services"
+	"gofiber-template/infrastructure/redis"
+	"gofiber-template/pkg/config"
+	"gofiber-template/pkg/totp"
+	"gofiber-template/pkg/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newTestRedis starts an in-process Redis for the test
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.RedisService) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	return server, redis.NewRedisService(redis.NewRedisClient(redis.RedisConfig{
+		Host: server.Host(),
+		Port: server.Port(),
+	}))
+}
+
+// stubUserRepo serves a single user; other methods panic through the nil interface
+type stubUserRepo struct {
+	repositories.UserRepository
+	user *models.User
+}
+
+func (r *stubUserRepo) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	if email != r.user.Email {
+		return nil, errors.New("record not found")
+	}
+	return r.user, nil
+}
+
+func (r *stubUserRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	if id != r.user.ID {
+		return nil, errors.New("record not found")
+	}
+	return r.user, nil
+}
+
+type stubAccountService struct {
+	services.AccountService
+}
+
+func (stubAccountService) NotifyAccountLocked(ctx context.Context, userID uuid.UUID, lockedFor time.Duration) error {
+	return nil
+}
+
+// wrongTOTPCode returns a six digit code that is not valid for secret right now
+func wrongTOTPCode(t *testing.T, secret string) string {
+	t.Helper()
+	for candidate := 0; ; candidate++ {
+		code := fmt.Sprintf("%06d", candidate)
+		if _, ok := totp.Validate(secret, code, time.Now(), totpSkew); !ok {
+			return code
+		}
+	}
+}
+
+func TestLoginWithMFADoesNotResetFailedSecondFactors(t *testing.T) {
+	ctx := context.Background()
+	server, redisService := newTestRedis(t)
+	cfg := &config.Config{JWT: config.JWTConfig{Secret: "test-secret"}}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := utils.NewSecretBox(cfg.JWT.Secret + ":totp").Seal(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := &models.User{
+		ID:          uuid.New(),
+		Email:       "owner@example.com",
+		Password:    string(passwordHash),
+		IsActive:    true,
+		TOTPSecret:  sealed,
+		TOTPEnabled: true,
+	}
+	userRepo := &stubUserRepo{user: user}
+	accountService := stubAccountService{}
+
+	mfaService := NewMFAService(userRepo, nil, nil, accountService, redisService, nil, cfg)
+	userService := NewUserService(userRepo, nil, nil, nil, nil, accountService, mfaService, redisService)
+
+	login := &dto.LoginRequest{Email: user.Email, Password: "correct horse"}
+	wrongCode := wrongTOTPCode(t, secret)
+
+	// Each round: the right password, then a wrong second factor from a fresh IP so
+	// only the account counter applies. The backoff between rounds is skipped, the
+	// failures it was triggered by are not.
+	for round := 1; round <= accountLockoutAttempts; round++ {
+		client := &dto.ClientInfo{IPAddress: "203.0.113." + strconv.Itoa(round)}
+
+		resp, err := userService.Login(ctx, login, client)
+		if err != nil {
+			t.Fatalf("round %d: login failed: %v", round, err)
+		}
+		if !resp.MFARequired {
+			t.Fatalf("round %d: expected an MFA challenge", round)
+		}
+
+		if _, _, err := mfaService.VerifyChallenge(ctx, resp.MFAToken, wrongCode, client); err == nil {
+			t.Fatalf("round %d: wrong code accepted", round)
+		}
+
+		if round < accountLockoutAttempts {
+			server.FastForward(loginBackoffMax)
+		}
+	}
+
+	_, err = userService.Login(ctx, login, &dto.ClientInfo{IPAddress: "198.51.100.1"})
+	var throttled *services.LoginThrottledError
+	if !errors.As(err, &throttled) {
+		t.Fatalf("expected the account to stay locked after a correct password, got %v", err)
+	}
+	if throttled.RetryAfter <= accountLockoutDuration-time.Minute {
+		t.Fatalf("expected the lockout duration, got %s", throttled.RetryAfter)
+	}
+}
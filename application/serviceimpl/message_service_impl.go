@@ -4,7 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"log"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"gorm.io/datatypes"
@@ -22,14 +26,37 @@ type MessageServiceImpl struct {
 	blockRepo        repositories.BlockRepository
 	userRepo         repositories.UserRepository
 	redisService     *redis.RedisService
+	reactionRepo     repositories.MessageReactionRepository
+	receiptRepo      repositories.MessageReceiptRepository
+	chatSettingsRepo repositories.ChatSettingsRepository
+
+	linkPreviewService  services.LinkPreviewService
+	linkPreviewListener func(message *dto.MessageResponse) // Notified when a preview is attached
 }
 
+const (
+	messageEditWindow   = 15 * time.Minute // Senders can fix typos shortly after sending
+	messageUnsendWindow = 24 * time.Hour   // Senders can delete for everyone within a day
+	maxReactionsPerUser = 5                // Distinct emojis one user can put on a message
+	maxReactionLength   = 32               // Bytes; allows multi-codepoint emoji sequences
+	maxRequestMessages  = 3                // Messages a requester can send before the recipient accepts
+	maxWaveformSamples  = 256              // Amplitude samples stored with a voice message
+
+	minSearchQueryLength = 2
+	maxSearchQueryLength = 100
+	snippetRadius        = 60 // Characters kept on each side of the first match
+)
+
 func NewMessageService(
 	messageRepo repositories.MessageRepository,
 	conversationRepo repositories.ConversationRepository,
 	blockRepo repositories.BlockRepository,
 	userRepo repositories.UserRepository,
 	redisService *redis.RedisService,
+	reactionRepo repositories.MessageReactionRepository,
+	receiptRepo repositories.MessageReceiptRepository,
+	chatSettingsRepo repositories.ChatSettingsRepository,
+	linkPreviewService services.LinkPreviewService,
 ) services.MessageService {
 	return &MessageServiceImpl{
 		messageRepo:      messageRepo,
@@ -37,9 +64,19 @@ func NewMessageService(
 		blockRepo:        blockRepo,
 		userRepo:         userRepo,
 		redisService:     redisService,
+		reactionRepo:     reactionRepo,
+		receiptRepo:      receiptRepo,
+		chatSettingsRepo: chatSettingsRepo,
+
+		linkPreviewService: linkPreviewService,
 	}
 }
 
+// SetLinkPreviewListener sets a callback for messages whose link preview was attached after sending
+func (s *MessageServiceImpl) SetLinkPreviewListener(listener func(message *dto.MessageResponse)) {
+	s.linkPreviewListener = listener
+}
+
 func (s *MessageServiceImpl) SendMessage(ctx context.Context, userID uuid.UUID, req *dto.SendMessageRequest) (*dto.MessageResponse, error) {
 	// Validate: Content OR Media must be provided
 	if (req.Content == nil || *req.Content == "") && len(req.Media) == 0 {
@@ -53,31 +90,55 @@ func (s *MessageServiceImpl) SendMessage(ctx context.Context, userID uuid.UUID,
 	}
 
 	// Check if user is participant
-	if conversation.User1ID != userID && conversation.User2ID != userID {
+	if !conversation.HasParticipant(userID) {
 		return nil, errors.New("access denied: not a participant")
 	}
 
-	// Determine sender and receiver
-	var receiverID uuid.UUID
-	if conversation.User1ID == userID {
-		receiverID = conversation.User2ID
-	} else {
-		receiverID = conversation.User1ID
-	}
+	// Direct messages have a single receiver; group messages go to every participant
+	var receiverID *uuid.UUID
+	if !conversation.IsGroup() {
+		otherUserID := conversation.OtherUserID(userID)
+		receiverID = &otherUserID
 
-	// Check block status
-	blocked, blockedBy, err := s.blockRepo.GetBlockStatus(ctx, userID, receiverID)
-	if err != nil {
-		return nil, err
+		// Check block status
+		blocked, blockedBy, err := s.blockRepo.GetBlockStatus(ctx, userID, otherUserID)
+		if err != nil {
+			return nil, err
+		}
+
+		if blocked || blockedBy {
+			return nil, errors.New("cannot send message: user is blocked")
+		}
+
+		if err := s.checkMessageRequest(ctx, conversation, userID); err != nil {
+			return nil, err
+		}
 	}
 
-	if blocked || blockedBy {
-		return nil, errors.New("cannot send message: user is blocked")
+	// Quoted message must belong to the same conversation and still exist
+	if req.ReplyToID != nil {
+		replyTo, err := s.messageRepo.GetByID(ctx, *req.ReplyToID)
+		if err != nil || replyTo.ConversationID != req.ConversationID {
+			return nil, errors.New("reply target not found in this conversation")
+		}
+		if replyTo.IsDeleted() {
+			return nil, errors.New("cannot reply to a deleted message")
+		}
 	}
 
 	// Convert MessageType string to enum
 	messageType := models.MessageType(req.Type)
 
+	// Voice messages carry exactly one clip; the waveform is display-only but stored as-is
+	if messageType == models.MessageTypeVoice {
+		if len(req.Media) != 1 || req.Media[0].Type != string(models.MessageTypeVoice) {
+			return nil, errors.New("voice message requires exactly one audio clip")
+		}
+		if !validWaveform(req.Media[0].Waveform) {
+			return nil, errors.New("invalid waveform")
+		}
+	}
+
 	// Convert Media array to JSONB
 	var mediaJSON datatypes.JSON
 	if len(req.Media) > 0 {
@@ -97,6 +158,7 @@ func (s *MessageServiceImpl) SendMessage(ctx context.Context, userID uuid.UUID,
 		Type:           messageType,
 		Content:        req.Content,
 		Media:          mediaJSON,
+		ReplyToID:      req.ReplyToID,
 		IsRead:         false,
 		CreatedAt:      now,
 		UpdatedAt:      now,
@@ -106,13 +168,17 @@ func (s *MessageServiceImpl) SendMessage(ctx context.Context, userID uuid.UUID,
 		return nil, err
 	}
 
-	// Update conversation last message and increment unread count
+	// Update conversation last message and increment unread counts
 	_ = s.conversationRepo.UpdateLastMessage(ctx, req.ConversationID, message.ID, now)
-	_ = s.conversationRepo.IncrementUnreadCount(ctx, req.ConversationID, receiverID)
+	_ = s.conversationRepo.IncrementUnreadCountForOthers(ctx, req.ConversationID, userID)
 
-	// Increment unread counts in Redis
-	_ = s.redisService.IncrementTotalUnread(ctx, receiverID)
-	_ = s.redisService.IncrementConversationUnread(ctx, receiverID, req.ConversationID)
+	// Increment unread counts in Redis (received message requests stay out of the total)
+	for _, recipientID := range conversation.OtherParticipantIDs(userID) {
+		if !conversation.IsRequestFor(recipientID) {
+			_ = s.redisService.IncrementTotalUnread(ctx, recipientID)
+		}
+		_ = s.redisService.IncrementConversationUnread(ctx, recipientID, req.ConversationID)
+	}
 
 	// Cache last message in Redis
 	_ = s.redisService.CacheLastMessage(ctx, req.ConversationID, message.ID, message.SenderID, message.Content, string(message.Type), message.CreatedAt)
@@ -129,21 +195,464 @@ func (s *MessageServiceImpl) SendMessage(ctx context.Context, userID uuid.UUID,
 		resp.TempID = req.TempID
 	}
 
+	// Unfurl links without delaying the send
+	if message.Type == models.MessageTypeText && message.Content != nil {
+		go s.attachLinkPreview(message.ID, *message.Content)
+	}
+
 	return resp, nil
 }
 
+// validWaveform checks a voice waveform is a bounded list of amplitudes in 0-100
+func validWaveform(waveform []int) bool {
+	if len(waveform) > maxWaveformSamples {
+		return false
+	}
+	for _, amplitude := range waveform {
+		if amplitude < 0 || amplitude > 100 {
+			return false
+		}
+	}
+	return true
+}
+
 func (s *MessageServiceImpl) GetMessage(ctx context.Context, messageID uuid.UUID, userID uuid.UUID) (*dto.MessageResponse, error) {
-	message, err := s.messageRepo.GetByID(ctx, messageID)
+	message, err := s.messageRepo.GetVisibleByID(ctx, messageID, userID)
 	if err != nil {
 		return nil, errors.New("message not found")
 	}
 
 	// Check if user is participant
-	if message.SenderID != userID && message.ReceiverID != userID {
+	if !s.isParticipant(ctx, message.ConversationID, userID) {
+		return nil, errors.New("access denied")
+	}
+
+	resp := dto.MessageToMessageResponse(message)
+	s.attachMessageState(ctx, userID, resp)
+
+	return resp, nil
+}
+
+func (s *MessageServiceImpl) EditMessage(ctx context.Context, messageID uuid.UUID, userID uuid.UUID, req *dto.EditMessageRequest) (*dto.MessageResponse, error) {
+	message, err := s.messageRepo.GetVisibleByID(ctx, messageID, userID)
+	if err != nil {
+		return nil, errors.New("message not found")
+	}
+
+	if message.SenderID != userID || !s.isParticipant(ctx, message.ConversationID, userID) {
+		return nil, errors.New("access denied: only the sender can edit a message")
+	}
+
+	if message.IsDeleted() {
+		return nil, errors.New("message has been deleted")
+	}
+
+	// Text messages and media captions are editable; media itself is not
+	if message.Type != models.MessageTypeText && message.Content == nil {
+		return nil, errors.New("only text can be edited")
+	}
+
+	if time.Since(message.CreatedAt) > messageEditWindow {
+		return nil, errors.New("edit window has expired")
+	}
+
+	if err := s.messageRepo.UpdateContent(ctx, messageID, req.Content, time.Now()); err != nil {
+		return nil, err
+	}
+
+	// Cached preview may show the old text
+	_ = s.redisService.InvalidateLastMessage(ctx, message.ConversationID)
+
+	// A different link replaces the preview; the stale one is dropped right away
+	if changed, hasLink := linkPreviewChanged(message.LinkPreview, req.Content); changed && message.Type == models.MessageTypeText {
+		if message.LinkPreviewID != nil {
+			_ = s.messageRepo.SetLinkPreview(ctx, messageID, nil)
+		}
+		if hasLink {
+			go s.attachLinkPreview(messageID, req.Content)
+		}
+	}
+
+	updated, err := s.messageRepo.GetByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := dto.MessageToMessageResponse(updated)
+	s.attachMessageState(ctx, userID, resp)
+
+	return resp, nil
+}
+
+func (s *MessageServiceImpl) DeleteMessage(ctx context.Context, messageID uuid.UUID, userID uuid.UUID, scope string) (*dto.MessageResponse, error) {
+	message, err := s.messageRepo.GetByID(ctx, messageID)
+	if err != nil {
+		return nil, errors.New("message not found")
+	}
+
+	if !s.isParticipant(ctx, message.ConversationID, userID) {
+		return nil, errors.New("access denied")
+	}
+
+	switch scope {
+	case models.MessageDeleteScopeMe:
+		if err := s.messageRepo.HideForUser(ctx, messageID, userID); err != nil {
+			return nil, err
+		}
+		return dto.MessageToMessageResponse(message), nil
+
+	case models.MessageDeleteScopeEveryone:
+		if message.SenderID != userID {
+			return nil, errors.New("access denied: only the sender can delete for everyone")
+		}
+
+		if message.IsDeleted() {
+			return dto.MessageToMessageResponse(message), nil
+		}
+
+		if time.Since(message.CreatedAt) > messageUnsendWindow {
+			return nil, errors.New("delete window has expired")
+		}
+
+		if err := s.messageRepo.MarkDeleted(ctx, messageID, time.Now()); err != nil {
+			return nil, err
+		}
+
+		// The conversation preview must not point at a deleted message
+		if err := s.conversationRepo.RecomputeLastMessage(ctx, message.ConversationID); err != nil {
+			return nil, err
+		}
+		_ = s.redisService.InvalidateLastMessage(ctx, message.ConversationID)
+
+		deleted, err := s.messageRepo.GetByID(ctx, messageID)
+		if err != nil {
+			return nil, err
+		}
+		return dto.MessageToMessageResponse(deleted), nil
+
+	default:
+		return nil, errors.New("invalid delete scope")
+	}
+}
+
+// ==================== Reactions ====================
+
+func (s *MessageServiceImpl) React(ctx context.Context, messageID uuid.UUID, userID uuid.UUID, emoji string) (*dto.MessageReactionUpdate, error) {
+	message, err := s.getReactableMessage(ctx, messageID, userID, emoji)
+	if err != nil {
+		return nil, err
+	}
+
+	// Blocked users cannot react to each other's messages
+	if message.SenderID != userID {
+		blocked, blockedBy, err := s.blockRepo.GetBlockStatus(ctx, userID, message.SenderID)
+		if err != nil {
+			return nil, err
+		}
+		if blocked || blockedBy {
+			return nil, errors.New("cannot react: user is blocked")
+		}
+	}
+
+	count, err := s.reactionRepo.CountByUser(ctx, messageID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxReactionsPerUser {
+		return nil, errors.New("reaction limit reached")
+	}
+
+	if err := s.reactionRepo.Add(ctx, &models.MessageReaction{
+		MessageID: messageID,
+		UserID:    userID,
+		Emoji:     emoji,
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.reactionUpdate(ctx, message, userID, emoji, "added")
+}
+
+func (s *MessageServiceImpl) Unreact(ctx context.Context, messageID uuid.UUID, userID uuid.UUID, emoji string) (*dto.MessageReactionUpdate, error) {
+	message, err := s.getReactableMessage(ctx, messageID, userID, emoji)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.reactionRepo.Remove(ctx, messageID, userID, emoji); err != nil {
+		return nil, err
+	}
+
+	return s.reactionUpdate(ctx, message, userID, emoji, "removed")
+}
+
+func (s *MessageServiceImpl) ListReactions(ctx context.Context, messageID uuid.UUID, userID uuid.UUID, emoji *string, offset, limit int) (*dto.MessageReactionListResponse, error) {
+	message, err := s.messageRepo.GetVisibleByID(ctx, messageID, userID)
+	if err != nil {
+		return nil, errors.New("message not found")
+	}
+
+	if !s.isParticipant(ctx, message.ConversationID, userID) {
+		return nil, errors.New("access denied")
+	}
+
+	// Set default limit
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	reactions, err := s.reactionRepo.ListByMessage(ctx, messageID, userID, emoji, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.reactionRepo.CountByMessage(ctx, messageID, userID, emoji)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.MessageReactionResponse, len(reactions))
+	for i, reaction := range reactions {
+		responses[i] = *dto.MessageReactionToResponse(reaction)
+	}
+
+	return &dto.MessageReactionListResponse{
+		Reactions: responses,
+		Meta: dto.PaginationMeta{
+			Total:  total,
+			Offset: offset,
+			Limit:  limit,
+		},
+	}, nil
+}
+
+func (s *MessageServiceImpl) MarkDelivered(ctx context.Context, userID uuid.UUID, messageIDs []uuid.UUID) ([]*dto.DeliveryReceiptUpdate, error) {
+	if len(messageIDs) == 0 {
+		return nil, errors.New("messageIds is required")
+	}
+	if len(messageIDs) > 100 {
+		return nil, errors.New("too many messages")
+	}
+
+	// Messages the user can't see are skipped by the repository
+	deliveredAt := time.Now()
+	messages, err := s.receiptRepo.MarkDelivered(ctx, userID, messageIDs, deliveredAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// One update per (conversation, sender)
+	type updateKey struct {
+		conversationID uuid.UUID
+		senderID       uuid.UUID
+	}
+	updates := make([]*dto.DeliveryReceiptUpdate, 0)
+	byKey := make(map[updateKey]*dto.DeliveryReceiptUpdate)
+	for _, message := range messages {
+		key := updateKey{message.ConversationID, message.SenderID}
+		update, ok := byKey[key]
+		if !ok {
+			update = &dto.DeliveryReceiptUpdate{
+				ConversationID: message.ConversationID,
+				SenderID:       message.SenderID,
+				DeliveredTo:    userID,
+				DeliveredAt:    deliveredAt,
+			}
+			byKey[key] = update
+			updates = append(updates, update)
+		}
+		update.MessageIDs = append(update.MessageIDs, message.ID)
+	}
+
+	return updates, nil
+}
+
+func (s *MessageServiceImpl) ListReceipts(ctx context.Context, messageID uuid.UUID, userID uuid.UUID) (*dto.MessageReceiptListResponse, error) {
+	message, err := s.messageRepo.GetByID(ctx, messageID)
+	if err != nil {
+		return nil, errors.New("message not found")
+	}
+
+	if message.SenderID != userID {
+		return nil, errors.New("access denied: only the sender can view receipts")
+	}
+
+	receipts, err := s.receiptRepo.ListByMessage(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries, err := s.receiptRepo.SummarizeForSender(ctx, []uuid.UUID{messageID}, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hide read times when either side turned read receipts off
+	userIDs := []uuid.UUID{userID}
+	for _, receipt := range receipts {
+		userIDs = append(userIDs, receipt.UserID)
+	}
+	disabled, err := s.chatSettingsRepo.ListReadReceiptsDisabled(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.MessageReceiptResponse, len(receipts))
+	for i, receipt := range receipts {
+		responses[i] = *dto.MessageReceiptToResponse(receipt)
+		if disabled[userID] || disabled[receipt.UserID] {
+			responses[i].ReadAt = nil
+		}
+	}
+
+	result := &dto.MessageReceiptListResponse{
+		Status:   string(models.MessageStatusSent),
+		Receipts: responses,
+	}
+	if len(summaries) > 0 {
+		status := summaries[0].Status()
+		if status == models.MessageStatusRead && disabled[userID] {
+			status = models.MessageStatusDelivered
+		}
+		result.Status = string(status)
+		result.Recipients = summaries[0].Recipients
+	}
+
+	return result, nil
+}
+
+// getReactableMessage validates the emoji and the caller's access to the message
+func (s *MessageServiceImpl) getReactableMessage(ctx context.Context, messageID uuid.UUID, userID uuid.UUID, emoji string) (*models.Message, error) {
+	if emoji == "" || len(emoji) > maxReactionLength || strings.ContainsAny(emoji, " \t\n") {
+		return nil, errors.New("invalid emoji")
+	}
+
+	message, err := s.messageRepo.GetVisibleByID(ctx, messageID, userID)
+	if err != nil {
+		return nil, errors.New("message not found")
+	}
+
+	if !s.isParticipant(ctx, message.ConversationID, userID) {
 		return nil, errors.New("access denied")
 	}
 
-	return dto.MessageToMessageResponse(message), nil
+	if message.IsDeleted() {
+		return nil, errors.New("message has been deleted")
+	}
+
+	return message, nil
+}
+
+// reactionUpdate builds the result of a react/unreact from the reacting user's view
+func (s *MessageServiceImpl) reactionUpdate(ctx context.Context, message *models.Message, userID uuid.UUID, emoji string, action string) (*dto.MessageReactionUpdate, error) {
+	resp := dto.MessageToMessageResponse(message)
+	s.attachReactions(ctx, userID, resp)
+
+	reactions := resp.Reactions
+	if reactions == nil {
+		reactions = []dto.ReactionSummary{}
+	}
+
+	return &dto.MessageReactionUpdate{
+		MessageID:      message.ID,
+		ConversationID: message.ConversationID,
+		UserID:         userID,
+		Emoji:          emoji,
+		Action:         action,
+		Reactions:      reactions,
+	}, nil
+}
+
+// attachMessageState fills the per-viewer parts of each message: reactions and, on own messages, receipt status
+func (s *MessageServiceImpl) attachMessageState(ctx context.Context, viewerID uuid.UUID, messages ...*dto.MessageResponse) {
+	s.attachReactions(ctx, viewerID, messages...)
+	s.attachReceiptStatus(ctx, viewerID, messages...)
+}
+
+// attachReceiptStatus sets sent/delivered/read on messages sent by viewerID
+func (s *MessageServiceImpl) attachReceiptStatus(ctx context.Context, viewerID uuid.UUID, messages ...*dto.MessageResponse) {
+	var messageIDs []uuid.UUID
+	for _, message := range messages {
+		if message.SenderId == viewerID {
+			messageIDs = append(messageIDs, message.ID)
+		}
+	}
+	if len(messageIDs) == 0 {
+		return
+	}
+
+	summaries, err := s.receiptRepo.SummarizeForSender(ctx, messageIDs, viewerID)
+	if err != nil {
+		// Non-critical, return messages without status
+		return
+	}
+
+	// Read receipts are reciprocal: a viewer who hides them sees delivered at most
+	readReceipts := s.readReceiptsEnabled(ctx, viewerID)
+
+	statuses := make(map[uuid.UUID]string)
+	for _, summary := range summaries {
+		status := summary.Status()
+		if status == models.MessageStatusRead && !readReceipts {
+			status = models.MessageStatusDelivered
+		}
+		statuses[summary.MessageID] = string(status)
+	}
+
+	for _, message := range messages {
+		if status, ok := statuses[message.ID]; ok {
+			message.Status = status
+		}
+	}
+}
+
+// readReceiptsEnabled reports whether userID shares (and therefore sees) read receipts
+func (s *MessageServiceImpl) readReceiptsEnabled(ctx context.Context, userID uuid.UUID) bool {
+	disabled, err := s.chatSettingsRepo.ListReadReceiptsDisabled(ctx, []uuid.UUID{userID})
+	if err != nil {
+		return true
+	}
+	return !disabled[userID]
+}
+
+// attachReactions fills each message's reaction summary as seen by viewerID
+func (s *MessageServiceImpl) attachReactions(ctx context.Context, viewerID uuid.UUID, messages ...*dto.MessageResponse) {
+	if len(messages) == 0 {
+		return
+	}
+
+	messageIDs := make([]uuid.UUID, len(messages))
+	for i, message := range messages {
+		messageIDs[i] = message.ID
+	}
+
+	counts, err := s.reactionRepo.SummarizeByMessages(ctx, messageIDs, viewerID)
+	if err != nil {
+		// Non-critical, return messages without reactions
+		return
+	}
+
+	byMessage := make(map[uuid.UUID][]dto.ReactionSummary)
+	for _, count := range counts {
+		byMessage[count.MessageID] = append(byMessage[count.MessageID], dto.ReactionSummary{
+			Emoji:       count.Emoji,
+			Count:       count.Count,
+			ReactedByMe: count.ReactedByMe,
+		})
+	}
+
+	for _, message := range messages {
+		message.Reactions = byMessage[message.ID]
+	}
+}
+
+// messagePointers lets attachReactions update a response slice in place
+func messagePointers(messages []dto.MessageResponse) []*dto.MessageResponse {
+	pointers := make([]*dto.MessageResponse, len(messages))
+	for i := range messages {
+		pointers[i] = &messages[i]
+	}
+	return pointers
 }
 
 func (s *MessageServiceImpl) ListMessages(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, cursorStr *string, limit int) (*dto.MessageListResponse, error) {
@@ -153,7 +662,7 @@ func (s *MessageServiceImpl) ListMessages(ctx context.Context, conversationID uu
 		return nil, errors.New("conversation not found")
 	}
 
-	if conversation.User1ID != userID && conversation.User2ID != userID {
+	if !conversation.HasParticipant(userID) {
 		return nil, errors.New("access denied: not a participant")
 	}
 
@@ -173,7 +682,7 @@ func (s *MessageServiceImpl) ListMessages(ctx context.Context, conversationID uu
 	}
 
 	// Fetch messages (limit + 1 to check for more)
-	messages, err := s.messageRepo.ListByConversation(ctx, conversationID, cursor, limit+1)
+	messages, err := s.messageRepo.ListByConversation(ctx, conversationID, userID, cursor, limit+1)
 	if err != nil {
 		return nil, err
 	}
@@ -189,6 +698,7 @@ func (s *MessageServiceImpl) ListMessages(ctx context.Context, conversationID uu
 	for i, msg := range messages {
 		messageResponses[i] = *dto.MessageToMessageResponse(msg)
 	}
+	s.attachMessageState(ctx, userID, messagePointers(messageResponses)...)
 
 	// Generate next cursor
 	var nextCursor *string
@@ -209,24 +719,24 @@ func (s *MessageServiceImpl) ListMessages(ctx context.Context, conversationID uu
 
 func (s *MessageServiceImpl) GetMessageContext(ctx context.Context, messageID uuid.UUID, userID uuid.UUID) (*dto.MessageContextResponse, error) {
 	// Get target message
-	targetMessage, err := s.messageRepo.GetByID(ctx, messageID)
+	targetMessage, err := s.messageRepo.GetVisibleByID(ctx, messageID, userID)
 	if err != nil {
 		return nil, errors.New("message not found")
 	}
 
 	// Verify user is participant
-	if targetMessage.SenderID != userID && targetMessage.ReceiverID != userID {
+	if !s.isParticipant(ctx, targetMessage.ConversationID, userID) {
 		return nil, errors.New("access denied")
 	}
 
 	// Get messages before (20 messages)
-	messagesBefore, err := s.messageRepo.GetMessagesBeforeTimestamp(ctx, targetMessage.ConversationID, targetMessage.CreatedAt, 20)
+	messagesBefore, err := s.messageRepo.GetMessagesBeforeTimestamp(ctx, targetMessage.ConversationID, userID, targetMessage.CreatedAt, 20)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get messages after (20 messages)
-	messagesAfter, err := s.messageRepo.GetMessagesAfterTimestamp(ctx, targetMessage.ConversationID, targetMessage.CreatedAt, 20)
+	messagesAfter, err := s.messageRepo.GetMessagesAfterTimestamp(ctx, targetMessage.ConversationID, userID, targetMessage.CreatedAt, 20)
 	if err != nil {
 		return nil, err
 	}
@@ -242,6 +752,9 @@ func (s *MessageServiceImpl) GetMessageContext(ctx context.Context, messageID uu
 		afterDTOs[i] = *dto.MessageToMessageResponse(msg)
 	}
 
+	targetDTO := dto.MessageToMessageResponse(targetMessage)
+	s.attachMessageState(ctx, userID, append(append(messagePointers(beforeDTOs), messagePointers(afterDTOs)...), targetDTO)...)
+
 	// Generate cursors
 	var beforeCursor, afterCursor *string
 	if len(messagesBefore) > 0 {
@@ -258,7 +771,7 @@ func (s *MessageServiceImpl) GetMessageContext(ctx context.Context, messageID uu
 	}
 
 	return &dto.MessageContextResponse{
-		TargetMessage:  *dto.MessageToMessageResponse(targetMessage),
+		TargetMessage:  *targetDTO,
 		Before:         beforeDTOs,
 		After:          afterDTOs,
 		BeforeCursor:   beforeCursor,
@@ -268,6 +781,28 @@ func (s *MessageServiceImpl) GetMessageContext(ctx context.Context, messageID uu
 	}, nil
 }
 
+func (s *MessageServiceImpl) GetReplyContext(ctx context.Context, messageID uuid.UUID, userID uuid.UUID) (*dto.MessageContextResponse, error) {
+	message, err := s.messageRepo.GetVisibleByID(ctx, messageID, userID)
+	if err != nil {
+		return nil, errors.New("message not found")
+	}
+
+	if !s.isParticipant(ctx, message.ConversationID, userID) {
+		return nil, errors.New("access denied")
+	}
+
+	if message.ReplyToID == nil {
+		return nil, errors.New("message is not a reply")
+	}
+
+	if message.ReplyTo == nil {
+		return nil, errors.New("original message no longer exists")
+	}
+
+	// Jump to the quoted message (a deleted original still has a tombstone to land on)
+	return s.GetMessageContext(ctx, *message.ReplyToID, userID)
+}
+
 func (s *MessageServiceImpl) MarkAsRead(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) error {
 	// Verify user is participant
 	conversation, err := s.conversationRepo.GetByID(ctx, conversationID)
@@ -275,7 +810,7 @@ func (s *MessageServiceImpl) MarkAsRead(ctx context.Context, conversationID uuid
 		return errors.New("conversation not found")
 	}
 
-	if conversation.User1ID != userID && conversation.User2ID != userID {
+	if !conversation.HasParticipant(userID) {
 		return errors.New("access denied: not a participant")
 	}
 
@@ -297,18 +832,16 @@ func (s *MessageServiceImpl) ListMediaMessages(ctx context.Context, conversation
 		return nil, errors.New("conversation not found")
 	}
 
-	if conversation.User1ID != userID && conversation.User2ID != userID {
+	if !conversation.HasParticipant(userID) {
 		return nil, errors.New("access denied: not a participant")
 	}
 
-	// Check if blocked
-	isBlocked, err := s.blockRepo.IsBlocked(ctx, userID, conversation.User1ID)
-	if err == nil && isBlocked {
-		return nil, errors.New("access denied: blocked")
-	}
-	isBlocked, err = s.blockRepo.IsBlocked(ctx, userID, conversation.User2ID)
-	if err == nil && isBlocked {
-		return nil, errors.New("access denied: blocked")
+	// Check if blocked (direct conversations only)
+	if !conversation.IsGroup() {
+		isBlocked, err := s.blockRepo.IsBlocked(ctx, userID, conversation.OtherUserID(userID))
+		if err == nil && isBlocked {
+			return nil, errors.New("access denied: blocked")
+		}
 	}
 
 	// Parse cursor
@@ -321,7 +854,7 @@ func (s *MessageServiceImpl) ListMediaMessages(ctx context.Context, conversation
 	}
 
 	// Get media messages
-	messages, err := s.messageRepo.ListMediaMessages(ctx, conversationID, mediaType, beforeCursor, limit)
+	messages, err := s.messageRepo.ListMediaMessages(ctx, conversationID, userID, mediaType, beforeCursor, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -331,6 +864,7 @@ func (s *MessageServiceImpl) ListMediaMessages(ctx context.Context, conversation
 	for _, message := range messages {
 		responseDTOs = append(responseDTOs, *dto.MessageToMessageResponse(message))
 	}
+	s.attachMessageState(ctx, userID, messagePointers(responseDTOs)...)
 
 	// Generate next cursor
 	var nextCursor *string
@@ -355,18 +889,16 @@ func (s *MessageServiceImpl) ListMessagesWithLinks(ctx context.Context, conversa
 		return nil, errors.New("conversation not found")
 	}
 
-	if conversation.User1ID != userID && conversation.User2ID != userID {
+	if !conversation.HasParticipant(userID) {
 		return nil, errors.New("access denied: not a participant")
 	}
 
-	// Check if blocked
-	isBlocked, err := s.blockRepo.IsBlocked(ctx, userID, conversation.User1ID)
-	if err == nil && isBlocked {
-		return nil, errors.New("access denied: blocked")
-	}
-	isBlocked, err = s.blockRepo.IsBlocked(ctx, userID, conversation.User2ID)
-	if err == nil && isBlocked {
-		return nil, errors.New("access denied: blocked")
+	// Check if blocked (direct conversations only)
+	if !conversation.IsGroup() {
+		isBlocked, err := s.blockRepo.IsBlocked(ctx, userID, conversation.OtherUserID(userID))
+		if err == nil && isBlocked {
+			return nil, errors.New("access denied: blocked")
+		}
 	}
 
 	// Parse cursor
@@ -379,7 +911,7 @@ func (s *MessageServiceImpl) ListMessagesWithLinks(ctx context.Context, conversa
 	}
 
 	// Get messages with links
-	messages, err := s.messageRepo.ListMessagesWithLinks(ctx, conversationID, beforeCursor, limit)
+	messages, err := s.messageRepo.ListMessagesWithLinks(ctx, conversationID, userID, beforeCursor, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -389,6 +921,7 @@ func (s *MessageServiceImpl) ListMessagesWithLinks(ctx context.Context, conversa
 	for _, message := range messages {
 		responseDTOs = append(responseDTOs, *dto.MessageToMessageResponse(message))
 	}
+	s.attachMessageState(ctx, userID, messagePointers(responseDTOs)...)
 
 	// Generate next cursor
 	var nextCursor *string
@@ -413,18 +946,16 @@ func (s *MessageServiceImpl) ListFileMessages(ctx context.Context, conversationI
 		return nil, errors.New("conversation not found")
 	}
 
-	if conversation.User1ID != userID && conversation.User2ID != userID {
+	if !conversation.HasParticipant(userID) {
 		return nil, errors.New("access denied: not a participant")
 	}
 
-	// Check if blocked
-	isBlocked, err := s.blockRepo.IsBlocked(ctx, userID, conversation.User1ID)
-	if err == nil && isBlocked {
-		return nil, errors.New("access denied: blocked")
-	}
-	isBlocked, err = s.blockRepo.IsBlocked(ctx, userID, conversation.User2ID)
-	if err == nil && isBlocked {
-		return nil, errors.New("access denied: blocked")
+	// Check if blocked (direct conversations only)
+	if !conversation.IsGroup() {
+		isBlocked, err := s.blockRepo.IsBlocked(ctx, userID, conversation.OtherUserID(userID))
+		if err == nil && isBlocked {
+			return nil, errors.New("access denied: blocked")
+		}
 	}
 
 	// Parse cursor
@@ -437,7 +968,7 @@ func (s *MessageServiceImpl) ListFileMessages(ctx context.Context, conversationI
 	}
 
 	// Get file messages
-	messages, err := s.messageRepo.ListFileMessages(ctx, conversationID, beforeCursor, limit)
+	messages, err := s.messageRepo.ListFileMessages(ctx, conversationID, userID, beforeCursor, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -447,6 +978,7 @@ func (s *MessageServiceImpl) ListFileMessages(ctx context.Context, conversationI
 	for _, message := range messages {
 		responseDTOs = append(responseDTOs, *dto.MessageToMessageResponse(message))
 	}
+	s.attachMessageState(ctx, userID, messagePointers(responseDTOs)...)
 
 	// Generate next cursor
 	var nextCursor *string
@@ -464,5 +996,249 @@ func (s *MessageServiceImpl) ListFileMessages(ctx context.Context, conversationI
 	}, nil
 }
 
+func (s *MessageServiceImpl) SearchMessages(ctx context.Context, userID uuid.UUID, query string, conversationID *uuid.UUID, cursorStr *string, limit int) (*dto.MessageSearchResponse, error) {
+	query = strings.TrimSpace(query)
+	if length := utf8.RuneCountInString(query); length < minSearchQueryLength {
+		return nil, errors.New("search query is too short")
+	} else if length > maxSearchQueryLength {
+		return nil, errors.New("search query is too long")
+	}
+
+	if conversationID != nil && !s.isParticipant(ctx, *conversationID, userID) {
+		return nil, errors.New("access denied: not a participant")
+	}
+
+	// Set default limit
+	if limit <= 0 || limit > 50 {
+		limit = 20
+	}
+
+	cursor := &utils.OffsetCursor{}
+	if cursorStr != nil && *cursorStr != "" {
+		decoded, err := utils.DecodeOffsetCursor(*cursorStr)
+		if err != nil {
+			return nil, errors.New("invalid cursor")
+		}
+		cursor = decoded
+	}
+
+	mode := cursor.Mode
+	if mode == "" {
+		mode = models.MessageSearchModeFullText
+	}
+
+	// Fetch one extra to know if there are more
+	hits, err := s.messageRepo.Search(ctx, userID, query, conversationID, mode, cursor.Offset, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	// Whole-word search finds nothing in text without spaces; retry as substring on the first page
+	if len(hits) == 0 && cursor.Mode == "" && cursor.Offset == 0 {
+		mode = models.MessageSearchModeSubstring
+		hits, err = s.messageRepo.Search(ctx, userID, query, conversationID, mode, 0, limit+1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	hasMore := len(hits) > limit
+	if hasMore {
+		hits = hits[:limit]
+	}
+
+	conversations := make(map[uuid.UUID]*dto.ConversationResponse)
+	results := make([]dto.MessageSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		if hit.Message == nil {
+			continue
+		}
+
+		conversation, ok := conversations[hit.Message.ConversationID]
+		if !ok {
+			model, err := s.conversationRepo.GetByID(ctx, hit.Message.ConversationID)
+			if err != nil {
+				continue
+			}
+			conversation = dto.ConversationToConversationResponse(model, userID)
+			conversations[hit.Message.ConversationID] = conversation
+		}
+
+		content := ""
+		if hit.Message.Content != nil {
+			content = *hit.Message.Content
+		}
+		snippet, highlights := searchSnippet(content, query)
+
+		results = append(results, dto.MessageSearchResult{
+			Message:      *dto.MessageToMessageResponse(hit.Message),
+			Conversation: *conversation,
+			Snippet:      snippet,
+			Highlights:   highlights,
+			Rank:         hit.Rank,
+		})
+	}
+
+	messages := make([]*dto.MessageResponse, len(results))
+	for i := range results {
+		messages[i] = &results[i].Message
+	}
+	s.attachMessageState(ctx, userID, messages...)
+
+	// Generate next cursor
+	var nextCursor *string
+	if hasMore {
+		encoded, err := utils.EncodeOffsetCursor(cursor.Offset+limit, mode)
+		if err == nil {
+			nextCursor = &encoded
+		}
+	}
+
+	return &dto.MessageSearchResponse{
+		Results:    results,
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	}, nil
+}
+
+// searchSnippet cuts a window of content around the first match of query (or one of its
+// words) and returns the ranges of every match inside it
+func searchSnippet(content string, query string) (string, []dto.TextRange) {
+	text := []rune(content)
+
+	// Lower-case rune by rune so offsets stay aligned with text
+	lower := make([]rune, len(text))
+	for i, r := range text {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	// Whole query first so phrases win over their words
+	phrase := strings.ToLower(query)
+	terms := [][]rune{[]rune(phrase)}
+	for _, word := range strings.Fields(phrase) {
+		if word != phrase {
+			terms = append(terms, []rune(word))
+		}
+	}
+
+	var matches []dto.TextRange
+	for i := 0; i < len(lower); i++ {
+		for _, term := range terms {
+			if len(term) > 0 && i+len(term) <= len(lower) && string(lower[i:i+len(term)]) == string(term) {
+				matches = append(matches, dto.TextRange{Start: i, End: i + len(term)})
+				i += len(term) - 1
+				break
+			}
+		}
+	}
+
+	start, end := 0, len(text)
+	if len(matches) > 0 {
+		start = matches[0].Start - snippetRadius
+		end = matches[0].End + snippetRadius
+	} else {
+		end = 2 * snippetRadius
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end > len(text) {
+		end = len(text)
+	}
+
+	prefix, suffix := "", ""
+	if start > 0 {
+		prefix = "…"
+	}
+	if end < len(text) {
+		suffix = "…"
+	}
+	offset := utf8.RuneCountInString(prefix) - start
+
+	var highlights []dto.TextRange
+	for _, match := range matches {
+		if match.Start >= start && match.End <= end {
+			highlights = append(highlights, dto.TextRange{Start: match.Start + offset, End: match.End + offset})
+		}
+	}
+
+	return prefix + string(text[start:end]) + suffix, highlights
+}
+
+// checkMessageRequest enforces message request rules on a direct conversation: the requester
+// may send a few messages until the recipient accepts, and a reply from the recipient accepts it
+func (s *MessageServiceImpl) checkMessageRequest(ctx context.Context, conversation *models.Conversation, userID uuid.UUID) error {
+	if conversation.RequesterID == nil || conversation.RequestStatus == models.ConversationRequestAccepted {
+		return nil
+	}
+
+	if conversation.IsRequestFor(userID) {
+		if err := s.conversationRepo.UpdateRequestStatus(ctx, conversation.ID, models.ConversationRequestAccepted); err != nil {
+			return err
+		}
+
+		// Its unread messages now count toward the inbox total
+		unreadCount, _ := s.redisService.GetConversationUnreadCount(ctx, userID, conversation.ID)
+		_ = s.redisService.AddTotalUnread(ctx, userID, unreadCount)
+
+		conversation.RequestStatus = models.ConversationRequestAccepted
+		return nil
+	}
+
+	if conversation.RequestStatus == models.ConversationRequestDeclined {
+		return errors.New("cannot send message: message request was declined")
+	}
+
+	sent, err := s.messageRepo.CountBySender(ctx, conversation.ID, userID)
+	if err != nil {
+		return err
+	}
+	if sent >= maxRequestMessages {
+		return errors.New("message request limit reached: wait for the recipient to accept")
+	}
+
+	return nil
+}
+
+// isParticipant checks active membership without loading the whole conversation
+func (s *MessageServiceImpl) isParticipant(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) bool {
+	_, err := s.conversationRepo.GetParticipant(ctx, conversationID, userID)
+	return err == nil
+}
+
+// attachLinkPreview unfurls the first link of a message in the background and notifies the listener
+func (s *MessageServiceImpl) attachLinkPreview(messageID uuid.UUID, content string) {
+	if s.linkPreviewService == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), linkPreviewTimeout)
+	defer cancel()
+
+	preview, err := s.linkPreviewService.PreviewForText(ctx, content)
+	if err != nil {
+		log.Printf("Failed to get link preview for message %s: %v", messageID, err)
+		return
+	}
+	if preview == nil {
+		return
+	}
+
+	if err := s.messageRepo.SetLinkPreview(ctx, messageID, &preview.ID); err != nil {
+		log.Printf("Failed to attach link preview to message %s: %v", messageID, err)
+		return
+	}
+
+	if s.linkPreviewListener == nil {
+		return
+	}
+
+	message, err := s.messageRepo.GetByID(ctx, messageID)
+	if err != nil || message.IsDeleted() {
+		return
+	}
+	s.linkPreviewListener(dto.MessageToMessageResponse(message))
+}
+
 // Ensure interface compliance
 var _ services.MessageService = (*MessageServiceImpl)(nil)
@@ -0,0 +1,383 @@
+package serviceimpl
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gofiber-template/domain/dto"
+	"gofiber-template/domain/models"
+	"gofiber-template/domain/repositories"
+	"gofiber-template/domain/services"
+	"gofiber-template/infrastructure/redis"
+	"gofiber-template/pkg/config"
+	"gofiber-template/pkg/totp"
+	"gofiber-template/pkg/utils"
+)
+
+const (
+	mfaChallengeTTL    = 5 * time.Minute // A challenge takes a single code; a wrong one means signing in again
+	totpSkew           = 1               // Steps of clock drift accepted either way
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10 // Shown as two groups of five
+)
+
+// Lowercase letters and digits without the easily confused 0/o, 1/l/i
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+var errInvalidMFACode = errors.New("invalid verification code")
+
+type MFAServiceImpl struct {
+	userRepo         repositories.UserRepository
+	recoveryCodeRepo repositories.MFARecoveryCodeRepository
+	authService      services.AuthService
+	redisService     *redis.RedisService
+	txManager        repositories.TransactionManager
+	loginThrottle    *loginThrottle
+	secretBox        *utils.SecretBox
+	jwtSecret        string
+	issuer           string
+}
+
+func NewMFAService(
+	userRepo repositories.UserRepository,
+	recoveryCodeRepo repositories.MFARecoveryCodeRepository,
+	authService services.AuthService,
+	accountService services.AccountService,
+	redisService *redis.RedisService,
+	txManager repositories.TransactionManager,
+	cfg *config.Config,
+) services.MFAService {
+	encryptionKey := cfg.MFA.EncryptionKey
+	if encryptionKey == "" {
+		encryptionKey = cfg.JWT.Secret + ":totp"
+	}
+
+	return &MFAServiceImpl{
+		userRepo:         userRepo,
+		recoveryCodeRepo: recoveryCodeRepo,
+		authService:      authService,
+		redisService:     redisService,
+		txManager:        txManager,
+		loginThrottle:    newLoginThrottle(redisService, accountService),
+		secretBox:        utils.NewSecretBox(encryptionKey),
+		jwtSecret:        cfg.JWT.Secret,
+		issuer:           cfg.MFA.Issuer,
+	}
+}
+
+func (s *MFAServiceImpl) GetStatus(ctx context.Context, userID uuid.UUID) (*dto.MFAStatusResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	status := &dto.MFAStatusResponse{
+		Enabled:   user.TOTPEnabled,
+		EnabledAt: user.TOTPEnabledAt,
+		Required:  models.RoleRequiresMFA(user.Role),
+	}
+
+	if user.TOTPEnabled {
+		status.RecoveryCodesRemaining, err = s.recoveryCodeRepo.CountUnused(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return status, nil
+}
+
+func (s *MFAServiceImpl) BeginEnrollment(ctx context.Context, userID uuid.UUID) (*dto.MFASetupResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := s.secretBox.Seal(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	// Starting over replaces any unconfirmed secret
+	if err := s.userRepo.UpdateTOTP(ctx, userID, sealed, nil); err != nil {
+		return nil, err
+	}
+
+	return &dto.MFASetupResponse{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+func (s *MFAServiceImpl) ConfirmEnrollment(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, code string) (*dto.RecoveryCodesResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+	if user.TOTPSecret == "" {
+		return nil, errors.New("two-factor setup has not been started")
+	}
+
+	if err := s.verifyTOTP(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	codes, records, err := generateRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.UpdateTOTP(ctx, userID, user.TOTPSecret, &now); err != nil {
+			return err
+		}
+		return s.recoveryCodeRepo.ReplaceForUser(ctx, userID, records)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The code just entered proves the factor for this session too
+	if err := s.authService.MarkSessionMFAVerified(ctx, sessionID); err != nil {
+		log.Printf("Failed to mark session %s as MFA verified: %v", sessionID, err)
+	}
+
+	log.Printf("🔐 Two-factor authentication enabled for user %s", userID)
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (s *MFAServiceImpl) Disable(ctx context.Context, userID uuid.UUID, code string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	if !user.TOTPEnabled {
+		return errors.New("two-factor authentication is not enabled")
+	}
+
+	if models.RoleRequiresMFA(user.Role) {
+		return errors.New("two-factor authentication is required for your role")
+	}
+
+	if err := s.verifyCode(ctx, user, code); err != nil {
+		return err
+	}
+
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.UpdateTOTP(ctx, userID, "", nil); err != nil {
+			return err
+		}
+		return s.recoveryCodeRepo.DeleteByUser(ctx, userID)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("🔓 Two-factor authentication disabled for user %s", userID)
+	return nil
+}
+
+func (s *MFAServiceImpl) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) (*dto.RecoveryCodesResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if !user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is not enabled")
+	}
+
+	// Only the authenticator itself may mint new codes
+	if err := s.verifyTOTP(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	codes, records, err := generateRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.recoveryCodeRepo.ReplaceForUser(ctx, userID, records); err != nil {
+		return nil, err
+	}
+
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (s *MFAServiceImpl) CreateChallenge(ctx context.Context, user *models.User) (string, error) {
+	// Bound to the secret, so disabling or re-enrolling 2FA voids pending challenges
+	token, _, err := utils.GenerateActionToken(utils.ActionMFAChallenge, user.ID, utils.ActionTokenBinding(user.TOTPSecret), mfaChallengeTTL, s.jwtSecret)
+	return token, err
+}
+
+func (s *MFAServiceImpl) VerifyChallenge(ctx context.Context, mfaToken string, code string, client *dto.ClientInfo) (*dto.TokenPair, *models.User, error) {
+	claims, err := utils.ParseActionToken(mfaToken, utils.ActionMFAChallenge, s.jwtSecret)
+	if err != nil {
+		if errors.Is(err, utils.ErrExpiredToken) {
+			return nil, nil, errors.New("login challenge has expired, please sign in again")
+		}
+		return nil, nil, errors.New("invalid login challenge")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil || !user.TOTPEnabled || claims.Binding != utils.ActionTokenBinding(user.TOTPSecret) {
+		return nil, nil, errors.New("invalid login challenge")
+	}
+
+	if !user.IsActive {
+		return nil, nil, errors.New("account is disabled")
+	}
+
+	// Second factor guesses count against the same account and IP limits as passwords,
+	// so signing in again for fresh challenges doesn't reset the budget
+	if err := s.loginThrottle.check(ctx, user.Email, client.IPAddress); err != nil {
+		return nil, nil, err
+	}
+
+	// Consumed before the code is checked, so a replayed or parallel request can't spend
+	// a recovery code or TOTP step. A wrong code burns the challenge as well.
+	fresh, err := s.redisService.ConsumeActionToken(ctx, claims.Nonce, mfaChallengeTTL)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !fresh {
+		return nil, nil, errors.New("login challenge has already been used")
+	}
+
+	if err := s.verifyCode(ctx, user, code); err != nil {
+		if errors.Is(err, errInvalidMFACode) {
+			s.loginThrottle.recordFailure(ctx, user.Email, client.IPAddress, &user.ID)
+			return nil, nil, errors.New("invalid verification code, please sign in again")
+		}
+		return nil, nil, err
+	}
+
+	s.loginThrottle.recordSuccess(ctx, user.Email)
+
+	tokens, err := s.authService.IssueTokens(ctx, user, client, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tokens, user, nil
+}
+
+func (s *MFAServiceImpl) VerifyCode(ctx context.Context, userID uuid.UUID, code string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	if !user.TOTPEnabled {
+		return errors.New("two-factor authentication is not enabled")
+	}
+
+	return s.verifyCode(ctx, user, code)
+}
+
+// verifyCode accepts either a TOTP code or an unused recovery code
+func (s *MFAServiceImpl) verifyCode(ctx context.Context, user *models.User, code string) error {
+	normalized := normalizeRecoveryCode(code)
+	if len(normalized) == totp.Digits {
+		return s.verifyTOTP(ctx, user, code)
+	}
+
+	used, err := s.recoveryCodeRepo.Consume(ctx, user.ID, hashRecoveryCode(normalized), time.Now())
+	if err != nil {
+		return err
+	}
+	if !used {
+		return errInvalidMFACode
+	}
+
+	log.Printf("🔑 Recovery code used by user %s", user.ID)
+	return nil
+}
+
+// verifyTOTP checks a code against the user's secret and rejects codes already used
+func (s *MFAServiceImpl) verifyTOTP(ctx context.Context, user *models.User, code string) error {
+	secret, err := s.secretBox.Open(user.TOTPSecret)
+	if err != nil {
+		return err
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now(), totpSkew)
+	if !ok {
+		return errInvalidMFACode
+	}
+
+	// Remember the step for as long as the code could still validate
+	fresh, err := s.redisService.MarkTOTPStepUsed(ctx, user.ID, step, time.Duration(2*totpSkew+1)*totp.Period*time.Second)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return errors.New("verification code has already been used")
+	}
+
+	return nil
+}
+
+// generateRecoveryCodes returns the codes to show the user and the hashed rows to store
+func generateRecoveryCodes(userID uuid.UUID) ([]string, []*models.MFARecoveryCode, error) {
+	codes := make([]string, recoveryCodeCount)
+	records := make([]*models.MFARecoveryCode, recoveryCodeCount)
+	alphabetSize := big.NewInt(int64(len(recoveryCodeAlphabet)))
+
+	for i := range codes {
+		raw := make([]byte, recoveryCodeLength)
+		for j := range raw {
+			n, err := rand.Int(rand.Reader, alphabetSize)
+			if err != nil {
+				return nil, nil, err
+			}
+			raw[j] = recoveryCodeAlphabet[n.Int64()]
+		}
+
+		half := recoveryCodeLength / 2
+		codes[i] = string(raw[:half]) + "-" + string(raw[half:])
+		records[i] = &models.MFARecoveryCode{
+			ID:       uuid.New(),
+			UserID:   userID,
+			CodeHash: hashRecoveryCode(string(raw)),
+		}
+	}
+
+	return codes, records, nil
+}
+
+// normalizeRecoveryCode drops separators and case so codes can be typed loosely
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+}
+
+func hashRecoveryCode(normalized string) string {
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// Ensure interface compliance
+var _ services.MFAService = (*MFAServiceImpl)(nil)
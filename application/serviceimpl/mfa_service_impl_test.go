@@ -0,0 +1,150 @@
+package serviceimpl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gofiber-template/domain/dto"
+	"gofiber-template/domain/models"
+	"gofiber-template/domain/repositories"
+	"gofiber-template/pkg/config"
+	"gofiber-template/pkg/totp"
+	"gofiber-template/pkg/utils"
+)
+
+// fakeRecoveryCodeRepo keeps recovery code hashes and whether each was used
+type fakeRecoveryCodeRepo struct {
+	repositories.MFARecoveryCodeRepository
+	used map[string]bool
+}
+
+func (r *fakeRecoveryCodeRepo) Consume(ctx context.Context, userID uuid.UUID, codeHash string, at time.Time) (bool, error) {
+	used, exists := r.used[codeHash]
+	if !exists || used {
+		return false, nil
+	}
+	r.used[codeHash] = true
+	return true, nil
+}
+
+type mfaFixture struct {
+	service       *MFAServiceImpl
+	user          *models.User
+	secret        string
+	recoveryCodes *fakeRecoveryCodeRepo
+}
+
+// newMFAFixture returns an MFA service for a user with TOTP enabled and the given recovery codes
+func newMFAFixture(t *testing.T, recoveryCodes ...string) *mfaFixture {
+	t.Helper()
+	_, redisService := newTestRedis(t)
+	cfg := &config.Config{JWT: config.JWTConfig{Secret: "test-secret"}}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := utils.NewSecretBox(cfg.JWT.Secret + ":totp").Seal(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := &models.User{
+		ID:          uuid.New(),
+		Email:       "owner@example.com",
+		IsActive:    true,
+		TOTPSecret:  sealed,
+		TOTPEnabled: true,
+	}
+	repo := &fakeRecoveryCodeRepo{used: map[string]bool{}}
+	for _, code := range recoveryCodes {
+		repo.used[hashRecoveryCode(normalizeRecoveryCode(code))] = false
+	}
+
+	service := NewMFAService(&stubUserRepo{user: user}, repo, stubAuthService{}, stubAccountService{}, redisService, nil, cfg)
+	return &mfaFixture{service: service.(*MFAServiceImpl), user: user, secret: secret, recoveryCodes: repo}
+}
+
+func (f *mfaFixture) currentCode(t *testing.T) string {
+	t.Helper()
+	code, err := totp.Code(f.secret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func (f *mfaFixture) challenge(t *testing.T) string {
+	t.Helper()
+	token, err := f.service.CreateChallenge(context.Background(), f.user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestTOTPCodeCannotBeReplayed(t *testing.T) {
+	f := newMFAFixture(t)
+	ctx := context.Background()
+	code := f.currentCode(t)
+
+	if err := f.service.VerifyCode(ctx, f.user.ID, code); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+	if err := f.service.VerifyCode(ctx, f.user.ID, code); err == nil {
+		t.Fatal("replayed TOTP code accepted")
+	}
+}
+
+func TestRecoveryCodeWorksOnce(t *testing.T) {
+	f := newMFAFixture(t, "abcde-fghjk")
+	ctx := context.Background()
+
+	// Separators and case don't matter
+	if err := f.service.VerifyCode(ctx, f.user.ID, "ABCDE FGHJK"); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+	if err := f.service.VerifyCode(ctx, f.user.ID, "abcde-fghjk"); !errors.Is(err, errInvalidMFACode) {
+		t.Fatalf("second use: got %v, want %v", err, errInvalidMFACode)
+	}
+}
+
+func TestReplayedChallengeDoesNotSpendRecoveryCode(t *testing.T) {
+	f := newMFAFixture(t, "abcde-fghjk", "mnpqr-stuvw")
+	ctx := context.Background()
+	client := &dto.ClientInfo{IPAddress: "203.0.113.1"}
+	challenge := f.challenge(t)
+
+	if _, _, err := f.service.VerifyChallenge(ctx, challenge, "abcde-fghjk", client); err != nil {
+		t.Fatalf("first verification: %v", err)
+	}
+	if _, _, err := f.service.VerifyChallenge(ctx, challenge, "mnpqr-stuvw", client); err == nil {
+		t.Fatal("used challenge accepted again")
+	}
+
+	if f.recoveryCodes.used[hashRecoveryCode("mnpqrstuvw")] {
+		t.Fatal("replayed challenge spent a recovery code")
+	}
+}
+
+func TestWrongCodeEndsChallenge(t *testing.T) {
+	f := newMFAFixture(t)
+	ctx := context.Background()
+	client := &dto.ClientInfo{IPAddress: "203.0.113.1"}
+	challenge := f.challenge(t)
+
+	if _, _, err := f.service.VerifyChallenge(ctx, challenge, wrongTOTPCode(t, f.secret), client); err == nil {
+		t.Fatal("wrong code accepted")
+	}
+	if _, _, err := f.service.VerifyChallenge(ctx, challenge, f.currentCode(t), client); err == nil {
+		t.Fatal("challenge still usable after a wrong code")
+	}
+
+	// The right code was not spent by the rejected attempt
+	if err := f.service.VerifyCode(ctx, f.user.ID, f.currentCode(t)); err != nil {
+		t.Fatalf("code spent by a burned challenge: %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package serviceimpl
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"log"
 	"time"
@@ -14,22 +15,33 @@ import (
 	"gofiber-template/infrastructure/websocket"
 )
 
+const (
+	notificationOutboxMaxAttempts = 5 // Per channel, before the entry is marked failed
+)
+
 type NotificationServiceImpl struct {
 	notifRepo         repositories.NotificationRepository
 	notifSettingsRepo repositories.NotificationSettingsRepository
+	outboxRepo        repositories.NotificationOutboxRepository
 	userRepo          repositories.UserRepository
+	txManager         repositories.TransactionManager
 	pushService       services.PushService
+	deliveryTrigger   func()
 }
 
 func NewNotificationService(
 	notifRepo repositories.NotificationRepository,
 	notifSettingsRepo repositories.NotificationSettingsRepository,
+	outboxRepo repositories.NotificationOutboxRepository,
 	userRepo repositories.UserRepository,
+	txManager repositories.TransactionManager,
 ) services.NotificationService {
 	return &NotificationServiceImpl{
 		notifRepo:         notifRepo,
 		notifSettingsRepo: notifSettingsRepo,
+		outboxRepo:        outboxRepo,
 		userRepo:          userRepo,
+		txManager:         txManager,
 		pushService:       nil, // Will be set later via SetPushService
 	}
 }
@@ -39,6 +51,11 @@ func (s *NotificationServiceImpl) SetPushService(pushService services.PushServic
 	s.pushService = pushService
 }
 
+// SetDeliveryTrigger sets a callback that wakes the outbox worker after new entries are queued
+func (s *NotificationServiceImpl) SetDeliveryTrigger(trigger func()) {
+	s.deliveryTrigger = trigger
+}
+
 func (s *NotificationServiceImpl) GetNotifications(ctx context.Context, userID uuid.UUID, offset, limit int) (*dto.NotificationListResponse, error) {
 	notifications, err := s.notifRepo.ListByUser(ctx, userID, offset, limit)
 	if err != nil {
@@ -179,15 +196,7 @@ func (s *NotificationServiceImpl) GetSettings(ctx context.Context, userID uuid.U
 	settings, err := s.notifSettingsRepo.GetByUserID(ctx, userID)
 	if err != nil {
 		// If not found, create default settings
-		defaultSettings := &models.NotificationSettings{
-			UserID:             userID,
-			Replies:            true,
-			Mentions:           true,
-			Votes:              false,
-			Follows:            true,
-			EmailNotifications: false,
-			UpdatedAt:          time.Now(),
-		}
+		defaultSettings := models.DefaultNotificationSettings(userID)
 		err = s.notifSettingsRepo.Create(ctx, defaultSettings)
 		if err != nil {
 			return nil, err
@@ -203,26 +212,52 @@ func (s *NotificationServiceImpl) UpdateSettings(ctx context.Context, userID uui
 	settings, err := s.notifSettingsRepo.GetByUserID(ctx, userID)
 	if err != nil {
 		// Create if not exists
-		settings = &models.NotificationSettings{
-			UserID: userID,
-		}
+		settings = models.DefaultNotificationSettings(userID)
 	}
 
-	// Update fields
-	if req.Replies != nil {
-		settings.Replies = *req.Replies
+	matrix := settings.EffectivePreferences()
+
+	// Legacy switches apply to every channel of their type
+	setType := func(notifType string, enabled *bool) {
+		if enabled == nil {
+			return
+		}
+		for _, channel := range models.PreferenceChannels {
+			matrix[notifType][channel] = *enabled
+		}
 	}
-	if req.Mentions != nil {
-		settings.Mentions = *req.Mentions
+	setType(models.NotificationTypeReply, req.Replies)
+	setType(models.NotificationTypeMention, req.Mentions)
+	setType(models.NotificationTypeVote, req.Votes)
+	setType(models.NotificationTypeFollow, req.Follows)
+	if req.EmailNotifications != nil {
+		for _, notifType := range models.NotificationTypes {
+			matrix[notifType][models.PreferenceChannelEmail] = *req.EmailNotifications
+		}
 	}
-	if req.Votes != nil {
-		settings.Votes = *req.Votes
+
+	// Matrix cells override legacy switches
+	for notifType, channels := range req.Matrix {
+		normalized := models.NormalizeNotificationType(notifType)
+		if _, ok := matrix[normalized]; !ok {
+			return nil, errors.New("unknown notification type: " + notifType)
+		}
+		for channel, enabled := range channels {
+			if _, ok := matrix[normalized][channel]; !ok {
+				return nil, errors.New("unknown notification channel: " + channel)
+			}
+			matrix[normalized][channel] = enabled
+		}
 	}
-	if req.Follows != nil {
-		settings.Follows = *req.Follows
+
+	if err := settings.SetPreferences(matrix); err != nil {
+		return nil, err
 	}
-	if req.EmailNotifications != nil {
-		settings.EmailNotifications = *req.EmailNotifications
+
+	if req.QuietHours != nil {
+		if err := applyQuietHours(settings, req.QuietHours); err != nil {
+			return nil, err
+		}
 	}
 	settings.UpdatedAt = time.Now()
 
@@ -234,11 +269,52 @@ func (s *NotificationServiceImpl) UpdateSettings(ctx context.Context, userID uui
 	return dto.NotificationSettingsToResponse(settings), nil
 }
 
+// applyQuietHours validates and copies a quiet hours update onto settings
+func applyQuietHours(settings *models.NotificationSettings, req *dto.QuietHoursRequest) error {
+	if req.Start != nil {
+		if _, err := models.ParseClockMinutes(*req.Start); err != nil {
+			return errors.New("invalid quiet hours start, expected HH:MM")
+		}
+		settings.QuietHoursStart = *req.Start
+	}
+	if req.End != nil {
+		if _, err := models.ParseClockMinutes(*req.End); err != nil {
+			return errors.New("invalid quiet hours end, expected HH:MM")
+		}
+		settings.QuietHoursEnd = *req.End
+	}
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil {
+			return errors.New("invalid timezone")
+		}
+		settings.Timezone = *req.Timezone
+	}
+	if req.Enabled != nil {
+		settings.QuietHoursEnabled = *req.Enabled
+	}
+	if settings.QuietHoursEnabled && settings.QuietHoursStart == settings.QuietHoursEnd {
+		return errors.New("quiet hours start and end must differ")
+	}
+	return nil
+}
+
+// CreateNotification stores the notification and its outbox entries in the caller's
+// transaction, so they commit or roll back with the change being notified about.
+// Only failing to store them fails that transaction: settings that can't be loaded
+// fall back to the defaults and a channel that can't be rendered is skipped.
 func (s *NotificationServiceImpl) CreateNotification(ctx context.Context, userID uuid.UUID, senderID uuid.UUID, notifType string, message string, postID *uuid.UUID, commentID *uuid.UUID) error {
-	// Check if user wants to receive this notification type
-	shouldNotify, _ := s.notifSettingsRepo.ShouldNotify(ctx, userID, notifType)
-	if !shouldNotify {
-		return nil // User has disabled this notification type
+	// Lookups run outside the caller's transaction, where a failed query would abort it
+	lookupCtx := s.txManager.WithoutTransaction(ctx)
+	now := time.Now()
+
+	// Check which channels the user wants for this notification type
+	decision, err := s.notifSettingsRepo.ShouldNotify(lookupCtx, userID, notifType, now)
+	if err != nil {
+		log.Printf("⚠️ Failed to load notification settings of user %s, using defaults: %v", userID.String(), err)
+		decision = models.DefaultNotificationSettings(userID).Decide(notifType, now)
+	}
+	if !decision.Any() {
+		return nil // User has disabled this notification type on every channel
 	}
 
 	notification := &models.Notification{
@@ -250,57 +326,237 @@ func (s *NotificationServiceImpl) CreateNotification(ctx context.Context, userID
 		PostID:    postID,
 		CommentID: commentID,
 		IsRead:    false,
-		CreatedAt: time.Now(),
+		CreatedAt: now,
 	}
 
-	// Create notification in database
-	err := s.notifRepo.Create(ctx, notification)
+	entries := s.buildOutboxEntries(lookupCtx, notification, decision)
+	if len(entries) == 0 {
+		return nil
+	}
+
+	// Notification and its outbox entries are committed together. When the caller
+	// already runs a transaction (e.g. creating a comment), this joins it.
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		// The notification row backs the in-app list; push and email carry their own payload
+		if decision.InApp {
+			if err := s.notifRepo.Create(ctx, notification); err != nil {
+				return err
+			}
+		}
+		return s.enqueue(ctx, entries)
+	})
 	if err != nil {
 		return err
 	}
 
-	// Fetch notification with relations for real-time broadcast
-	createdNotification, err := s.notifRepo.GetByID(ctx, notification.ID)
+	log.Printf("📬 Notification queued for user %s (%d channel(s)): %s", userID.String(), len(entries), message)
+
+	// The worker can only see the entries once the caller's transaction commits
+	if s.deliveryTrigger != nil {
+		s.txManager.AfterCommit(ctx, s.deliveryTrigger)
+	}
+
+	return nil
+}
+
+func (s *NotificationServiceImpl) DispatchPush(ctx context.Context, userID uuid.UUID, notifType string, payload *dto.PushNotificationPayload) error {
+	decision, err := s.notifSettingsRepo.ShouldNotify(ctx, userID, notifType, time.Now())
 	if err != nil {
-		log.Printf("Warning: Failed to fetch notification for WebSocket broadcast: %v", err)
-		return nil // Don't fail the whole operation
+		return err
+	}
+	if !decision.Push || s.pushService == nil {
+		return nil
 	}
 
-	// Convert to DTO
-	notificationDTO := dto.NotificationToNotificationResponse(createdNotification)
+	entry, err := s.newPushEntry(nil, userID, payload, decision.PushDeferredUntil)
+	if err != nil {
+		return err
+	}
 
-	// Send real-time notification via WebSocket
-	websocket.Manager.BroadcastToUser(userID, "notification", map[string]interface{}{
-		"notification": notificationDTO,
-		"unreadCount":  s.getUnreadCount(ctx, userID),
-	})
+	if err := s.enqueue(ctx, []*models.NotificationOutbox{entry}); err != nil {
+		return err
+	}
 
-	log.Printf("📬 Real-time notification sent to user %s: %s", userID.String(), message)
-
-	// Send push notification (if user is offline and pushService is available)
-	if s.pushService != nil {
-		// Prepare push payload
-		pushPayload := &dto.PushNotificationPayload{
-			Title: "VOOBIZE",
-			Body:  message,
-			Icon:  "/logo.png",
-			Badge: "/logo.png",
-			Tag:   notifType,
-			Data: map[string]interface{}{
-				"notificationId": notification.ID.String(),
-				"url":            s.buildNotificationURL(postID, commentID),
-			},
+	if decision.PushDeferredUntil != nil {
+		log.Printf("🌙 Push for user %s deferred until %s (quiet hours)", userID.String(), decision.PushDeferredUntil.Format(time.RFC3339))
+	} else if s.deliveryTrigger != nil {
+		s.txManager.AfterCommit(ctx, s.deliveryTrigger)
+	}
+
+	return nil
+}
+
+func (s *NotificationServiceImpl) GetDeliveryStatus(ctx context.Context, notificationID uuid.UUID, userID uuid.UUID) ([]dto.NotificationDeliveryResponse, error) {
+	notification, err := s.notifRepo.GetByID(ctx, notificationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check ownership
+	if notification.UserID != userID {
+		return nil, errors.New("unauthorized: not notification owner")
+	}
+
+	entries, err := s.outboxRepo.ListByNotification(ctx, notificationID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.NotificationDeliveryResponse, len(entries))
+	for i, entry := range entries {
+		responses[i] = *dto.NotificationOutboxToDeliveryResponse(entry)
+	}
+
+	return responses, nil
+}
+
+// buildOutboxEntries prepares one outbox entry per channel allowed by decision.
+// A channel whose payload can't be rendered is logged and left out.
+func (s *NotificationServiceImpl) buildOutboxEntries(ctx context.Context, notification *models.Notification, decision *models.NotificationDecision) []*models.NotificationOutbox {
+	var entries []*models.NotificationOutbox
+	url := s.buildNotificationURL(notification.PostID, notification.CommentID)
+
+	// Real-time delivery payload is rendered at delivery time
+	if decision.InApp {
+		entries = append(entries, newOutboxEntry(&notification.ID, notification.UserID, models.NotificationChannelWebSocket, []byte("{}"), time.Now()))
+	}
+
+	if decision.Push && s.pushService != nil {
+		data := map[string]interface{}{"url": url}
+		if decision.InApp {
+			data["notificationId"] = notification.ID.String()
+		}
+
+		entry, err := s.newPushEntry(s.notificationRef(notification, decision), notification.UserID, &dto.PushNotificationPayload{
+			Title:   "VOOBIZE",
+			Body:    notification.Message,
+			Icon:    "/logo.png",
+			Badge:   "/logo.png",
+			Tag:     notification.Type,
+			Data:    data,
+			Options: s.pushOptions(notification),
+		}, decision.PushDeferredUntil)
+		if err != nil {
+			log.Printf("⚠️ Failed to render push for notification %s: %v", notification.ID.String(), err)
+		} else {
+			entries = append(entries, entry)
 		}
+	}
 
-		// Send push notification (non-blocking)
-		go func() {
-			if err := s.pushService.SendToUser(context.Background(), userID, pushPayload); err != nil {
-				log.Printf("⚠️  Failed to send push notification: %v", err)
+	if decision.Email {
+		entry, err := s.newEmailEntry(ctx, notification, decision, url)
+		if err != nil {
+			log.Printf("⚠️ Failed to render email for notification %s: %v", notification.ID.String(), err)
+		} else if entry != nil {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+// newPushEntry prepares a push outbox entry, optionally deferred (quiet hours)
+func (s *NotificationServiceImpl) newPushEntry(notificationID *uuid.UUID, userID uuid.UUID, payload *dto.PushNotificationPayload, deferredUntil *time.Time) (*models.NotificationOutbox, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	nextAttemptAt := time.Now()
+	if deferredUntil != nil {
+		nextAttemptAt = *deferredUntil
+	}
+
+	entry := newOutboxEntry(notificationID, userID, models.NotificationChannelPush, data, nextAttemptAt)
+	if payload.Options != nil {
+		entry.Topic = payload.Options.Topic
+	}
+	return entry, nil
+}
+
+// newEmailEntry renders the email now so delivery doesn't depend on the in-app row
+func (s *NotificationServiceImpl) newEmailEntry(ctx context.Context, notification *models.Notification, decision *models.NotificationDecision, url string) (*models.NotificationOutbox, error) {
+	recipient, err := s.userRepo.GetByID(ctx, notification.UserID)
+	if err != nil || recipient.Email == "" {
+		return nil, nil // Nothing to send to
+	}
+
+	senderName := "VOOBIZE"
+	if sender, err := s.userRepo.GetByID(ctx, notification.SenderID); err == nil {
+		senderName = sender.Username
+		if sender.DisplayName != "" {
+			senderName = sender.DisplayName
+		}
+	}
+
+	data, err := json.Marshal(map[string]string{
+		"to":      recipient.Email,
+		"subject": "VOOBIZE: " + senderName + " " + notification.Message,
+		"body":    senderName + " " + notification.Message,
+		"url":     url,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newOutboxEntry(s.notificationRef(notification, decision), notification.UserID, models.NotificationChannelEmail, data, time.Now()), nil
+}
+
+// notificationRef links outbox entries to the notification row when one is stored
+func (s *NotificationServiceImpl) notificationRef(notification *models.Notification, decision *models.NotificationDecision) *uuid.UUID {
+	if !decision.InApp {
+		return nil
+	}
+	return &notification.ID
+}
+
+// enqueue stores outbox entries. A deferred push replaces older deferred pushes
+// with the same topic, so quiet hours end with one push per conversation/post.
+func (s *NotificationServiceImpl) enqueue(ctx context.Context, entries []*models.NotificationOutbox) error {
+	for _, entry := range entries {
+		if entry.Channel == models.NotificationChannelPush && entry.Topic != "" && entry.NextAttemptAt.After(time.Now()) {
+			if err := s.outboxRepo.DeletePendingByTopic(ctx, entry.UserID, entry.Channel, entry.Topic); err != nil {
+				return err
 			}
-		}()
+		}
 	}
+	return s.outboxRepo.Create(ctx, entries)
+}
 
-	return nil
+func newOutboxEntry(notificationID *uuid.UUID, userID uuid.UUID, channel string, payload []byte, nextAttemptAt time.Time) *models.NotificationOutbox {
+	now := time.Now()
+	return &models.NotificationOutbox{
+		ID:             uuid.New(),
+		NotificationID: notificationID,
+		UserID:         userID,
+		Channel:        channel,
+		Payload:        payload,
+		Status:         models.OutboxStatusPending,
+		MaxAttempts:    notificationOutboxMaxAttempts,
+		NextAttemptAt:  nextAttemptAt,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// pushOptions picks urgency and a collapse topic per notification type,
+// so e.g. a burst of upvotes on one post shows up as a single push
+func (s *NotificationServiceImpl) pushOptions(notification *models.Notification) *dto.PushDeliveryOptions {
+	options := &dto.PushDeliveryOptions{Urgency: "normal"}
+
+	switch notification.Type {
+	case "vote", "votes":
+		options.Urgency = "low"
+		if notification.CommentID != nil {
+			options.Topic = "vote:comment:" + notification.CommentID.String()
+		} else if notification.PostID != nil {
+			options.Topic = "vote:post:" + notification.PostID.String()
+		}
+	case "follow", "follows":
+		options.Urgency = "low"
+	}
+
+	return options
 }
 
 // Helper function to get unread count
@@ -0,0 +1,95 @@
+package serviceimpl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gofiber-template/domain/models"
+	"gofiber-template/domain/repositories"
+)
+
+// fakeTxManager runs units of work directly and holds AfterCommit hooks until commit
+type fakeTxManager struct {
+	hooks []func()
+}
+
+func (m *fakeTxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (m *fakeTxManager) AfterCommit(ctx context.Context, fn func()) {
+	m.hooks = append(m.hooks, fn)
+}
+
+func (m *fakeTxManager) WithoutTransaction(ctx context.Context) context.Context {
+	return ctx
+}
+
+func (m *fakeTxManager) commit() {
+	for _, hook := range m.hooks {
+		hook()
+	}
+	m.hooks = nil
+}
+
+type failingSettingsRepo struct {
+	repositories.NotificationSettingsRepository
+}
+
+func (failingSettingsRepo) ShouldNotify(ctx context.Context, userID uuid.UUID, notificationType string, now time.Time) (*models.NotificationDecision, error) {
+	return nil, errors.New("connection reset")
+}
+
+type recordingNotificationRepo struct {
+	repositories.NotificationRepository
+	created []*models.Notification
+}
+
+func (r *recordingNotificationRepo) Create(ctx context.Context, notification *models.Notification) error {
+	r.created = append(r.created, notification)
+	return nil
+}
+
+type recordingOutboxRepo struct {
+	repositories.NotificationOutboxRepository
+	entries []*models.NotificationOutbox
+}
+
+func (r *recordingOutboxRepo) Create(ctx context.Context, entries []*models.NotificationOutbox) error {
+	r.entries = append(r.entries, entries...)
+	return nil
+}
+
+func TestCreateNotificationFallsBackToDefaultSettings(t *testing.T) {
+	notifRepo := &recordingNotificationRepo{}
+	outboxRepo := &recordingOutboxRepo{}
+	txManager := &fakeTxManager{}
+	recipient := &models.User{ID: uuid.New()} // No email: the email channel is skipped
+
+	service := NewNotificationService(notifRepo, failingSettingsRepo{}, outboxRepo, &stubUserRepo{user: recipient}, txManager).(*NotificationServiceImpl)
+	triggered := 0
+	service.SetDeliveryTrigger(func() { triggered++ })
+
+	err := service.CreateNotification(context.Background(), recipient.ID, uuid.New(), models.NotificationTypeFollow, "started following you", nil, nil)
+	if err != nil {
+		t.Fatalf("a settings lookup failure must not fail the caller: %v", err)
+	}
+
+	if len(notifRepo.created) != 1 {
+		t.Fatalf("expected the in-app notification from the defaults, got %d", len(notifRepo.created))
+	}
+	if len(outboxRepo.entries) == 0 || outboxRepo.entries[0].Channel != models.NotificationChannelWebSocket {
+		t.Fatalf("expected a websocket outbox entry, got %+v", outboxRepo.entries)
+	}
+
+	if triggered != 0 {
+		t.Fatal("worker woken before the transaction committed")
+	}
+	txManager.commit()
+	if triggered != 1 {
+		t.Fatalf("expected the worker to be woken once after commit, got %d", triggered)
+	}
+}
@@ -5,113 +5,234 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
+	"log"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
-	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
 	"gofiber-template/domain/dto"
 	"gofiber-template/domain/models"
 	"gofiber-template/domain/repositories"
 	"gofiber-template/domain/services"
+	"gofiber-template/infrastructure/redis"
+	"gofiber-template/pkg/auth_code_store"
 	"gofiber-template/pkg/config"
+	"gofiber-template/pkg/oauth"
 	"gofiber-template/pkg/utils"
 )
 
+const oauthStateTTL = 10 * time.Minute // Time to finish logging in at the provider
+
+// oauthState is what the callback needs from the start of the login
+type oauthState struct {
+	Provider      string     `json:"provider"`
+	Verifier      string     `json:"verifier"`
+	CodeChallenge string     `json:"codeChallenge,omitempty"` // The frontend's own PKCE challenge for /auth/exchange
+	LinkUserID    *uuid.UUID `json:"linkUserId,omitempty"`    // Set when linking to a signed-in user instead of signing in
+}
+
 type OAuthServiceImpl struct {
 	userRepo     repositories.UserRepository
-	config       *config.Config
-	googleConfig *oauth2.Config
+	identityRepo repositories.UserIdentityRepository
+	txManager    repositories.TransactionManager
+	authService  services.AuthService
+	mfaService   services.MFAService
+	redisService  *redis.RedisService
+	authCodeStore auth_code_store.Store
+	config        *config.Config
+	providers     map[string]oauth.Provider
 }
 
-func NewOAuthService(userRepo repositories.UserRepository, cfg *config.Config) services.OAuthService {
-	googleConfig := &oauth2.Config{
-		ClientID:     cfg.OAuth.Google.ClientID,
-		ClientSecret: cfg.OAuth.Google.ClientSecret,
-		RedirectURL:  cfg.OAuth.Google.RedirectURL,
-		Scopes: []string{
-			"https://www.googleapis.com/auth/userinfo.email",
-			"https://www.googleapis.com/auth/userinfo.profile",
-		},
-		Endpoint: google.Endpoint,
+func NewOAuthService(
+	userRepo repositories.UserRepository,
+	identityRepo repositories.UserIdentityRepository,
+	txManager repositories.TransactionManager,
+	authService services.AuthService,
+	mfaService services.MFAService,
+	redisService *redis.RedisService,
+	authCodeStore auth_code_store.Store,
+	providers []oauth.Provider,
+	cfg *config.Config,
+) services.OAuthService {
+	byName := make(map[string]oauth.Provider, len(providers))
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
 	}
 
 	return &OAuthServiceImpl{
 		userRepo:     userRepo,
-		config:       cfg,
-		googleConfig: googleConfig,
+		identityRepo: identityRepo,
+		txManager:    txManager,
+		authService:  authService,
+		mfaService:   mfaService,
+		redisService:  redisService,
+		authCodeStore: authCodeStore,
+		config:        cfg,
+		providers:     byName,
+	}
+}
+
+func (s *OAuthServiceImpl) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *OAuthServiceImpl) GetAuthURL(ctx context.Context, provider string, state string, codeChallenge string) (string, error) {
+	return s.authCodeURL(ctx, provider, &oauthState{Provider: provider, CodeChallenge: codeChallenge}, state)
 }
 
-func (s *OAuthServiceImpl) GetGoogleAuthURL(state string) string {
-	return s.googleConfig.AuthCodeURL(state, oauth2.AccessTypeOffline)
+func (s *OAuthServiceImpl) GetLinkURL(ctx context.Context, provider string, userID uuid.UUID, sessionID uuid.UUID, state string) (string, error) {
+	if err := s.authService.RequireRecentAuth(ctx, userID, sessionID); err != nil {
+		return "", err
+	}
+	return s.authCodeURL(ctx, provider, &oauthState{Provider: provider, LinkUserID: &userID}, state)
 }
 
-func (s *OAuthServiceImpl) HandleGoogleCallback(ctx context.Context, code string) (*dto.OAuthLoginResponse, error) {
-	// Debug logging
-	fmt.Printf("\n=== OAuth Service Debug ===\n")
-	fmt.Printf("Exchanging code: %s\n", code)
-	fmt.Printf("Code length: %d\n", len(code))
-	fmt.Printf("Client ID: %s\n", s.googleConfig.ClientID)
-	fmt.Printf("Redirect URL: %s\n", s.googleConfig.RedirectURL)
-	fmt.Printf("===========================\n\n")
-
-	// Exchange code for token
-	token, err := s.googleConfig.Exchange(ctx, code)
+// authCodeURL saves the login's state and returns the provider's authorization URL
+func (s *OAuthServiceImpl) authCodeURL(ctx context.Context, provider string, saved *oauthState, state string) (string, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return "", errors.New("unknown oauth provider")
+	}
+
+	// The PKCE verifier stays on the server; only its challenge goes through the browser
+	saved.Verifier = oauth.GenerateVerifier()
+	data, err := json.Marshal(saved)
 	if err != nil {
-		fmt.Printf("ERROR: Failed to exchange code: %v\n", err)
-		return nil, fmt.Errorf("failed to exchange code: %w", err)
+		return "", err
 	}
+	if err := s.redisService.SaveOAuthState(ctx, state, data, oauthStateTTL); err != nil {
+		return "", fmt.Errorf("failed to save oauth state: %w", err)
+	}
+
+	return p.AuthCodeURL(ctx, state, saved.Verifier)
+}
 
-	// Get user info from Google
-	userInfo, err := s.GetUserInfoFromGoogle(ctx, token.AccessToken)
+func (s *OAuthServiceImpl) HandleCallback(ctx context.Context, provider string, code string, state string, browserBound bool, client *dto.ClientInfo) (*dto.OAuthCallbackResult, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, errors.New("unknown oauth provider")
+	}
+
+	data, err := s.redisService.ConsumeOAuthState(ctx, state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load oauth state: %w", err)
+	}
+
+	var saved oauthState
+	if data == nil || json.Unmarshal(data, &saved) != nil || saved.Provider != provider {
+		return nil, errors.New("invalid or expired login, please try again")
+	}
+
+	// Otherwise someone could start linking to their own account and have a victim
+	// finish it, attaching the victim's provider account to theirs
+	if saved.LinkUserID != nil && !browserBound {
+		return nil, errors.New("could not confirm this browser started linking, please try again")
+	}
+
+	userInfo, err := p.Authenticate(ctx, code, saved.Verifier)
 	if err != nil {
 		return nil, err
 	}
 
-	// Check if user already exists by OAuth ID
-	existingUser, err := s.userRepo.GetByOAuth(ctx, "google", userInfo.OAuthID)
-	if err == nil && existingUser != nil {
-		// User exists - login
-		jwtToken, err := utils.GenerateToken(existingUser.ID, s.config.JWT.Secret)
+	if saved.LinkUserID != nil {
+		if err := s.linkIdentity(ctx, *saved.LinkUserID, userInfo); err != nil {
+			return nil, err
+		}
+		return &dto.OAuthCallbackResult{LinkedProvider: provider}, nil
+	}
+
+	response, err := s.login(ctx, userInfo, client)
+	if err != nil {
+		return nil, err
+	}
+
+	// The tokens wait under a short-lived code the frontend exchanges, so they never
+	// appear in a URL. The code only works with this login's state (and PKCE verifier).
+	authCode, err := auth_code_store.NewCode()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.authCodeStore.Save(ctx, authCode, &auth_code_store.AuthCodeData{
+		Tokens: dto.TokenPair{
+			Token:        response.Token,
+			RefreshToken: response.RefreshToken,
+			ExpiresIn:    response.ExpiresIn,
+		},
+		MFAToken:      response.MFAToken,
+		User:          response.User,
+		IsNewUser:     response.IsNewUser,
+		State:         state,
+		CodeChallenge: saved.CodeChallenge,
+	}, auth_code_store.CodeTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save authorization code: %w", err)
+	}
+
+	return &dto.OAuthCallbackResult{Code: authCode}, nil
+}
+
+func (s *OAuthServiceImpl) ExchangeCode(ctx context.Context, req *dto.ExchangeCodeRequest) (*dto.ExchangeCodeResponse, error) {
+	data, err := s.authCodeStore.Take(ctx, req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	// A mismatch still burns the code; an intercepted code is only good to its own client
+	if data == nil || !data.Matches(req.State, req.CodeVerifier) {
+		return nil, errors.New("invalid or expired authorization code")
+	}
+
+	return &dto.ExchangeCodeResponse{
+		Token:        data.Tokens.Token,
+		RefreshToken: data.Tokens.RefreshToken,
+		ExpiresIn:    data.Tokens.ExpiresIn,
+		MFARequired:  data.MFAToken != "",
+		MFAToken:     data.MFAToken,
+		IsNewUser:    data.IsNewUser,
+		User:         data.User,
+	}, nil
+}
+
+// login signs in the owner of the provider account, merging with or creating a user as needed
+func (s *OAuthServiceImpl) login(ctx context.Context, userInfo *dto.OAuthUserInfo, client *dto.ClientInfo) (*dto.OAuthLoginResponse, error) {
+	// Check if user already exists by linked identity
+	identity, err := s.identityRepo.GetByProvider(ctx, userInfo.Provider, userInfo.OAuthID)
+	if err == nil && identity != nil {
+		existingUser, err := s.userRepo.GetByID(ctx, identity.UserID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to generate JWT: %w", err)
+			return nil, errors.New("user not found")
 		}
 
-		return &dto.OAuthLoginResponse{
-			Token:        jwtToken,
-			User:         *dto.UserToUserResponse(existingUser),
-			IsNewUser:    false,
-			NeedsProfile: false,
-		}, nil
+		if err := s.identityRepo.Touch(ctx, identity.ID, time.Now()); err != nil {
+			log.Printf("Failed to record sign-in with %s identity %s: %v", identity.Provider, identity.ID, err)
+		}
+
+		// User exists - login
+		return s.signIn(ctx, existingUser, client)
 	}
 
-	// Check if email already exists (user registered with email/password)
+	// Check if email already exists (user registered with email/password or another provider)
 	existingEmailUser, err := s.userRepo.GetByEmail(ctx, userInfo.Email)
 	if err == nil && existingEmailUser != nil {
-		// Email exists but not linked to Google
-		// Link Google account to existing user
-		existingEmailUser.OAuthProvider = "google"
-		existingEmailUser.OAuthID = userInfo.OAuthID
-		existingEmailUser.IsOAuthUser = true
-		existingEmailUser.UpdatedAt = time.Now()
-
-		if err := s.userRepo.Update(ctx, existingEmailUser.ID, existingEmailUser); err != nil {
-			return nil, fmt.Errorf("failed to link Google account: %w", err)
+		// Merge only when both sides have confirmed the address. An unverified provider
+		// email could belong to anyone, and an unverified or grandfathered account may have
+		// been registered by someone squatting on the address.
+		if !userInfo.Verified || !existingEmailUser.HasConfirmedEmail() {
+			return nil, fmt.Errorf("an account with this email already exists, sign in to it and link %s from your account settings", userInfo.Provider)
 		}
 
-		jwtToken, err := utils.GenerateToken(existingEmailUser.ID, s.config.JWT.Secret)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate JWT: %w", err)
+		now := time.Now()
+		if err := s.identityRepo.Create(ctx, newIdentity(existingEmailUser.ID, userInfo, &now)); err != nil {
+			return nil, fmt.Errorf("failed to link %s account: %w", userInfo.Provider, err)
 		}
 
-		return &dto.OAuthLoginResponse{
-			Token:        jwtToken,
-			User:         *dto.UserToUserResponse(existingEmailUser),
-			IsNewUser:    false,
-			NeedsProfile: false,
-		}, nil
+		return s.signIn(ctx, existingEmailUser, client)
 	}
 
 	// Create new user
@@ -126,77 +247,135 @@ func (s *OAuthServiceImpl) HandleGoogleCallback(ctx context.Context, code string
 		Username:      username,
 		DisplayName:   userInfo.Name,
 		Avatar:        userInfo.Picture,
-		OAuthProvider: "google",
-		OAuthID:       userInfo.OAuthID,
 		IsOAuthUser:   true,
+		EmailVerified: userInfo.Verified,
 		Role:          "user",
 		IsActive:      true,
 		Karma:         0,
 		CreatedAt:     time.Now(),
 		UpdatedAt:     time.Now(),
 	}
+	if newUser.EmailVerified {
+		newUser.EmailVerifiedAt = &newUser.CreatedAt
+	}
 
-	if err := s.userRepo.Create(ctx, newUser); err != nil {
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Create(ctx, newUser); err != nil {
+			return err
+		}
+		return s.identityRepo.Create(ctx, newIdentity(newUser.ID, userInfo, &newUser.CreatedAt))
+	})
+	if err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
-	jwtToken, err := utils.GenerateToken(newUser.ID, s.config.JWT.Secret)
+	tokens, err := s.authService.IssueTokens(ctx, newUser, client, false)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate JWT: %w", err)
+		return nil, fmt.Errorf("failed to issue tokens: %w", err)
 	}
 
 	return &dto.OAuthLoginResponse{
-		Token:        jwtToken,
+		Token:        tokens.Token,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
 		User:         *dto.UserToUserResponse(newUser),
 		IsNewUser:    true,
-		NeedsProfile: false, // Google provides all necessary info
+		NeedsProfile: false, // Providers give us email, name and avatar
 	}, nil
 }
 
-func (s *OAuthServiceImpl) GetUserInfoFromGoogle(ctx context.Context, accessToken string) (*dto.OAuthUserInfo, error) {
-	// Get user info from Google
-	resp, err := oauth2.NewClient(ctx, oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: accessToken},
-	)).Get("https://www.googleapis.com/oauth2/v2/userinfo")
+// linkIdentity attaches the provider account to a signed-in user
+func (s *OAuthServiceImpl) linkIdentity(ctx context.Context, userID uuid.UUID, userInfo *dto.OAuthUserInfo) error {
+	existing, err := s.identityRepo.GetByProvider(ctx, userInfo.Provider, userInfo.OAuthID)
+	if err == nil && existing != nil {
+		if existing.UserID == userID {
+			return nil // Already linked
+		}
+		return fmt.Errorf("this %s account is already linked to another user", userInfo.Provider)
+	}
 
+	identities, err := s.identityRepo.ListByUser(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user info: %w", err)
+		return err
+	}
+	for _, identity := range identities {
+		if identity.Provider == userInfo.Provider {
+			return fmt.Errorf("another %s account is already linked, unlink it first", userInfo.Provider)
+		}
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+	if err := s.identityRepo.Create(ctx, newIdentity(userID, userInfo, nil)); err != nil {
+		return fmt.Errorf("failed to link %s account: %w", userInfo.Provider, err)
 	}
 
-	var googleUser struct {
-		ID            string `json:"id"`
-		Email         string `json:"email"`
-		VerifiedEmail bool   `json:"verified_email"`
-		Name          string `json:"name"`
-		GivenName     string `json:"given_name"`
-		FamilyName    string `json:"family_name"`
-		Picture       string `json:"picture"`
-		Locale        string `json:"locale"`
+	log.Printf("🔗 User %s linked a %s account", userID, userInfo.Provider)
+	return nil
+}
+
+func (s *OAuthServiceImpl) UnlinkIdentity(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, provider string) error {
+	if err := s.authService.RequireRecentAuth(ctx, userID, sessionID); err != nil {
+		return err
+	}
+
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		user, err := s.userRepo.GetByIDForUpdate(ctx, userID)
+		if err != nil {
+			return errors.New("user not found")
+		}
+
+		deleted, err := s.identityRepo.Delete(ctx, userID, provider)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return fmt.Errorf("no %s account is linked", provider)
+		}
+
+		return ensureLoginMethodLeft(ctx, s.identityRepo, userID, user.Password != "")
+	})
+}
+
+func newIdentity(userID uuid.UUID, userInfo *dto.OAuthUserInfo, lastUsedAt *time.Time) *models.UserIdentity {
+	return &models.UserIdentity{
+		UserID:     userID,
+		Provider:   userInfo.Provider,
+		Subject:    userInfo.OAuthID,
+		Email:      userInfo.Email,
+		LastUsedAt: lastUsedAt,
 	}
+}
 
-	if err := json.Unmarshal(body, &googleUser); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal user info: %w", err)
+// signIn starts a session for an existing user, or returns an MFA challenge when 2FA is on
+func (s *OAuthServiceImpl) signIn(ctx context.Context, user *models.User, client *dto.ClientInfo) (*dto.OAuthLoginResponse, error) {
+	if !user.IsActive {
+		return nil, errors.New("account is disabled")
 	}
 
-	if googleUser.Email == "" {
-		return nil, errors.New("email not provided by Google")
+	if user.TOTPEnabled {
+		mfaToken, err := s.mfaService.CreateChallenge(ctx, user)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create MFA challenge: %w", err)
+		}
+
+		return &dto.OAuthLoginResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+			User:        *dto.UserToUserResponse(user),
+		}, nil
 	}
 
-	return &dto.OAuthUserInfo{
-		Provider:   "google",
-		OAuthID:    googleUser.ID,
-		Email:      googleUser.Email,
-		Name:       googleUser.Name,
-		Picture:    googleUser.Picture,
-		GivenName:  googleUser.GivenName,
-		FamilyName: googleUser.FamilyName,
-		Verified:   googleUser.VerifiedEmail,
+	tokens, err := s.authService.IssueTokens(ctx, user, client, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue tokens: %w", err)
+	}
+
+	return &dto.OAuthLoginResponse{
+		Token:        tokens.Token,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		User:         *dto.UserToUserResponse(user),
+		IsNewUser:    false,
+		NeedsProfile: false,
 	}, nil
 }
 
@@ -0,0 +1,89 @@
+package serviceimpl
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"gofiber-template/domain/dto"
+	"gofiber-template/domain/models"
+	"gofiber-template/domain/repositories"
+	"gofiber-template/domain/services"
+)
+
+// stubIdentityRepo knows no identities and records the ones created
+type stubIdentityRepo struct {
+	repositories.UserIdentityRepository
+	created []*models.UserIdentity
+}
+
+func (r *stubIdentityRepo) GetByProvider(ctx context.Context, provider string, subject string) (*models.UserIdentity, error) {
+	return nil, errors.New("record not found")
+}
+
+func (r *stubIdentityRepo) Create(ctx context.Context, identity *models.UserIdentity) error {
+	r.created = append(r.created, identity)
+	return nil
+}
+
+type stubAuthService struct {
+	services.AuthService
+}
+
+func (stubAuthService) IssueTokens(ctx context.Context, user *models.User, client *dto.ClientInfo, mfaVerified bool) (*dto.TokenPair, error) {
+	return &dto.TokenPair{Token: "access", RefreshToken: "refresh"}, nil
+}
+
+func TestOAuthLoginMergesOnlyConfirmedEmails(t *testing.T) {
+	cases := []struct {
+		name           string
+		verified       bool
+		grandfathered  bool
+		providerVerify bool
+		wantMerge      bool
+	}{
+		{name: "confirmed on both sides", verified: true, providerVerify: true, wantMerge: true},
+		{name: "grandfathered account", verified: true, grandfathered: true, providerVerify: true},
+		{name: "unverified account", providerVerify: true},
+		{name: "unverified provider email", verified: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			user := &models.User{
+				ID:                 uuid.New(),
+				Email:              "owner@example.com",
+				IsActive:           true,
+				EmailVerified:      tc.verified,
+				EmailGrandfathered: tc.grandfathered,
+			}
+			identities := &stubIdentityRepo{}
+			service := &OAuthServiceImpl{
+				userRepo:     &stubUserRepo{user: user},
+				identityRepo: identities,
+				authService:  stubAuthService{},
+			}
+
+			resp, err := service.login(context.Background(), &dto.OAuthUserInfo{
+				Provider: "google",
+				OAuthID:  "google-subject",
+				Email:    user.Email,
+				Verified: tc.providerVerify,
+			}, &dto.ClientInfo{})
+
+			if !tc.wantMerge {
+				if err == nil || len(identities.created) != 0 {
+					t.Fatalf("linked %d identities (err %v), want the merge refused", len(identities.created), err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("login: %v", err)
+			}
+			if len(identities.created) != 1 || identities.created[0].UserID != user.ID || resp.Token == "" {
+				t.Fatalf("identity not linked to the existing account: %+v", identities.created)
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package serviceimpl
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
 	"math"
 	"time"
 
@@ -13,6 +15,8 @@ import (
 	"gofiber-template/domain/services"
 )
 
+const unverifiedDailyPostLimit = 3 // Posts per 24 hours for accounts without a verified email
+
 type PostServiceImpl struct {
 	postRepo      repositories.PostRepository
 	userRepo      repositories.UserRepository
@@ -20,6 +24,8 @@ type PostServiceImpl struct {
 	savedPostRepo repositories.SavedPostRepository
 	tagService    services.TagService
 	mediaRepo     repositories.MediaRepository
+
+	linkPreviewService services.LinkPreviewService
 }
 
 func NewPostService(
@@ -29,6 +35,7 @@ func NewPostService(
 	savedPostRepo repositories.SavedPostRepository,
 	tagService services.TagService,
 	mediaRepo repositories.MediaRepository,
+	linkPreviewService services.LinkPreviewService,
 ) services.PostService {
 	return &PostServiceImpl{
 		postRepo:      postRepo,
@@ -37,10 +44,16 @@ func NewPostService(
 		savedPostRepo: savedPostRepo,
 		tagService:    tagService,
 		mediaRepo:     mediaRepo,
+
+		linkPreviewService: linkPreviewService,
 	}
 }
 
 func (s *PostServiceImpl) CreatePost(ctx context.Context, userID uuid.UUID, req *dto.CreatePostRequest) (*dto.PostResponse, error) {
+	if err := s.checkUnverifiedPostLimit(ctx, userID); err != nil {
+		return nil, err
+	}
+
 	// Create post
 	post := &models.Post{
 		ID:           uuid.New(),
@@ -89,6 +102,9 @@ func (s *PostServiceImpl) CreatePost(ctx context.Context, userID uuid.UUID, req
 		}
 	}
 
+	// Unfurl links without delaying the response
+	go s.attachLinkPreview(post.ID, post.Content)
+
 	// Get full post with relations
 	return s.GetPost(ctx, post.ID, &userID)
 }
@@ -117,7 +133,7 @@ func (s *PostServiceImpl) GetPost(ctx context.Context, postID uuid.UUID, userID
 	return resp, nil
 }
 
-func (s *PostServiceImpl) UpdatePost(ctx context.Context, postID uuid.UUID, userID uuid.UUID, req *dto.UpdatePostRequest) (*dto.PostResponse, error) {
+func (s *PostServiceImpl) UpdatePost(ctx context.Context, postID uuid.UUID, actor *dto.Actor, req *dto.UpdatePostRequest) (*dto.PostResponse, error) {
 	// Get existing post
 	post, err := s.postRepo.GetByID(ctx, postID)
 	if err != nil {
@@ -125,15 +141,24 @@ func (s *PostServiceImpl) UpdatePost(ctx context.Context, postID uuid.UUID, user
 	}
 
 	// Check ownership
-	if post.AuthorID != userID {
-		return nil, errors.New("unauthorized: not post owner")
+	if post.AuthorID != actor.UserID {
+		if !actor.Can(models.PermissionPostEditAny) {
+			return nil, errors.New("unauthorized: not post owner")
+		}
+		log.Printf("🛡️ Post %s of user %s edited by %s", postID, post.AuthorID, actor.UserID)
 	}
 
 	// Update fields
+	var dropPreview, unfurl bool
 	if req.Title != "" {
 		post.Title = req.Title
 	}
 	if req.Content != "" {
+		// A different link replaces the preview
+		if changed, hasLink := linkPreviewChanged(post.LinkPreview, req.Content); changed {
+			dropPreview = post.LinkPreviewID != nil
+			unfurl = hasLink
+		}
 		post.Content = req.Content
 	}
 	post.UpdatedAt = time.Now()
@@ -143,6 +168,14 @@ func (s *PostServiceImpl) UpdatePost(ctx context.Context, postID uuid.UUID, user
 		return nil, err
 	}
 
+	// The stale preview is dropped right away, the new one follows in the background
+	if dropPreview {
+		_ = s.postRepo.SetLinkPreview(ctx, postID, nil)
+	}
+	if unfurl {
+		go s.attachLinkPreview(postID, post.Content)
+	}
+
 	// Update tags if provided
 	if len(req.Tags) > 0 {
 		tagIDs, err := s.tagService.GetOrCreateTags(ctx, req.Tags)
@@ -155,10 +188,33 @@ func (s *PostServiceImpl) UpdatePost(ctx context.Context, postID uuid.UUID, user
 		}
 	}
 
-	return s.GetPost(ctx, postID, &userID)
+	return s.GetPost(ctx, postID, &actor.UserID)
+}
+
+// attachLinkPreview unfurls the first link of a post in the background
+func (s *PostServiceImpl) attachLinkPreview(postID uuid.UUID, content string) {
+	if s.linkPreviewService == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), linkPreviewTimeout)
+	defer cancel()
+
+	preview, err := s.linkPreviewService.PreviewForText(ctx, content)
+	if err != nil {
+		log.Printf("Failed to get link preview for post %s: %v", postID, err)
+		return
+	}
+	if preview == nil {
+		return
+	}
+
+	if err := s.postRepo.SetLinkPreview(ctx, postID, &preview.ID); err != nil {
+		log.Printf("Failed to attach link preview to post %s: %v", postID, err)
+	}
 }
 
-func (s *PostServiceImpl) DeletePost(ctx context.Context, postID uuid.UUID, userID uuid.UUID) error {
+func (s *PostServiceImpl) DeletePost(ctx context.Context, postID uuid.UUID, actor *dto.Actor) error {
 	// Get post
 	post, err := s.postRepo.GetByID(ctx, postID)
 	if err != nil {
@@ -166,8 +222,11 @@ func (s *PostServiceImpl) DeletePost(ctx context.Context, postID uuid.UUID, user
 	}
 
 	// Check ownership
-	if post.AuthorID != userID {
-		return errors.New("unauthorized: not post owner")
+	if post.AuthorID != actor.UserID {
+		if !actor.Can(models.PermissionPostRemoveAny) {
+			return errors.New("unauthorized: not post owner")
+		}
+		log.Printf("🛡️ Post %s of user %s removed by %s", postID, post.AuthorID, actor.UserID)
 	}
 
 	// Soft delete
@@ -346,4 +405,29 @@ func (s *PostServiceImpl) buildPostListResponse(ctx context.Context, posts []*mo
 	}, nil
 }
 
+// checkUnverifiedPostLimit caps how much accounts without a verified email can post,
+// which keeps throwaway spam accounts in check
+func (s *PostServiceImpl) checkUnverifiedPostLimit(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	if user.EmailVerified {
+		return nil
+	}
+
+	// Deleted posts count too, so deleting and reposting doesn't reset the limit
+	count, err := s.postRepo.CountByAuthorSince(ctx, userID, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		return err
+	}
+
+	if count >= unverifiedDailyPostLimit {
+		return fmt.Errorf("verify your email address to create more than %d posts a day", unverifiedDailyPostLimit)
+	}
+
+	return nil
+}
+
 var _ services.PostService = (*PostServiceImpl)(nil)
@@ -1,33 +1,71 @@
 package serviceimpl
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
 
 	webpush "github.com/SherClockHolmes/webpush-go"
 	"github.com/google/uuid"
 	"gofiber-template/domain/dto"
+	"gofiber-template/domain/models"
 	"gofiber-template/domain/repositories"
 	"gofiber-template/domain/services"
+	"gofiber-template/infrastructure/redis"
 	"gofiber-template/pkg/config"
 )
 
+const (
+	pushRateWindow  = time.Minute
+	pushSendTimeout = 10 * time.Second
+)
+
+var (
+	errPushRateLimited = errors.New("push rate limit exceeded")
+
+	// RFC 8030: topics are at most 32 characters from the URL-safe base64 alphabet
+	pushTopicPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+)
+
 type PushServiceImpl struct {
-	pushRepo repositories.PushSubscriptionRepository
-	config   *config.Config
+	pushRepo     repositories.PushSubscriptionRepository
+	redisService *redis.RedisService
+	config       *config.Config
+	httpClient   webpush.HTTPClient
+	metrics      *pushMetrics
 }
 
 func NewPushService(
 	pushRepo repositories.PushSubscriptionRepository,
+	redisService *redis.RedisService,
 	config *config.Config,
 ) services.PushService {
 	return &PushServiceImpl{
-		pushRepo: pushRepo,
-		config:   config,
+		pushRepo:     pushRepo,
+		redisService: redisService,
+		config:       config,
+		httpClient:   &http.Client{Timeout: pushSendTimeout},
+		metrics:      newPushMetrics(),
 	}
 }
 
+// SetHTTPClient replaces the client pushes are sent with (e.g. to route through a proxy)
+func (s *PushServiceImpl) SetHTTPClient(client webpush.HTTPClient) {
+	s.httpClient = client
+}
+
 func (s *PushServiceImpl) Subscribe(ctx context.Context, userID uuid.UUID, req *dto.PushSubscriptionRequest) (*dto.PushSubscriptionResponse, error) {
 	// Convert DTO to model
 	subscription := dto.PushSubscriptionRequestToModel(req, userID)
@@ -62,65 +100,64 @@ func (s *PushServiceImpl) SendToUser(ctx context.Context, userID uuid.UUID, payl
 		return err
 	}
 
+	// Drop subscriptions the browser told us have expired
+	subscriptions = s.pruneExpired(ctx, subscriptions)
+
 	if len(subscriptions) == 0 {
 		log.Printf("📭 No push subscriptions found for user %s", userID.String())
 		return nil
 	}
 
-	// Convert payload to JSON
-	payloadJSON, err := json.Marshal(payload)
+	// Per-user cap so a burst of activity can't flood a device
+	if err := s.checkRateLimit(ctx, userID); err != nil {
+		return err
+	}
+
+	// Delivery options go into headers; the browser only receives the notification itself
+	options := s.resolveOptions(payload.Options)
+	body := *payload
+	body.Options = nil
+
+	payloadJSON, err := json.Marshal(&body)
 	if err != nil {
 		return err
 	}
 
-	// Send to all subscriptions concurrently
-	for _, sub := range subscriptions {
-		go func(subscription *dto.PushSubscriptionRequest, endpoint string) {
-			// Create webpush subscription
-			webpushSub := &webpush.Subscription{
-				Endpoint: subscription.Endpoint,
-				Keys: webpush.Keys{
-					P256dh: subscription.Keys.P256dh,
-					Auth:   subscription.Keys.Auth,
-				},
-			}
+	// Send to all subscriptions concurrently and wait for the results,
+	// so callers (the notification outbox) can retry transient failures
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		delivered int
+		lastErr   error
+	)
 
-			// Send notification
-			resp, err := webpush.SendNotification(payloadJSON, webpushSub, &webpush.Options{
-				Subscriber:      s.config.VAPID.Subject,
-				VAPIDPublicKey:  s.config.VAPID.PublicKey,
-				VAPIDPrivateKey: s.config.VAPID.PrivateKey,
-				TTL:             30,
-			})
+	for _, sub := range subscriptions {
+		wg.Add(1)
+		go func(sub *models.PushSubscription) {
+			defer wg.Done()
 
-			if err != nil {
-				log.Printf("❌ Push notification error for %s: %v", endpoint, err)
+			if err := s.sendToSubscription(ctx, sub, payloadJSON, options); err != nil {
+				mu.Lock()
+				lastErr = err
+				mu.Unlock()
 				return
 			}
-			defer resp.Body.Close()
-
-			// Check response status
-			if resp.StatusCode == 201 {
-				log.Printf("✅ Push notification sent successfully to: %s", endpoint)
-			} else {
-				log.Printf("⚠️  Push notification failed with status %d for: %s", resp.StatusCode, endpoint)
-
-				// If subscription expired (410 Gone or 404 Not Found), delete it
-				if resp.StatusCode == 410 || resp.StatusCode == 404 {
-					log.Printf("🗑️  Removing expired subscription: %s", endpoint)
-					s.pushRepo.DeleteByEndpoint(context.Background(), endpoint)
-				}
-			}
-		}(&dto.PushSubscriptionRequest{
-			Endpoint: sub.Endpoint,
-			Keys: dto.PushSubscriptionKeys{
-				P256dh: sub.P256dh,
-				Auth:   sub.Auth,
-			},
-		}, sub.Endpoint)
+
+			mu.Lock()
+			delivered++
+			mu.Unlock()
+		}(sub)
 	}
 
-	log.Printf("📤 Push notifications sent to %d subscription(s) for user %s", len(subscriptions), userID.String())
+	wg.Wait()
+
+	log.Printf("📤 Push notifications sent to %d/%d subscription(s) for user %s", delivered, len(subscriptions), userID.String())
+
+	// Partial success counts as delivered; retrying would duplicate on the healthy endpoints
+	if delivered == 0 && lastErr != nil {
+		return lastErr
+	}
 
 	return nil
 }
@@ -129,5 +166,218 @@ func (s *PushServiceImpl) GetPublicKey() string {
 	return s.config.VAPID.PublicKey
 }
 
+func (s *PushServiceImpl) GetMetrics() *dto.PushMetricsResponse {
+	return s.metrics.snapshot()
+}
+
+// sendToSubscription delivers one push. Gone subscriptions (404/410) are removed and
+// reported as success so they never trigger a retry.
+func (s *PushServiceImpl) sendToSubscription(ctx context.Context, sub *models.PushSubscription, payloadJSON []byte, options *dto.PushDeliveryOptions) error {
+	domain := endpointDomain(sub.Endpoint)
+
+	// webpush pads the payload in place, so concurrent sends each need their own copy
+	resp, err := webpush.SendNotificationWithContext(ctx, bytes.Clone(payloadJSON), &webpush.Subscription{
+		Endpoint: sub.Endpoint,
+		Keys: webpush.Keys{
+			P256dh: sub.P256dh,
+			Auth:   sub.Auth,
+		},
+	}, &webpush.Options{
+		HTTPClient:      s.httpClient,
+		Subscriber:      s.config.VAPID.Subject,
+		VAPIDPublicKey:  s.config.VAPID.PublicKey,
+		VAPIDPrivateKey: s.config.VAPID.PrivateKey,
+		TTL:             options.TTL,
+		Urgency:         webpush.Urgency(options.Urgency),
+		Topic:           options.Topic,
+	})
+	if err != nil {
+		log.Printf("❌ Push notification error for %s: %v", sub.Endpoint, err)
+		s.metrics.recordFailure(domain, 0, err.Error())
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		s.metrics.recordSuccess(domain, resp.StatusCode)
+		return nil
+
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		// Subscription expired or was revoked by the user agent
+		log.Printf("🗑️  Removing expired subscription (%d): %s", resp.StatusCode, sub.Endpoint)
+		s.metrics.recordGone(domain, resp.StatusCode)
+		if err := s.pushRepo.Delete(context.Background(), sub.UserID, sub.Endpoint); err != nil {
+			log.Printf("❌ Failed to remove subscription %s: %v", sub.Endpoint, err)
+		}
+		return nil
+
+	default:
+		err := fmt.Errorf("push service responded with status %d", resp.StatusCode)
+		log.Printf("⚠️  Push notification failed with status %d for: %s", resp.StatusCode, sub.Endpoint)
+		s.metrics.recordFailure(domain, resp.StatusCode, err.Error())
+		return err
+	}
+}
+
+// pruneExpired removes subscriptions past their expirationTime and returns the rest
+func (s *PushServiceImpl) pruneExpired(ctx context.Context, subscriptions []*models.PushSubscription) []*models.PushSubscription {
+	nowMillis := time.Now().UnixMilli()
+	active := subscriptions[:0]
+
+	for _, sub := range subscriptions {
+		if sub.ExpirationTime != nil && *sub.ExpirationTime > 0 && *sub.ExpirationTime < nowMillis {
+			log.Printf("🗑️  Removing expired subscription: %s", sub.Endpoint)
+			if err := s.pushRepo.Delete(ctx, sub.UserID, sub.Endpoint); err != nil {
+				log.Printf("❌ Failed to remove subscription %s: %v", sub.Endpoint, err)
+			}
+			continue
+		}
+		active = append(active, sub)
+	}
+
+	return active
+}
+
+// checkRateLimit enforces the per-user push cap. Redis errors fail open.
+func (s *PushServiceImpl) checkRateLimit(ctx context.Context, userID uuid.UUID) error {
+	limit := s.config.Push.MaxPerUserMinute
+	if limit <= 0 || s.redisService == nil {
+		return nil
+	}
+
+	count, err := s.redisService.IncrementPushCounter(ctx, userID, pushRateWindow)
+	if err != nil {
+		log.Printf("⚠️  Push rate limit check failed for user %s: %v", userID.String(), err)
+		return nil
+	}
+
+	if count > int64(limit) {
+		s.metrics.recordRateLimited()
+		log.Printf("⚠️  Push rate limit reached for user %s (%d/%d per minute)", userID.String(), count, limit)
+		return errPushRateLimited
+	}
+
+	return nil
+}
+
+// resolveOptions fills in defaults and normalizes the topic
+func (s *PushServiceImpl) resolveOptions(options *dto.PushDeliveryOptions) *dto.PushDeliveryOptions {
+	resolved := &dto.PushDeliveryOptions{
+		TTL:     s.config.Push.DefaultTTL,
+		Urgency: string(webpush.UrgencyNormal),
+	}
+	if options == nil {
+		return resolved
+	}
+
+	if options.TTL > 0 {
+		resolved.TTL = options.TTL
+	}
+
+	switch webpush.Urgency(options.Urgency) {
+	case webpush.UrgencyVeryLow, webpush.UrgencyLow, webpush.UrgencyNormal, webpush.UrgencyHigh:
+		resolved.Urgency = options.Urgency
+	}
+
+	resolved.Topic = normalizePushTopic(options.Topic)
+	return resolved
+}
+
+// normalizePushTopic hashes topics that don't fit the Topic header format
+func normalizePushTopic(topic string) string {
+	if topic == "" || pushTopicPattern.MatchString(topic) {
+		return topic
+	}
+
+	sum := sha256.Sum256([]byte(topic))
+	return base64.RawURLEncoding.EncodeToString(sum[:])[:32]
+}
+
+// endpointDomain returns the push service host used for metrics (e.g. fcm.googleapis.com)
+func endpointDomain(endpoint string) string {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Hostname() == "" {
+		return "unknown"
+	}
+	return parsed.Hostname()
+}
+
+// ==================== Metrics ====================
+
+// pushMetrics keeps in-process delivery counters per push service domain
+type pushMetrics struct {
+	mu          sync.Mutex
+	rateLimited int64
+	endpoints   map[string]*dto.PushEndpointMetrics
+}
+
+func newPushMetrics() *pushMetrics {
+	return &pushMetrics{
+		endpoints: make(map[string]*dto.PushEndpointMetrics),
+	}
+}
+
+func (m *pushMetrics) entry(domain string) *dto.PushEndpointMetrics {
+	e, ok := m.endpoints[domain]
+	if !ok {
+		e = &dto.PushEndpointMetrics{Domain: domain}
+		m.endpoints[domain] = e
+	}
+	now := time.Now()
+	e.LastAttemptAt = &now
+	return e
+}
+
+func (m *pushMetrics) recordSuccess(domain string, status int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.entry(domain)
+	e.Success++
+	e.LastStatus = status
+}
+
+func (m *pushMetrics) recordGone(domain string, status int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.entry(domain)
+	e.Gone++
+	e.LastStatus = status
+}
+
+func (m *pushMetrics) recordFailure(domain string, status int, errMsg string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.entry(domain)
+	e.Failure++
+	e.LastStatus = status
+	e.LastError = errMsg
+}
+
+func (m *pushMetrics) recordRateLimited() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rateLimited++
+}
+
+func (m *pushMetrics) snapshot() *dto.PushMetricsResponse {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	endpoints := make([]dto.PushEndpointMetrics, 0, len(m.endpoints))
+	for _, e := range m.endpoints {
+		endpoints = append(endpoints, *e)
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		return endpoints[i].Domain < endpoints[j].Domain
+	})
+
+	return &dto.PushMetricsResponse{
+		RateLimited: m.rateLimited,
+		Endpoints:   endpoints,
+	}
+}
+
 // Compiler check to ensure implementation satisfies interface
 var _ services.PushService = (*PushServiceImpl)(nil)
@@ -0,0 +1,194 @@
+package serviceimpl
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	webpush "github.com/SherClockHolmes/webpush-go"
+	"github.com/google/uuid"
+	"gofiber-template/domain/dto"
+	"gofiber-template/domain/models"
+	"gofiber-template/domain/repositories"
+	"gofiber-template/pkg/config"
+)
+
+// fakePushRepo serves fixed subscriptions and records removals
+type fakePushRepo struct {
+	repositories.PushSubscriptionRepository
+	subscriptions []*models.PushSubscription
+
+	mu      sync.Mutex
+	deleted []string
+}
+
+func (r *fakePushRepo) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*models.PushSubscription, error) {
+	subscriptions := make([]*models.PushSubscription, len(r.subscriptions))
+	copy(subscriptions, r.subscriptions)
+	return subscriptions, nil
+}
+
+func (r *fakePushRepo) Delete(ctx context.Context, userID uuid.UUID, endpoint string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deleted = append(r.deleted, endpoint)
+	return nil
+}
+
+// fakePushEndpoint is a push service answering with the status set for each path
+type fakePushEndpoint struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses map[string]int
+	requests atomic.Int32
+}
+
+func newFakePushEndpoint(t *testing.T) *fakePushEndpoint {
+	t.Helper()
+	endpoint := &fakePushEndpoint{statuses: make(map[string]int)}
+	endpoint.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		endpoint.requests.Add(1)
+		if r.Header.Get("Content-Encoding") != "aes128gcm" || r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		endpoint.mu.Lock()
+		status, ok := endpoint.statuses[r.URL.Path]
+		endpoint.mu.Unlock()
+		if !ok {
+			status = http.StatusCreated
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(endpoint.Close)
+	return endpoint
+}
+
+func (e *fakePushEndpoint) respond(path string, status int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.statuses[path] = status
+}
+
+// newTestSubscription returns a subscription at path with valid browser keys
+func newTestSubscription(t *testing.T, endpoint *fakePushEndpoint, userID uuid.UUID, path string) *models.PushSubscription {
+	t.Helper()
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := make([]byte, 16)
+	if _, err := rand.Read(auth); err != nil {
+		t.Fatal(err)
+	}
+
+	return &models.PushSubscription{
+		ID:       uuid.New(),
+		UserID:   userID,
+		Endpoint: endpoint.URL + path,
+		P256dh:   base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(auth),
+	}
+}
+
+func newTestPushService(t *testing.T, endpoint *fakePushEndpoint, repo *fakePushRepo) *PushServiceImpl {
+	t.Helper()
+	privateKey, publicKey, err := webpush.GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service := NewPushService(repo, nil, &config.Config{
+		VAPID: config.VAPIDConfig{PublicKey: publicKey, PrivateKey: privateKey, Subject: "mailto:test@example.com"},
+		Push:  config.PushConfig{DefaultTTL: 60},
+	}).(*PushServiceImpl)
+	service.SetHTTPClient(endpoint.Client())
+	return service
+}
+
+var testPushPayload = &dto.PushNotificationPayload{Title: "Test", Body: "Hello"}
+
+func TestSendToUserDelivers(t *testing.T) {
+	endpoint := newFakePushEndpoint(t)
+	userID := uuid.New()
+	repo := &fakePushRepo{subscriptions: []*models.PushSubscription{newTestSubscription(t, endpoint, userID, "/device-1")}}
+	service := newTestPushService(t, endpoint, repo)
+
+	if err := service.SendToUser(context.Background(), userID, testPushPayload); err != nil {
+		t.Fatal(err)
+	}
+	if endpoint.requests.Load() != 1 || len(repo.deleted) != 0 {
+		t.Fatalf("expected one delivery and no removals, got %d requests, removed %v", endpoint.requests.Load(), repo.deleted)
+	}
+
+	metrics := service.GetMetrics()
+	if len(metrics.Endpoints) != 1 || metrics.Endpoints[0].Success != 1 {
+		t.Fatalf("expected one recorded success, got %+v", metrics.Endpoints)
+	}
+}
+
+func TestSendToUserRemovesGoneSubscriptions(t *testing.T) {
+	for _, status := range []int{http.StatusNotFound, http.StatusGone} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			endpoint := newFakePushEndpoint(t)
+			endpoint.respond("/gone", status)
+			userID := uuid.New()
+			gone := newTestSubscription(t, endpoint, userID, "/gone")
+			repo := &fakePushRepo{subscriptions: []*models.PushSubscription{gone}}
+			service := newTestPushService(t, endpoint, repo)
+
+			// A gone subscription is not worth retrying
+			if err := service.SendToUser(context.Background(), userID, testPushPayload); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if len(repo.deleted) != 1 || repo.deleted[0] != gone.Endpoint {
+				t.Fatalf("expected %s to be removed, got %v", gone.Endpoint, repo.deleted)
+			}
+		})
+	}
+}
+
+func TestSendToUserReportsServerErrorsForRetry(t *testing.T) {
+	endpoint := newFakePushEndpoint(t)
+	endpoint.respond("/flaky", http.StatusServiceUnavailable)
+	userID := uuid.New()
+	repo := &fakePushRepo{subscriptions: []*models.PushSubscription{newTestSubscription(t, endpoint, userID, "/flaky")}}
+	service := newTestPushService(t, endpoint, repo)
+
+	// The error makes the outbox schedule a retry
+	if err := service.SendToUser(context.Background(), userID, testPushPayload); err == nil {
+		t.Fatal("expected an error for a 503")
+	}
+	if len(repo.deleted) != 0 {
+		t.Fatalf("a failing subscription must be kept, removed %v", repo.deleted)
+	}
+
+	// The retry goes through once the push service recovers
+	endpoint.respond("/flaky", http.StatusCreated)
+	if err := service.SendToUser(context.Background(), userID, testPushPayload); err != nil {
+		t.Fatalf("expected the retry to succeed, got %v", err)
+	}
+}
+
+func TestSendToUserPartialFailureCountsAsDelivered(t *testing.T) {
+	endpoint := newFakePushEndpoint(t)
+	endpoint.respond("/broken", http.StatusInternalServerError)
+	userID := uuid.New()
+	repo := &fakePushRepo{subscriptions: []*models.PushSubscription{
+		newTestSubscription(t, endpoint, userID, "/healthy"),
+		newTestSubscription(t, endpoint, userID, "/broken"),
+	}}
+	service := newTestPushService(t, endpoint, repo)
+
+	// Retrying would push to the healthy device twice
+	if err := service.SendToUser(context.Background(), userID, testPushPayload); err != nil {
+		t.Fatalf("expected partial success to count as delivered, got %v", err)
+	}
+}
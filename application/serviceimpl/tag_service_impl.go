@@ -2,9 +2,12 @@ package serviceimpl
 
 import (
 	"context"
+	"errors"
+	"log"
 
 	"github.com/google/uuid"
 	"gofiber-template/domain/dto"
+	"gofiber-template/domain/models"
 	"gofiber-template/domain/repositories"
 	"gofiber-template/domain/services"
 )
@@ -100,6 +103,31 @@ func (s *TagServiceImpl) SearchTags(ctx context.Context, query string, limit int
 	}, nil
 }
 
+func (s *TagServiceImpl) MergeTags(ctx context.Context, actor *dto.Actor, sourceID, targetID uuid.UUID) (*dto.TagResponse, error) {
+	if !actor.Can(models.PermissionTagMerge) {
+		return nil, errors.New("unauthorized: cannot merge tags")
+	}
+	if sourceID == targetID {
+		return nil, errors.New("cannot merge a tag into itself")
+	}
+
+	source, err := s.tagRepo.GetByID(ctx, sourceID)
+	if err != nil {
+		return nil, errors.New("tag not found")
+	}
+	target, err := s.tagRepo.GetByID(ctx, targetID)
+	if err != nil {
+		return nil, errors.New("target tag not found")
+	}
+
+	if err := s.tagRepo.Merge(ctx, sourceID, targetID); err != nil {
+		return nil, err
+	}
+
+	log.Printf("🛡️ Tag %q merged into %q by %s", source.Name, target.Name, actor.UserID)
+	return s.GetTag(ctx, targetID)
+}
+
 func (s *TagServiceImpl) GetOrCreateTags(ctx context.Context, tagNames []string) ([]uuid.UUID, error) {
 	tagIDs := make([]uuid.UUID, 0, len(tagNames))
 
@@ -7,24 +7,35 @@ import (
 	"gofiber-template/domain/models"
 	"gofiber-template/domain/repositories"
 	"gofiber-template/domain/services"
+	"gofiber-template/infrastructure/redis"
+	"log"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type UserServiceImpl struct {
-	userRepo   repositories.UserRepository
-	followRepo repositories.FollowRepository
-	jwtSecret  string
+	userRepo       repositories.UserRepository
+	followRepo     repositories.FollowRepository
+	identityRepo   repositories.UserIdentityRepository
+	txManager      repositories.TransactionManager
+	authService    services.AuthService
+	accountService services.AccountService
+	mfaService     services.MFAService
+	loginThrottle  *loginThrottle
 }
 
-func NewUserService(userRepo repositories.UserRepository, followRepo repositories.FollowRepository, jwtSecret string) services.UserService {
+func NewUserService(userRepo repositories.UserRepository, followRepo repositories.FollowRepository, identityRepo repositories.UserIdentityRepository, txManager repositories.TransactionManager, authService services.AuthService, accountService services.AccountService, mfaService services.MFAService, redisService *redis.RedisService) services.UserService {
 	return &UserServiceImpl{
-		userRepo:   userRepo,
-		followRepo: followRepo,
-		jwtSecret:  jwtSecret,
+		userRepo:       userRepo,
+		followRepo:     followRepo,
+		identityRepo:   identityRepo,
+		txManager:      txManager,
+		authService:    authService,
+		accountService: accountService,
+		mfaService:     mfaService,
+		loginThrottle:  newLoginThrottle(redisService, accountService),
 	}
 }
 
@@ -61,30 +72,73 @@ func (s *UserServiceImpl) Register(ctx context.Context, req *dto.CreateUserReque
 		return nil, err
 	}
 
+	// Sent in the background so a slow mail server doesn't hold up sign-up;
+	// the user can request another one if it never arrives
+	go func() {
+		if err := s.accountService.SendVerificationEmail(context.Background(), user.ID); err != nil {
+			log.Printf("Failed to send verification email to user %s: %v", user.ID, err)
+		}
+	}()
+
 	return user, nil
 }
 
-func (s *UserServiceImpl) Login(ctx context.Context, req *dto.LoginRequest) (string, *models.User, error) {
-	user, err := s.userRepo.GetByEmail(ctx, req.Email)
-	if err != nil {
-		return "", nil, errors.New("invalid email or password")
+func (s *UserServiceImpl) Login(ctx context.Context, req *dto.LoginRequest, client *dto.ClientInfo) (*dto.LoginResponse, error) {
+	if err := s.loginThrottle.check(ctx, req.Email, client.IPAddress); err != nil {
+		return nil, err
+	}
+
+	// Unknown emails and accounts without a password (OAuth only) still pay for a bcrypt
+	// comparison, so response times don't reveal which accounts exist
+	user, _ := s.userRepo.GetByEmail(ctx, req.Email)
+	passwordHash := dummyPasswordHash()
+	if user != nil && user.Password != "" {
+		passwordHash = []byte(user.Password)
+	}
+
+	err := bcrypt.CompareHashAndPassword(passwordHash, []byte(req.Password))
+	if err != nil || user == nil || user.Password == "" {
+		var userID *uuid.UUID
+		if user != nil {
+			userID = &user.ID
+		}
+		s.loginThrottle.recordFailure(ctx, req.Email, client.IPAddress, userID)
+		return nil, errors.New("invalid email or password")
 	}
 
 	if !user.IsActive {
-		return "", nil, errors.New("account is disabled")
+		return nil, errors.New("account is disabled")
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
-	if err != nil {
-		return "", nil, errors.New("invalid email or password")
+	// With 2FA the password only earns a challenge; the session starts at /auth/mfa/verify
+	if user.TOTPEnabled {
+		mfaToken, err := s.mfaService.CreateChallenge(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+
+		return &dto.LoginResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+			User:        *dto.UserToUserResponse(user),
+		}, nil
 	}
 
-	token, err := s.GenerateJWT(user)
+	// Only a completed sign-in resets the account's failures; with 2FA that happens once
+	// the second factor is verified, so fresh challenges don't renew the guessing budget
+	s.loginThrottle.recordSuccess(ctx, req.Email)
+
+	tokens, err := s.authService.IssueTokens(ctx, user, client, false)
 	if err != nil {
-		return "", nil, err
+		return nil, err
 	}
 
-	return token, user, nil
+	return &dto.LoginResponse{
+		Token:        tokens.Token,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		User:         *dto.UserToUserResponse(user),
+	}, nil
 }
 
 func (s *UserServiceImpl) GetProfile(ctx context.Context, userID uuid.UUID) (*models.User, error) {
@@ -153,6 +207,147 @@ func (s *UserServiceImpl) UpdateProfile(ctx context.Context, userID uuid.UUID, r
 	return user, nil
 }
 
+func (s *UserServiceImpl) ChangePassword(ctx context.Context, userID uuid.UUID, currentSessionID uuid.UUID, req *dto.ChangePasswordRequest) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	// Accounts created through OAuth have no password to change
+	if user.Password == "" {
+		return errors.New("account has no password set")
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword))
+	if err != nil {
+		return errors.New("current password is incorrect")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	user.Password = string(hashedPassword)
+	user.UpdatedAt = time.Now()
+
+	err = s.userRepo.Update(ctx, userID, user)
+	if err != nil {
+		return err
+	}
+
+	// Sign out every other device; whoever knew the old password loses access
+	_, err = s.authService.RevokeOtherSessions(ctx, userID, currentSessionID, models.SessionRevokeReasonPasswordChanged)
+	return err
+}
+
+func (s *UserServiceImpl) Reauthenticate(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, req *dto.ReauthenticateRequest, client *dto.ClientInfo) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	// Guesses here count against the same limits as the login form
+	if err := s.loginThrottle.check(ctx, user.Email, client.IPAddress); err != nil {
+		return err
+	}
+
+	if req.Password != "" {
+		if user.Password == "" {
+			return errors.New("account has no password set")
+		}
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+			s.loginThrottle.recordFailure(ctx, user.Email, client.IPAddress, &user.ID)
+			return errors.New("password is incorrect")
+		}
+	} else {
+		if err := s.mfaService.VerifyCode(ctx, userID, req.Code); err != nil {
+			if errors.Is(err, errInvalidMFACode) {
+				s.loginThrottle.recordFailure(ctx, user.Email, client.IPAddress, &user.ID)
+			}
+			return err
+		}
+	}
+
+	s.loginThrottle.recordSuccess(ctx, user.Email)
+
+	return s.authService.MarkSessionReauthenticated(ctx, sessionID)
+}
+
+func (s *UserServiceImpl) GetLoginMethods(ctx context.Context, userID uuid.UUID) (*dto.LoginMethodsResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	identities, err := s.identityRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.LoginMethodsResponse{
+		HasPassword: user.Password != "",
+		Identities:  make([]dto.IdentityResponse, len(identities)),
+	}
+	for i, identity := range identities {
+		response.Identities[i] = dto.IdentityResponse{
+			Provider:   identity.Provider,
+			Email:      identity.Email,
+			LastUsedAt: identity.LastUsedAt,
+			CreatedAt:  identity.CreatedAt,
+		}
+	}
+
+	return response, nil
+}
+
+func (s *UserServiceImpl) SetPassword(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, req *dto.SetPasswordRequest) error {
+	if err := s.authService.RequireRecentAuth(ctx, userID, sessionID); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		user, err := s.userRepo.GetByIDForUpdate(ctx, userID)
+		if err != nil {
+			return errors.New("user not found")
+		}
+
+		if user.Password != "" {
+			return errors.New("account already has a password")
+		}
+
+		return s.userRepo.UpdatePassword(ctx, userID, string(hashedPassword))
+	})
+}
+
+func (s *UserServiceImpl) RemovePassword(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error {
+	if err := s.authService.RequireRecentAuth(ctx, userID, sessionID); err != nil {
+		return err
+	}
+
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		user, err := s.userRepo.GetByIDForUpdate(ctx, userID)
+		if err != nil {
+			return errors.New("user not found")
+		}
+
+		if user.Password == "" {
+			return errors.New("account has no password set")
+		}
+
+		if err := ensureLoginMethodLeft(ctx, s.identityRepo, userID, false); err != nil {
+			return err
+		}
+
+		return s.userRepo.UpdatePassword(ctx, userID, "")
+	})
+}
+
 func (s *UserServiceImpl) DeleteUser(ctx context.Context, userID uuid.UUID) error {
 	return s.userRepo.Delete(ctx, userID)
 }
@@ -171,55 +366,96 @@ func (s *UserServiceImpl) ListUsers(ctx context.Context, offset, limit int) ([]*
 	return users, count, nil
 }
 
-func (s *UserServiceImpl) GenerateJWT(user *models.User) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id":  user.ID.String(),
-		"username": user.Username,
-		"email":    user.Email,
-		"role":     user.Role,
-		"exp":      time.Now().Add(time.Hour * 24 * 7).Unix(),
-		"iat":      time.Now().Unix(),
+func (s *UserServiceImpl) AssignRole(ctx context.Context, actor *dto.Actor, userID uuid.UUID, role string) (*models.User, error) {
+	if !actor.Can(models.PermissionUserAssignRole) {
+		return nil, errors.New("unauthorized: cannot assign roles")
+	}
+	if !models.IsValidRole(role) {
+		return nil, errors.New("invalid role")
+	}
+	// Also keeps the last admin from demoting themselves
+	if userID == actor.UserID {
+		return nil, errors.New("you cannot change your own role")
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(s.jwtSecret))
+	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		return "", err
+		return nil, errors.New("user not found")
+	}
+	if user.Role == role {
+		return user, nil
 	}
 
-	return tokenString, nil
+	if err := s.userRepo.UpdateRole(ctx, userID, role); err != nil {
+		return nil, err
+	}
+
+	// Access tokens carry the old role until they expire
+	if _, err := s.authService.RevokeOtherSessions(ctx, userID, uuid.Nil, models.SessionRevokeReasonRoleChanged); err != nil {
+		log.Printf("Failed to sign out user %s after role change: %v", userID, err)
+	}
+
+	log.Printf("🛡️ Role of user %s changed from %s to %s by %s", userID, user.Role, role, actor.UserID)
+	user.Role = role
+	return user, nil
 }
 
-func (s *UserServiceImpl) ValidateJWT(tokenString string) (*models.User, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return []byte(s.jwtSecret), nil
-	})
+func (s *UserServiceImpl) BanUser(ctx context.Context, actor *dto.Actor, userID uuid.UUID) error {
+	user, err := s.banTarget(ctx, actor, userID)
+	if err != nil {
+		return err
+	}
+	if !user.IsActive {
+		return nil
+	}
 
+	if err := s.userRepo.SetActive(ctx, userID, false); err != nil {
+		return err
+	}
+
+	if _, err := s.authService.RevokeOtherSessions(ctx, userID, uuid.Nil, models.SessionRevokeReasonAccountDisabled); err != nil {
+		log.Printf("Failed to sign out banned user %s: %v", userID, err)
+	}
+
+	log.Printf("🛡️ User %s banned by %s", userID, actor.UserID)
+	return nil
+}
+
+func (s *UserServiceImpl) UnbanUser(ctx context.Context, actor *dto.Actor, userID uuid.UUID) error {
+	user, err := s.banTarget(ctx, actor, userID)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if user.IsActive {
+		return nil
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		userIDStr, ok := claims["user_id"].(string)
-		if !ok {
-			return nil, errors.New("invalid token claims")
-		}
+	if err := s.userRepo.SetActive(ctx, userID, true); err != nil {
+		return err
+	}
 
-		userID, err := uuid.Parse(userIDStr)
-		if err != nil {
-			return nil, errors.New("invalid user ID in token")
-		}
+	log.Printf("🛡️ User %s unbanned by %s", userID, actor.UserID)
+	return nil
+}
 
-		user, err := s.userRepo.GetByID(context.Background(), userID)
-		if err != nil {
-			return nil, errors.New("user not found")
-		}
+// banTarget loads a user the actor may ban or unban. Staff can only be banned by
+// someone who could also take their role away.
+func (s *UserServiceImpl) banTarget(ctx context.Context, actor *dto.Actor, userID uuid.UUID) (*models.User, error) {
+	if !actor.Can(models.PermissionUserBan) {
+		return nil, errors.New("unauthorized: cannot ban users")
+	}
+	if userID == actor.UserID {
+		return nil, errors.New("you cannot ban yourself")
+	}
 
-		return user, nil
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if len(models.RolePermissions(user.Role)) > 0 && !actor.Can(models.PermissionUserAssignRole) {
+		return nil, errors.New("unauthorized: cannot ban moderators or admins")
 	}
 
-	return nil, errors.New("invalid token")
+	return user, nil
 }
@@ -17,6 +17,7 @@ type VoteServiceImpl struct {
 	commentRepo  repositories.CommentRepository
 	userRepo     repositories.UserRepository
 	notifService services.NotificationService
+	txManager    repositories.TransactionManager
 }
 
 func NewVoteService(
@@ -25,6 +26,7 @@ func NewVoteService(
 	commentRepo repositories.CommentRepository,
 	userRepo repositories.UserRepository,
 	notifService services.NotificationService,
+	txManager repositories.TransactionManager,
 ) services.VoteService {
 	return &VoteServiceImpl{
 		voteRepo:     voteRepo,
@@ -32,6 +34,7 @@ func NewVoteService(
 		commentRepo:  commentRepo,
 		userRepo:     userRepo,
 		notifService: notifService,
+		txManager:    txManager,
 	}
 }
 
@@ -65,22 +68,28 @@ func (s *VoteServiceImpl) Vote(ctx context.Context, userID uuid.UUID, req *dto.V
 		}
 	}
 
-	// Save vote (upsert)
-	err := s.voteRepo.Vote(ctx, vote)
-	if err != nil {
-		return nil, err
-	}
+	// Vote, counters and notification outbox are committed atomically
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Save vote (upsert)
+		if err := s.voteRepo.Vote(ctx, vote); err != nil {
+			return err
+		}
 
-	// Update vote count on target
-	if voteChange != 0 {
+		if voteChange == 0 {
+			return nil
+		}
+
+		// Update vote count on target
 		if req.TargetType == "post" {
-			_ = s.postRepo.UpdateVoteCount(ctx, req.TargetID, voteChange)
+			if err := s.postRepo.UpdateVoteCount(ctx, req.TargetID, voteChange); err != nil {
+				return err
+			}
 
 			// Send notification to post author (only for upvotes, and only if new vote)
 			if req.VoteType == "up" && existingVote == nil {
 				post, _ := s.postRepo.GetByID(ctx, req.TargetID)
 				if post != nil && post.AuthorID != userID {
-					_ = s.notifService.CreateNotification(
+					return s.notifService.CreateNotification(
 						ctx,
 						post.AuthorID,
 						userID,
@@ -92,13 +101,15 @@ func (s *VoteServiceImpl) Vote(ctx context.Context, userID uuid.UUID, req *dto.V
 				}
 			}
 		} else if req.TargetType == "comment" {
-			_ = s.commentRepo.UpdateVoteCount(ctx, req.TargetID, voteChange)
+			if err := s.commentRepo.UpdateVoteCount(ctx, req.TargetID, voteChange); err != nil {
+				return err
+			}
 
 			// Send notification to comment author (only for upvotes, and only if new vote)
 			if req.VoteType == "up" && existingVote == nil {
 				comment, _ := s.commentRepo.GetByID(ctx, req.TargetID)
 				if comment != nil && comment.AuthorID != userID {
-					_ = s.notifService.CreateNotification(
+					return s.notifService.CreateNotification(
 						ctx,
 						comment.AuthorID,
 						userID,
@@ -110,6 +121,11 @@ func (s *VoteServiceImpl) Vote(ctx context.Context, userID uuid.UUID, req *dto.V
 				}
 			}
 		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &dto.VoteResponse{
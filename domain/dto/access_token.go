@@ -0,0 +1,44 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateAccessTokenRequest - Issue a personal access token for a bot or integration
+type CreateAccessTokenRequest struct {
+	Name          string   `json:"name" validate:"required,min=1,max=100"`
+	Scopes        []string `json:"scopes" validate:"required,min=1,dive,oneof=read post vote chat admin"`
+	ExpiresInDays int      `json:"expiresInDays" validate:"omitempty,min=1,max=365"` // Defaults to 90
+}
+
+// AccessTokenResponse - A personal access token, without its secret
+type AccessTokenResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	TokenPrefix string     `json:"tokenPrefix"`
+	Scopes      []string   `json:"scopes"`
+	ExpiresAt   time.Time  `json:"expiresAt"`
+	LastUsedAt  *time.Time `json:"lastUsedAt"`
+	LastUsedIP  string     `json:"lastUsedIp"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// CreatedAccessTokenResponse - A new token; Token is only ever returned here
+type CreatedAccessTokenResponse struct {
+	AccessTokenResponse
+	Token string `json:"token"`
+}
+
+// AccessTokenPrincipal - The user and grants behind a valid personal access token
+type AccessTokenPrincipal struct {
+	TokenID       uuid.UUID
+	UserID        uuid.UUID
+	Username      string
+	Email         string
+	Role          string
+	Scopes        []string
+	EmailVerified bool
+	MFAVerified   bool
+}
@@ -0,0 +1,18 @@
+package dto
+
+import (
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// Actor - The user making a request and the role permissions the request may use
+type Actor struct {
+	UserID      uuid.UUID
+	Permissions []string
+}
+
+// Can reports whether the actor holds permission
+func (a *Actor) Can(permission string) bool {
+	return slices.Contains(a.Permissions, permission)
+}
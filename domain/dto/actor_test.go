@@ -0,0 +1,22 @@
+package dto
+
+import (
+	"testing"
+
+	"gofiber-template/domain/models"
+)
+
+func TestActorCan(t *testing.T) {
+	actor := &Actor{Permissions: models.RolePermissions(models.RoleModerator)}
+	if !actor.Can(models.PermissionUserBan) {
+		t.Error("moderator actor cannot ban")
+	}
+	if actor.Can(models.PermissionUserAssignRole) {
+		t.Error("moderator actor can assign roles")
+	}
+
+	// A request without usable role permissions (token scope or missing 2FA) has none
+	if (&Actor{}).Can(models.PermissionUserBan) {
+		t.Error("actor without permissions can ban")
+	}
+}
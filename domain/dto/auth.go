@@ -5,9 +5,15 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required,min=1"`
 }
 
+// LoginResponse - Either a session, or when 2FA is enabled an MFA challenge to
+// complete at /auth/mfa/verify
 type LoginResponse struct {
-	Token string       `json:"token"`
-	User  UserResponse `json:"user"`
+	Token        string       `json:"token,omitempty"`
+	RefreshToken string       `json:"refreshToken,omitempty"`
+	ExpiresIn    int64        `json:"expiresIn,omitempty"` // Access token lifetime in seconds
+	MFARequired  bool         `json:"mfaRequired"`
+	MFAToken     string       `json:"mfaToken,omitempty"` // Short-lived challenge token
+	User         UserResponse `json:"user"`
 }
 
 type RegisterRequest struct {
@@ -27,21 +33,37 @@ type RefreshTokenRequest struct {
 	RefreshToken string `json:"refreshToken" validate:"required"`
 }
 
-type RefreshTokenResponse struct {
+// TokenPair is a short-lived access token plus the refresh token that renews it
+type TokenPair struct {
 	Token        string `json:"token"`
 	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int64  `json:"expiresIn"` // Access token lifetime in seconds
+}
+
+// ClientInfo identifies the device a session was started from
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
 }
 
 type ForgotPasswordRequest struct {
 	Email string `json:"email" validate:"required,email,max=255"`
 }
 
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
 type ResetPasswordRequest struct {
 	Token           string `json:"token" validate:"required"`
 	NewPassword     string `json:"newPassword" validate:"required,min=8,max=72"`
 	ConfirmPassword string `json:"confirmPassword" validate:"required,eqfield=NewPassword"`
 }
 
+type LogoutRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
 type LogoutResponse struct {
 	Message string `json:"message"`
 }
\ No newline at end of file
@@ -13,7 +13,19 @@ import (
 // ConversationResponse - Single conversation
 type ConversationResponse struct {
 	ID               uuid.UUID        `json:"id"`
-	OtherUser        UserResponse     `json:"otherUser"` // The other participant
+	Type             string           `json:"type"` // "direct", "group"
+	OtherUser        *UserResponse    `json:"otherUser,omitempty"` // The other participant (direct only)
+	Title            *string          `json:"title,omitempty"`     // Group only
+	Avatar           *string          `json:"avatar,omitempty"`    // Group only
+	OwnerID          *uuid.UUID       `json:"ownerId,omitempty"`   // Group only
+	MyRole           string           `json:"myRole,omitempty"`    // Current user's role (group only)
+	Participants     []ConversationParticipantResponse `json:"participants,omitempty"` // Group only
+	RequestStatus    string           `json:"requestStatus,omitempty"` // Direct only: "accepted", "pending", "declined"
+	IsRequest        bool             `json:"isRequest"`               // A message request the current user received
+	IsPinned         bool             `json:"isPinned"`
+	IsArchived       bool             `json:"isArchived"`
+	IsMuted          bool             `json:"isMuted"`
+	MutedUntil       *time.Time       `json:"mutedUntil,omitempty"` // Omitted while muted means until unmuted
 	LastMessage      *MessageResponse `json:"lastMessage,omitempty"`
 	LastMessageAt    time.Time        `json:"lastMessageAt"`
 	UnreadCount      int              `json:"unreadCount"`
@@ -21,6 +33,20 @@ type ConversationResponse struct {
 	UpdatedAt        time.Time        `json:"updatedAt"`
 }
 
+// ConversationParticipantResponse - Participant with role and read position
+type ConversationParticipantResponse struct {
+	User              UserResponse `json:"user"`
+	Role              string       `json:"role"` // "owner", "admin", "member"
+	LastReadMessageID *uuid.UUID   `json:"lastReadMessageId,omitempty"`
+	LastReadAt        *time.Time   `json:"lastReadAt,omitempty"`
+	JoinedAt          time.Time    `json:"joinedAt"`
+}
+
+// ConversationParticipantListResponse - Active participants of a conversation
+type ConversationParticipantListResponse struct {
+	Participants []ConversationParticipantResponse `json:"participants"`
+}
+
 // ConversationListResponse - List of conversations with cursor pagination
 type ConversationListResponse struct {
 	Conversations []ConversationResponse `json:"conversations"`
@@ -33,11 +59,51 @@ type UnreadCountResponse struct {
 	TotalUnread int `json:"totalUnread"`
 }
 
+// MessageRequestCountResponse - Number of pending message requests
+type MessageRequestCountResponse struct {
+	Count int64 `json:"count"`
+}
+
+// ReportConversationRequest - Report a message request (declines it)
+type ReportConversationRequest struct {
+	Reason  string  `json:"reason" validate:"required,oneof=spam harassment inappropriate other"`
+	Details *string `json:"details" validate:"omitempty,max=1000"`
+	Block   bool    `json:"block"` // Also block the sender
+}
+
+// MuteConversationRequest - Mute push notifications for a conversation
+type MuteConversationRequest struct {
+	DurationMinutes *int `json:"durationMinutes" validate:"omitempty,min=1,max=525600"` // Omit to mute until unmuted
+}
+
 // CreateConversationRequest - Request to create/get a conversation
 type CreateConversationRequest struct {
 	Username string `json:"username" validate:"required,min=3,max=20"`
 }
 
+// CreateGroupRequest - Request to create a group conversation
+type CreateGroupRequest struct {
+	Title     string   `json:"title" validate:"required,min=1,max=100"`
+	Avatar    *string  `json:"avatar,omitempty" validate:"omitempty,url"`
+	Usernames []string `json:"usernames" validate:"required,min=1,max=100,dive,min=3,max=20"` // Members besides the creator
+}
+
+// UpdateGroupRequest - Request to update group title/avatar
+type UpdateGroupRequest struct {
+	Title  *string `json:"title,omitempty" validate:"omitempty,min=1,max=100"`
+	Avatar *string `json:"avatar,omitempty" validate:"omitempty,url"`
+}
+
+// AddParticipantsRequest - Request to add members to a group
+type AddParticipantsRequest struct {
+	Usernames []string `json:"usernames" validate:"required,min=1,max=100,dive,min=3,max=20"`
+}
+
+// UpdateParticipantRoleRequest - Request to change a member's role ("owner" transfers ownership)
+type UpdateParticipantRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=owner admin member"`
+}
+
 // ============================================================================
 // Message DTOs
 // ============================================================================
@@ -46,13 +112,14 @@ type CreateConversationRequest struct {
 type MessageMedia struct {
 	URL       string  `json:"url"`
 	Thumbnail *string `json:"thumbnail,omitempty"`
-	Type      string  `json:"type"` // "image", "video", "file"
+	Type      string  `json:"type"` // "image", "video", "file", "voice"
 	Filename  *string `json:"filename,omitempty"`
 	MimeType  *string `json:"mimeType,omitempty"`
 	Size      *int64  `json:"size,omitempty"`
 	Width     *int    `json:"width,omitempty"`
 	Height    *int    `json:"height,omitempty"`
-	Duration  *int    `json:"duration,omitempty"` // seconds, for videos
+	Duration  *int    `json:"duration,omitempty"` // seconds, for videos and voice
+	Waveform  []int   `json:"waveform,omitempty"` // amplitude samples (0-100), for voice
 	// Video streaming fields (for Bunny Stream HLS)
 	VideoID          *string `json:"videoId,omitempty"`
 	HLSURL           *string `json:"hlsUrl,omitempty"`
@@ -63,10 +130,11 @@ type MessageMedia struct {
 // SendMessageRequest - Request to send a message
 type SendMessageRequest struct {
 	ConversationID uuid.UUID      `json:"conversationId" validate:"required,uuid"`
-	Type           string         `json:"type" validate:"required,oneof=text image video file"`
+	Type           string         `json:"type" validate:"required,oneof=text image video file voice"`
 	Content        *string        `json:"content,omitempty" validate:"omitempty,min=1,max=5000"`
 	Media          []MessageMedia `json:"media,omitempty"`
 	TempID         *string        `json:"tempId,omitempty"` // Client-generated ID for optimistic updates
+	ReplyToID      *uuid.UUID     `json:"replyToId,omitempty"`      // Quoted message in the same conversation
 }
 
 // MessageResponse - Single message
@@ -74,12 +142,19 @@ type MessageResponse struct {
 	ID             uuid.UUID      `json:"id"`
 	ConversationID uuid.UUID      `json:"conversationId"`
 	Sender         UserResponse   `json:"sender"`
-	Receiver       UserResponse   `json:"receiver"`
-	Type           string         `json:"type"` // "text", "image", "video", "file"
+	Receiver       *UserResponse  `json:"receiver,omitempty"` // nil for group messages
+	Type           string         `json:"type"` // "text", "image", "video", "file", "voice"
 	Content        *string        `json:"content,omitempty"`
 	Media          []MessageMedia `json:"media,omitempty"`
 	IsRead         bool           `json:"isRead"`
 	ReadAt         *time.Time     `json:"readAt,omitempty"`
+	EditedAt       *time.Time     `json:"editedAt,omitempty"`
+	IsDeleted      bool           `json:"isDeleted"` // Deleted for everyone (tombstone)
+	DeletedAt      *time.Time     `json:"deletedAt,omitempty"`
+	Reactions      []ReactionSummary `json:"reactions,omitempty"`
+	ReplyTo        *MessageReplyPreview `json:"replyTo,omitempty"`
+	LinkPreview    *LinkPreviewResponse `json:"linkPreview,omitempty"`
+	Status         string         `json:"status,omitempty"` // Own messages only: "sent", "delivered", "read"
 	CreatedAt      time.Time      `json:"createdAt"`
 	UpdatedAt      time.Time      `json:"updatedAt"`
 	TempID         *string        `json:"tempId,omitempty"` // Echo back client's tempId if provided
@@ -88,6 +163,75 @@ type MessageResponse struct {
 	SenderId   uuid.UUID `json:"senderId"`   // Same as Sender.ID, for easier access
 }
 
+// MessageReplyPreview - Compact preview of a quoted message
+type MessageReplyPreview struct {
+	ID          uuid.UUID `json:"id"`
+	SenderID    uuid.UUID `json:"senderId"`
+	SenderName  string    `json:"senderName,omitempty"`
+	Type        string    `json:"type,omitempty"`
+	Content     *string   `json:"content,omitempty"`     // Truncated
+	Thumbnail   *string   `json:"thumbnail,omitempty"`   // First media item, if any
+	IsDeleted   bool      `json:"isDeleted"`
+	Placeholder *string   `json:"placeholder,omitempty"` // Shown instead of content when deleted
+}
+
+// EditMessageRequest - Request to edit a message's text
+type EditMessageRequest struct {
+	Content string `json:"content" validate:"required,min=1,max=5000"`
+}
+
+// ReactionSummary - Count of one emoji on a message
+type ReactionSummary struct {
+	Emoji       string `json:"emoji"`
+	Count       int    `json:"count"`
+	ReactedByMe bool   `json:"reactedByMe"`
+}
+
+// MessageReactionUpdate - Result of adding/removing a reaction
+type MessageReactionUpdate struct {
+	MessageID      uuid.UUID         `json:"messageId"`
+	ConversationID uuid.UUID         `json:"conversationId"`
+	UserID         uuid.UUID         `json:"userId"`
+	Emoji          string            `json:"emoji"`
+	Action         string            `json:"action"`    // "added", "removed"
+	Reactions      []ReactionSummary `json:"reactions"` // Summary as seen by the reacting user
+}
+
+// MessageReactionResponse - Who reacted with what
+type MessageReactionResponse struct {
+	User      UserResponse `json:"user"`
+	Emoji     string       `json:"emoji"`
+	CreatedAt time.Time    `json:"createdAt"`
+}
+
+// MessageReactionListResponse - Users who reacted to a message
+type MessageReactionListResponse struct {
+	Reactions []MessageReactionResponse `json:"reactions"`
+	Meta      PaginationMeta            `json:"meta"`
+}
+
+// MessageSearchResult - One search match; open it with GET /chat/messages/:id/context
+type MessageSearchResult struct {
+	Message      MessageResponse      `json:"message"`
+	Conversation ConversationResponse `json:"conversation"`
+	Snippet      string               `json:"snippet"`
+	Highlights   []TextRange          `json:"highlights,omitempty"` // Matched terms within Snippet
+	Rank         float64              `json:"rank"`
+}
+
+// TextRange - Half-open range of character (rune) offsets
+type TextRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// MessageSearchResponse - Ranked search results with cursor pagination
+type MessageSearchResponse struct {
+	Results    []MessageSearchResult `json:"results"`
+	NextCursor *string               `json:"nextCursor,omitempty"`
+	HasMore    bool                  `json:"hasMore"`
+}
+
 // MessageListResponse - List of messages with cursor pagination
 type MessageListResponse struct {
 	Messages   []MessageResponse `json:"messages"`
@@ -111,6 +255,61 @@ type MarkMessagesAsReadRequest struct {
 	ConversationID uuid.UUID `json:"conversationId" validate:"required,uuid"`
 }
 
+// MarkConversationReadRequest - Read position (omit lastReadMessageId to read everything)
+type MarkConversationReadRequest struct {
+	LastReadMessageID *uuid.UUID `json:"lastReadMessageId"`
+}
+
+// MarkDeliveredRequest - Acknowledge messages received by the client
+type MarkDeliveredRequest struct {
+	MessageIDs []uuid.UUID `json:"messageIds" validate:"required,min=1,max=100"`
+}
+
+// DeliveryReceiptUpdate - Messages of one sender newly delivered to a recipient
+type DeliveryReceiptUpdate struct {
+	ConversationID uuid.UUID   `json:"conversationId"`
+	SenderID       uuid.UUID   `json:"-"` // Who to notify
+	MessageIDs     []uuid.UUID `json:"messageIds"`
+	DeliveredTo    uuid.UUID   `json:"deliveredTo"`
+	DeliveredAt    time.Time   `json:"deliveredAt"`
+}
+
+// ReadReceiptUpdate - Result of moving a reader's read position
+type ReadReceiptUpdate struct {
+	ConversationID    uuid.UUID   `json:"conversationId"`
+	ReadBy            uuid.UUID   `json:"readBy"`
+	LastReadMessageID *uuid.UUID  `json:"lastReadMessageId,omitempty"`
+	ReadAt            time.Time   `json:"readAt"`
+	UnreadCount       int         `json:"unreadCount"`
+	NotifyUserIDs     []uuid.UUID `json:"-"` // Senders allowed to see this read (empty when receipts are off)
+}
+
+// MessageReceiptResponse - Delivery/read state of a message for one recipient
+type MessageReceiptResponse struct {
+	User        UserResponse `json:"user"`
+	DeliveredAt *time.Time   `json:"deliveredAt,omitempty"`
+	ReadAt      *time.Time   `json:"readAt,omitempty"` // Hidden when either side turned read receipts off
+}
+
+// MessageReceiptListResponse - Receipts of a message, for its sender
+type MessageReceiptListResponse struct {
+	Status     string                   `json:"status"`
+	Recipients int                      `json:"recipients"`
+	Receipts   []MessageReceiptResponse `json:"receipts"`
+}
+
+// ChatSettingsResponse - Chat privacy settings
+type ChatSettingsResponse struct {
+	ReadReceipts bool   `json:"readReceipts"`
+	DMPolicy     string `json:"dmPolicy"` // "everyone", "followers", "nobody"
+}
+
+// UpdateChatSettingsRequest - Request to update chat privacy settings
+type UpdateChatSettingsRequest struct {
+	ReadReceipts *bool   `json:"readReceipts"`
+	DMPolicy     *string `json:"dmPolicy" validate:"omitempty,oneof=everyone followers nobody"`
+}
+
 // ============================================================================
 // Block DTOs
 // ============================================================================
@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+// ReauthenticateRequest - Confirm identity before a sensitive account change, with
+// the password or (for accounts with 2FA) a TOTP or recovery code
+type ReauthenticateRequest struct {
+	Password string `json:"password" validate:"required_without=Code,omitempty,max=72"`
+	Code     string `json:"code" validate:"required_without=Password,omitempty,min=6,max=32"`
+}
+
+// SetPasswordRequest - Add a password to an account that signs in through OAuth only
+type SetPasswordRequest struct {
+	NewPassword     string `json:"newPassword" validate:"required,min=8,max=72"`
+	ConfirmPassword string `json:"confirmPassword" validate:"required,eqfield=NewPassword"`
+}
+
+// IdentityResponse - An OAuth account linked to the user
+type IdentityResponse struct {
+	Provider   string     `json:"provider"`
+	Email      string     `json:"email"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// LoginMethodsResponse - Every way the user can sign in
+type LoginMethodsResponse struct {
+	HasPassword bool               `json:"hasPassword"`
+	Identities  []IdentityResponse `json:"identities"`
+}
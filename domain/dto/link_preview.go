@@ -0,0 +1,10 @@
+package dto
+
+// LinkPreviewResponse - Unfurled metadata for the first link in a message or post
+type LinkPreviewResponse struct {
+	URL         string `json:"url"` // Canonical URL (falls back to the URL as written)
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	SiteName    string `json:"siteName,omitempty"`
+	ImageURL    string `json:"imageUrl,omitempty"`
+}
@@ -2,6 +2,7 @@ package dto
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/google/uuid"
 	"gofiber-template/domain/models"
@@ -25,6 +26,7 @@ func UserToUserResponse(user *models.User) *UserResponse {
 		FollowingCount: user.FollowingCount,
 		Role:           user.Role,
 		IsActive:       user.IsActive,
+		EmailVerified:  user.EmailVerified,
 		CreatedAt:      user.CreatedAt,
 		UpdatedAt:      user.UpdatedAt,
 	}
@@ -179,9 +181,31 @@ func PostToPostResponse(post *models.Post) *PostResponse {
 		resp.SourcePost = PostToPostResponse(post.SourcePost)
 	}
 
+	resp.LinkPreview = LinkPreviewToResponse(post.LinkPreview)
+
 	return resp
 }
 
+// LinkPreviewToResponse converts a cached preview to DTO (nil when unfurling failed)
+func LinkPreviewToResponse(preview *models.LinkPreview) *LinkPreviewResponse {
+	if preview == nil || preview.Status != models.LinkPreviewStatusOK {
+		return nil
+	}
+
+	url := preview.CanonicalURL
+	if url == "" {
+		url = preview.URL
+	}
+
+	return &LinkPreviewResponse{
+		URL:         url,
+		Title:       preview.Title,
+		Description: preview.Description,
+		SiteName:    preview.SiteName,
+		ImageURL:    preview.ImageURL,
+	}
+}
+
 // Comment mappers
 func CommentToCommentResponse(comment *models.Comment) *CommentResponse {
 	if comment == nil {
@@ -345,10 +369,36 @@ func NotificationSettingsToResponse(settings *models.NotificationSettings) *Noti
 		Votes:              settings.Votes,
 		Follows:            settings.Follows,
 		EmailNotifications: settings.EmailNotifications,
-		UpdatedAt:          settings.UpdatedAt,
+		Matrix:             settings.EffectivePreferences(),
+		QuietHours: QuietHoursResponse{
+			Enabled:  settings.QuietHoursEnabled,
+			Start:    settings.QuietHoursStart,
+			End:      settings.QuietHoursEnd,
+			Timezone: settings.Timezone,
+		},
+		UpdatedAt: settings.UpdatedAt,
 	}
 }
 
+func NotificationOutboxToDeliveryResponse(entry *models.NotificationOutbox) *NotificationDeliveryResponse {
+	if entry == nil {
+		return nil
+	}
+
+	resp := &NotificationDeliveryResponse{
+		Channel:     entry.Channel,
+		Status:      entry.Status,
+		Attempts:    entry.Attempts,
+		LastError:   entry.LastError,
+		DeliveredAt: entry.DeliveredAt,
+	}
+	if entry.Status == models.OutboxStatusPending {
+		nextAttemptAt := entry.NextAttemptAt
+		resp.NextAttemptAt = &nextAttemptAt
+	}
+	return resp
+}
+
 // SearchHistory mappers
 func SearchHistoryToResponse(history *models.SearchHistory) *SearchHistoryResponse {
 	if history == nil {
@@ -377,11 +427,13 @@ func MessageToMessageResponse(message *models.Message) *MessageResponse {
 		ID:             message.ID,
 		ConversationID: message.ConversationID,
 		Sender:         *UserToUserResponse(&message.Sender),
-		Receiver:       *UserToUserResponse(&message.Receiver),
 		Type:           string(message.Type),
 		Content:        message.Content,
 		IsRead:         message.IsRead,
 		ReadAt:         message.ReadAt,
+		EditedAt:       message.EditedAt,
+		IsDeleted:      message.IsDeleted(),
+		DeletedAt:      message.DeletedAt,
 		CreatedAt:      message.CreatedAt,
 		UpdatedAt:      message.UpdatedAt,
 
@@ -389,6 +441,18 @@ func MessageToMessageResponse(message *models.Message) *MessageResponse {
 		SenderId:   message.SenderID,
 	}
 
+	if message.Receiver != nil {
+		resp.Receiver = UserToUserResponse(message.Receiver)
+	}
+
+	if message.ReplyToID != nil {
+		resp.ReplyTo = MessageToReplyPreview(message.ReplyTo, *message.ReplyToID)
+	}
+
+	if !message.IsDeleted() {
+		resp.LinkPreview = LinkPreviewToResponse(message.LinkPreview)
+	}
+
 	// Unmarshal Media JSONB to []MessageMedia
 	if message.Media != nil && len(message.Media) > 0 {
 		var mediaList []MessageMedia
@@ -400,6 +464,92 @@ func MessageToMessageResponse(message *models.Message) *MessageResponse {
 	return resp
 }
 
+// replyPreviewMaxLength caps quoted text in reply previews (runes)
+const replyPreviewMaxLength = 200
+
+// MessageToReplyPreview converts a quoted message to a compact preview.
+// A deleted (or missing) original yields a placeholder preview.
+func MessageToReplyPreview(message *models.Message, messageID uuid.UUID) *MessageReplyPreview {
+	if message == nil || message.IsDeleted() {
+		placeholder := "Message deleted"
+		preview := &MessageReplyPreview{
+			ID:          messageID,
+			IsDeleted:   true,
+			Placeholder: &placeholder,
+		}
+		if message != nil {
+			preview.SenderID = message.SenderID
+			preview.SenderName = message.Sender.DisplayName
+		}
+		return preview
+	}
+
+	preview := &MessageReplyPreview{
+		ID:         message.ID,
+		SenderID:   message.SenderID,
+		SenderName: message.Sender.DisplayName,
+		Type:       string(message.Type),
+	}
+
+	if message.Content != nil {
+		content := []rune(*message.Content)
+		if len(content) > replyPreviewMaxLength {
+			content = append(content[:replyPreviewMaxLength-3], []rune("...")...)
+		}
+		text := string(content)
+		preview.Content = &text
+	}
+
+	if len(message.Media) > 0 {
+		var mediaList []MessageMedia
+		if err := json.Unmarshal(message.Media, &mediaList); err == nil && len(mediaList) > 0 {
+			if mediaList[0].Thumbnail != nil {
+				preview.Thumbnail = mediaList[0].Thumbnail
+			} else if mediaList[0].Type == "image" {
+				preview.Thumbnail = &mediaList[0].URL
+			}
+		}
+	}
+
+	return preview
+}
+
+// MessageReactionToResponse converts MessageReaction model to MessageReactionResponse DTO
+func MessageReactionToResponse(reaction *models.MessageReaction) *MessageReactionResponse {
+	if reaction == nil {
+		return nil
+	}
+
+	return &MessageReactionResponse{
+		User:      *UserToUserResponse(&reaction.User),
+		Emoji:     reaction.Emoji,
+		CreatedAt: reaction.CreatedAt,
+	}
+}
+
+func MessageReceiptToResponse(receipt *models.MessageReceipt) *MessageReceiptResponse {
+	if receipt == nil {
+		return nil
+	}
+
+	return &MessageReceiptResponse{
+		User:        *UserToUserResponse(&receipt.User),
+		DeliveredAt: receipt.DeliveredAt,
+		ReadAt:      receipt.ReadAt,
+	}
+}
+
+func ChatSettingsToResponse(settings *models.ChatSettings) *ChatSettingsResponse {
+	if settings == nil {
+		return nil
+	}
+
+	return &ChatSettingsResponse{
+		ReadReceipts: settings.ReadReceipts,
+		DMPolicy:     settings.DMPolicy,
+	}
+}
+
 // ConversationToConversationResponse converts Conversation model to ConversationResponse DTO
 // currentUserID is needed to determine who the "other user" is and which unread count to show
 func ConversationToConversationResponse(conversation *models.Conversation, currentUserID uuid.UUID) *ConversationResponse {
@@ -407,30 +557,68 @@ func ConversationToConversationResponse(conversation *models.Conversation, curre
 		return nil
 	}
 
-	// Determine who is the "other user" and get their unread count
-	var otherUser models.User
-	var unreadCount int
-
-	if conversation.User1ID == currentUserID {
-		otherUser = conversation.User2
-		unreadCount = conversation.User1UnreadCount
-	} else {
-		otherUser = conversation.User1
-		unreadCount = conversation.User2UnreadCount
-	}
-
 	resp := &ConversationResponse{
 		ID:            conversation.ID,
-		OtherUser:     *UserToUserResponse(&otherUser),
+		Type:          string(conversation.Type),
 		LastMessageAt: conversation.LastMessageAt,
-		UnreadCount:   unreadCount,
 		CreatedAt:     conversation.CreatedAt,
 		UpdatedAt:     conversation.UpdatedAt,
 	}
 
+	// Unread count and per-user state come from the current user's participant row
+	if participant := conversation.Participant(currentUserID); participant != nil {
+		resp.UnreadCount = participant.UnreadCount
+		resp.IsPinned = participant.PinnedAt != nil
+		resp.IsArchived = participant.IsArchived(conversation.LastMessageAt)
+		if participant.IsMuted(time.Now()) {
+			resp.IsMuted = true
+			resp.MutedUntil = participant.MutedUntil
+		}
+		if conversation.IsGroup() {
+			resp.MyRole = string(participant.Role)
+		}
+	}
+
+	if conversation.IsGroup() {
+		resp.Title = conversation.Title
+		resp.Avatar = conversation.Avatar
+		resp.OwnerID = conversation.OwnerID
+
+		resp.Participants = make([]ConversationParticipantResponse, 0, len(conversation.Participants))
+		for i := range conversation.Participants {
+			resp.Participants = append(resp.Participants, *ConversationParticipantToResponse(&conversation.Participants[i]))
+		}
+		return resp
+	}
+
+	resp.RequestStatus = string(conversation.RequestStatus)
+	resp.IsRequest = conversation.IsRequestFor(currentUserID)
+
+	// Determine who is the "other user"
+	otherUser := conversation.User1
+	if conversation.User1ID == currentUserID {
+		otherUser = conversation.User2
+	}
+	resp.OtherUser = UserToUserResponse(&otherUser)
+
 	return resp
 }
 
+// ConversationParticipantToResponse converts ConversationParticipant model to DTO
+func ConversationParticipantToResponse(participant *models.ConversationParticipant) *ConversationParticipantResponse {
+	if participant == nil {
+		return nil
+	}
+
+	return &ConversationParticipantResponse{
+		User:              *UserToUserResponse(&participant.User),
+		Role:              string(participant.Role),
+		LastReadMessageID: participant.LastReadMessageID,
+		LastReadAt:        participant.LastReadAt,
+		JoinedAt:          participant.JoinedAt,
+	}
+}
+
 // BlockToBlockedUserResponse converts Block model to BlockedUserResponse DTO
 func BlockToBlockedUserResponse(block *models.Block) *BlockedUserResponse {
 	if block == nil {
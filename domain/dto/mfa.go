@@ -0,0 +1,33 @@
+package dto
+
+import "time"
+
+// MFAStatusResponse - Two-factor authentication state of the current user
+type MFAStatusResponse struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabledAt,omitempty"`
+	Required               bool       `json:"required"` // The user's role can't act without 2FA
+	RecoveryCodesRemaining int64      `json:"recoveryCodesRemaining"`
+}
+
+// MFASetupResponse - Secret to add to an authenticator app
+type MFASetupResponse struct {
+	Secret     string `json:"secret"`     // For manual entry
+	OTPAuthURI string `json:"otpauthUri"` // Render as a QR code
+}
+
+// MFACodeRequest - A TOTP code, or a recovery code where accepted
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required,min=6,max=32"`
+}
+
+// MFAVerifyRequest - Second step of a login with 2FA
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfaToken" validate:"required"`
+	Code     string `json:"code" validate:"required,min=6,max=32"` // TOTP or recovery code
+}
+
+// RecoveryCodesResponse - Shown once; only hashes are stored
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
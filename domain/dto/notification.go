@@ -33,20 +33,54 @@ type MarkAsReadRequest struct {
 
 // NotificationSettingsRequest - Request for updating notification settings
 type NotificationSettingsRequest struct {
+	// Legacy per-type switches: each one turns a type on or off on every channel
 	Replies            *bool `json:"replies" validate:"omitempty"`
 	Mentions           *bool `json:"mentions" validate:"omitempty"`
 	Votes              *bool `json:"votes" validate:"omitempty"`
 	Follows            *bool `json:"follows" validate:"omitempty"`
 	EmailNotifications *bool `json:"emailNotifications" validate:"omitempty"`
+
+	// Partial type × channel matrix, e.g. {"vote": {"push": false}}
+	Matrix map[string]map[string]bool `json:"matrix" validate:"omitempty"`
+
+	QuietHours *QuietHoursRequest `json:"quietHours" validate:"omitempty"`
+}
+
+// QuietHoursRequest - Quiet hours window in the user's timezone
+type QuietHoursRequest struct {
+	Enabled  *bool   `json:"enabled" validate:"omitempty"`
+	Start    *string `json:"start" validate:"omitempty,len=5"` // HH:MM
+	End      *string `json:"end" validate:"omitempty,len=5"`   // HH:MM
+	Timezone *string `json:"timezone" validate:"omitempty,max=64"`
 }
 
 // NotificationSettingsResponse - Response for notification settings
 type NotificationSettingsResponse struct {
-	UserID             uuid.UUID `json:"userId"`
-	Replies            bool      `json:"replies"`
-	Mentions           bool      `json:"mentions"`
-	Votes              bool      `json:"votes"`
-	Follows            bool      `json:"follows"`
-	EmailNotifications bool      `json:"emailNotifications"`
-	UpdatedAt          time.Time `json:"updatedAt"`
+	UserID             uuid.UUID                  `json:"userId"`
+	Replies            bool                       `json:"replies"`
+	Mentions           bool                       `json:"mentions"`
+	Votes              bool                       `json:"votes"`
+	Follows            bool                       `json:"follows"`
+	EmailNotifications bool                       `json:"emailNotifications"`
+	Matrix             map[string]map[string]bool `json:"matrix"`
+	QuietHours         QuietHoursResponse         `json:"quietHours"`
+	UpdatedAt          time.Time                  `json:"updatedAt"`
+}
+
+// QuietHoursResponse - Quiet hours window
+type QuietHoursResponse struct {
+	Enabled  bool   `json:"enabled"`
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Timezone string `json:"timezone"`
+}
+
+// NotificationDeliveryResponse - Delivery status of a notification on one channel
+type NotificationDeliveryResponse struct {
+	Channel       string     `json:"channel"` // "websocket", "push", "email"
+	Status        string     `json:"status"`  // "pending", "processing", "delivered", "failed"
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt *time.Time `json:"nextAttemptAt,omitempty"`
+	LastError     *string    `json:"lastError,omitempty"`
+	DeliveredAt   *time.Time `json:"deliveredAt,omitempty"`
 }
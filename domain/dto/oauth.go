@@ -20,26 +20,46 @@ type OAuthUserInfo struct {
 
 // OAuthLoginResponse - Response after successful OAuth login
 type OAuthLoginResponse struct {
-	Token        string       `json:"token"`
+	Token        string       `json:"token,omitempty"`
+	RefreshToken string       `json:"refreshToken,omitempty"`
+	ExpiresIn    int64        `json:"expiresIn,omitempty"`
+	MFARequired  bool         `json:"mfaRequired"`
+	MFAToken     string       `json:"mfaToken,omitempty"` // Complete at /auth/mfa/verify
 	User         UserResponse `json:"user"`
 	IsNewUser    bool         `json:"isNewUser"`
 	NeedsProfile bool         `json:"needsProfile"` // True if user needs to complete profile (e.g., choose username)
 }
 
+// OAuthCallbackResult - Outcome of a provider callback, passed on to the frontend
+type OAuthCallbackResult struct {
+	Code           string // Authorization code for /auth/exchange after a login
+	LinkedProvider string // Set instead when the callback linked an account
+}
+
 // OAuthURLResponse - Response containing OAuth authorization URL
 type OAuthURLResponse struct {
 	URL string `json:"url"`
 }
 
+// OAuthProvidersResponse - Providers users can sign in with
+type OAuthProvidersResponse struct {
+	Providers []string `json:"providers"`
+}
+
 // ExchangeCodeRequest - Request to exchange authorization code for token
 type ExchangeCodeRequest struct {
-	Code  string `json:"code" validate:"required"`
-	State string `json:"state"`
+	Code         string `json:"code" validate:"required"`
+	State        string `json:"state" validate:"required"`
+	CodeVerifier string `json:"codeVerifier" validate:"omitempty,min=43,max=128"` // Required when the login was started with a code_challenge
 }
 
 // ExchangeCodeResponse - Response after exchanging code for token
 type ExchangeCodeResponse struct {
-	Token     string       `json:"token"`
-	User      UserResponse `json:"user"`
-	IsNewUser bool         `json:"isNewUser"`
+	Token        string       `json:"token,omitempty"`
+	RefreshToken string       `json:"refreshToken,omitempty"`
+	ExpiresIn    int64        `json:"expiresIn,omitempty"`
+	MFARequired  bool         `json:"mfaRequired"`
+	MFAToken     string       `json:"mfaToken,omitempty"` // Complete at /auth/mfa/verify
+	User         UserResponse `json:"user"`
+	IsNewUser    bool         `json:"isNewUser"`
 }
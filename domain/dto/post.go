@@ -33,6 +33,7 @@ type PostResponse struct {
 	Media        []MediaResponse `json:"media,omitempty"`
 	Tags         []TagResponse  `json:"tags,omitempty"`
 	SourcePost   *PostResponse  `json:"sourcePost,omitempty"` // For crossposts
+	LinkPreview  *LinkPreviewResponse `json:"linkPreview,omitempty"`
 	CreatedAt    time.Time      `json:"createdAt"`
 	UpdatedAt    time.Time      `json:"updatedAt"`
 
@@ -34,6 +34,33 @@ type PushNotificationPayload struct {
 	Badge string                 `json:"badge,omitempty"`
 	Tag   string                 `json:"tag,omitempty"`
 	Data  map[string]interface{} `json:"data,omitempty"`
+
+	// Delivery options for the push service (stripped before sending to the browser)
+	Options *PushDeliveryOptions `json:"options,omitempty"`
+}
+
+// PushDeliveryOptions controls how the push service treats a notification
+type PushDeliveryOptions struct {
+	TTL     int    `json:"ttl,omitempty"`     // Seconds to keep the message while the device is offline
+	Urgency string `json:"urgency,omitempty"` // "very-low", "low", "normal", "high"
+	Topic   string `json:"topic,omitempty"`   // Pending messages with the same topic are replaced
+}
+
+// PushEndpointMetrics - Delivery counters for one push service domain
+type PushEndpointMetrics struct {
+	Domain        string     `json:"domain"`
+	Success       int64      `json:"success"`
+	Failure       int64      `json:"failure"`
+	Gone          int64      `json:"gone"` // 404/410 responses (subscription removed)
+	LastStatus    int        `json:"lastStatus,omitempty"`
+	LastError     string     `json:"lastError,omitempty"`
+	LastAttemptAt *time.Time `json:"lastAttemptAt,omitempty"`
+}
+
+// PushMetricsResponse - Web Push delivery metrics
+type PushMetricsResponse struct {
+	RateLimited int64                 `json:"rateLimited"`
+	Endpoints   []PushEndpointMetrics `json:"endpoints"`
 }
 
 // RequestToModel converts DTO to model
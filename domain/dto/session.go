@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SessionResponse - A signed-in device
+type SessionResponse struct {
+	ID           uuid.UUID `json:"id"`
+	Device       string    `json:"device"` // e.g. "Chrome on macOS"
+	UserAgent    string    `json:"userAgent"`
+	IPAddress    string    `json:"ipAddress"`
+	IsCurrent    bool      `json:"isCurrent"` // The session making the request
+	LastActiveAt time.Time `json:"lastActiveAt"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// RevokeSessionsResponse - Result of signing out other devices
+type RevokeSessionsResponse struct {
+	RevokedCount int `json:"revokedCount"`
+}
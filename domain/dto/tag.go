@@ -30,3 +30,8 @@ type SearchTagsRequest struct {
 	Query string `json:"query" validate:"required,min=1,max=50"`
 	Limit int    `json:"limit" validate:"omitempty,min=1,max=50"`
 }
+
+// MergeTagRequest - Fold a tag into another; the tag in the URL is deleted
+type MergeTagRequest struct {
+	TargetTagID uuid.UUID `json:"targetTagId" validate:"required"`
+}
@@ -35,6 +35,7 @@ type UserResponse struct {
 	FollowingCount  int       `json:"followingCount"`
 	Role            string    `json:"role,omitempty"`
 	IsActive        bool      `json:"isActive"`
+	EmailVerified   bool      `json:"emailVerified"`
 	CreatedAt       time.Time `json:"createdAt"`
 	UpdatedAt       time.Time `json:"updatedAt"`
 	IsFollowing     *bool     `json:"isFollowing,omitempty"` // Only when authenticated
@@ -50,3 +51,14 @@ type ChangePasswordRequest struct {
 	NewPassword     string `json:"newPassword" validate:"required,min=8,max=72"`
 	ConfirmPassword string `json:"confirmPassword" validate:"required,eqfield=NewPassword"`
 }
+
+// AssignRoleRequest - Change a user's role (admin)
+type AssignRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=user moderator admin"`
+}
+
+// RoleResponse - A role and the permissions it grants
+type RoleResponse struct {
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+}
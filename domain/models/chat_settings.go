@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Who may start a direct conversation with a user. People the user follows can always
+// message them; everyone else lands in the message requests inbox.
+const (
+	DMPolicyEveryone  = "everyone"
+	DMPolicyFollowers = "followers" // Only users who follow them
+	DMPolicyNobody    = "nobody"
+)
+
+// ChatSettings holds a user's chat privacy preferences
+type ChatSettings struct {
+	UserID uuid.UUID `gorm:"primaryKey"`
+	User   User      `gorm:"foreignKey:UserID"`
+
+	// Share when messages are read. Turning it off is reciprocal:
+	// the user also stops seeing when others read their messages.
+	ReadReceipts bool `gorm:"default:true"`
+
+	// Who can send new direct messages (everyone, followers, nobody)
+	DMPolicy string `gorm:"type:varchar(20);not null;default:'everyone'"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (ChatSettings) TableName() string {
+	return "chat_settings"
+}
+
+// DefaultChatSettings returns the settings used until a user saves their own
+func DefaultChatSettings(userID uuid.UUID) *ChatSettings {
+	return &ChatSettings{
+		UserID:       userID,
+		ReadReceipts: true,
+		DMPolicy:     DMPolicyEveryone,
+	}
+}
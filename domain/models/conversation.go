@@ -7,16 +7,47 @@ import (
 	"gorm.io/gorm"
 )
 
+// ConversationType distinguishes 1:1 DMs from group chats
+type ConversationType string
+
+const (
+	ConversationTypeDirect ConversationType = "direct"
+	ConversationTypeGroup  ConversationType = "group"
+)
+
+// ConversationRequestStatus tracks a direct conversation started by a stranger
+type ConversationRequestStatus string
+
+const (
+	ConversationRequestAccepted ConversationRequestStatus = "accepted" // Regular inbox conversation
+	ConversationRequestPending  ConversationRequestStatus = "pending"  // In the recipient's requests inbox
+	ConversationRequestDeclined ConversationRequestStatus = "declined"
+)
+
 type Conversation struct {
 	ID uuid.UUID `gorm:"primaryKey;type:uuid"`
 
-	// Participants (ordered by UUID for consistency)
+	// Conversation Type (direct, group)
+	Type ConversationType `gorm:"type:varchar(10);not null;default:'direct';index"`
+
+	// Group metadata (nil for direct conversations)
+	Title   *string    `gorm:"type:varchar(100)"`
+	Avatar  *string    `gorm:"type:text"`
+	OwnerID *uuid.UUID `gorm:"type:uuid;index"`
+
+	// Direct participants (ordered by UUID for consistency).
+	// Groups store the creator in both columns; membership lives in Participants.
 	User1ID uuid.UUID `gorm:"not null;index:idx_conversation_users"`
 	User1   User      `gorm:"foreignKey:User1ID"`
 
 	User2ID uuid.UUID `gorm:"not null;index:idx_conversation_users"`
 	User2   User      `gorm:"foreignKey:User2ID"`
 
+	// Message request state (direct conversations only). RequesterID is the
+	// user who started the conversation while not followed by the recipient.
+	RequestStatus ConversationRequestStatus `gorm:"type:varchar(10);not null;default:'accepted';index"`
+	RequesterID   *uuid.UUID                `gorm:"type:uuid"`
+
 	// Last Message (denormalized for performance)
 	// Note: No FK constraint to avoid circular dependency with Message table
 	// We manually manage this relationship in application layer
@@ -24,10 +55,14 @@ type Conversation struct {
 	LastMessage   *Message   `gorm:"-"` // Skip this field during migration
 	LastMessageAt time.Time  `gorm:"index"`
 
-	// Unread Counts (denormalized for performance)
+	// Legacy unread counts for direct conversations.
+	// Per-user unread counts now live on ConversationParticipant.
 	User1UnreadCount int `gorm:"default:0"`
 	User2UnreadCount int `gorm:"default:0"`
 
+	// Active participants (both users for direct conversations)
+	Participants []ConversationParticipant `gorm:"foreignKey:ConversationID"`
+
 	// Timestamps
 	CreatedAt time.Time `gorm:"index"`
 	UpdatedAt time.Time
@@ -44,3 +79,57 @@ func (c *Conversation) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// IsGroup reports whether this is a group conversation
+func (c *Conversation) IsGroup() bool {
+	return c.Type == ConversationTypeGroup
+}
+
+// HasParticipant reports whether userID is an active participant.
+// Participants must be preloaded for group conversations.
+func (c *Conversation) HasParticipant(userID uuid.UUID) bool {
+	if !c.IsGroup() {
+		return c.User1ID == userID || c.User2ID == userID
+	}
+	return c.Participant(userID) != nil
+}
+
+// Participant returns the active participant row for userID, or nil
+func (c *Conversation) Participant(userID uuid.UUID) *ConversationParticipant {
+	for i := range c.Participants {
+		if c.Participants[i].UserID == userID && c.Participants[i].LeftAt == nil {
+			return &c.Participants[i]
+		}
+	}
+	return nil
+}
+
+// IsRequestFor reports whether the conversation is a message request that
+// userID received but has not accepted (kept out of their inbox)
+func (c *Conversation) IsRequestFor(userID uuid.UUID) bool {
+	return c.RequestStatus != "" && c.RequestStatus != ConversationRequestAccepted &&
+		c.RequesterID != nil && *c.RequesterID != userID
+}
+
+// OtherUserID returns the other participant of a direct conversation
+func (c *Conversation) OtherUserID(userID uuid.UUID) uuid.UUID {
+	if c.User1ID == userID {
+		return c.User2ID
+	}
+	return c.User1ID
+}
+
+// OtherParticipantIDs returns every active participant except userID
+func (c *Conversation) OtherParticipantIDs(userID uuid.UUID) []uuid.UUID {
+	if !c.IsGroup() {
+		return []uuid.UUID{c.OtherUserID(userID)}
+	}
+
+	ids := make([]uuid.UUID, 0, len(c.Participants))
+	for _, participant := range c.Participants {
+		if participant.UserID != userID && participant.LeftAt == nil {
+			ids = append(ids, participant.UserID)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ParticipantRole represents a participant's permissions in a conversation
+type ParticipantRole string
+
+const (
+	ParticipantRoleOwner  ParticipantRole = "owner" // Group creator, manages admins
+	ParticipantRoleAdmin  ParticipantRole = "admin" // Manages members and group info
+	ParticipantRoleMember ParticipantRole = "member"
+)
+
+// ConversationFolder selects a user's conversations by their per-user state
+type ConversationFolder string
+
+const (
+	ConversationFolderAll      ConversationFolder = "all"      // Every conversation, regardless of per-user state
+	ConversationFolderInbox    ConversationFolder = "inbox"    // Not pinned, archived or deleted
+	ConversationFolderPinned   ConversationFolder = "pinned"   // Pinned to the top of the list
+	ConversationFolderArchived ConversationFolder = "archived" // Archived with no message since
+)
+
+type ConversationParticipant struct {
+	ConversationID uuid.UUID `gorm:"primaryKey;type:uuid"`
+	UserID         uuid.UUID `gorm:"primaryKey;type:uuid;index"`
+	User           User      `gorm:"foreignKey:UserID"`
+
+	Role ParticipantRole `gorm:"type:varchar(10);not null;default:'member'"`
+
+	// Unread count and read position (denormalized for performance)
+	UnreadCount       int `gorm:"default:0"`
+	LastReadMessageID *uuid.UUID
+	LastReadAt        *time.Time
+
+	// Membership (LeftAt is set when the user leaves or is removed)
+	JoinedAt time.Time
+	LeftAt   *time.Time `gorm:"index"`
+
+	// Per-user conversation state (never visible to other participants)
+	PinnedAt   *time.Time
+	ArchivedAt *time.Time // Hidden from the inbox until a newer message arrives
+	Muted      bool       `gorm:"default:false"`
+	MutedUntil *time.Time // nil while muted means until unmuted
+	ClearedAt  *time.Time // Delete-for-me: messages up to this point are hidden from the user
+}
+
+func (ConversationParticipant) TableName() string {
+	return "conversation_participants"
+}
+
+// CanManage reports whether the participant can manage members and group info
+func (p *ConversationParticipant) CanManage() bool {
+	return p.Role == ParticipantRoleOwner || p.Role == ParticipantRoleAdmin
+}
+
+// IsMuted reports whether push notifications for the conversation are suppressed at now
+func (p *ConversationParticipant) IsMuted(now time.Time) bool {
+	return p.Muted && (p.MutedUntil == nil || now.Before(*p.MutedUntil))
+}
+
+// IsArchived reports whether the conversation is still archived given its latest activity
+func (p *ConversationParticipant) IsArchived(lastMessageAt time.Time) bool {
+	return p.ArchivedAt != nil && !lastMessageAt.After(*p.ArchivedAt)
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Reasons for reporting a conversation
+const (
+	ConversationReportSpam          = "spam"
+	ConversationReportHarassment    = "harassment"
+	ConversationReportInappropriate = "inappropriate"
+	ConversationReportOther         = "other"
+)
+
+// ConversationReport is a user's report about a conversation, typically a message request
+type ConversationReport struct {
+	ID             uuid.UUID `gorm:"primaryKey;type:uuid"`
+	ConversationID uuid.UUID `gorm:"type:uuid;not null;index"`
+	ReporterID     uuid.UUID `gorm:"type:uuid;not null;index"`
+	Reporter       User      `gorm:"foreignKey:ReporterID"`
+	ReportedUserID uuid.UUID `gorm:"type:uuid;not null;index"`
+	ReportedUser   User      `gorm:"foreignKey:ReportedUserID"`
+	Reason         string    `gorm:"type:varchar(20);not null"`
+	Details        *string   `gorm:"type:text"`
+	CreatedAt      time.Time `gorm:"index"`
+}
+
+func (ConversationReport) TableName() string {
+	return "conversation_reports"
+}
+
+// BeforeCreate hook to generate UUID before creating report
+func (r *ConversationReport) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
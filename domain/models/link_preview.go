@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LinkPreviewStatus records whether unfurling a URL succeeded
+type LinkPreviewStatus string
+
+const (
+	LinkPreviewStatusOK     LinkPreviewStatus = "ok"
+	LinkPreviewStatusFailed LinkPreviewStatus = "failed" // Kept so the URL isn't refetched on every mention
+)
+
+// LinkPreview is cached Open Graph / Twitter card metadata for a URL
+type LinkPreview struct {
+	ID           uuid.UUID         `gorm:"primaryKey;type:uuid"`
+	URL          string            `gorm:"type:varchar(2048);not null;uniqueIndex"` // Normalized URL as written by the user
+	CanonicalURL string            `gorm:"type:varchar(2048)"`                      // Final URL after redirects / og:url
+	Title        string            `gorm:"type:varchar(300)"`
+	Description  string            `gorm:"type:text"`
+	SiteName     string            `gorm:"type:varchar(300)"`
+	ImageURL     string            `gorm:"type:varchar(2048)"`
+	Status       LinkPreviewStatus `gorm:"type:varchar(10);not null;default:'ok'"`
+	FetchedAt    time.Time         `gorm:"index"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (LinkPreview) TableName() string {
+	return "link_previews"
+}
+
+// BeforeCreate hook to generate UUID before creating preview
+func (p *LinkPreview) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsFresh reports whether the cached result can still be used at now
+func (p *LinkPreview) IsFresh(now time.Time, okTTL, failedTTL time.Duration) bool {
+	ttl := okTTL
+	if p.Status == LinkPreviewStatusFailed {
+		ttl = failedTTL
+	}
+	return now.Before(p.FetchedAt.Add(ttl))
+}
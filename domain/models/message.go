@@ -16,19 +16,27 @@ const (
 	MessageTypeImage MessageType = "image"
 	MessageTypeVideo MessageType = "video"
 	MessageTypeFile  MessageType = "file"
+	MessageTypeVoice MessageType = "voice"
+)
+
+// Delete scopes for a message
+const (
+	MessageDeleteScopeMe       = "me"       // Hidden for the requesting user only
+	MessageDeleteScopeEveryone = "everyone" // Replaced by a tombstone for all participants
 )
 
 // MessageMedia represents media attached to a message
 type MessageMedia struct {
 	URL       string  `json:"url"`
 	Thumbnail *string `json:"thumbnail,omitempty"`
-	Type      string  `json:"type"` // "image", "video", "file"
+	Type      string  `json:"type"` // "image", "video", "file", "voice"
 	Filename  *string `json:"filename,omitempty"`
 	MimeType  *string `json:"mimeType,omitempty"`
 	Size      *int64  `json:"size,omitempty"`
 	Width     *int    `json:"width,omitempty"`
 	Height    *int    `json:"height,omitempty"`
-	Duration  *int    `json:"duration,omitempty"` // seconds, for videos
+	Duration  *int    `json:"duration,omitempty"` // seconds, for videos and voice
+	Waveform  []int   `json:"waveform,omitempty"` // amplitude samples (0-100), for voice
 }
 
 type Message struct {
@@ -36,13 +44,17 @@ type Message struct {
 	ConversationID uuid.UUID `gorm:"not null;index:idx_conversation_messages"`
 	Conversation   Conversation `gorm:"foreignKey:ConversationID"`
 
-	// Sender & Receiver
-	SenderID   uuid.UUID `gorm:"not null;index"`
-	Sender     User      `gorm:"foreignKey:SenderID"`
-	ReceiverID uuid.UUID `gorm:"not null;index"`
-	Receiver   User      `gorm:"foreignKey:ReceiverID"`
+	// Sender & Receiver (receiver is nil for group messages)
+	SenderID   uuid.UUID  `gorm:"not null;index"`
+	Sender     User       `gorm:"foreignKey:SenderID"`
+	ReceiverID *uuid.UUID `gorm:"index"`
+	Receiver   *User      `gorm:"foreignKey:ReceiverID"`
+
+	// Quoted message (must be in the same conversation)
+	ReplyToID *uuid.UUID `gorm:"type:uuid;index"`
+	ReplyTo   *Message   `gorm:"foreignKey:ReplyToID;constraint:OnDelete:SET NULL"`
 
-	// Message Type (text, image, video, file)
+	// Message Type (text, image, video, file, voice)
 	Type MessageType `gorm:"type:varchar(20);not null;default:'text';index:idx_messages_type"`
 
 	// Content (nullable - for media-only messages)
@@ -51,10 +63,20 @@ type Message struct {
 	// Media (JSONB array of MessageMedia)
 	Media datatypes.JSON `gorm:"type:jsonb"`
 
-	// Read Status
+	// Preview of the first link in the content (set asynchronously)
+	LinkPreviewID *uuid.UUID   `gorm:"type:uuid"`
+	LinkPreview   *LinkPreview `gorm:"foreignKey:LinkPreviewID;constraint:OnDelete:SET NULL"`
+
+	// Read status of a direct message by its receiver. Never set on group messages, which have
+	// no single receiver; members' reads are tracked by MessageReceipt and read positions.
 	IsRead bool       `gorm:"default:false;index"`
 	ReadAt *time.Time
 
+	// Edit / unsend. A message deleted for everyone stays as a tombstone
+	// (content and media cleared) so conversation history keeps its shape.
+	EditedAt  *time.Time
+	DeletedAt *time.Time `gorm:"index"`
+
 	// Timestamps (for cursor pagination)
 	CreatedAt time.Time `gorm:"index:idx_conversation_messages"`
 	UpdatedAt time.Time
@@ -64,6 +86,11 @@ func (Message) TableName() string {
 	return "messages"
 }
 
+// IsDeleted reports whether the message was deleted for everyone
+func (m *Message) IsDeleted() bool {
+	return m.DeletedAt != nil
+}
+
 // BeforeCreate hook to generate UUID before creating message
 func (m *Message) BeforeCreate(tx *gorm.DB) error {
 	if m.ID == uuid.Nil {
@@ -71,3 +98,16 @@ func (m *Message) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// Chat search strategies
+const (
+	MessageSearchModeFullText  = "fts"       // Whole-word matches through the full-text index, ranked
+	MessageSearchModeSubstring = "substring" // Fallback for text written without spaces (e.g. Thai)
+)
+
+// MessageSearchHit is one search match with its relevance
+type MessageSearchHit struct {
+	MessageID uuid.UUID
+	Rank      float64
+	Message   *Message `gorm:"-"`
+}
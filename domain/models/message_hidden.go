@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MessageHidden records a message a user deleted for themselves only
+type MessageHidden struct {
+	MessageID uuid.UUID `gorm:"primaryKey;type:uuid"`
+	Message   Message   `gorm:"foreignKey:MessageID;constraint:OnDelete:CASCADE"`
+	UserID    uuid.UUID `gorm:"primaryKey;type:uuid;index"`
+
+	CreatedAt time.Time
+}
+
+func (MessageHidden) TableName() string {
+	return "message_hidden"
+}
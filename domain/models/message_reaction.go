@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MessageReaction is one user's emoji reaction to a chat message
+type MessageReaction struct {
+	MessageID uuid.UUID `gorm:"primaryKey;type:uuid"`
+	Message   Message   `gorm:"foreignKey:MessageID;constraint:OnDelete:CASCADE"`
+	UserID    uuid.UUID `gorm:"primaryKey;type:uuid;index"`
+	User      User      `gorm:"foreignKey:UserID"`
+	Emoji     string    `gorm:"primaryKey;type:varchar(32)"`
+
+	CreatedAt time.Time `gorm:"index"`
+}
+
+func (MessageReaction) TableName() string {
+	return "message_reactions"
+}
+
+// MessageReactionCount is an aggregated reaction row (not a table)
+type MessageReactionCount struct {
+	MessageID   uuid.UUID
+	Emoji       string
+	Count       int
+	ReactedByMe bool
+}
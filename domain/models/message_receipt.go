@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MessageStatus is the delivery state of a message as seen by its sender
+type MessageStatus string
+
+const (
+	MessageStatusSent      MessageStatus = "sent"
+	MessageStatusDelivered MessageStatus = "delivered"
+	MessageStatusRead      MessageStatus = "read"
+)
+
+// MessageReceipt records when one recipient's client received and read a message
+type MessageReceipt struct {
+	MessageID   uuid.UUID `gorm:"primaryKey;type:uuid"`
+	Message     Message   `gorm:"foreignKey:MessageID;constraint:OnDelete:CASCADE"`
+	UserID      uuid.UUID `gorm:"primaryKey;type:uuid;index"`
+	User        User      `gorm:"foreignKey:UserID"`
+	DeliveredAt *time.Time
+	ReadAt      *time.Time
+}
+
+func (MessageReceipt) TableName() string {
+	return "message_receipts"
+}
+
+// MessageReceiptSummary aggregates receipts of a message across its recipients
+type MessageReceiptSummary struct {
+	MessageID  uuid.UUID
+	Recipients int // Active participants other than the sender
+	Delivered  int
+	Read       int // Only recipients who share read receipts
+}
+
+// Status reduces the summary to a single state; a message counts as
+// delivered/read once every recipient has reached that state
+func (s *MessageReceiptSummary) Status() MessageStatus {
+	if s.Recipients == 0 {
+		return MessageStatusSent
+	}
+	if s.Read >= s.Recipients {
+		return MessageStatusRead
+	}
+	if s.Delivered >= s.Recipients {
+		return MessageStatusDelivered
+	}
+	return MessageStatusSent
+}
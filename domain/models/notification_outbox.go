@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Delivery channels for notifications
+const (
+	NotificationChannelWebSocket = "websocket"
+	NotificationChannelPush      = "push"
+	NotificationChannelEmail     = "email"
+)
+
+// Outbox delivery statuses
+const (
+	OutboxStatusPending    = "pending"
+	OutboxStatusProcessing = "processing"
+	OutboxStatusDelivered  = "delivered"
+	OutboxStatusFailed     = "failed"
+)
+
+// NotificationOutbox is written in the same transaction as the change that
+// produced the notification. Each row tracks delivery on a single channel.
+type NotificationOutbox struct {
+	ID uuid.UUID `gorm:"primaryKey;type:uuid"`
+
+	NotificationID *uuid.UUID    `gorm:"type:uuid;index"`
+	Notification   *Notification `gorm:"foreignKey:NotificationID;constraint:OnDelete:CASCADE"`
+
+	UserID uuid.UUID `gorm:"type:uuid;not null;index"` // Recipient
+	User   User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+
+	Channel string         `gorm:"type:varchar(20);not null"` // websocket, push, email
+	Payload datatypes.JSON `gorm:"type:jsonb"`                // Channel-specific payload
+	Topic   string         `gorm:"type:varchar(100);index"`   // Push collapse topic (deferred pushes with the same topic replace each other)
+
+	// Delivery state
+	Status        string     `gorm:"type:varchar(20);not null;default:'pending';index:idx_outbox_due,priority:1"`
+	Attempts      int        `gorm:"default:0"`
+	MaxAttempts   int        `gorm:"default:5"`
+	NextAttemptAt time.Time  `gorm:"not null;index:idx_outbox_due,priority:2"`
+	LockedUntil   *time.Time // Lease held by the worker while processing
+	LeaseID       *uuid.UUID `gorm:"type:uuid"` // Claim holding the lease; results from an expired claim are discarded
+	LastError     *string    `gorm:"type:text"`
+	DeliveredAt   *time.Time
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (NotificationOutbox) TableName() string {
+	return "notification_outbox"
+}
@@ -1,24 +1,259 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/datatypes"
 )
 
+// Preference channels (what the user controls per notification type)
+const (
+	PreferenceChannelInApp = "in_app" // Notification list + real-time WebSocket
+	PreferenceChannelPush  = "push"
+	PreferenceChannelEmail = "email"
+)
+
+// Notification types with configurable preferences
+const (
+	NotificationTypeReply       = "reply"
+	NotificationTypeMention     = "mention"
+	NotificationTypeVote        = "vote"
+	NotificationTypeFollow      = "follow"
+	NotificationTypeChatMessage = "chat_message" // Only push/email apply; chat delivery itself is always on
+)
+
+// NotificationTypes lists every type exposed in the preference matrix
+var NotificationTypes = []string{
+	NotificationTypeReply,
+	NotificationTypeMention,
+	NotificationTypeVote,
+	NotificationTypeFollow,
+	NotificationTypeChatMessage,
+}
+
+// PreferenceChannels lists every channel exposed in the preference matrix
+var PreferenceChannels = []string{
+	PreferenceChannelInApp,
+	PreferenceChannelPush,
+	PreferenceChannelEmail,
+}
+
+// NotificationPreferences is a type × channel matrix, e.g. {"reply": {"push": false}}
+type NotificationPreferences map[string]map[string]bool
+
 type NotificationSettings struct {
 	UserID uuid.UUID `gorm:"primaryKey"`
 	User   User      `gorm:"foreignKey:UserID"`
 
+	// Legacy per-type switches (all channels). Used as the fallback
+	// when no preference matrix has been saved yet.
 	Replies            bool `gorm:"default:true"`
 	Mentions           bool `gorm:"default:true"`
 	Votes              bool `gorm:"default:false"`
 	Follows            bool `gorm:"default:true"`
 	EmailNotifications bool `gorm:"default:false"`
 
+	// Type × channel matrix (JSONB NotificationPreferences)
+	Preferences datatypes.JSON `gorm:"type:jsonb"`
+
+	// Quiet hours in the user's timezone: pushes are deferred until the window ends
+	QuietHoursEnabled bool   `gorm:"default:false"`
+	QuietHoursStart   string `gorm:"type:varchar(5);default:'22:00'"` // HH:MM
+	QuietHoursEnd     string `gorm:"type:varchar(5);default:'07:00'"` // HH:MM
+	Timezone          string `gorm:"type:varchar(64);default:'UTC'"`  // IANA name, e.g. Asia/Bangkok
+
 	UpdatedAt time.Time
 }
 
 func (NotificationSettings) TableName() string {
 	return "notification_settings"
 }
+
+// NotificationDecision is the per-channel outcome of a preference check
+type NotificationDecision struct {
+	InApp bool
+	Push  bool
+	Email bool
+
+	// Set when Push is allowed but falls inside quiet hours
+	PushDeferredUntil *time.Time
+}
+
+// Any reports whether the notification goes out on at least one channel
+func (d *NotificationDecision) Any() bool {
+	return d.InApp || d.Push || d.Email
+}
+
+// DefaultNotificationSettings returns the settings used before a user saves their own
+func DefaultNotificationSettings(userID uuid.UUID) *NotificationSettings {
+	return &NotificationSettings{
+		UserID:             userID,
+		Replies:            true,
+		Mentions:           true,
+		Votes:              false,
+		Follows:            true,
+		EmailNotifications: false,
+		QuietHoursStart:    "22:00",
+		QuietHoursEnd:      "07:00",
+		Timezone:           "UTC",
+		UpdatedAt:          time.Now(),
+	}
+}
+
+// NormalizeNotificationType maps legacy plural names to preference types
+func NormalizeNotificationType(notificationType string) string {
+	switch notificationType {
+	case "replies":
+		return NotificationTypeReply
+	case "mentions":
+		return NotificationTypeMention
+	case "votes":
+		return NotificationTypeVote
+	case "follows":
+		return NotificationTypeFollow
+	default:
+		return notificationType
+	}
+}
+
+// EffectivePreferences returns the full matrix, filling gaps from the legacy switches
+func (s *NotificationSettings) EffectivePreferences() NotificationPreferences {
+	legacy := map[string]bool{
+		NotificationTypeReply:       s.Replies,
+		NotificationTypeMention:     s.Mentions,
+		NotificationTypeVote:        s.Votes,
+		NotificationTypeFollow:      s.Follows,
+		NotificationTypeChatMessage: true,
+	}
+
+	matrix := make(NotificationPreferences, len(NotificationTypes))
+	for _, t := range NotificationTypes {
+		matrix[t] = map[string]bool{
+			PreferenceChannelInApp: legacy[t],
+			PreferenceChannelPush:  legacy[t],
+			PreferenceChannelEmail: legacy[t] && s.EmailNotifications,
+		}
+	}
+
+	var saved NotificationPreferences
+	if len(s.Preferences) > 0 && json.Unmarshal(s.Preferences, &saved) == nil {
+		for t, channels := range saved {
+			if _, ok := matrix[t]; !ok {
+				continue
+			}
+			for channel, enabled := range channels {
+				if _, ok := matrix[t][channel]; ok {
+					matrix[t][channel] = enabled
+				}
+			}
+		}
+	}
+
+	return matrix
+}
+
+// SetPreferences stores the matrix and keeps the legacy switches in sync
+func (s *NotificationSettings) SetPreferences(matrix NotificationPreferences) error {
+	data, err := json.Marshal(matrix)
+	if err != nil {
+		return err
+	}
+	s.Preferences = data
+
+	s.Replies = matrix[NotificationTypeReply][PreferenceChannelInApp]
+	s.Mentions = matrix[NotificationTypeMention][PreferenceChannelInApp]
+	s.Votes = matrix[NotificationTypeVote][PreferenceChannelInApp]
+	s.Follows = matrix[NotificationTypeFollow][PreferenceChannelInApp]
+
+	emailEnabled := false
+	for _, channels := range matrix {
+		emailEnabled = emailEnabled || channels[PreferenceChannelEmail]
+	}
+	s.EmailNotifications = emailEnabled
+
+	return nil
+}
+
+// Decide returns which channels a notification of the given type goes out on at time now
+func (s *NotificationSettings) Decide(notificationType string, now time.Time) *NotificationDecision {
+	notificationType = NormalizeNotificationType(notificationType)
+
+	channels, ok := s.EffectivePreferences()[notificationType]
+	if !ok {
+		// Types without preferences (e.g. system messages) are delivered in-app and by push
+		channels = map[string]bool{
+			PreferenceChannelInApp: true,
+			PreferenceChannelPush:  true,
+		}
+	}
+
+	decision := &NotificationDecision{
+		InApp: channels[PreferenceChannelInApp],
+		Push:  channels[PreferenceChannelPush],
+		Email: channels[PreferenceChannelEmail],
+	}
+
+	if decision.Push {
+		decision.PushDeferredUntil = s.QuietHoursEndAfter(now)
+	}
+
+	return decision
+}
+
+// QuietHoursEndAfter returns when the current quiet-hours window ends,
+// or nil when now is outside quiet hours (or they are disabled)
+func (s *NotificationSettings) QuietHoursEndAfter(now time.Time) *time.Time {
+	if !s.QuietHoursEnabled {
+		return nil
+	}
+
+	startMin, err := ParseClockMinutes(s.QuietHoursStart)
+	if err != nil {
+		return nil
+	}
+	endMin, err := ParseClockMinutes(s.QuietHoursEnd)
+	if err != nil || startMin == endMin {
+		return nil
+	}
+
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	local := now.In(loc)
+	nowMin := local.Hour()*60 + local.Minute()
+
+	var inQuietHours bool
+	if startMin < endMin {
+		inQuietHours = nowMin >= startMin && nowMin < endMin
+	} else {
+		// Window crosses midnight, e.g. 22:00-07:00
+		inQuietHours = nowMin >= startMin || nowMin < endMin
+	}
+	if !inQuietHours {
+		return nil
+	}
+
+	end := time.Date(local.Year(), local.Month(), local.Day(), endMin/60, endMin%60, 0, 0, loc)
+	if !end.After(local) {
+		end = end.AddDate(0, 0, 1)
+	}
+
+	return &end
+}
+
+// ParseClockMinutes parses "HH:MM" into minutes after midnight
+func ParseClockMinutes(value string) (int, error) {
+	var hour, minute int
+	if _, err := fmt.Sscanf(value, "%d:%d", &hour, &minute); err != nil || len(value) != 5 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	if hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return hour*60 + minute, nil
+}
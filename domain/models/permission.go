@@ -0,0 +1,57 @@
+package models
+
+import "slices"
+
+// Permissions granted through roles. Names read resource.action[.scope];
+// ".any" permissions apply to content the user doesn't own.
+const (
+	PermissionPostEditAny      = "post.edit.any"
+	PermissionPostRemoveAny    = "post.remove.any"
+	PermissionCommentRemoveAny = "comment.remove.any"
+	PermissionTagMerge         = "tag.merge"
+	PermissionUserList         = "user.list"
+	PermissionUserBan          = "user.ban"
+	PermissionUserAssignRole   = "user.role.assign"
+	PermissionSystemManage     = "system.manage" // Jobs, push metrics and other operations tooling
+)
+
+// rolePermissions maps each role to what it may do beyond acting on its own content
+var rolePermissions = map[string][]string{
+	RoleUser: {},
+	RoleModerator: {
+		PermissionPostRemoveAny,
+		PermissionCommentRemoveAny,
+		PermissionTagMerge,
+		PermissionUserList,
+		PermissionUserBan,
+	},
+	RoleAdmin: {
+		PermissionPostEditAny,
+		PermissionPostRemoveAny,
+		PermissionCommentRemoveAny,
+		PermissionTagMerge,
+		PermissionUserList,
+		PermissionUserBan,
+		PermissionUserAssignRole,
+		PermissionSystemManage,
+	},
+}
+
+// Roles lists every role from least to most privileged
+var Roles = []string{RoleUser, RoleModerator, RoleAdmin}
+
+// IsValidRole reports whether role is one of Roles
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// RolePermissions returns the permissions granted to role (none for unknown roles)
+func RolePermissions(role string) []string {
+	return slices.Clone(rolePermissions[role])
+}
+
+// RoleHasPermission reports whether role grants permission
+func RoleHasPermission(role string, permission string) bool {
+	return slices.Contains(rolePermissions[role], permission)
+}
@@ -0,0 +1,50 @@
+package models
+
+import "testing"
+
+func TestRoleHasPermission(t *testing.T) {
+	cases := []struct {
+		role       string
+		permission string
+		want       bool
+	}{
+		{RoleUser, PermissionPostRemoveAny, false},
+		{RoleUser, PermissionUserList, false},
+		{RoleModerator, PermissionPostRemoveAny, true},
+		{RoleModerator, PermissionUserBan, true},
+		{RoleModerator, PermissionPostEditAny, false},
+		{RoleModerator, PermissionUserAssignRole, false},
+		{RoleModerator, PermissionSystemManage, false},
+		{RoleAdmin, PermissionPostEditAny, true},
+		{RoleAdmin, PermissionUserAssignRole, true},
+		{RoleAdmin, PermissionSystemManage, true},
+		{"superuser", PermissionUserBan, false},
+		{"", PermissionUserBan, false},
+	}
+
+	for _, tc := range cases {
+		if got := RoleHasPermission(tc.role, tc.permission); got != tc.want {
+			t.Errorf("RoleHasPermission(%q, %q) = %v, want %v", tc.role, tc.permission, got, tc.want)
+		}
+	}
+}
+
+func TestRolePermissionsReturnsCopy(t *testing.T) {
+	permissions := RolePermissions(RoleModerator)
+	permissions[0] = PermissionSystemManage
+
+	if RoleHasPermission(RoleModerator, PermissionSystemManage) {
+		t.Fatal("changing the returned slice granted the role a permission")
+	}
+}
+
+func TestIsValidRole(t *testing.T) {
+	for _, role := range Roles {
+		if !IsValidRole(role) {
+			t.Errorf("IsValidRole(%q) = false", role)
+		}
+	}
+	if IsValidRole("superuser") {
+		t.Error("IsValidRole accepted an unknown role")
+	}
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Scopes a personal access token can be granted
+const (
+	TokenScopeRead  = "read"  // Read anything the user can see
+	TokenScopePost  = "post"  // Create, edit and delete posts, comments and media; follow and save
+	TokenScopeVote  = "vote"  // Vote on posts and comments
+	TokenScopeChat  = "chat"  // Read and send chat messages
+	TokenScopeAdmin = "admin" // Use the role's permissions (moderation, administration); staff only
+)
+
+// PersonalAccessTokenPrefix starts every personal access token, telling them apart from JWTs
+const PersonalAccessTokenPrefix = "pat_"
+
+// TokenScopes lists every scope in display order
+var TokenScopes = []string{TokenScopeRead, TokenScopePost, TokenScopeVote, TokenScopeChat, TokenScopeAdmin}
+
+// IsValidTokenScope reports whether scope is one of TokenScopes
+func IsValidTokenScope(scope string) bool {
+	return slices.Contains(TokenScopes, scope)
+}
+
+// PersonalAccessToken lets bots and integrations call the API as the user without a session.
+// It only grants its scopes and is shown once at creation; only its hash is stored.
+type PersonalAccessToken struct {
+	ID          uuid.UUID `gorm:"primaryKey;type:uuid"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;index"`
+	User        User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Name        string    `gorm:"type:varchar(100);not null"`
+	TokenHash   string    `gorm:"type:varchar(64);not null;uniqueIndex"` // SHA-256 of the token
+	TokenPrefix string    `gorm:"type:varchar(16);not null"`             // Start of the token, to tell tokens apart
+	Scopes      string    `gorm:"type:varchar(255);not null"`            // Space-separated
+	MFAVerified bool      `gorm:"default:false;not null"`                // Created from a session signed in with 2FA
+	ExpiresAt   time.Time `gorm:"not null"`
+	LastUsedAt  *time.Time
+	LastUsedIP  string `gorm:"type:varchar(64)"`
+	RevokedAt   *time.Time
+	CreatedAt   time.Time
+}
+
+func (PersonalAccessToken) TableName() string {
+	return "personal_access_tokens"
+}
+
+// BeforeCreate hook to generate UUID before creating token
+func (t *PersonalAccessToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// ScopeList returns the token's scopes
+func (t *PersonalAccessToken) ScopeList() []string {
+	return strings.Fields(t.Scopes)
+}
+
+// IsActive reports whether the token is accepted at now
+func (t *PersonalAccessToken) IsActive(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -23,6 +23,10 @@ type Post struct {
 	SourcePostID *uuid.UUID `gorm:"index"`
 	SourcePost   *Post      `gorm:"foreignKey:SourcePostID"`
 
+	// Preview of the first link in the content (set asynchronously)
+	LinkPreviewID *uuid.UUID   `gorm:"type:uuid"`
+	LinkPreview   *LinkPreview `gorm:"foreignKey:LinkPreviewID;constraint:OnDelete:SET NULL"`
+
 	// Media & Tags (relationships)
 	Media []Media `gorm:"many2many:post_media;"`
 	Tags  []Tag   `gorm:"many2many:post_tags;"`
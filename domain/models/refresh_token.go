@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RefreshToken is one link in a session's rotation chain. Every refresh revokes the
+// presented token and issues its successor in the same session.
+type RefreshToken struct {
+	ID           uuid.UUID  `gorm:"primaryKey;type:uuid"`
+	SessionID    uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Session      Session    `gorm:"foreignKey:SessionID;constraint:OnDelete:CASCADE"`
+	UserID       uuid.UUID  `gorm:"type:uuid;not null;index"`
+	TokenHash    string     `gorm:"type:varchar(64);not null;uniqueIndex"` // SHA-256 of the opaque token; the token itself is never stored
+	ExpiresAt    time.Time  `gorm:"not null;index"`
+	RevokedAt    *time.Time // Set when rotated
+	ReplacedByID *uuid.UUID `gorm:"type:uuid"` // Successor issued on rotation
+	CreatedAt    time.Time
+}
+
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// BeforeCreate hook to generate UUID before creating token
+func (t *RefreshToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsRotated reports whether the token was already exchanged for a successor
+func (t *RefreshToken) IsRotated() bool {
+	return t.RevokedAt != nil
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Why a session was revoked
+const (
+	SessionRevokeReasonLogout          = "logout"
+	SessionRevokeReasonUser            = "revoked"          // Signed out from the sessions list
+	SessionRevokeReasonPasswordReset   = "password_reset"   // Every session ends when a reset link is used
+	SessionRevokeReasonPasswordChanged = "password_changed" // Every other session ends on password change
+	SessionRevokeReasonTokenReuse      = "token_reuse"      // A rotated refresh token was presented again
+	SessionRevokeReasonAccountDisabled = "account_disabled"
+	SessionRevokeReasonRoleChanged     = "role_changed" // Access tokens carry the role, so it applies on the next sign-in
+)
+
+// Session is one signed-in device. Its ID is the sid claim of access tokens and
+// it owns the refresh token rotation chain.
+type Session struct {
+	ID           uuid.UUID `gorm:"primaryKey;type:uuid"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null;index:idx_sessions_user_active,priority:1"`
+	User         User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	UserAgent    string    `gorm:"type:varchar(512)"` // Latest seen (updated on refresh)
+	IPAddress    string    `gorm:"type:varchar(64)"`  // Latest seen (updated on refresh)
+	LastActiveAt time.Time `gorm:"not null;index:idx_sessions_user_active,priority:2"`
+	ExpiresAt    time.Time `gorm:"not null;index"`         // Extended on every refresh
+	MFAVerified  bool      `gorm:"default:false;not null"` // Signed in (or stepped up) with a second factor
+	RevokedAt    *time.Time
+	RevokeReason string `gorm:"type:varchar(30)"`
+	CreatedAt    time.Time
+
+	// Last time the user confirmed their password or second factor after signing in
+	ReauthenticatedAt *time.Time
+}
+
+func (Session) TableName() string {
+	return "sessions"
+}
+
+// BeforeCreate hook to generate UUID before creating session
+func (s *Session) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// AuthenticatedAt is when the user last proved who they are in this session
+func (s *Session) AuthenticatedAt() time.Time {
+	if s.ReauthenticatedAt != nil && s.ReauthenticatedAt.After(s.CreatedAt) {
+		return *s.ReauthenticatedAt
+	}
+	return s.CreatedAt
+}
+
+// IsActive reports whether the session can still be refreshed at now
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -12,10 +12,11 @@ type User struct {
 	Username string    `gorm:"uniqueIndex;not null"`
 	Password string    // Optional for OAuth users
 
-	// OAuth Fields
+	// OAuth Fields. Linked accounts live in user_identities; OAuthProvider and OAuthID
+	// are only read once to migrate accounts linked before that table existed.
 	OAuthProvider string `gorm:"index"` // google, facebook, github, etc.
 	OAuthID       string `gorm:"index"` // OAuth provider's user ID
-	IsOAuthUser   bool   `gorm:"default:false"`
+	IsOAuthUser   bool   `gorm:"default:false"` // Signed up through an OAuth provider
 
 	// Profile Fields
 	DisplayName string `gorm:"not null"`
@@ -30,14 +31,45 @@ type User struct {
 	FollowingCount int `gorm:"default:0"`
 
 	// Status
-	Role     string `gorm:"default:'user'"` // user, admin
+	Role     string `gorm:"default:'user'"` // user, moderator, admin; see rolePermissions
 	IsActive bool   `gorm:"default:true"`
 
+	// Email verification; unverified accounts can't chat and have a daily post limit
+	EmailVerified   bool `gorm:"default:false;not null"`
+	EmailVerifiedAt *time.Time
+
+	// Verified by the migration that introduced verification, without proving the address
+	EmailGrandfathered bool `gorm:"default:false;not null"`
+
+	// Two-factor authentication; the secret is stored encrypted and is set
+	// (but not yet enabled) while enrollment awaits confirmation
+	TOTPSecret    string `gorm:"type:varchar(255)"`
+	TOTPEnabled   bool   `gorm:"default:false;not null"`
+	TOTPEnabledAt *time.Time
+
 	// Timestamps
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
+// Roles
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// RoleRequiresMFA reports whether a role may only act from a session signed in with 2FA
+func RoleRequiresMFA(role string) bool {
+	return role == RoleAdmin || role == RoleModerator
+}
+
 func (User) TableName() string {
 	return "users"
+}
+
+// HasConfirmedEmail reports whether the user proved they own their address.
+// Grandfathered accounts count as verified but never did.
+func (u *User) HasConfirmedEmail() bool {
+	return u.EmailVerified && !u.EmailGrandfathered
 }
\ No newline at end of file
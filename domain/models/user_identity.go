@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserIdentity is an external account (Google, GitHub, ...) a user can sign in with.
+// A user has at most one identity per provider.
+type UserIdentity struct {
+	ID         uuid.UUID `gorm:"primaryKey;type:uuid"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_user_identities_user_provider,priority:1"`
+	User       User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Provider   string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_user_identities_user_provider,priority:2;uniqueIndex:idx_user_identities_subject,priority:1"`
+	Subject    string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identities_subject,priority:2"` // The provider's user ID
+	Email      string    `gorm:"type:varchar(255)"`                                                             // As reported by the provider when linked
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
+
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
+
+// BeforeCreate hook to generate UUID before creating identity
+func (i *UserIdentity) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+	"gofiber-template/domain/models"
+	"github.com/google/uuid"
+)
+
+type ChatSettingsRepository interface {
+	// Get settings (ErrRecordNotFound until the user saves them)
+	GetByUserID(ctx context.Context, userID uuid.UUID) (*models.ChatSettings, error)
+
+	// Create or update settings
+	Upsert(ctx context.Context, settings *models.ChatSettings) error
+
+	// Which of userIDs have turned read receipts off
+	ListReadReceiptsDisabled(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+}
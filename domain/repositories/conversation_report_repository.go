@@ -0,0 +1,10 @@
+package repositories
+
+import (
+	"context"
+	"gofiber-template/domain/models"
+)
+
+type ConversationReportRepository interface {
+	Create(ctx context.Context, report *models.ConversationReport) error
+}
@@ -14,24 +14,47 @@ type ConversationRepository interface {
 	Update(ctx context.Context, id uuid.UUID, conversation *models.Conversation) error
 	Delete(ctx context.Context, id uuid.UUID) error
 
-	// Get or Create conversation between two users
-	GetOrCreateByUsers(ctx context.Context, user1ID, user2ID uuid.UUID) (*models.Conversation, bool, error) // returns conversation, isNew, error
+	// Get or Create conversation between two users; a new one starts as a message request when requesterID is set
+	GetOrCreateByUsers(ctx context.Context, user1ID, user2ID uuid.UUID, requesterID *uuid.UUID) (*models.Conversation, bool, error) // returns conversation, isNew, error
 
 	// Get conversation by participants
 	GetByUsers(ctx context.Context, user1ID, user2ID uuid.UUID) (*models.Conversation, error)
 
-	// List conversations for a user (cursor-based pagination)
-	ListByUser(ctx context.Context, userID uuid.UUID, cursor *time.Time, limit int) ([]*models.Conversation, error)
+	// List conversations for a user in a folder (direct and group, cursor-based pagination), excluding requests they received
+	ListByUser(ctx context.Context, userID uuid.UUID, folder models.ConversationFolder, cursor *time.Time, limit int) ([]*models.Conversation, error)
+	CountPinnedByUser(ctx context.Context, userID uuid.UUID) (int64, error)
 
-	// Unread count
+	// Message requests received by a user (pending, with at least one message)
+	ListRequestsByUser(ctx context.Context, userID uuid.UUID, cursor *time.Time, limit int) ([]*models.Conversation, error)
+	CountRequestsByUser(ctx context.Context, userID uuid.UUID) (int64, error)
+	UpdateRequestStatus(ctx context.Context, conversationID uuid.UUID, status models.ConversationRequestStatus) error
+
+	// Unread count (message requests not included)
 	GetTotalUnreadCount(ctx context.Context, userID uuid.UUID) (int, error)
 
 	// Mark as read
 	ResetUnreadCount(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) error
+	UpdateReadPosition(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, lastReadMessageID uuid.UUID, unreadCount int) error
 
 	// Update conversation metadata
 	UpdateLastMessage(ctx context.Context, conversationID uuid.UUID, messageID uuid.UUID, timestamp time.Time) error
+	RecomputeLastMessage(ctx context.Context, conversationID uuid.UUID) error // After the last message is deleted
 	IncrementUnreadCount(ctx context.Context, conversationID uuid.UUID, receiverID uuid.UUID) error
+	IncrementUnreadCountForOthers(ctx context.Context, conversationID uuid.UUID, senderID uuid.UUID) error
+
+	// Participants (active = not left)
+	GetParticipant(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) (*models.ConversationParticipant, error)
+	ListParticipants(ctx context.Context, conversationID uuid.UUID) ([]*models.ConversationParticipant, error)
+	CountParticipants(ctx context.Context, conversationID uuid.UUID) (int64, error)
+	AddParticipants(ctx context.Context, conversationID uuid.UUID, userIDs []uuid.UUID, role models.ParticipantRole) error
+	RemoveParticipant(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) error
+	UpdateParticipantRole(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, role models.ParticipantRole) error
+
+	// Per-user conversation state (pinning unarchives, archiving unpins)
+	SetPinned(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, pinnedAt *time.Time) error
+	SetArchived(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, archivedAt *time.Time) error
+	SetMuted(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, muted bool, mutedUntil *time.Time) error
+	ClearHistory(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, clearedAt time.Time) error // Delete-for-me
 
 	// Stats
 	Count(ctx context.Context) (int64, error)
@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+
+	"gofiber-template/domain/models"
+)
+
+type LinkPreviewRepository interface {
+	GetByURL(ctx context.Context, url string) (*models.LinkPreview, error)
+
+	// Insert or refresh the preview for preview.URL; returns the stored row
+	Upsert(ctx context.Context, preview *models.LinkPreview) (*models.LinkPreview, error)
+}
@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"context"
+	"gofiber-template/domain/models"
+	"github.com/google/uuid"
+)
+
+type MessageReactionRepository interface {
+	// Add / remove (adding an existing reaction is a no-op)
+	Add(ctx context.Context, reaction *models.MessageReaction) error
+	Remove(ctx context.Context, messageID uuid.UUID, userID uuid.UUID, emoji string) error
+
+	// Who reacted, excluding users blocked by or blocking viewerID (emoji nil = all)
+	ListByMessage(ctx context.Context, messageID uuid.UUID, viewerID uuid.UUID, emoji *string, offset, limit int) ([]*models.MessageReaction, error)
+	CountByMessage(ctx context.Context, messageID uuid.UUID, viewerID uuid.UUID, emoji *string) (int64, error)
+
+	// Emoji counts for a batch of messages as seen by viewerID
+	SummarizeByMessages(ctx context.Context, messageIDs []uuid.UUID, viewerID uuid.UUID) ([]*models.MessageReactionCount, error)
+
+	// Limit distinct emojis per user per message
+	CountByUser(ctx context.Context, messageID uuid.UUID, userID uuid.UUID) (int64, error)
+}
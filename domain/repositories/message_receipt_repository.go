@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"gofiber-template/domain/models"
+	"github.com/google/uuid"
+)
+
+type MessageReceiptRepository interface {
+	// Record delivery to userID; returns the messages newly marked (already delivered ones are skipped)
+	MarkDelivered(ctx context.Context, userID uuid.UUID, messageIDs []uuid.UUID, deliveredAt time.Time) ([]*models.Message, error)
+
+	// Record reading of every message from others in (after, until]; returns the messages newly marked
+	MarkRead(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, after *time.Time, until time.Time, readAt time.Time) ([]*models.Message, error)
+
+	// Per-recipient receipts of a message
+	ListByMessage(ctx context.Context, messageID uuid.UUID) ([]*models.MessageReceipt, error)
+
+	// Receipt counts for a batch of senderID's own messages (read counts honor recipients' privacy)
+	SummarizeForSender(ctx context.Context, messageIDs []uuid.UUID, senderID uuid.UUID) ([]*models.MessageReceiptSummary, error)
+}
@@ -11,28 +11,44 @@ type MessageRepository interface {
 	// Basic CRUD
 	Create(ctx context.Context, message *models.Message) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Message, error)
+	GetVisibleByID(ctx context.Context, id uuid.UUID, viewerID uuid.UUID) (*models.Message, error) // Not found when hidden from viewerID
 	Update(ctx context.Context, id uuid.UUID, message *models.Message) error
 	Delete(ctx context.Context, id uuid.UUID) error
 
-	// List messages (cursor-based pagination)
+	// Edit / unsend
+	UpdateContent(ctx context.Context, id uuid.UUID, content string, editedAt time.Time) error
+	MarkDeleted(ctx context.Context, id uuid.UUID, deletedAt time.Time) error // Tombstone: clears content and media
+	HideForUser(ctx context.Context, id uuid.UUID, userID uuid.UUID) error    // Delete for me
+
+	// Link preview of the first URL in the content (nil clears it)
+	SetLinkPreview(ctx context.Context, id uuid.UUID, previewID *uuid.UUID) error
+
+	// List messages (cursor-based pagination), excluding messages hidden by viewerID
 	// Cursor is based on created_at timestamp
-	ListByConversation(ctx context.Context, conversationID uuid.UUID, beforeCursor *time.Time, limit int) ([]*models.Message, error)
+	ListByConversation(ctx context.Context, conversationID uuid.UUID, viewerID uuid.UUID, beforeCursor *time.Time, limit int) ([]*models.Message, error)
 
 	// Jump to message with context (for search results, media tabs, etc.)
-	GetMessagesBeforeTimestamp(ctx context.Context, conversationID uuid.UUID, timestamp time.Time, limit int) ([]*models.Message, error)
-	GetMessagesAfterTimestamp(ctx context.Context, conversationID uuid.UUID, timestamp time.Time, limit int) ([]*models.Message, error)
+	GetMessagesBeforeTimestamp(ctx context.Context, conversationID uuid.UUID, viewerID uuid.UUID, timestamp time.Time, limit int) ([]*models.Message, error)
+	GetMessagesAfterTimestamp(ctx context.Context, conversationID uuid.UUID, viewerID uuid.UUID, timestamp time.Time, limit int) ([]*models.Message, error)
 
-	// Mark messages as read
+	// Mark direct messages to userID as read (is_read flag; group messages are left alone,
+	// their reads are tracked per member by receipts and read positions)
 	MarkAsRead(ctx context.Context, messageID uuid.UUID) error
 	MarkAllAsRead(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) error
+	MarkReadUntil(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, until time.Time) error
 
 	// Stats
 	Count(ctx context.Context) (int64, error)
 	CountByConversation(ctx context.Context, conversationID uuid.UUID) (int64, error)
 	CountUnread(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) (int64, error)
+	CountBySender(ctx context.Context, conversationID uuid.UUID, senderID uuid.UUID) (int64, error)
+	CountFromOthersAfter(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, after time.Time) (int64, error) // Unread behind a read position
 
 	// Phase 2: Media/Links/Files Queries
-	ListMediaMessages(ctx context.Context, conversationID uuid.UUID, mediaType *string, beforeCursor *time.Time, limit int) ([]*models.Message, error)
-	ListMessagesWithLinks(ctx context.Context, conversationID uuid.UUID, beforeCursor *time.Time, limit int) ([]*models.Message, error)
-	ListFileMessages(ctx context.Context, conversationID uuid.UUID, beforeCursor *time.Time, limit int) ([]*models.Message, error)
+	ListMediaMessages(ctx context.Context, conversationID uuid.UUID, viewerID uuid.UUID, mediaType *string, beforeCursor *time.Time, limit int) ([]*models.Message, error)
+	ListMessagesWithLinks(ctx context.Context, conversationID uuid.UUID, viewerID uuid.UUID, beforeCursor *time.Time, limit int) ([]*models.Message, error)
+	ListFileMessages(ctx context.Context, conversationID uuid.UUID, viewerID uuid.UUID, beforeCursor *time.Time, limit int) ([]*models.Message, error)
+
+	// Search message text in viewerID's conversations (conversationID nil = all), best matches first
+	Search(ctx context.Context, viewerID uuid.UUID, query string, conversationID *uuid.UUID, mode string, offset, limit int) ([]*models.MessageSearchHit, error)
 }
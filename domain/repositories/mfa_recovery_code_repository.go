@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gofiber-template/domain/models"
+)
+
+type MFARecoveryCodeRepository interface {
+	// Replace all of a user's codes with a new set
+	ReplaceForUser(ctx context.Context, userID uuid.UUID, codes []*models.MFARecoveryCode) error
+
+	// Mark an unused code as used; false if no unused code matches
+	Consume(ctx context.Context, userID uuid.UUID, codeHash string, at time.Time) (bool, error)
+
+	CountUnused(ctx context.Context, userID uuid.UUID) (int64, error)
+	DeleteByUser(ctx context.Context, userID uuid.UUID) error
+}
@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gofiber-template/domain/models"
+	"github.com/google/uuid"
+)
+
+// ErrOutboxLeaseLost is returned when recording a delivery result for an entry whose
+// lease expired and that was claimed again in the meantime
+var ErrOutboxLeaseLost = errors.New("outbox lease lost")
+
+type NotificationOutboxRepository interface {
+	// Create outbox entries (joins the caller's transaction when present)
+	Create(ctx context.Context, entries []*models.NotificationOutbox) error
+
+	// Remove pending entries that a newer entry supersedes
+	DeletePendingByTopic(ctx context.Context, userID uuid.UUID, channel string, topic string) error
+
+	// Claim due entries for delivery; claimed entries are leased until lease expires
+	// and carry the claim's LeaseID
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*models.NotificationOutbox, error)
+
+	// Delivery results, recorded only while leaseID still holds the entry
+	// (ErrOutboxLeaseLost otherwise)
+	MarkDelivered(ctx context.Context, id uuid.UUID, leaseID uuid.UUID, attempts int) error
+	ScheduleRetry(ctx context.Context, id uuid.UUID, leaseID uuid.UUID, attempts int, nextAttemptAt time.Time, lastError string) error
+	MarkFailed(ctx context.Context, id uuid.UUID, leaseID uuid.UUID, attempts int, lastError string) error
+
+	// Delivery status
+	ListByNotification(ctx context.Context, notificationID uuid.UUID) ([]*models.NotificationOutbox, error)
+
+	// Cleanup
+	DeleteDeliveredBefore(ctx context.Context, before time.Time) (int64, error)
+}
@@ -2,6 +2,8 @@ package repositories
 
 import (
 	"context"
+	"time"
+
 	"gofiber-template/domain/models"
 	"github.com/google/uuid"
 )
@@ -16,6 +18,6 @@ type NotificationSettingsRepository interface {
 	// Update settings
 	Update(ctx context.Context, userID uuid.UUID, settings *models.NotificationSettings) error
 
-	// Check if user wants to receive specific notification type
-	ShouldNotify(ctx context.Context, userID uuid.UUID, notificationType string) (bool, error)
+	// Decide per channel (in-app, push, email) whether the user receives a notification type at time now
+	ShouldNotify(ctx context.Context, userID uuid.UUID, notificationType string, now time.Time) (*models.NotificationDecision, error)
 }
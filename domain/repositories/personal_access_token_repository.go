@@ -0,0 +1,27 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gofiber-template/domain/models"
+)
+
+type PersonalAccessTokenRepository interface {
+	Create(ctx context.Context, token *models.PersonalAccessToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error)
+
+	// Unrevoked, unexpired tokens of a user, newest first
+	ListActiveByUser(ctx context.Context, userID uuid.UUID, now time.Time) ([]*models.PersonalAccessToken, error)
+	CountActiveByUser(ctx context.Context, userID uuid.UUID, now time.Time) (int64, error)
+
+	// Record a request made with the token
+	Touch(ctx context.Context, id uuid.UUID, ip string, at time.Time) error
+
+	// Revoke one of the user's tokens; false if there was no such active token
+	Revoke(ctx context.Context, userID uuid.UUID, id uuid.UUID, at time.Time) (bool, error)
+
+	// Delete tokens that expired or were revoked before cutoff
+	DeleteInactive(ctx context.Context, cutoff time.Time) (int64, error)
+}
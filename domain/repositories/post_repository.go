@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"time"
 	"gofiber-template/domain/models"
 	"github.com/google/uuid"
 )
@@ -22,6 +23,9 @@ type PostRepository interface {
 	Update(ctx context.Context, id uuid.UUID, post *models.Post) error
 	Delete(ctx context.Context, id uuid.UUID) error // Soft delete
 
+	// Link preview of the first URL in the content (nil clears it)
+	SetLinkPreview(ctx context.Context, id uuid.UUID, previewID *uuid.UUID) error
+
 	// List & Filter
 	List(ctx context.Context, offset, limit int, sortBy PostSortBy) ([]*models.Post, error)
 	ListByAuthor(ctx context.Context, authorID uuid.UUID, offset, limit int) ([]*models.Post, error)
@@ -37,6 +41,7 @@ type PostRepository interface {
 	// Stats
 	Count(ctx context.Context) (int64, error)
 	CountByAuthor(ctx context.Context, authorID uuid.UUID) (int64, error)
+	CountByAuthorSince(ctx context.Context, authorID uuid.UUID, since time.Time) (int64, error) // Includes deleted posts
 
 	// Comment count management
 	IncrementCommentCount(ctx context.Context, postID uuid.UUID) error
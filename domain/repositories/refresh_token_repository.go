@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gofiber-template/domain/models"
+)
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *models.RefreshToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+
+	// Revoke the token in favour of its successor; false if it was already revoked
+	Rotate(ctx context.Context, id uuid.UUID, replacedByID uuid.UUID, at time.Time) (bool, error)
+
+	// Remove tokens that expired before the given time
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gofiber-template/domain/models"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *models.Session) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Session, error)
+
+	// Sessions that are neither revoked nor expired, most recently active first
+	ListActiveByUser(ctx context.Context, userID uuid.UUID, now time.Time) ([]*models.Session, error)
+
+	// Record activity on refresh: latest device details and the extended expiry
+	Touch(ctx context.Context, id uuid.UUID, userAgent string, ipAddress string, at time.Time, expiresAt time.Time) error
+
+	// Record that the session passed a second factor
+	MarkMFAVerified(ctx context.Context, id uuid.UUID) error
+
+	// Record that the user confirmed their identity again
+	MarkReauthenticated(ctx context.Context, id uuid.UUID, at time.Time) error
+
+	// Revoke one session; false if it was already revoked
+	Revoke(ctx context.Context, id uuid.UUID, reason string, at time.Time) (bool, error)
+
+	// Revoke every active session of a user except one (uuid.Nil keeps none); returns the revoked IDs
+	RevokeAllByUser(ctx context.Context, userID uuid.UUID, exceptID uuid.UUID, reason string, at time.Time) ([]uuid.UUID, error)
+
+	// Remove sessions (and their refresh tokens) that expired before the given time
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
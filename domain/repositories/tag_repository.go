@@ -31,4 +31,7 @@ type TagRepository interface {
 
 	// Delete
 	Delete(ctx context.Context, id uuid.UUID) error
+
+	// Move the posts of sourceID to targetID and delete sourceID
+	Merge(ctx context.Context, sourceID, targetID uuid.UUID) error
 }
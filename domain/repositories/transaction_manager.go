@@ -0,0 +1,17 @@
+package repositories
+
+import "context"
+
+// TransactionManager runs a unit of work inside a single database transaction.
+// Repositories called with the ctx passed to fn join that transaction.
+type TransactionManager interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+
+	// AfterCommit runs fn once the transaction carried by ctx has committed, or right
+	// away when ctx carries none. fn is dropped when the transaction rolls back.
+	AfterCommit(ctx context.Context, fn func())
+
+	// WithoutTransaction returns ctx detached from the transaction it carries, for
+	// reads that must not abort the caller's transaction when they fail
+	WithoutTransaction(ctx context.Context) context.Context
+}
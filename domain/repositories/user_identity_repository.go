@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gofiber-template/domain/models"
+)
+
+type UserIdentityRepository interface {
+	Create(ctx context.Context, identity *models.UserIdentity) error
+	GetByProvider(ctx context.Context, provider string, subject string) (*models.UserIdentity, error)
+
+	// Identities of a user, oldest first
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.UserIdentity, error)
+	CountByUser(ctx context.Context, userID uuid.UUID) (int64, error)
+
+	// Record a sign-in with the identity
+	Touch(ctx context.Context, id uuid.UUID, at time.Time) error
+
+	// Remove the user's identity with provider; false if there was none
+	Delete(ctx context.Context, userID uuid.UUID, provider string) (bool, error)
+}
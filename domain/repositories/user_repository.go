@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"time"
 	"gofiber-template/domain/models"
 	"github.com/google/uuid"
 )
@@ -11,8 +12,13 @@ type UserRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
 	GetByUsername(ctx context.Context, username string) (*models.User, error)
-	GetByOAuth(ctx context.Context, provider, oauthID string) (*models.User, error)
+	GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.User, error) // Locks the row until the surrounding transaction ends
 	Update(ctx context.Context, id uuid.UUID, user *models.User) error
+	UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error // Empty hash removes the password
+	UpdateTOTP(ctx context.Context, id uuid.UUID, secret string, enabledAt *time.Time) error // Empty secret clears 2FA; enabledAt nil leaves it pending
+	UpdateRole(ctx context.Context, id uuid.UUID, role string) error
+	ConfirmEmail(ctx context.Context, id uuid.UUID, verifiedAt time.Time) error // Also clears a grandfathered verification
+	SetActive(ctx context.Context, id uuid.UUID, active bool) error // Inactive accounts can't sign in
 	Delete(ctx context.Context, id uuid.UUID) error
 	List(ctx context.Context, offset, limit int) ([]*models.User, error)
 	Count(ctx context.Context) (int64, error)
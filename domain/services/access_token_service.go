@@ -0,0 +1,27 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gofiber-template/domain/dto"
+)
+
+type AccessTokenService interface {
+	// Issue a personal access token; needs a recent sign-in. mfaVerified tells whether
+	// the creating session signed in with a second factor.
+	CreateToken(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, mfaVerified bool, req *dto.CreateAccessTokenRequest) (*dto.CreatedAccessTokenResponse, error)
+
+	// Active tokens of the user, newest first
+	ListTokens(ctx context.Context, userID uuid.UUID) ([]dto.AccessTokenResponse, error)
+
+	// Revoke one of the user's tokens
+	RevokeToken(ctx context.Context, userID uuid.UUID, tokenID uuid.UUID) error
+
+	// Resolve a token presented by a client and record its use. Fails with
+	// utils.ErrExpiredToken or utils.ErrInvalidToken.
+	Authenticate(ctx context.Context, token string, ip string) (*dto.AccessTokenPrincipal, error)
+
+	// Remove tokens that expired or were revoked a while ago; returns how many were deleted
+	PurgeInactiveTokens(ctx context.Context) (int64, error)
+}
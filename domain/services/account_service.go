@@ -0,0 +1,26 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gofiber-template/domain/dto"
+)
+
+type AccountService interface {
+	// Email a link confirming the user owns their address
+	SendVerificationEmail(ctx context.Context, userID uuid.UUID) error
+
+	// Confirm an email address with a token from a verification link
+	VerifyEmail(ctx context.Context, token string) error
+
+	// Tell the owner that failed logins locked their account for a while
+	NotifyAccountLocked(ctx context.Context, userID uuid.UUID, lockedFor time.Duration) error
+
+	// Email a password reset link. Never reveals whether the address has an account.
+	RequestPasswordReset(ctx context.Context, email string) error
+
+	// Set a new password with a token from a reset link and sign out every session
+	ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) error
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gofiber-template/domain/dto"
+	"gofiber-template/domain/models"
+)
+
+// ErrReauthenticationRequired is returned by sensitive account changes when the user
+// hasn't signed in or confirmed their password or second factor recently
+var ErrReauthenticationRequired = errors.New("please confirm your identity to continue")
+
+type AuthService interface {
+	// Start a new session for an authenticated user. mfaVerified records that the
+	// sign-in included a second factor.
+	IssueTokens(ctx context.Context, user *models.User, client *dto.ClientInfo, mfaVerified bool) (*dto.TokenPair, error)
+
+	// Rotate a refresh token. Presenting an already rotated token revokes the whole session.
+	Refresh(ctx context.Context, refreshToken string, client *dto.ClientInfo) (*dto.TokenPair, error)
+
+	// Revoke the session the refresh token belongs to
+	Logout(ctx context.Context, refreshToken string) error
+
+	// Whether access tokens of the session are still honoured
+	IsSessionActive(ctx context.Context, sessionID uuid.UUID) (bool, error)
+
+	// Record that a session proved a second factor; takes effect on the next refresh
+	MarkSessionMFAVerified(ctx context.Context, sessionID uuid.UUID) error
+
+	// Record that the user confirmed their password or second factor again in the session
+	MarkSessionReauthenticated(ctx context.Context, sessionID uuid.UUID) error
+
+	// ErrReauthenticationRequired unless the session was authenticated within the last few minutes
+	RequireRecentAuth(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error
+
+	// Signed-in devices of a user
+	ListSessions(ctx context.Context, userID uuid.UUID, currentSessionID uuid.UUID) ([]dto.SessionResponse, error)
+
+	// Sign out one of the user's sessions
+	RevokeSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error
+
+	// Sign out every session of the user except the current one; returns how many were revoked
+	RevokeOtherSessions(ctx context.Context, userID uuid.UUID, currentSessionID uuid.UUID, reason string) (int, error)
+
+	// Remove expired sessions and refresh tokens; returns how many rows were deleted
+	PurgeExpiredSessions(ctx context.Context) (int64, error)
+}
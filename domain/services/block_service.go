@@ -15,6 +15,7 @@ type BlockService interface {
 
 	// Check block status
 	GetBlockStatus(ctx context.Context, userID uuid.UUID, otherUsername string) (*dto.BlockStatusResponse, error)
+	IsBlockedBetween(ctx context.Context, userID uuid.UUID, otherUserID uuid.UUID) (bool, error) // Either direction
 
 	// List blocked users
 	ListBlockedUsers(ctx context.Context, userID uuid.UUID, offset, limit int) (*dto.BlockedUsersResponse, error)
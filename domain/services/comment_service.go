@@ -12,7 +12,7 @@ type CommentService interface {
 	CreateComment(ctx context.Context, userID uuid.UUID, req *dto.CreateCommentRequest) (*dto.CommentResponse, error)
 	GetComment(ctx context.Context, commentID uuid.UUID, userID *uuid.UUID) (*dto.CommentResponse, error)
 	UpdateComment(ctx context.Context, commentID uuid.UUID, userID uuid.UUID, req *dto.UpdateCommentRequest) (*dto.CommentResponse, error)
-	DeleteComment(ctx context.Context, commentID uuid.UUID, userID uuid.UUID) error
+	DeleteComment(ctx context.Context, commentID uuid.UUID, actor *dto.Actor) error // The author, or an actor with comment.remove.any
 
 	// List comments
 	ListCommentsByPost(ctx context.Context, postID uuid.UUID, offset, limit int, sortBy repositories.CommentSortBy, userID *uuid.UUID) (*dto.CommentListResponse, error)
@@ -11,14 +11,42 @@ type ConversationService interface {
 	GetOrCreateConversation(ctx context.Context, userID uuid.UUID, otherUsername string) (*dto.ConversationResponse, error)
 	GetConversation(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) (*dto.ConversationResponse, error)
 
-	// List conversations with cursor pagination
+	// List conversations with cursor pagination (pinned first on the first page, archived excluded)
 	ListConversations(ctx context.Context, userID uuid.UUID, cursor *string, limit int) (*dto.ConversationListResponse, error)
+	ListArchivedConversations(ctx context.Context, userID uuid.UUID, cursor *string, limit int) (*dto.ConversationListResponse, error)
+
+	// Per-user conversation management
+	PinConversation(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, pinned bool) (*dto.ConversationResponse, error)
+	ArchiveConversation(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, archived bool) (*dto.ConversationResponse, error)
+	MuteConversation(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, req *dto.MuteConversationRequest) (*dto.ConversationResponse, error)
+	UnmuteConversation(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) (*dto.ConversationResponse, error)
+	DeleteConversationForMe(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) error
 
 	// Unread counts
 	GetUnreadCount(ctx context.Context, userID uuid.UUID) (*dto.UnreadCountResponse, error)
 
-	// Mark as read
-	MarkAsRead(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) error
+	// Mark as read up to lastReadMessageID (nil = latest message)
+	MarkAsRead(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, lastReadMessageID *uuid.UUID) (*dto.ReadReceiptUpdate, error)
+
+	// Message requests (conversations started by users the recipient doesn't follow)
+	ListMessageRequests(ctx context.Context, userID uuid.UUID, cursor *string, limit int) (*dto.ConversationListResponse, error)
+	CountMessageRequests(ctx context.Context, userID uuid.UUID) (*dto.MessageRequestCountResponse, error)
+	AcceptMessageRequest(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) (*dto.ConversationResponse, error)
+	DeclineMessageRequest(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) error
+	ReportMessageRequest(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, req *dto.ReportConversationRequest) error
+
+	// Chat privacy settings
+	GetChatSettings(ctx context.Context, userID uuid.UUID) (*dto.ChatSettingsResponse, error)
+	UpdateChatSettings(ctx context.Context, userID uuid.UUID, req *dto.UpdateChatSettingsRequest) (*dto.ChatSettingsResponse, error)
+
+	// Group conversations
+	CreateGroup(ctx context.Context, userID uuid.UUID, req *dto.CreateGroupRequest) (*dto.ConversationResponse, error)
+	UpdateGroup(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, req *dto.UpdateGroupRequest) (*dto.ConversationResponse, error)
+	ListParticipants(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) (*dto.ConversationParticipantListResponse, error)
+	AddParticipants(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, req *dto.AddParticipantsRequest) (*dto.ConversationResponse, error)
+	RemoveParticipant(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, targetUserID uuid.UUID) error
+	UpdateParticipantRole(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, targetUserID uuid.UUID, role string) (*dto.ConversationResponse, error)
+	LeaveGroup(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) error
 
 	// Search users for chat
 	SearchUsersForChat(ctx context.Context, userID uuid.UUID, query string, limit int) (*dto.ChatUserSearchResponse, error)
@@ -0,0 +1,13 @@
+package services
+
+import (
+	"context"
+
+	"gofiber-template/domain/models"
+)
+
+type LinkPreviewService interface {
+	// Preview for the first link in text, from cache or fetched. Returns nil when text
+	// has no link or the page could not be unfurled.
+	PreviewForText(ctx context.Context, text string) (*models.LinkPreview, error)
+}
@@ -13,17 +13,34 @@ type MessageService interface {
 	// Get message
 	GetMessage(ctx context.Context, messageID uuid.UUID, userID uuid.UUID) (*dto.MessageResponse, error)
 
+	// Edit / unsend (scope: "me" or "everyone")
+	EditMessage(ctx context.Context, messageID uuid.UUID, userID uuid.UUID, req *dto.EditMessageRequest) (*dto.MessageResponse, error)
+	DeleteMessage(ctx context.Context, messageID uuid.UUID, userID uuid.UUID, scope string) (*dto.MessageResponse, error)
+
+	// Reactions
+	React(ctx context.Context, messageID uuid.UUID, userID uuid.UUID, emoji string) (*dto.MessageReactionUpdate, error)
+	Unreact(ctx context.Context, messageID uuid.UUID, userID uuid.UUID, emoji string) (*dto.MessageReactionUpdate, error)
+	ListReactions(ctx context.Context, messageID uuid.UUID, userID uuid.UUID, emoji *string, offset, limit int) (*dto.MessageReactionListResponse, error)
+
 	// List messages with cursor pagination
 	ListMessages(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, cursor *string, limit int) (*dto.MessageListResponse, error)
 
 	// Jump to message with context (for search, media tabs, etc.)
 	GetMessageContext(ctx context.Context, messageID uuid.UUID, userID uuid.UUID) (*dto.MessageContextResponse, error)
+	GetReplyContext(ctx context.Context, messageID uuid.UUID, userID uuid.UUID) (*dto.MessageContextResponse, error) // Context around the message quoted by messageID
 
 	// Mark as read
 	MarkAsRead(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) error
 
+	// Delivery receipts
+	MarkDelivered(ctx context.Context, userID uuid.UUID, messageIDs []uuid.UUID) ([]*dto.DeliveryReceiptUpdate, error)
+	ListReceipts(ctx context.Context, messageID uuid.UUID, userID uuid.UUID) (*dto.MessageReceiptListResponse, error) // Sender only
+
 	// Phase 2: Media/Links/Files Queries
 	ListMediaMessages(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, mediaType *string, cursor *string, limit int) (*dto.MessageListResponse, error)
 	ListMessagesWithLinks(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, cursor *string, limit int) (*dto.MessageListResponse, error)
 	ListFileMessages(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, cursor *string, limit int) (*dto.MessageListResponse, error)
+
+	// Full-text search across the user's conversations (conversationID nil) or within one
+	SearchMessages(ctx context.Context, userID uuid.UUID, query string, conversationID *uuid.UUID, cursor *string, limit int) (*dto.MessageSearchResponse, error)
 }
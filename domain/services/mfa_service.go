@@ -0,0 +1,35 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gofiber-template/domain/dto"
+	"gofiber-template/domain/models"
+)
+
+type MFAService interface {
+	GetStatus(ctx context.Context, userID uuid.UUID) (*dto.MFAStatusResponse, error)
+
+	// Generate a new TOTP secret; 2FA stays off until ConfirmEnrollment
+	BeginEnrollment(ctx context.Context, userID uuid.UUID) (*dto.MFASetupResponse, error)
+
+	// Turn 2FA on once the user proves their authenticator works. The current session
+	// counts as verified from then on. Returns the recovery codes.
+	ConfirmEnrollment(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, code string) (*dto.RecoveryCodesResponse, error)
+
+	// Turn 2FA off with a TOTP or recovery code
+	Disable(ctx context.Context, userID uuid.UUID, code string) error
+
+	// Replace the recovery codes, confirmed with a TOTP code
+	RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) (*dto.RecoveryCodesResponse, error)
+
+	// Check a TOTP or recovery code, e.g. to confirm identity before a sensitive change
+	VerifyCode(ctx context.Context, userID uuid.UUID, code string) error
+
+	// Short-lived token standing for a correct password until the second factor is entered
+	CreateChallenge(ctx context.Context, user *models.User) (string, error)
+
+	// Complete a login challenge with a TOTP or recovery code and start the session
+	VerifyChallenge(ctx context.Context, mfaToken string, code string, client *dto.ClientInfo) (*dto.TokenPair, *models.User, error)
+}
@@ -27,6 +27,13 @@ type NotificationService interface {
 	GetSettings(ctx context.Context, userID uuid.UUID) (*dto.NotificationSettingsResponse, error)
 	UpdateSettings(ctx context.Context, userID uuid.UUID, req *dto.NotificationSettingsRequest) (*dto.NotificationSettingsResponse, error)
 
+	// Delivery status per channel (websocket, push, email)
+	GetDeliveryStatus(ctx context.Context, notificationID uuid.UUID, userID uuid.UUID) ([]dto.NotificationDeliveryResponse, error)
+
 	// Internal methods for creating notifications (used by other services)
 	CreateNotification(ctx context.Context, userID uuid.UUID, senderID uuid.UUID, notifType string, message string, postID *uuid.UUID, commentID *uuid.UUID) error
+
+	// Queue a push that has no in-app notification (e.g. chat messages), honoring
+	// the user's push preference for notifType and deferring during quiet hours
+	DispatchPush(ctx context.Context, userID uuid.UUID, notifType string, payload *dto.PushNotificationPayload) error
 }
@@ -2,16 +2,30 @@ package services
 
 import (
 	"context"
+
+	"github.com/google/uuid"
 	"gofiber-template/domain/dto"
 )
 
 type OAuthService interface {
-	// GetGoogleAuthURL generates Google OAuth authorization URL
-	GetGoogleAuthURL(state string) string
+	// Providers lists the names of the configured OAuth providers
+	Providers() []string
+
+	// GetAuthURL starts an OAuth login and returns the provider's authorization URL.
+	// codeChallenge is the frontend's optional S256 PKCE challenge for the code exchange.
+	GetAuthURL(ctx context.Context, provider string, state string, codeChallenge string) (string, error)
+
+	// GetLinkURL starts linking a provider account to the signed-in user; needs a recent sign-in
+	GetLinkURL(ctx context.Context, provider string, userID uuid.UUID, sessionID uuid.UUID, state string) (string, error)
+
+	// HandleCallback finishes an OAuth login or link with the code the provider sent back.
+	// browserBound reports whether the state cookie showed this browser started the flow;
+	// links are refused without it.
+	HandleCallback(ctx context.Context, provider string, code string, state string, browserBound bool, client *dto.ClientInfo) (*dto.OAuthCallbackResult, error)
 
-	// HandleGoogleCallback processes Google OAuth callback
-	HandleGoogleCallback(ctx context.Context, code string) (*dto.OAuthLoginResponse, error)
+	// ExchangeCode redeems the authorization code from a login callback for its tokens, once
+	ExchangeCode(ctx context.Context, req *dto.ExchangeCodeRequest) (*dto.ExchangeCodeResponse, error)
 
-	// GetUserInfoFromGoogle retrieves user info from Google OAuth token
-	GetUserInfoFromGoogle(ctx context.Context, accessToken string) (*dto.OAuthUserInfo, error)
+	// UnlinkIdentity removes a linked provider account; refused when it is the last login method
+	UnlinkIdentity(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, provider string) error
 }
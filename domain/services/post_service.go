@@ -11,8 +11,9 @@ type PostService interface {
 	// Create and manage posts
 	CreatePost(ctx context.Context, userID uuid.UUID, req *dto.CreatePostRequest) (*dto.PostResponse, error)
 	GetPost(ctx context.Context, postID uuid.UUID, userID *uuid.UUID) (*dto.PostResponse, error)
-	UpdatePost(ctx context.Context, postID uuid.UUID, userID uuid.UUID, req *dto.UpdatePostRequest) (*dto.PostResponse, error)
-	DeletePost(ctx context.Context, postID uuid.UUID, userID uuid.UUID) error
+	// The author, or an actor with post.edit.any / post.remove.any
+	UpdatePost(ctx context.Context, postID uuid.UUID, actor *dto.Actor, req *dto.UpdatePostRequest) (*dto.PostResponse, error)
+	DeletePost(ctx context.Context, postID uuid.UUID, actor *dto.Actor) error
 
 	// List and filter posts
 	ListPosts(ctx context.Context, offset, limit int, sortBy repositories.PostSortBy, userID *uuid.UUID) (*dto.PostListResponse, error)
@@ -18,4 +18,7 @@ type PushService interface {
 
 	// Get VAPID public key (for frontend)
 	GetPublicKey() string
+
+	// Delivery metrics per push service domain
+	GetMetrics() *dto.PushMetricsResponse
 }
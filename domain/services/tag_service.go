@@ -18,6 +18,9 @@ type TagService interface {
 	// Search tags
 	SearchTags(ctx context.Context, query string, limit int) (*dto.TagListResponse, error)
 
+	// Fold a duplicate tag into another; needs tag.merge. Returns the merged tag.
+	MergeTags(ctx context.Context, actor *dto.Actor, sourceID, targetID uuid.UUID) (*dto.TagResponse, error)
+
 	// Internal methods (used by PostService)
 	GetOrCreateTags(ctx context.Context, tagNames []string) ([]uuid.UUID, error)
 }
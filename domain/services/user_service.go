@@ -2,19 +2,46 @@ package services
 
 import (
 	"context"
+	"time"
 	"gofiber-template/domain/dto"
 	"gofiber-template/domain/models"
 	"github.com/google/uuid"
 )
 
+// LoginThrottledError is returned while repeated failures make an account or IP
+// address wait before the next login attempt
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return "too many failed login attempts, please try again later"
+}
+
 type UserService interface {
 	Register(ctx context.Context, req *dto.CreateUserRequest) (*models.User, error)
-	Login(ctx context.Context, req *dto.LoginRequest) (string, *models.User, error)
+	Login(ctx context.Context, req *dto.LoginRequest, client *dto.ClientInfo) (*dto.LoginResponse, error)
 	GetProfile(ctx context.Context, userID uuid.UUID) (*models.User, error)
 	GetPublicProfile(ctx context.Context, username string, currentUserID *uuid.UUID) (*dto.UserResponse, error)
 	UpdateProfile(ctx context.Context, userID uuid.UUID, req *dto.UpdateUserRequest) (*models.User, error)
+	ChangePassword(ctx context.Context, userID uuid.UUID, currentSessionID uuid.UUID, req *dto.ChangePasswordRequest) error
+
+	// Confirm the password or second factor again, allowing sensitive changes in the session for a few minutes
+	Reauthenticate(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, req *dto.ReauthenticateRequest, client *dto.ClientInfo) error
+
+	// The password and linked OAuth accounts the user can sign in with
+	GetLoginMethods(ctx context.Context, userID uuid.UUID) (*dto.LoginMethodsResponse, error)
+
+	// Add a password to an OAuth-only account; needs a recent sign-in or re-authentication
+	SetPassword(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, req *dto.SetPasswordRequest) error
+
+	// Remove the password, leaving linked accounts; refused when it is the last login method
+	RemovePassword(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error
 	DeleteUser(ctx context.Context, userID uuid.UUID) error
 	ListUsers(ctx context.Context, offset, limit int) ([]*models.User, int64, error)
-	GenerateJWT(user *models.User) (string, error)
-	ValidateJWT(token string) (*models.User, error)
+
+	// Administration. Changing a role or banning signs the user out everywhere.
+	AssignRole(ctx context.Context, actor *dto.Actor, userID uuid.UUID, role string) (*models.User, error)
+	BanUser(ctx context.Context, actor *dto.Actor, userID uuid.UUID) error
+	UnbanUser(ctx context.Context, actor *dto.Actor, userID uuid.UUID) error
 }
\ No newline at end of file
@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gofiber-template/domain/models"
+	"gofiber-template/domain/repositories"
+)
+
+type ChatSettingsRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewChatSettingsRepository(db *gorm.DB) repositories.ChatSettingsRepository {
+	return &ChatSettingsRepositoryImpl{db: db}
+}
+
+func (r *ChatSettingsRepositoryImpl) GetByUserID(ctx context.Context, userID uuid.UUID) (*models.ChatSettings, error) {
+	var settings models.ChatSettings
+	err := dbFromContext(ctx, r.db).
+		Where("user_id = ?", userID).
+		First(&settings).Error
+	if err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func (r *ChatSettingsRepositoryImpl) Upsert(ctx context.Context, settings *models.ChatSettings) error {
+	// Explicit columns so boolean false values are written
+	return dbFromContext(ctx, r.db).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"read_receipts", "dm_policy", "updated_at"}),
+		}).
+		Select("*").
+		Omit("User").
+		Create(settings).Error
+}
+
+func (r *ChatSettingsRepositoryImpl) ListReadReceiptsDisabled(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	disabled := make(map[uuid.UUID]bool)
+	if len(userIDs) == 0 {
+		return disabled, nil
+	}
+
+	var ids []uuid.UUID
+	err := dbFromContext(ctx, r.db).
+		Model(&models.ChatSettings{}).
+		Where("user_id IN ? AND read_receipts = ?", userIDs, false).
+		Pluck("user_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		disabled[id] = true
+	}
+	return disabled, nil
+}
+
+var _ repositories.ChatSettingsRepository = (*ChatSettingsRepositoryImpl)(nil)
@@ -20,12 +20,12 @@ func NewCommentRepository(db *gorm.DB) repositories.CommentRepository {
 }
 
 func (r *CommentRepositoryImpl) Create(ctx context.Context, comment *models.Comment) error {
-	return r.db.WithContext(ctx).Create(comment).Error
+	return dbFromContext(ctx, r.db).Create(comment).Error
 }
 
 func (r *CommentRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*models.Comment, error) {
 	var comment models.Comment
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Preload("Author").
 		Preload("Post").
 		Where("id = ? AND is_deleted = ?", id, false).
@@ -37,12 +37,12 @@ func (r *CommentRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*mod
 }
 
 func (r *CommentRepositoryImpl) Update(ctx context.Context, id uuid.UUID, comment *models.Comment) error {
-	return r.db.WithContext(ctx).Where("id = ?", id).Updates(comment).Error
+	return dbFromContext(ctx, r.db).Where("id = ?", id).Updates(comment).Error
 }
 
 func (r *CommentRepositoryImpl) Delete(ctx context.Context, id uuid.UUID) error {
 	now := time.Now()
-	return r.db.WithContext(ctx).
+	return dbFromContext(ctx, r.db).
 		Model(&models.Comment{}).
 		Where("id = ?", id).
 		Updates(map[string]interface{}{
@@ -53,7 +53,7 @@ func (r *CommentRepositoryImpl) Delete(ctx context.Context, id uuid.UUID) error
 
 func (r *CommentRepositoryImpl) ListByPost(ctx context.Context, postID uuid.UUID, offset, limit int, sortBy repositories.CommentSortBy) ([]*models.Comment, error) {
 	var comments []*models.Comment
-	query := r.db.WithContext(ctx).
+	query := dbFromContext(ctx, r.db).
 		Preload("Author").
 		Where("post_id = ? AND parent_id IS NULL AND is_deleted = ?", postID, false)
 
@@ -76,7 +76,7 @@ func (r *CommentRepositoryImpl) ListByPost(ctx context.Context, postID uuid.UUID
 
 func (r *CommentRepositoryImpl) ListByAuthor(ctx context.Context, authorID uuid.UUID, offset, limit int) ([]*models.Comment, error) {
 	var comments []*models.Comment
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Preload("Author").
 		Preload("Post").
 		Preload("Post.Author").
@@ -89,7 +89,7 @@ func (r *CommentRepositoryImpl) ListByAuthor(ctx context.Context, authorID uuid.
 
 func (r *CommentRepositoryImpl) ListReplies(ctx context.Context, parentID uuid.UUID, offset, limit int, sortBy repositories.CommentSortBy) ([]*models.Comment, error) {
 	var comments []*models.Comment
-	query := r.db.WithContext(ctx).
+	query := dbFromContext(ctx, r.db).
 		Preload("Author").
 		Where("parent_id = ? AND is_deleted = ?", parentID, false)
 
@@ -113,7 +113,7 @@ func (r *CommentRepositoryImpl) ListReplies(ctx context.Context, parentID uuid.U
 func (r *CommentRepositoryImpl) GetCommentTree(ctx context.Context, postID uuid.UUID, maxDepth int) ([]*models.Comment, error) {
 	var comments []*models.Comment
 	// Get all comments for the post up to maxDepth
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Preload("Author").
 		Where("post_id = ? AND is_deleted = ? AND depth <= ?", postID, false, maxDepth).
 		Order("depth ASC, created_at ASC").
@@ -126,7 +126,7 @@ func (r *CommentRepositoryImpl) GetParentChain(ctx context.Context, commentID uu
 	var currentComment *models.Comment
 
 	// Start with the current comment
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Preload("Author").
 		Where("id = ?", commentID).
 		First(&currentComment).Error
@@ -139,7 +139,7 @@ func (r *CommentRepositoryImpl) GetParentChain(ctx context.Context, commentID uu
 	// Traverse up the parent chain
 	for currentComment.ParentID != nil {
 		var parent models.Comment
-		err := r.db.WithContext(ctx).
+		err := dbFromContext(ctx, r.db).
 			Preload("Author").
 			Where("id = ?", *currentComment.ParentID).
 			First(&parent).Error
@@ -155,13 +155,13 @@ func (r *CommentRepositoryImpl) GetParentChain(ctx context.Context, commentID uu
 
 func (r *CommentRepositoryImpl) Count(ctx context.Context) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&models.Comment{}).Where("is_deleted = ?", false).Count(&count).Error
+	err := dbFromContext(ctx, r.db).Model(&models.Comment{}).Where("is_deleted = ?", false).Count(&count).Error
 	return count, err
 }
 
 func (r *CommentRepositoryImpl) CountByPost(ctx context.Context, postID uuid.UUID) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Model(&models.Comment{}).
 		Where("post_id = ? AND parent_id IS NULL AND is_deleted = ?", postID, false).
 		Count(&count).Error
@@ -170,7 +170,7 @@ func (r *CommentRepositoryImpl) CountByPost(ctx context.Context, postID uuid.UUI
 
 func (r *CommentRepositoryImpl) CountByAuthor(ctx context.Context, authorID uuid.UUID) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Model(&models.Comment{}).
 		Where("author_id = ? AND is_deleted = ?", authorID, false).
 		Count(&count).Error
@@ -179,7 +179,7 @@ func (r *CommentRepositoryImpl) CountByAuthor(ctx context.Context, authorID uuid
 
 func (r *CommentRepositoryImpl) CountReplies(ctx context.Context, parentID uuid.UUID) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Model(&models.Comment{}).
 		Where("parent_id = ? AND is_deleted = ?", parentID, false).
 		Count(&count).Error
@@ -187,7 +187,7 @@ func (r *CommentRepositoryImpl) CountReplies(ctx context.Context, parentID uuid.
 }
 
 func (r *CommentRepositoryImpl) UpdateVoteCount(ctx context.Context, commentID uuid.UUID, voteChange int) error {
-	return r.db.WithContext(ctx).
+	return dbFromContext(ctx, r.db).
 		Model(&models.Comment{}).
 		Where("id = ?", commentID).
 		UpdateColumn("votes", gorm.Expr("votes + ?", voteChange)).Error
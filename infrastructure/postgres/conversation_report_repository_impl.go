@@ -0,0 +1,23 @@
+package postgres
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gofiber-template/domain/models"
+	"gofiber-template/domain/repositories"
+)
+
+type ConversationReportRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewConversationReportRepository(db *gorm.DB) repositories.ConversationReportRepository {
+	return &ConversationReportRepositoryImpl{db: db}
+}
+
+func (r *ConversationReportRepositoryImpl) Create(ctx context.Context, report *models.ConversationReport) error {
+	return dbFromContext(ctx, r.db).Create(report).Error
+}
+
+var _ repositories.ConversationReportRepository = (*ConversationReportRepositoryImpl)(nil)
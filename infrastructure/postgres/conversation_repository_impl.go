@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gofiber-template/domain/models"
 	"gofiber-template/domain/repositories"
 )
@@ -18,15 +19,22 @@ func NewConversationRepository(db *gorm.DB) repositories.ConversationRepository
 	return &ConversationRepositoryImpl{db: db}
 }
 
+// withConversationRelations preloads users and active participants
+func withConversationRelations(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("User1").
+		Preload("User2").
+		Preload("Participants", "left_at IS NULL").
+		Preload("Participants.User")
+}
+
 func (r *ConversationRepositoryImpl) Create(ctx context.Context, conversation *models.Conversation) error {
-	return r.db.WithContext(ctx).Create(conversation).Error
+	return dbFromContext(ctx, r.db).Create(conversation).Error
 }
 
 func (r *ConversationRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*models.Conversation, error) {
 	var conversation models.Conversation
-	err := r.db.WithContext(ctx).
-		Preload("User1").
-		Preload("User2").
+	err := withConversationRelations(dbFromContext(ctx, r.db)).
 		First(&conversation, "id = ?", id).Error
 	if err != nil {
 		return nil, err
@@ -35,17 +43,17 @@ func (r *ConversationRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID)
 }
 
 func (r *ConversationRepositoryImpl) Update(ctx context.Context, id uuid.UUID, conversation *models.Conversation) error {
-	return r.db.WithContext(ctx).
+	return dbFromContext(ctx, r.db).
 		Model(&models.Conversation{}).
 		Where("id = ?", id).
 		Updates(conversation).Error
 }
 
 func (r *ConversationRepositoryImpl) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&models.Conversation{}, "id = ?", id).Error
+	return dbFromContext(ctx, r.db).Delete(&models.Conversation{}, "id = ?", id).Error
 }
 
-func (r *ConversationRepositoryImpl) GetOrCreateByUsers(ctx context.Context, user1ID, user2ID uuid.UUID) (*models.Conversation, bool, error) {
+func (r *ConversationRepositoryImpl) GetOrCreateByUsers(ctx context.Context, user1ID, user2ID uuid.UUID, requesterID *uuid.UUID) (*models.Conversation, bool, error) {
 	// Ensure consistent ordering (smaller UUID first)
 	if user1ID.String() > user2ID.String() {
 		user1ID, user2ID = user2ID, user1ID
@@ -53,10 +61,8 @@ func (r *ConversationRepositoryImpl) GetOrCreateByUsers(ctx context.Context, use
 
 	// Try to get existing conversation
 	var conversation models.Conversation
-	err := r.db.WithContext(ctx).
-		Preload("User1").
-		Preload("User2").
-		Where("user1_id = ? AND user2_id = ?", user1ID, user2ID).
+	err := withConversationRelations(dbFromContext(ctx, r.db)).
+		Where("type = ? AND user1_id = ? AND user2_id = ?", models.ConversationTypeDirect, user1ID, user2ID).
 		First(&conversation).Error
 
 	if err == nil {
@@ -69,24 +75,37 @@ func (r *ConversationRepositoryImpl) GetOrCreateByUsers(ctx context.Context, use
 		return nil, false, err
 	}
 
-	// Create new conversation
+	// Create new conversation with both participants
 	now := time.Now()
 	conversation = models.Conversation{
+		Type:             models.ConversationTypeDirect,
 		User1ID:          user1ID,
 		User2ID:          user2ID,
 		LastMessageAt:    now,
 		User1UnreadCount: 0,
 		User2UnreadCount: 0,
+		RequestStatus:    models.ConversationRequestAccepted,
+	}
+	if requesterID != nil {
+		conversation.RequestStatus = models.ConversationRequestPending
+		conversation.RequesterID = requesterID
 	}
 
-	if err := r.db.WithContext(ctx).Create(&conversation).Error; err != nil {
+	err = dbFromContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&conversation).Error; err != nil {
+			return err
+		}
+		return tx.Create(&[]models.ConversationParticipant{
+			{ConversationID: conversation.ID, UserID: user1ID, Role: models.ParticipantRoleMember, JoinedAt: now},
+			{ConversationID: conversation.ID, UserID: user2ID, Role: models.ParticipantRoleMember, JoinedAt: now},
+		}).Error
+	})
+	if err != nil {
 		return nil, false, err
 	}
 
 	// Reload with preloaded users
-	err = r.db.WithContext(ctx).
-		Preload("User1").
-		Preload("User2").
+	err = withConversationRelations(dbFromContext(ctx, r.db)).
 		First(&conversation, "id = ?", conversation.ID).Error
 	if err != nil {
 		return nil, false, err
@@ -102,10 +121,8 @@ func (r *ConversationRepositoryImpl) GetByUsers(ctx context.Context, user1ID, us
 	}
 
 	var conversation models.Conversation
-	err := r.db.WithContext(ctx).
-		Preload("User1").
-		Preload("User2").
-		Where("user1_id = ? AND user2_id = ?", user1ID, user2ID).
+	err := withConversationRelations(dbFromContext(ctx, r.db)).
+		Where("type = ? AND user1_id = ? AND user2_id = ?", models.ConversationTypeDirect, user1ID, user2ID).
 		First(&conversation).Error
 
 	if err != nil {
@@ -114,16 +131,45 @@ func (r *ConversationRepositoryImpl) GetByUsers(ctx context.Context, user1ID, us
 	return &conversation, nil
 }
 
-func (r *ConversationRepositoryImpl) ListByUser(ctx context.Context, userID uuid.UUID, cursor *time.Time, limit int) ([]*models.Conversation, error) {
-	query := r.db.WithContext(ctx).
-		Preload("User1").
-		Preload("User2").
-		Where("user1_id = ? OR user2_id = ?", userID, userID).
-		Order("last_message_at DESC")
+// inFolder limits a conversation query joined with the user's participant row (cp) to a folder.
+// Conversations deleted for the user stay hidden until a newer message arrives.
+func inFolder(folder models.ConversationFolder) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if folder == models.ConversationFolderAll {
+			return db
+		}
+
+		db = db.Where("(cp.cleared_at IS NULL OR conversations.last_message_at > cp.cleared_at)")
+		archived := "cp.archived_at IS NOT NULL AND conversations.last_message_at <= cp.archived_at"
+
+		switch folder {
+		case models.ConversationFolderPinned:
+			return db.Where("cp.pinned_at IS NOT NULL")
+		case models.ConversationFolderArchived:
+			return db.Where(archived)
+		default:
+			return db.Where("cp.pinned_at IS NULL").Where("NOT (" + archived + ")")
+		}
+	}
+}
+
+func (r *ConversationRepositoryImpl) ListByUser(ctx context.Context, userID uuid.UUID, folder models.ConversationFolder, cursor *time.Time, limit int) ([]*models.Conversation, error) {
+	query := withConversationRelations(dbFromContext(ctx, r.db)).
+		Joins("JOIN conversation_participants cp ON cp.conversation_id = conversations.id").
+		Where("cp.user_id = ? AND cp.left_at IS NULL", userID).
+		Where("(conversations.request_status = ? OR conversations.requester_id = ?)", models.ConversationRequestAccepted, userID).
+		Scopes(inFolder(folder))
+
+	// Pinned conversations are ordered by when they were pinned
+	if folder == models.ConversationFolderPinned {
+		query = query.Order("cp.pinned_at DESC")
+	} else {
+		query = query.Order("conversations.last_message_at DESC")
+	}
 
 	// Apply cursor pagination
 	if cursor != nil {
-		query = query.Where("last_message_at < ?", *cursor)
+		query = query.Where("conversations.last_message_at < ?", *cursor)
 	}
 
 	var conversations []*models.Conversation
@@ -131,61 +177,98 @@ func (r *ConversationRepositoryImpl) ListByUser(ctx context.Context, userID uuid
 	return conversations, err
 }
 
-func (r *ConversationRepositoryImpl) GetTotalUnreadCount(ctx context.Context, userID uuid.UUID) (int, error) {
-	var totalUnread int64
+func (r *ConversationRepositoryImpl) CountPinnedByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := dbFromContext(ctx, r.db).
+		Model(&models.ConversationParticipant{}).
+		Where("user_id = ? AND left_at IS NULL AND pinned_at IS NOT NULL", userID).
+		Count(&count).Error
+	return count, err
+}
 
-	// Sum unread counts where user is user1
-	var unreadAsUser1 int64
-	err := r.db.WithContext(ctx).
-		Model(&models.Conversation{}).
-		Where("user1_id = ?", userID).
-		Select("COALESCE(SUM(user1_unread_count), 0)").
-		Scan(&unreadAsUser1).Error
-	if err != nil {
-		return 0, err
+// receivedRequests limits a conversation query to pending requests sent to userID
+func receivedRequests(userID uuid.UUID) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.
+			Joins("JOIN conversation_participants cp ON cp.conversation_id = conversations.id").
+			Where("cp.user_id = ? AND cp.left_at IS NULL", userID).
+			Where("conversations.request_status = ? AND conversations.requester_id <> ?", models.ConversationRequestPending, userID).
+			Where("conversations.last_message_id IS NOT NULL")
 	}
+}
+
+func (r *ConversationRepositoryImpl) ListRequestsByUser(ctx context.Context, userID uuid.UUID, cursor *time.Time, limit int) ([]*models.Conversation, error) {
+	query := withConversationRelations(dbFromContext(ctx, r.db)).
+		Scopes(receivedRequests(userID)).
+		Order("conversations.last_message_at DESC")
+
+	// Apply cursor pagination
+	if cursor != nil {
+		query = query.Where("conversations.last_message_at < ?", *cursor)
+	}
+
+	var conversations []*models.Conversation
+	err := query.Limit(limit).Find(&conversations).Error
+	return conversations, err
+}
 
-	// Sum unread counts where user is user2
-	var unreadAsUser2 int64
-	err = r.db.WithContext(ctx).
+func (r *ConversationRepositoryImpl) CountRequestsByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := dbFromContext(ctx, r.db).
 		Model(&models.Conversation{}).
-		Where("user2_id = ?", userID).
-		Select("COALESCE(SUM(user2_unread_count), 0)").
-		Scan(&unreadAsUser2).Error
+		Scopes(receivedRequests(userID)).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *ConversationRepositoryImpl) UpdateRequestStatus(ctx context.Context, conversationID uuid.UUID, status models.ConversationRequestStatus) error {
+	return dbFromContext(ctx, r.db).
+		Model(&models.Conversation{}).
+		Where("id = ?", conversationID).
+		Update("request_status", status).Error
+}
+
+func (r *ConversationRepositoryImpl) GetTotalUnreadCount(ctx context.Context, userID uuid.UUID) (int, error) {
+	var totalUnread int64
+	err := dbFromContext(ctx, r.db).
+		Model(&models.ConversationParticipant{}).
+		Joins("JOIN conversations c ON c.id = conversation_participants.conversation_id").
+		Where("conversation_participants.user_id = ? AND conversation_participants.left_at IS NULL", userID).
+		Where("(c.request_status = ? OR c.requester_id = ?)", models.ConversationRequestAccepted, userID).
+		Select("COALESCE(SUM(conversation_participants.unread_count), 0)").
+		Scan(&totalUnread).Error
 	if err != nil {
 		return 0, err
 	}
 
-	totalUnread = unreadAsUser1 + unreadAsUser2
 	return int(totalUnread), nil
 }
 
 func (r *ConversationRepositoryImpl) ResetUnreadCount(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) error {
-	// Get conversation to determine which field to reset
-	var conversation models.Conversation
-	err := r.db.WithContext(ctx).First(&conversation, "id = ?", conversationID).Error
-	if err != nil {
-		return err
-	}
-
-	// Reset appropriate unread count based on which user this is
-	if conversation.User1ID == userID {
-		return r.db.WithContext(ctx).
-			Model(&models.Conversation{}).
-			Where("id = ?", conversationID).
-			Update("user1_unread_count", 0).Error
-	} else if conversation.User2ID == userID {
-		return r.db.WithContext(ctx).
-			Model(&models.Conversation{}).
-			Where("id = ?", conversationID).
-			Update("user2_unread_count", 0).Error
-	}
+	// Reset unread count and move the read position to the latest message
+	return dbFromContext(ctx, r.db).
+		Model(&models.ConversationParticipant{}).
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Updates(map[string]interface{}{
+			"unread_count":         0,
+			"last_read_at":         time.Now(),
+			"last_read_message_id": gorm.Expr("(SELECT last_message_id FROM conversations WHERE id = ?)", conversationID),
+		}).Error
+}
 
-	return nil
+func (r *ConversationRepositoryImpl) UpdateReadPosition(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, lastReadMessageID uuid.UUID, unreadCount int) error {
+	return dbFromContext(ctx, r.db).
+		Model(&models.ConversationParticipant{}).
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Updates(map[string]interface{}{
+			"unread_count":         unreadCount,
+			"last_read_at":         time.Now(),
+			"last_read_message_id": lastReadMessageID,
+		}).Error
 }
 
 func (r *ConversationRepositoryImpl) UpdateLastMessage(ctx context.Context, conversationID uuid.UUID, messageID uuid.UUID, timestamp time.Time) error {
-	return r.db.WithContext(ctx).
+	return dbFromContext(ctx, r.db).
 		Model(&models.Conversation{}).
 		Where("id = ?", conversationID).
 		Updates(map[string]interface{}{
@@ -194,41 +277,174 @@ func (r *ConversationRepositoryImpl) UpdateLastMessage(ctx context.Context, conv
 		}).Error
 }
 
+func (r *ConversationRepositoryImpl) RecomputeLastMessage(ctx context.Context, conversationID uuid.UUID) error {
+	// Point at the newest message not deleted for everyone (NULL when none remain)
+	return dbFromContext(ctx, r.db).Exec(`
+		UPDATE conversations SET
+			last_message_id = (
+				SELECT id FROM messages
+				WHERE conversation_id = conversations.id AND deleted_at IS NULL
+				ORDER BY created_at DESC LIMIT 1
+			),
+			last_message_at = COALESCE((
+				SELECT created_at FROM messages
+				WHERE conversation_id = conversations.id AND deleted_at IS NULL
+				ORDER BY created_at DESC LIMIT 1
+			), conversations.created_at)
+		WHERE id = ?
+	`, conversationID).Error
+}
+
 func (r *ConversationRepositoryImpl) IncrementUnreadCount(ctx context.Context, conversationID uuid.UUID, receiverID uuid.UUID) error {
-	// Get conversation to determine which field to increment
-	var conversation models.Conversation
-	err := r.db.WithContext(ctx).First(&conversation, "id = ?", conversationID).Error
+	return dbFromContext(ctx, r.db).
+		Model(&models.ConversationParticipant{}).
+		Where("conversation_id = ? AND user_id = ? AND left_at IS NULL", conversationID, receiverID).
+		UpdateColumn("unread_count", gorm.Expr("unread_count + ?", 1)).Error
+}
+
+func (r *ConversationRepositoryImpl) IncrementUnreadCountForOthers(ctx context.Context, conversationID uuid.UUID, senderID uuid.UUID) error {
+	return dbFromContext(ctx, r.db).
+		Model(&models.ConversationParticipant{}).
+		Where("conversation_id = ? AND user_id <> ? AND left_at IS NULL", conversationID, senderID).
+		UpdateColumn("unread_count", gorm.Expr("unread_count + ?", 1)).Error
+}
+
+func (r *ConversationRepositoryImpl) GetParticipant(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) (*models.ConversationParticipant, error) {
+	var participant models.ConversationParticipant
+	err := dbFromContext(ctx, r.db).
+		Preload("User").
+		Where("conversation_id = ? AND user_id = ? AND left_at IS NULL", conversationID, userID).
+		First(&participant).Error
 	if err != nil {
-		return err
+		return nil, err
+	}
+	return &participant, nil
+}
+
+func (r *ConversationRepositoryImpl) ListParticipants(ctx context.Context, conversationID uuid.UUID) ([]*models.ConversationParticipant, error) {
+	var participants []*models.ConversationParticipant
+	err := dbFromContext(ctx, r.db).
+		Preload("User").
+		Where("conversation_id = ? AND left_at IS NULL", conversationID).
+		Order("joined_at ASC").
+		Find(&participants).Error
+	return participants, err
+}
+
+func (r *ConversationRepositoryImpl) CountParticipants(ctx context.Context, conversationID uuid.UUID) (int64, error) {
+	var count int64
+	err := dbFromContext(ctx, r.db).
+		Model(&models.ConversationParticipant{}).
+		Where("conversation_id = ? AND left_at IS NULL", conversationID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *ConversationRepositoryImpl) AddParticipants(ctx context.Context, conversationID uuid.UUID, userIDs []uuid.UUID, role models.ParticipantRole) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	participants := make([]models.ConversationParticipant, len(userIDs))
+	for i, userID := range userIDs {
+		participants[i] = models.ConversationParticipant{
+			ConversationID: conversationID,
+			UserID:         userID,
+			Role:           role,
+			JoinedAt:       now,
+		}
+	}
+
+	// Users who left earlier rejoin with a fresh membership
+	return dbFromContext(ctx, r.db).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "conversation_id"}, {Name: "user_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"role":         role,
+				"joined_at":    now,
+				"left_at":      nil,
+				"unread_count": 0,
+			}),
+		}).
+		Create(&participants).Error
+}
+
+func (r *ConversationRepositoryImpl) RemoveParticipant(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) error {
+	return dbFromContext(ctx, r.db).
+		Model(&models.ConversationParticipant{}).
+		Where("conversation_id = ? AND user_id = ? AND left_at IS NULL", conversationID, userID).
+		Updates(map[string]interface{}{
+			"left_at":      time.Now(),
+			"unread_count": 0,
+		}).Error
+}
+
+func (r *ConversationRepositoryImpl) UpdateParticipantRole(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, role models.ParticipantRole) error {
+	return dbFromContext(ctx, r.db).
+		Model(&models.ConversationParticipant{}).
+		Where("conversation_id = ? AND user_id = ? AND left_at IS NULL", conversationID, userID).
+		Update("role", role).Error
+}
+
+func (r *ConversationRepositoryImpl) SetPinned(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, pinnedAt *time.Time) error {
+	updates := map[string]interface{}{"pinned_at": pinnedAt}
+	if pinnedAt != nil {
+		updates["archived_at"] = nil
 	}
 
-	// Increment appropriate unread count
-	if conversation.User1ID == receiverID {
-		return r.db.WithContext(ctx).
-			Model(&models.Conversation{}).
-			Where("id = ?", conversationID).
-			UpdateColumn("user1_unread_count", gorm.Expr("user1_unread_count + ?", 1)).Error
-	} else if conversation.User2ID == receiverID {
-		return r.db.WithContext(ctx).
-			Model(&models.Conversation{}).
-			Where("id = ?", conversationID).
-			UpdateColumn("user2_unread_count", gorm.Expr("user2_unread_count + ?", 1)).Error
+	return dbFromContext(ctx, r.db).
+		Model(&models.ConversationParticipant{}).
+		Where("conversation_id = ? AND user_id = ? AND left_at IS NULL", conversationID, userID).
+		Updates(updates).Error
+}
+
+func (r *ConversationRepositoryImpl) SetArchived(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, archivedAt *time.Time) error {
+	updates := map[string]interface{}{"archived_at": archivedAt}
+	if archivedAt != nil {
+		updates["pinned_at"] = nil
 	}
 
-	return nil
+	return dbFromContext(ctx, r.db).
+		Model(&models.ConversationParticipant{}).
+		Where("conversation_id = ? AND user_id = ? AND left_at IS NULL", conversationID, userID).
+		Updates(updates).Error
+}
+
+func (r *ConversationRepositoryImpl) SetMuted(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, muted bool, mutedUntil *time.Time) error {
+	return dbFromContext(ctx, r.db).
+		Model(&models.ConversationParticipant{}).
+		Where("conversation_id = ? AND user_id = ? AND left_at IS NULL", conversationID, userID).
+		Updates(map[string]interface{}{
+			"muted":       muted,
+			"muted_until": mutedUntil,
+		}).Error
+}
+
+func (r *ConversationRepositoryImpl) ClearHistory(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, clearedAt time.Time) error {
+	// Hidden history counts as read; the conversation leaves the pinned and archived lists
+	return dbFromContext(ctx, r.db).
+		Model(&models.ConversationParticipant{}).
+		Where("conversation_id = ? AND user_id = ? AND left_at IS NULL", conversationID, userID).
+		Updates(map[string]interface{}{
+			"cleared_at":   clearedAt,
+			"unread_count": 0,
+			"pinned_at":    nil,
+			"archived_at":  nil,
+		}).Error
 }
 
 func (r *ConversationRepositoryImpl) Count(ctx context.Context) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&models.Conversation{}).Count(&count).Error
+	err := dbFromContext(ctx, r.db).Model(&models.Conversation{}).Count(&count).Error
 	return count, err
 }
 
 func (r *ConversationRepositoryImpl) CountByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).
-		Model(&models.Conversation{}).
-		Where("user1_id = ? OR user2_id = ?", userID, userID).
+	err := dbFromContext(ctx, r.db).
+		Model(&models.ConversationParticipant{}).
+		Where("user_id = ? AND left_at IS NULL", userID).
 		Count(&count).Error
 	return count, err
 }
@@ -32,9 +32,23 @@ func NewDatabase(config DatabaseConfig) (*gorm.DB, error) {
 }
 
 func Migrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	if err := migrateRefreshTokenFamilies(db); err != nil {
+		return err
+	}
+
+	// Accounts created before email verification existed are grandfathered in
+	grandfatherUsers := db.Migrator().HasTable(&models.User{}) &&
+		!db.Migrator().HasColumn(&models.User{}, "email_verified")
+
+	err := db.AutoMigrate(
 		// Core models (enhanced/new)
 		&models.User{},
+		&models.Session{},
+		&models.RefreshToken{},
+		&models.MFARecoveryCode{},
+		&models.UserIdentity{},
+		&models.PersonalAccessToken{},
+		&models.LinkPreview{}, // Referenced by posts and messages
 		&models.Post{},
 		&models.Comment{},
 		&models.Media{},
@@ -47,6 +61,7 @@ func Migrate(db *gorm.DB) error {
 		// Notifications
 		&models.Notification{},
 		&models.NotificationSettings{},
+		&models.NotificationOutbox{},
 		&models.PushSubscription{},
 
 		// Tags
@@ -57,12 +72,125 @@ func Migrate(db *gorm.DB) error {
 
 		// Chat System (Order matters: Conversation first, then Message, then Block)
 		&models.Conversation{},
+		&models.ConversationParticipant{},
 		&models.Block{},
 		&models.Message{},
+		&models.MessageHidden{},
+		&models.MessageReaction{},
+		&models.MessageReceipt{},
+		&models.ChatSettings{},
+		&models.ConversationReport{},
 
 		// Legacy models (keep for now, can remove later)
 		&models.Task{},
 		&models.File{},
 		&models.Job{},
 	)
-}
\ No newline at end of file
+	if err != nil {
+		return err
+	}
+
+	if grandfatherUsers {
+		if err := markExistingUsersVerified(db); err != nil {
+			return err
+		}
+	}
+
+	if err := backfillUserIdentities(db); err != nil {
+		return err
+	}
+
+	if err := backfillConversationParticipants(db); err != nil {
+		return err
+	}
+
+	return createMessageSearchIndex(db)
+}
+
+// migrateRefreshTokenFamilies turns the token families of the first rotation scheme into
+// sessions. Each family becomes the session with the same ID, so signed-in devices keep
+// their refresh tokens and the sid claim of their access tokens stays valid.
+func migrateRefreshTokenFamilies(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&models.RefreshToken{}, "family_id") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.AutoMigrate(&models.Session{}); err != nil {
+			return err
+		}
+
+		// Device details come from the family's latest token; a family whose tokens
+		// were all revoked (logout or reuse detection) becomes a revoked session
+		if err := tx.Exec(`
+			INSERT INTO sessions (id, user_id, user_agent, ip_address, last_active_at, expires_at, revoked_at, created_at)
+			SELECT DISTINCT ON (family_id)
+				family_id, user_id, user_agent, ip_address,
+				MAX(created_at) OVER family,
+				MAX(expires_at) OVER family,
+				CASE WHEN bool_and(revoked_at IS NOT NULL) OVER family THEN MAX(revoked_at) OVER family END,
+				MIN(created_at) OVER family
+			FROM refresh_tokens
+			WINDOW family AS (PARTITION BY family_id)
+			ORDER BY family_id, refresh_tokens.created_at DESC
+			ON CONFLICT (id) DO NOTHING
+		`).Error; err != nil {
+			return err
+		}
+
+		for _, statement := range []string{
+			`ALTER TABLE refresh_tokens RENAME COLUMN family_id TO session_id`,
+			`ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS user_agent, DROP COLUMN IF EXISTS ip_address`,
+			`DROP INDEX IF EXISTS idx_refresh_tokens_family_id`,
+		} {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// markExistingUsersVerified marks every account as verified, used once when the
+// email_verified column is first added. None of them proved their address, so they are
+// flagged as grandfathered and not trusted for OAuth account merging until they do.
+func markExistingUsersVerified(db *gorm.DB) error {
+	return db.Exec(`UPDATE users SET email_verified = true, email_grandfathered = true`).Error
+}
+
+// backfillUserIdentities copies OAuth links made before user_identities existed,
+// when a user could only have the one stored on the users row
+func backfillUserIdentities(db *gorm.DB) error {
+	return db.Exec(`
+		INSERT INTO user_identities (id, user_id, provider, subject, email, created_at)
+		SELECT gen_random_uuid(), id, oauth_provider, oauth_id, email, created_at FROM users
+		WHERE oauth_provider <> '' AND oauth_id <> ''
+		ON CONFLICT DO NOTHING
+	`).Error
+}
+
+// backfillConversationParticipants creates participant rows for direct conversations
+// created before group chat existed, carrying over their unread counts
+func backfillConversationParticipants(db *gorm.DB) error {
+	// Group messages have no receiver
+	if err := db.Exec(`ALTER TABLE messages ALTER COLUMN receiver_id DROP NOT NULL`).Error; err != nil {
+		return err
+	}
+
+	return db.Exec(`
+		INSERT INTO conversation_participants (conversation_id, user_id, role, unread_count, joined_at)
+		SELECT id, user1_id, 'member', user1_unread_count, created_at FROM conversations WHERE type = 'direct'
+		UNION ALL
+		SELECT id, user2_id, 'member', user2_unread_count, created_at FROM conversations WHERE type = 'direct'
+		ON CONFLICT (conversation_id, user_id) DO NOTHING
+	`).Error
+}
+
+// createMessageSearchIndex adds the full-text index used by chat search.
+// The 'simple' configuration skips stemming, which suits mixed-language chat.
+func createMessageSearchIndex(db *gorm.DB) error {
+	return db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_messages_content_fts
+		ON messages USING GIN (to_tsvector('simple', COALESCE(content, '')))
+	`).Error
+}
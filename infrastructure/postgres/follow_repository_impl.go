@@ -22,18 +22,18 @@ func (r *FollowRepositoryImpl) Follow(ctx context.Context, followerID uuid.UUID,
 		FollowerID:  followerID,
 		FollowingID: followingID,
 	}
-	return r.db.WithContext(ctx).Create(follow).Error
+	return dbFromContext(ctx, r.db).Create(follow).Error
 }
 
 func (r *FollowRepositoryImpl) Unfollow(ctx context.Context, followerID uuid.UUID, followingID uuid.UUID) error {
-	return r.db.WithContext(ctx).
+	return dbFromContext(ctx, r.db).
 		Where("follower_id = ? AND following_id = ?", followerID, followingID).
 		Delete(&models.Follow{}).Error
 }
 
 func (r *FollowRepositoryImpl) IsFollowing(ctx context.Context, followerID uuid.UUID, followingID uuid.UUID) (bool, error) {
 	var count int64
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Model(&models.Follow{}).
 		Where("follower_id = ? AND following_id = ?", followerID, followingID).
 		Count(&count).Error
@@ -42,7 +42,7 @@ func (r *FollowRepositoryImpl) IsFollowing(ctx context.Context, followerID uuid.
 
 func (r *FollowRepositoryImpl) GetFollowers(ctx context.Context, userID uuid.UUID, offset, limit int) ([]*models.User, error) {
 	var users []*models.User
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Joins("JOIN follows ON follows.follower_id = users.id").
 		Where("follows.following_id = ?", userID).
 		Offset(offset).Limit(limit).
@@ -52,7 +52,7 @@ func (r *FollowRepositoryImpl) GetFollowers(ctx context.Context, userID uuid.UUI
 
 func (r *FollowRepositoryImpl) CountFollowers(ctx context.Context, userID uuid.UUID) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Model(&models.Follow{}).
 		Where("following_id = ?", userID).
 		Count(&count).Error
@@ -61,7 +61,7 @@ func (r *FollowRepositoryImpl) CountFollowers(ctx context.Context, userID uuid.U
 
 func (r *FollowRepositoryImpl) GetFollowing(ctx context.Context, userID uuid.UUID, offset, limit int) ([]*models.User, error) {
 	var users []*models.User
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Joins("JOIN follows ON follows.following_id = users.id").
 		Where("follows.follower_id = ?", userID).
 		Offset(offset).Limit(limit).
@@ -71,7 +71,7 @@ func (r *FollowRepositoryImpl) GetFollowing(ctx context.Context, userID uuid.UUI
 
 func (r *FollowRepositoryImpl) CountFollowing(ctx context.Context, userID uuid.UUID) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Model(&models.Follow{}).
 		Where("follower_id = ?", userID).
 		Count(&count).Error
@@ -80,7 +80,7 @@ func (r *FollowRepositoryImpl) CountFollowing(ctx context.Context, userID uuid.U
 
 func (r *FollowRepositoryImpl) GetFollowStatus(ctx context.Context, followerID uuid.UUID, userIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
 	var follows []models.Follow
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Where("follower_id = ? AND following_id IN ?", followerID, userIDs).
 		Find(&follows).Error
 	if err != nil {
@@ -102,7 +102,7 @@ func (r *FollowRepositoryImpl) GetFollowStatus(ctx context.Context, followerID u
 func (r *FollowRepositoryImpl) GetMutualFollows(ctx context.Context, userID uuid.UUID, offset, limit int) ([]*models.User, error) {
 	var users []*models.User
 	// Find users who follow userID and are followed by userID
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Joins("JOIN follows f1 ON f1.following_id = users.id").
 		Joins("JOIN follows f2 ON f2.follower_id = users.id").
 		Where("f1.follower_id = ? AND f2.following_id = ?", userID, userID).
@@ -112,14 +112,14 @@ func (r *FollowRepositoryImpl) GetMutualFollows(ctx context.Context, userID uuid
 }
 
 func (r *FollowRepositoryImpl) UpdateFollowerCount(ctx context.Context, userID uuid.UUID, delta int) error {
-	return r.db.WithContext(ctx).
+	return dbFromContext(ctx, r.db).
 		Model(&models.User{}).
 		Where("id = ?", userID).
 		UpdateColumn("followers_count", gorm.Expr("followers_count + ?", delta)).Error
 }
 
 func (r *FollowRepositoryImpl) UpdateFollowingCount(ctx context.Context, userID uuid.UUID, delta int) error {
-	return r.db.WithContext(ctx).
+	return dbFromContext(ctx, r.db).
 		Model(&models.User{}).
 		Where("id = ?", userID).
 		UpdateColumn("following_count", gorm.Expr("following_count + ?", delta)).Error
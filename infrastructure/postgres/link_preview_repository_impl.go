@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gofiber-template/domain/models"
+	"gofiber-template/domain/repositories"
+)
+
+type LinkPreviewRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewLinkPreviewRepository(db *gorm.DB) repositories.LinkPreviewRepository {
+	return &LinkPreviewRepositoryImpl{db: db}
+}
+
+func (r *LinkPreviewRepositoryImpl) GetByURL(ctx context.Context, url string) (*models.LinkPreview, error) {
+	var preview models.LinkPreview
+	err := dbFromContext(ctx, r.db).
+		Where("url = ?", url).
+		First(&preview).Error
+	if err != nil {
+		return nil, err
+	}
+	return &preview, nil
+}
+
+func (r *LinkPreviewRepositoryImpl) Upsert(ctx context.Context, preview *models.LinkPreview) (*models.LinkPreview, error) {
+	// A refetch keeps the existing row (and ID) so posts and messages stay linked
+	err := dbFromContext(ctx, r.db).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "url"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"canonical_url", "title", "description", "site_name", "image_url",
+				"status", "fetched_at", "updated_at",
+			}),
+		}).
+		Create(preview).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByURL(ctx, preview.URL)
+}
+
+var _ repositories.LinkPreviewRepository = (*LinkPreviewRepositoryImpl)(nil)
@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gofiber-template/domain/models"
+	"gofiber-template/domain/repositories"
+)
+
+type MessageReactionRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewMessageReactionRepository(db *gorm.DB) repositories.MessageReactionRepository {
+	return &MessageReactionRepositoryImpl{db: db}
+}
+
+// notBlockedWith hides reactions from users blocked by or blocking the viewer
+func notBlockedWith(viewerID uuid.UUID) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(`NOT EXISTS (
+			SELECT 1 FROM blocks b
+			WHERE (b.blocker_id = ? AND b.blocked_id = message_reactions.user_id)
+			   OR (b.blocker_id = message_reactions.user_id AND b.blocked_id = ?)
+		)`, viewerID, viewerID)
+	}
+}
+
+func (r *MessageReactionRepositoryImpl) Add(ctx context.Context, reaction *models.MessageReaction) error {
+	return dbFromContext(ctx, r.db).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(reaction).Error
+}
+
+func (r *MessageReactionRepositoryImpl) Remove(ctx context.Context, messageID uuid.UUID, userID uuid.UUID, emoji string) error {
+	return dbFromContext(ctx, r.db).
+		Where("message_id = ? AND user_id = ? AND emoji = ?", messageID, userID, emoji).
+		Delete(&models.MessageReaction{}).Error
+}
+
+func (r *MessageReactionRepositoryImpl) ListByMessage(ctx context.Context, messageID uuid.UUID, viewerID uuid.UUID, emoji *string, offset, limit int) ([]*models.MessageReaction, error) {
+	query := dbFromContext(ctx, r.db).
+		Preload("User").
+		Scopes(notBlockedWith(viewerID)).
+		Where("message_id = ?", messageID).
+		Order("created_at ASC")
+
+	if emoji != nil && *emoji != "" {
+		query = query.Where("emoji = ?", *emoji)
+	}
+
+	var reactions []*models.MessageReaction
+	err := query.Offset(offset).Limit(limit).Find(&reactions).Error
+	return reactions, err
+}
+
+func (r *MessageReactionRepositoryImpl) CountByMessage(ctx context.Context, messageID uuid.UUID, viewerID uuid.UUID, emoji *string) (int64, error) {
+	query := dbFromContext(ctx, r.db).
+		Model(&models.MessageReaction{}).
+		Scopes(notBlockedWith(viewerID)).
+		Where("message_id = ?", messageID)
+
+	if emoji != nil && *emoji != "" {
+		query = query.Where("emoji = ?", *emoji)
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	return count, err
+}
+
+func (r *MessageReactionRepositoryImpl) SummarizeByMessages(ctx context.Context, messageIDs []uuid.UUID, viewerID uuid.UUID) ([]*models.MessageReactionCount, error) {
+	if len(messageIDs) == 0 {
+		return []*models.MessageReactionCount{}, nil
+	}
+
+	var counts []*models.MessageReactionCount
+	err := dbFromContext(ctx, r.db).
+		Model(&models.MessageReaction{}).
+		Scopes(notBlockedWith(viewerID)).
+		Select("message_id, emoji, COUNT(*) AS count, BOOL_OR(user_id = ?) AS reacted_by_me", viewerID).
+		Where("message_id IN ?", messageIDs).
+		Group("message_id, emoji").
+		Order("MIN(created_at) ASC").
+		Scan(&counts).Error
+	return counts, err
+}
+
+func (r *MessageReactionRepositoryImpl) CountByUser(ctx context.Context, messageID uuid.UUID, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := dbFromContext(ctx, r.db).
+		Model(&models.MessageReaction{}).
+		Where("message_id = ? AND user_id = ?", messageID, userID).
+		Count(&count).Error
+	return count, err
+}
+
+// Ensure interface compliance
+var _ repositories.MessageReactionRepository = (*MessageReactionRepositoryImpl)(nil)
@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gofiber-template/domain/models"
+	"gofiber-template/domain/repositories"
+)
+
+type MessageReceiptRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewMessageReceiptRepository(db *gorm.DB) repositories.MessageReceiptRepository {
+	return &MessageReceiptRepositoryImpl{db: db}
+}
+
+func (r *MessageReceiptRepositoryImpl) MarkDelivered(ctx context.Context, userID uuid.UUID, messageIDs []uuid.UUID, deliveredAt time.Time) ([]*models.Message, error) {
+	if len(messageIDs) == 0 {
+		return nil, nil
+	}
+
+	// Only messages from others in conversations the user is still part of, sent since they joined
+	var marked []uuid.UUID
+	err := dbFromContext(ctx, r.db).Raw(`
+		INSERT INTO message_receipts (message_id, user_id, delivered_at)
+		SELECT m.id, ?, ?
+		FROM messages m
+		JOIN conversation_participants cp
+		  ON cp.conversation_id = m.conversation_id AND cp.user_id = ? AND cp.left_at IS NULL
+		WHERE m.id IN ? AND m.sender_id <> ? AND m.created_at >= cp.joined_at
+		ON CONFLICT (message_id, user_id) DO NOTHING
+		RETURNING message_id`,
+		userID, deliveredAt, userID, messageIDs, userID,
+	).Scan(&marked).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return r.loadMessages(ctx, marked)
+}
+
+func (r *MessageReceiptRepositoryImpl) MarkRead(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, after *time.Time, until time.Time, readAt time.Time) ([]*models.Message, error) {
+	query := `
+		INSERT INTO message_receipts (message_id, user_id, delivered_at, read_at)
+		SELECT m.id, ?, ?, ?
+		FROM messages m
+		WHERE m.conversation_id = ? AND m.sender_id <> ? AND m.deleted_at IS NULL AND m.created_at <= ?
+		  AND m.created_at >= (SELECT cp.joined_at FROM conversation_participants cp
+			WHERE cp.conversation_id = m.conversation_id AND cp.user_id = ?)` // History from before joining isn't theirs to read
+	args := []interface{}{userID, readAt, readAt, conversationID, userID, until, userID}
+	if after != nil {
+		query += ` AND m.created_at > ?`
+		args = append(args, *after)
+	}
+	query += `
+		ON CONFLICT (message_id, user_id) DO UPDATE SET
+			read_at = EXCLUDED.read_at,
+			delivered_at = COALESCE(message_receipts.delivered_at, EXCLUDED.delivered_at)
+		WHERE message_receipts.read_at IS NULL
+		RETURNING message_id`
+
+	var marked []uuid.UUID
+	if err := dbFromContext(ctx, r.db).Raw(query, args...).Scan(&marked).Error; err != nil {
+		return nil, err
+	}
+
+	return r.loadMessages(ctx, marked)
+}
+
+func (r *MessageReceiptRepositoryImpl) ListByMessage(ctx context.Context, messageID uuid.UUID) ([]*models.MessageReceipt, error) {
+	var receipts []*models.MessageReceipt
+	err := dbFromContext(ctx, r.db).
+		Preload("User").
+		Where("message_id = ?", messageID).
+		Order("read_at DESC NULLS LAST, delivered_at DESC").
+		Find(&receipts).Error
+	return receipts, err
+}
+
+func (r *MessageReceiptRepositoryImpl) SummarizeForSender(ctx context.Context, messageIDs []uuid.UUID, senderID uuid.UUID) ([]*models.MessageReceiptSummary, error) {
+	var summaries []*models.MessageReceiptSummary
+	if len(messageIDs) == 0 {
+		return summaries, nil
+	}
+
+	err := dbFromContext(ctx, r.db).Raw(`
+		SELECT m.id AS message_id,
+			(SELECT COUNT(*) FROM conversation_participants cp
+			 WHERE cp.conversation_id = m.conversation_id AND cp.user_id <> m.sender_id AND cp.left_at IS NULL) AS recipients,
+			COUNT(mr.user_id) FILTER (WHERE mr.delivered_at IS NOT NULL) AS delivered,
+			COUNT(mr.user_id) FILTER (WHERE mr.read_at IS NOT NULL AND COALESCE(cs.read_receipts, TRUE)) AS read
+		FROM messages m
+		LEFT JOIN message_receipts mr ON mr.message_id = m.id
+		LEFT JOIN chat_settings cs ON cs.user_id = mr.user_id
+		WHERE m.id IN ? AND m.sender_id = ?
+		GROUP BY m.id`,
+		messageIDs, senderID,
+	).Scan(&summaries).Error
+	return summaries, err
+}
+
+// loadMessages fetches the fields callers need to route receipt updates
+func (r *MessageReceiptRepositoryImpl) loadMessages(ctx context.Context, ids []uuid.UUID) ([]*models.Message, error) {
+	var messages []*models.Message
+	if len(ids) == 0 {
+		return messages, nil
+	}
+
+	err := dbFromContext(ctx, r.db).
+		Select("id", "conversation_id", "sender_id", "created_at").
+		Where("id IN ?", ids).
+		Order("created_at ASC").
+		Find(&messages).Error
+	return messages, err
+}
+
+var _ repositories.MessageReceiptRepository = (*MessageReceiptRepositoryImpl)(nil)
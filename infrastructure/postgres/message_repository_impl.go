@@ -2,10 +2,12 @@ package postgres
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gofiber-template/domain/models"
 	"gofiber-template/domain/repositories"
 )
@@ -19,14 +21,13 @@ func NewMessageRepository(db *gorm.DB) repositories.MessageRepository {
 }
 
 func (r *MessageRepositoryImpl) Create(ctx context.Context, message *models.Message) error {
-	return r.db.WithContext(ctx).Create(message).Error
+	return dbFromContext(ctx, r.db).Create(message).Error
 }
 
 func (r *MessageRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*models.Message, error) {
 	var message models.Message
-	err := r.db.WithContext(ctx).
-		Preload("Sender").
-		Preload("Receiver").
+	err := dbFromContext(ctx, r.db).
+		Scopes(withMessageRelations).
 		First(&message, "id = ?", id).Error
 	if err != nil {
 		return nil, err
@@ -34,21 +35,93 @@ func (r *MessageRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*mod
 	return &message, nil
 }
 
+func (r *MessageRepositoryImpl) GetVisibleByID(ctx context.Context, id uuid.UUID, viewerID uuid.UUID) (*models.Message, error) {
+	var message models.Message
+	err := dbFromContext(ctx, r.db).
+		Scopes(withMessageRelations).
+		Scopes(visibleTo(viewerID)).
+		First(&message, "messages.id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
 func (r *MessageRepositoryImpl) Update(ctx context.Context, id uuid.UUID, message *models.Message) error {
-	return r.db.WithContext(ctx).
+	return dbFromContext(ctx, r.db).
 		Model(&models.Message{}).
 		Where("id = ?", id).
 		Updates(message).Error
 }
 
 func (r *MessageRepositoryImpl) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&models.Message{}, "id = ?", id).Error
+	return dbFromContext(ctx, r.db).Delete(&models.Message{}, "id = ?", id).Error
+}
+
+func (r *MessageRepositoryImpl) UpdateContent(ctx context.Context, id uuid.UUID, content string, editedAt time.Time) error {
+	return dbFromContext(ctx, r.db).
+		Model(&models.Message{}).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"content":   content,
+			"edited_at": editedAt,
+		}).Error
 }
 
-func (r *MessageRepositoryImpl) ListByConversation(ctx context.Context, conversationID uuid.UUID, beforeCursor *time.Time, limit int) ([]*models.Message, error) {
-	query := r.db.WithContext(ctx).
+func (r *MessageRepositoryImpl) MarkDeleted(ctx context.Context, id uuid.UUID, deletedAt time.Time) error {
+	return dbFromContext(ctx, r.db).
+		Model(&models.Message{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"content":         nil,
+			"media":           nil,
+			"link_preview_id": nil,
+			"deleted_at":      deletedAt,
+		}).Error
+}
+
+func (r *MessageRepositoryImpl) SetLinkPreview(ctx context.Context, id uuid.UUID, previewID *uuid.UUID) error {
+	return dbFromContext(ctx, r.db).
+		Model(&models.Message{}).
+		Where("id = ?", id).
+		UpdateColumn("link_preview_id", previewID).Error
+}
+
+func (r *MessageRepositoryImpl) HideForUser(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	return dbFromContext(ctx, r.db).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.MessageHidden{MessageID: id, UserID: userID}).Error
+}
+
+// withMessageRelations preloads sender, receiver, the quoted message and the link preview
+func withMessageRelations(db *gorm.DB) *gorm.DB {
+	return db.
 		Preload("Sender").
 		Preload("Receiver").
+		Preload("ReplyTo").
+		Preload("ReplyTo.Sender").
+		Preload("LinkPreview")
+}
+
+// visibleTo excludes messages the viewer deleted for themselves, one by one or by deleting the
+// conversation, and messages outside their current membership: group members only see history
+// from when they (last) joined, and members who left stop seeing new messages
+func visibleTo(viewerID uuid.UUID) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.
+			Where("NOT EXISTS (SELECT 1 FROM message_hidden mh WHERE mh.message_id = messages.id AND mh.user_id = ?)", viewerID).
+			Where(`NOT EXISTS (SELECT 1 FROM conversation_participants cpv
+				WHERE cpv.conversation_id = messages.conversation_id AND cpv.user_id = ?
+				AND (messages.created_at < cpv.joined_at
+					OR (cpv.left_at IS NOT NULL AND messages.created_at > cpv.left_at)
+					OR (cpv.cleared_at IS NOT NULL AND messages.created_at <= cpv.cleared_at)))`, viewerID)
+	}
+}
+
+func (r *MessageRepositoryImpl) ListByConversation(ctx context.Context, conversationID uuid.UUID, viewerID uuid.UUID, beforeCursor *time.Time, limit int) ([]*models.Message, error) {
+	query := dbFromContext(ctx, r.db).
+		Scopes(withMessageRelations).
+		Scopes(visibleTo(viewerID)).
 		Where("conversation_id = ?", conversationID).
 		Order("created_at DESC") // Most recent first
 
@@ -62,11 +135,11 @@ func (r *MessageRepositoryImpl) ListByConversation(ctx context.Context, conversa
 	return messages, err
 }
 
-func (r *MessageRepositoryImpl) GetMessagesBeforeTimestamp(ctx context.Context, conversationID uuid.UUID, timestamp time.Time, limit int) ([]*models.Message, error) {
+func (r *MessageRepositoryImpl) GetMessagesBeforeTimestamp(ctx context.Context, conversationID uuid.UUID, viewerID uuid.UUID, timestamp time.Time, limit int) ([]*models.Message, error) {
 	var messages []*models.Message
-	err := r.db.WithContext(ctx).
-		Preload("Sender").
-		Preload("Receiver").
+	err := dbFromContext(ctx, r.db).
+		Scopes(withMessageRelations).
+		Scopes(visibleTo(viewerID)).
 		Where("conversation_id = ? AND created_at < ?", conversationID, timestamp).
 		Order("created_at DESC"). // Most recent first
 		Limit(limit).
@@ -74,11 +147,11 @@ func (r *MessageRepositoryImpl) GetMessagesBeforeTimestamp(ctx context.Context,
 	return messages, err
 }
 
-func (r *MessageRepositoryImpl) GetMessagesAfterTimestamp(ctx context.Context, conversationID uuid.UUID, timestamp time.Time, limit int) ([]*models.Message, error) {
+func (r *MessageRepositoryImpl) GetMessagesAfterTimestamp(ctx context.Context, conversationID uuid.UUID, viewerID uuid.UUID, timestamp time.Time, limit int) ([]*models.Message, error) {
 	var messages []*models.Message
-	err := r.db.WithContext(ctx).
-		Preload("Sender").
-		Preload("Receiver").
+	err := dbFromContext(ctx, r.db).
+		Scopes(withMessageRelations).
+		Scopes(visibleTo(viewerID)).
 		Where("conversation_id = ? AND created_at > ?", conversationID, timestamp).
 		Order("created_at ASC"). // Oldest first (to get next messages)
 		Limit(limit).
@@ -88,7 +161,7 @@ func (r *MessageRepositoryImpl) GetMessagesAfterTimestamp(ctx context.Context, c
 
 func (r *MessageRepositoryImpl) MarkAsRead(ctx context.Context, messageID uuid.UUID) error {
 	now := time.Now()
-	return r.db.WithContext(ctx).
+	return dbFromContext(ctx, r.db).
 		Model(&models.Message{}).
 		Where("id = ?", messageID).
 		Updates(map[string]interface{}{
@@ -97,9 +170,10 @@ func (r *MessageRepositoryImpl) MarkAsRead(ctx context.Context, messageID uuid.U
 		}).Error
 }
 
+// MarkAllAsRead only touches direct messages: group messages have no receiver_id
 func (r *MessageRepositoryImpl) MarkAllAsRead(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) error {
 	now := time.Now()
-	return r.db.WithContext(ctx).
+	return dbFromContext(ctx, r.db).
 		Model(&models.Message{}).
 		Where("conversation_id = ? AND receiver_id = ? AND is_read = ?", conversationID, userID, false).
 		Updates(map[string]interface{}{
@@ -108,15 +182,26 @@ func (r *MessageRepositoryImpl) MarkAllAsRead(ctx context.Context, conversationI
 		}).Error
 }
 
+func (r *MessageRepositoryImpl) MarkReadUntil(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, until time.Time) error {
+	now := time.Now()
+	return dbFromContext(ctx, r.db).
+		Model(&models.Message{}).
+		Where("conversation_id = ? AND receiver_id = ? AND is_read = ? AND created_at <= ?", conversationID, userID, false, until).
+		Updates(map[string]interface{}{
+			"is_read": true,
+			"read_at": now,
+		}).Error
+}
+
 func (r *MessageRepositoryImpl) Count(ctx context.Context) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&models.Message{}).Count(&count).Error
+	err := dbFromContext(ctx, r.db).Model(&models.Message{}).Count(&count).Error
 	return count, err
 }
 
 func (r *MessageRepositoryImpl) CountByConversation(ctx context.Context, conversationID uuid.UUID) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Model(&models.Message{}).
 		Where("conversation_id = ?", conversationID).
 		Count(&count).Error
@@ -125,19 +210,38 @@ func (r *MessageRepositoryImpl) CountByConversation(ctx context.Context, convers
 
 func (r *MessageRepositoryImpl) CountUnread(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Model(&models.Message{}).
 		Where("conversation_id = ? AND receiver_id = ? AND is_read = ?", conversationID, userID, false).
 		Count(&count).Error
 	return count, err
 }
 
+func (r *MessageRepositoryImpl) CountBySender(ctx context.Context, conversationID uuid.UUID, senderID uuid.UUID) (int64, error) {
+	var count int64
+	err := dbFromContext(ctx, r.db).
+		Model(&models.Message{}).
+		Where("conversation_id = ? AND sender_id = ?", conversationID, senderID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *MessageRepositoryImpl) CountFromOthersAfter(ctx context.Context, conversationID uuid.UUID, userID uuid.UUID, after time.Time) (int64, error) {
+	var count int64
+	err := dbFromContext(ctx, r.db).
+		Model(&models.Message{}).
+		Scopes(visibleTo(userID)).
+		Where("conversation_id = ? AND sender_id <> ? AND deleted_at IS NULL AND created_at > ?", conversationID, userID, after).
+		Count(&count).Error
+	return count, err
+}
+
 // Phase 2: Media/Links/Files Queries
 
-func (r *MessageRepositoryImpl) ListMediaMessages(ctx context.Context, conversationID uuid.UUID, mediaType *string, beforeCursor *time.Time, limit int) ([]*models.Message, error) {
-	query := r.db.WithContext(ctx).
-		Preload("Sender").
-		Preload("Receiver").
+func (r *MessageRepositoryImpl) ListMediaMessages(ctx context.Context, conversationID uuid.UUID, viewerID uuid.UUID, mediaType *string, beforeCursor *time.Time, limit int) ([]*models.Message, error) {
+	query := dbFromContext(ctx, r.db).
+		Scopes(withMessageRelations).
+		Scopes(visibleTo(viewerID)).
 		Where("conversation_id = ?", conversationID).
 		Where("type IN (?)", []string{"image", "video"}). // Media messages only
 		Where("media IS NOT NULL AND media != '[]'").     // Has media content
@@ -158,10 +262,10 @@ func (r *MessageRepositoryImpl) ListMediaMessages(ctx context.Context, conversat
 	return messages, err
 }
 
-func (r *MessageRepositoryImpl) ListMessagesWithLinks(ctx context.Context, conversationID uuid.UUID, beforeCursor *time.Time, limit int) ([]*models.Message, error) {
-	query := r.db.WithContext(ctx).
-		Preload("Sender").
-		Preload("Receiver").
+func (r *MessageRepositoryImpl) ListMessagesWithLinks(ctx context.Context, conversationID uuid.UUID, viewerID uuid.UUID, beforeCursor *time.Time, limit int) ([]*models.Message, error) {
+	query := dbFromContext(ctx, r.db).
+		Scopes(withMessageRelations).
+		Scopes(visibleTo(viewerID)).
 		Where("conversation_id = ?", conversationID).
 		Where("type = ?", "text").
 		Where("content IS NOT NULL").
@@ -179,10 +283,10 @@ func (r *MessageRepositoryImpl) ListMessagesWithLinks(ctx context.Context, conve
 	return messages, err
 }
 
-func (r *MessageRepositoryImpl) ListFileMessages(ctx context.Context, conversationID uuid.UUID, beforeCursor *time.Time, limit int) ([]*models.Message, error) {
-	query := r.db.WithContext(ctx).
-		Preload("Sender").
-		Preload("Receiver").
+func (r *MessageRepositoryImpl) ListFileMessages(ctx context.Context, conversationID uuid.UUID, viewerID uuid.UUID, beforeCursor *time.Time, limit int) ([]*models.Message, error) {
+	query := dbFromContext(ctx, r.db).
+		Scopes(withMessageRelations).
+		Scopes(visibleTo(viewerID)).
 		Where("conversation_id = ?", conversationID).
 		Where("type = ?", "file"). // File type messages
 		Where("media IS NOT NULL AND media != '[]'").
@@ -198,5 +302,77 @@ func (r *MessageRepositoryImpl) ListFileMessages(ctx context.Context, conversati
 	return messages, err
 }
 
+func (r *MessageRepositoryImpl) Search(ctx context.Context, viewerID uuid.UUID, query string, conversationID *uuid.UUID, mode string, offset, limit int) ([]*models.MessageSearchHit, error) {
+	// Only live messages in conversations the viewer is still part of, minus hidden
+	// messages and senders blocked by or blocking the viewer
+	base := dbFromContext(ctx, r.db).
+		Table("messages").
+		Joins("JOIN conversation_participants cp ON cp.conversation_id = messages.conversation_id AND cp.user_id = ? AND cp.left_at IS NULL", viewerID).
+		Scopes(visibleTo(viewerID)).
+		Where("messages.deleted_at IS NULL AND messages.content IS NOT NULL").
+		Where(`NOT EXISTS (
+			SELECT 1 FROM blocks b
+			WHERE (b.blocker_id = ? AND b.blocked_id = messages.sender_id)
+			   OR (b.blocker_id = messages.sender_id AND b.blocked_id = ?)
+		)`, viewerID, viewerID)
+
+	if conversationID != nil {
+		base = base.Where("messages.conversation_id = ?", *conversationID)
+	}
+
+	switch mode {
+	case models.MessageSearchModeSubstring:
+		base = base.
+			Select("messages.id AS message_id, 0 AS rank").
+			Where("messages.content ILIKE ?", "%"+escapeLike(query)+"%").
+			Order("messages.created_at DESC")
+	default:
+		// Must match the expression of idx_messages_content_fts
+		base = base.
+			Select("messages.id AS message_id, ts_rank(to_tsvector('simple', COALESCE(messages.content, '')), plainto_tsquery('simple', ?)) AS rank", query).
+			Where("to_tsvector('simple', COALESCE(messages.content, '')) @@ plainto_tsquery('simple', ?)", query).
+			Order("rank DESC").
+			Order("messages.created_at DESC")
+	}
+
+	var hits []*models.MessageSearchHit
+	if err := base.Order("messages.id").Offset(offset).Limit(limit).Scan(&hits).Error; err != nil {
+		return nil, err
+	}
+	if len(hits) == 0 {
+		return hits, nil
+	}
+
+	// Load full messages and keep the ranked order
+	ids := make([]uuid.UUID, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.MessageID
+	}
+
+	var messages []*models.Message
+	err := dbFromContext(ctx, r.db).
+		Scopes(withMessageRelations).
+		Where("id IN ?", ids).
+		Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uuid.UUID]*models.Message, len(messages))
+	for _, message := range messages {
+		byID[message.ID] = message
+	}
+	for _, hit := range hits {
+		hit.Message = byID[hit.MessageID]
+	}
+
+	return hits, nil
+}
+
+// escapeLike escapes LIKE wildcards so user input matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
 // Ensure interface compliance
 var _ repositories.MessageRepository = (*MessageRepositoryImpl)(nil)
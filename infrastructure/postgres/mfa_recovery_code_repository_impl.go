@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gofiber-template/domain/models"
+	"gofiber-template/domain/repositories"
+	"gorm.io/gorm"
+)
+
+type MFARecoveryCodeRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewMFARecoveryCodeRepository(db *gorm.DB) repositories.MFARecoveryCodeRepository {
+	return &MFARecoveryCodeRepositoryImpl{db: db}
+}
+
+func (r *MFARecoveryCodeRepositoryImpl) ReplaceForUser(ctx context.Context, userID uuid.UUID, codes []*models.MFARecoveryCode) error {
+	return dbFromContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *MFARecoveryCodeRepositoryImpl) Consume(ctx context.Context, userID uuid.UUID, codeHash string, at time.Time) (bool, error) {
+	result := dbFromContext(ctx, r.db).
+		Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", at)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *MFARecoveryCodeRepositoryImpl) CountUnused(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := dbFromContext(ctx, r.db).
+		Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *MFARecoveryCodeRepositoryImpl) DeleteByUser(ctx context.Context, userID uuid.UUID) error {
+	return dbFromContext(ctx, r.db).
+		Where("user_id = ?", userID).
+		Delete(&models.MFARecoveryCode{}).Error
+}
+
+var _ repositories.MFARecoveryCodeRepository = (*MFARecoveryCodeRepositoryImpl)(nil)
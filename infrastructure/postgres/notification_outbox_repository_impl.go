@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gofiber-template/domain/models"
+	"gofiber-template/domain/repositories"
+)
+
+type NotificationOutboxRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewNotificationOutboxRepository(db *gorm.DB) repositories.NotificationOutboxRepository {
+	return &NotificationOutboxRepositoryImpl{db: db}
+}
+
+func (r *NotificationOutboxRepositoryImpl) Create(ctx context.Context, entries []*models.NotificationOutbox) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return dbFromContext(ctx, r.db).Create(&entries).Error
+}
+
+func (r *NotificationOutboxRepositoryImpl) DeletePendingByTopic(ctx context.Context, userID uuid.UUID, channel string, topic string) error {
+	return dbFromContext(ctx, r.db).
+		Where("user_id = ? AND channel = ? AND topic = ? AND status = ?", userID, channel, topic, models.OutboxStatusPending).
+		Delete(&models.NotificationOutbox{}).Error
+}
+
+func (r *NotificationOutboxRepositoryImpl) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*models.NotificationOutbox, error) {
+	var entries []*models.NotificationOutbox
+	now := time.Now()
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// SKIP LOCKED lets several workers drain the outbox without double delivery.
+		// Entries whose lease expired (worker crashed mid-delivery) are picked up again.
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("(status = ? AND next_attempt_at <= ?) OR (status = ? AND locked_until < ?)",
+				models.OutboxStatusPending, now, models.OutboxStatusProcessing, now).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&entries).Error
+		if err != nil || len(entries) == 0 {
+			return err
+		}
+
+		leaseID := uuid.New()
+		lockedUntil := now.Add(lease)
+		ids := make([]uuid.UUID, len(entries))
+		for i, entry := range entries {
+			ids[i] = entry.ID
+			entry.Status = models.OutboxStatusProcessing
+			entry.LockedUntil = &lockedUntil
+			entry.LeaseID = &leaseID
+		}
+
+		return tx.Model(&models.NotificationOutbox{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"status":       models.OutboxStatusProcessing,
+				"locked_until": lockedUntil,
+				"lease_id":     leaseID,
+				"updated_at":   now,
+			}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *NotificationOutboxRepositoryImpl) MarkDelivered(ctx context.Context, id uuid.UUID, leaseID uuid.UUID, attempts int) error {
+	now := time.Now()
+	return r.releaseLease(ctx, id, leaseID, map[string]interface{}{
+		"status":       models.OutboxStatusDelivered,
+		"attempts":     attempts,
+		"delivered_at": now,
+		"last_error":   nil,
+		"updated_at":   now,
+	})
+}
+
+func (r *NotificationOutboxRepositoryImpl) ScheduleRetry(ctx context.Context, id uuid.UUID, leaseID uuid.UUID, attempts int, nextAttemptAt time.Time, lastError string) error {
+	return r.releaseLease(ctx, id, leaseID, map[string]interface{}{
+		"status":          models.OutboxStatusPending,
+		"attempts":        attempts,
+		"next_attempt_at": nextAttemptAt,
+		"last_error":      lastError,
+		"updated_at":      time.Now(),
+	})
+}
+
+func (r *NotificationOutboxRepositoryImpl) MarkFailed(ctx context.Context, id uuid.UUID, leaseID uuid.UUID, attempts int, lastError string) error {
+	return r.releaseLease(ctx, id, leaseID, map[string]interface{}{
+		"status":     models.OutboxStatusFailed,
+		"attempts":   attempts,
+		"last_error": lastError,
+		"updated_at": time.Now(),
+	})
+}
+
+// releaseLease records a delivery result if the entry is still held by leaseID. A
+// lease that expired mid-batch may have been claimed again; that claim wins.
+func (r *NotificationOutboxRepositoryImpl) releaseLease(ctx context.Context, id uuid.UUID, leaseID uuid.UUID, updates map[string]interface{}) error {
+	updates["locked_until"] = nil
+	updates["lease_id"] = nil
+
+	result := r.db.WithContext(ctx).
+		Model(&models.NotificationOutbox{}).
+		Where("id = ? AND status = ? AND lease_id = ?", id, models.OutboxStatusProcessing, leaseID).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repositories.ErrOutboxLeaseLost
+	}
+	return nil
+}
+
+func (r *NotificationOutboxRepositoryImpl) ListByNotification(ctx context.Context, notificationID uuid.UUID) ([]*models.NotificationOutbox, error) {
+	var entries []*models.NotificationOutbox
+	err := r.db.WithContext(ctx).
+		Where("notification_id = ?", notificationID).
+		Order("created_at ASC").
+		Find(&entries).Error
+	return entries, err
+}
+
+func (r *NotificationOutboxRepositoryImpl) DeleteDeliveredBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("status = ? AND delivered_at < ?", models.OutboxStatusDelivered, before).
+		Delete(&models.NotificationOutbox{})
+	return result.RowsAffected, result.Error
+}
+
+var _ repositories.NotificationOutboxRepository = (*NotificationOutboxRepositoryImpl)(nil)
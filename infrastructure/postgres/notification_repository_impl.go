@@ -18,12 +18,12 @@ func NewNotificationRepository(db *gorm.DB) repositories.NotificationRepository
 }
 
 func (r *NotificationRepositoryImpl) Create(ctx context.Context, notification *models.Notification) error {
-	return r.db.WithContext(ctx).Create(notification).Error
+	return dbFromContext(ctx, r.db).Create(notification).Error
 }
 
 func (r *NotificationRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*models.Notification, error) {
 	var notification models.Notification
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Preload("User").
 		Preload("Sender").
 		Preload("Post").
@@ -38,7 +38,7 @@ func (r *NotificationRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID)
 
 func (r *NotificationRepositoryImpl) ListByUser(ctx context.Context, userID uuid.UUID, offset, limit int) ([]*models.Notification, error) {
 	var notifications []*models.Notification
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Preload("User").
 		Preload("Sender").
 		Preload("Post").
@@ -52,7 +52,7 @@ func (r *NotificationRepositoryImpl) ListByUser(ctx context.Context, userID uuid
 
 func (r *NotificationRepositoryImpl) ListUnreadByUser(ctx context.Context, userID uuid.UUID, offset, limit int) ([]*models.Notification, error) {
 	var notifications []*models.Notification
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Preload("User").
 		Preload("Sender").
 		Preload("Post").
@@ -65,34 +65,34 @@ func (r *NotificationRepositoryImpl) ListUnreadByUser(ctx context.Context, userI
 }
 
 func (r *NotificationRepositoryImpl) MarkAsRead(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).
+	return dbFromContext(ctx, r.db).
 		Model(&models.Notification{}).
 		Where("id = ?", id).
 		Update("is_read", true).Error
 }
 
 func (r *NotificationRepositoryImpl) MarkAllAsRead(ctx context.Context, userID uuid.UUID) error {
-	return r.db.WithContext(ctx).
+	return dbFromContext(ctx, r.db).
 		Model(&models.Notification{}).
 		Where("user_id = ?", userID).
 		Update("is_read", true).Error
 }
 
 func (r *NotificationRepositoryImpl) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).
+	return dbFromContext(ctx, r.db).
 		Where("id = ?", id).
 		Delete(&models.Notification{}).Error
 }
 
 func (r *NotificationRepositoryImpl) DeleteAllByUser(ctx context.Context, userID uuid.UUID) error {
-	return r.db.WithContext(ctx).
+	return dbFromContext(ctx, r.db).
 		Where("user_id = ?", userID).
 		Delete(&models.Notification{}).Error
 }
 
 func (r *NotificationRepositoryImpl) CountByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Model(&models.Notification{}).
 		Where("user_id = ?", userID).
 		Count(&count).Error
@@ -101,7 +101,7 @@ func (r *NotificationRepositoryImpl) CountByUser(ctx context.Context, userID uui
 
 func (r *NotificationRepositoryImpl) CountUnreadByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Model(&models.Notification{}).
 		Where("user_id = ? AND is_read = ?", userID, false).
 		Count(&count).Error
@@ -2,9 +2,12 @@ package postgres
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gofiber-template/domain/models"
 	"gofiber-template/domain/repositories"
 )
@@ -18,12 +21,12 @@ func NewNotificationSettingsRepository(db *gorm.DB) repositories.NotificationSet
 }
 
 func (r *NotificationSettingsRepositoryImpl) Create(ctx context.Context, settings *models.NotificationSettings) error {
-	return r.db.WithContext(ctx).Create(settings).Error
+	return dbFromContext(ctx, r.db).Create(settings).Error
 }
 
 func (r *NotificationSettingsRepositoryImpl) GetByUserID(ctx context.Context, userID uuid.UUID) (*models.NotificationSettings, error) {
 	var settings models.NotificationSettings
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Where("user_id = ?", userID).
 		First(&settings).Error
 	if err != nil {
@@ -33,42 +36,33 @@ func (r *NotificationSettingsRepositoryImpl) GetByUserID(ctx context.Context, us
 }
 
 func (r *NotificationSettingsRepositoryImpl) Update(ctx context.Context, userID uuid.UUID, settings *models.NotificationSettings) error {
-	// Use map to ensure boolean false values are updated
-	return r.db.WithContext(ctx).
-		Model(&models.NotificationSettings{}).
-		Where("user_id = ?", userID).
-		Updates(map[string]interface{}{
-			"replies":             settings.Replies,
-			"mentions":            settings.Mentions,
-			"votes":               settings.Votes,
-			"follows":             settings.Follows,
-			"email_notifications": settings.EmailNotifications,
-			"updated_at":          settings.UpdatedAt,
-		}).Error
+	// Upsert with explicit columns so boolean false values are written
+	settings.UserID = userID
+	return dbFromContext(ctx, r.db).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"replies", "mentions", "votes", "follows", "email_notifications",
+				"preferences", "quiet_hours_enabled", "quiet_hours_start", "quiet_hours_end", "timezone",
+				"updated_at",
+			}),
+		}).
+		Select("*").
+		Omit("User").
+		Create(settings).Error
 }
 
-func (r *NotificationSettingsRepositoryImpl) ShouldNotify(ctx context.Context, userID uuid.UUID, notificationType string) (bool, error) {
+func (r *NotificationSettingsRepositoryImpl) ShouldNotify(ctx context.Context, userID uuid.UUID, notificationType string, now time.Time) (*models.NotificationDecision, error) {
 	settings, err := r.GetByUserID(ctx, userID)
 	if err != nil {
-		// If no settings found, use default (true for most types)
-		if err == gorm.ErrRecordNotFound {
-			return notificationType != "votes", nil // Default: all except votes
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
 		}
-		return false, err
+		// No settings saved yet: use defaults
+		settings = models.DefaultNotificationSettings(userID)
 	}
 
-	switch notificationType {
-	case "reply", "replies":
-		return settings.Replies, nil
-	case "mention", "mentions":
-		return settings.Mentions, nil
-	case "vote", "votes":
-		return settings.Votes, nil
-	case "follow", "follows":
-		return settings.Follows, nil
-	default:
-		return true, nil
-	}
+	return settings.Decide(notificationType, now), nil
 }
 
 var _ repositories.NotificationSettingsRepository = (*NotificationSettingsRepositoryImpl)(nil)
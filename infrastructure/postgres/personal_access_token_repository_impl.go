@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gofiber-template/domain/models"
+	"gofiber-template/domain/repositories"
+	"gorm.io/gorm"
+)
+
+type PersonalAccessTokenRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewPersonalAccessTokenRepository(db *gorm.DB) repositories.PersonalAccessTokenRepository {
+	return &PersonalAccessTokenRepositoryImpl{db: db}
+}
+
+func (r *PersonalAccessTokenRepositoryImpl) Create(ctx context.Context, token *models.PersonalAccessToken) error {
+	return dbFromContext(ctx, r.db).Create(token).Error
+}
+
+func (r *PersonalAccessTokenRepositoryImpl) GetByHash(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error) {
+	var token models.PersonalAccessToken
+	err := dbFromContext(ctx, r.db).
+		Where("token_hash = ?", tokenHash).
+		First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *PersonalAccessTokenRepositoryImpl) ListActiveByUser(ctx context.Context, userID uuid.UUID, now time.Time) ([]*models.PersonalAccessToken, error) {
+	var tokens []*models.PersonalAccessToken
+	err := dbFromContext(ctx, r.db).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+func (r *PersonalAccessTokenRepositoryImpl) CountActiveByUser(ctx context.Context, userID uuid.UUID, now time.Time) (int64, error) {
+	var count int64
+	err := dbFromContext(ctx, r.db).
+		Model(&models.PersonalAccessToken{}).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *PersonalAccessTokenRepositoryImpl) Touch(ctx context.Context, id uuid.UUID, ip string, at time.Time) error {
+	return dbFromContext(ctx, r.db).
+		Model(&models.PersonalAccessToken{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"last_used_at": at,
+			"last_used_ip": ip,
+		}).Error
+}
+
+func (r *PersonalAccessTokenRepositoryImpl) Revoke(ctx context.Context, userID uuid.UUID, id uuid.UUID, at time.Time) (bool, error) {
+	result := dbFromContext(ctx, r.db).
+		Model(&models.PersonalAccessToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?", id, userID, at).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *PersonalAccessTokenRepositoryImpl) DeleteInactive(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := dbFromContext(ctx, r.db).
+		Where("expires_at < ? OR revoked_at < ?", cutoff, cutoff).
+		Delete(&models.PersonalAccessToken{})
+	return result.RowsAffected, result.Error
+}
+
+var _ repositories.PersonalAccessTokenRepository = (*PersonalAccessTokenRepositoryImpl)(nil)
@@ -22,15 +22,16 @@ func NewPostRepository(db *gorm.DB) repositories.PostRepository {
 }
 
 func (r *PostRepositoryImpl) Create(ctx context.Context, post *models.Post) error {
-	return r.db.WithContext(ctx).Create(post).Error
+	return dbFromContext(ctx, r.db).Create(post).Error
 }
 
 func (r *PostRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*models.Post, error) {
 	var post models.Post
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Preload("Author").
 		Preload("Media").
 		Preload("Tags").
+		Preload("LinkPreview").
 		Preload("SourcePost").
 		Preload("SourcePost.Author").
 		Preload("SourcePost.Media").
@@ -44,12 +45,19 @@ func (r *PostRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*models
 }
 
 func (r *PostRepositoryImpl) Update(ctx context.Context, id uuid.UUID, post *models.Post) error {
-	return r.db.WithContext(ctx).Where("id = ?", id).Updates(post).Error
+	return dbFromContext(ctx, r.db).Where("id = ?", id).Updates(post).Error
+}
+
+func (r *PostRepositoryImpl) SetLinkPreview(ctx context.Context, id uuid.UUID, previewID *uuid.UUID) error {
+	return dbFromContext(ctx, r.db).
+		Model(&models.Post{}).
+		Where("id = ?", id).
+		UpdateColumn("link_preview_id", previewID).Error
 }
 
 func (r *PostRepositoryImpl) Delete(ctx context.Context, id uuid.UUID) error {
 	now := time.Now()
-	return r.db.WithContext(ctx).
+	return dbFromContext(ctx, r.db).
 		Model(&models.Post{}).
 		Where("id = ?", id).
 		Updates(map[string]interface{}{
@@ -60,10 +68,11 @@ func (r *PostRepositoryImpl) Delete(ctx context.Context, id uuid.UUID) error {
 
 func (r *PostRepositoryImpl) List(ctx context.Context, offset, limit int, sortBy repositories.PostSortBy) ([]*models.Post, error) {
 	var posts []*models.Post
-	query := r.db.WithContext(ctx).
+	query := dbFromContext(ctx, r.db).
 		Preload("Author").
 		Preload("Media").
 		Preload("Tags").
+		Preload("LinkPreview").
 		Preload("SourcePost").
 		Preload("SourcePost.Author").
 		Preload("SourcePost.Media").
@@ -91,10 +100,11 @@ func (r *PostRepositoryImpl) List(ctx context.Context, offset, limit int, sortBy
 
 func (r *PostRepositoryImpl) ListByAuthor(ctx context.Context, authorID uuid.UUID, offset, limit int) ([]*models.Post, error) {
 	var posts []*models.Post
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Preload("Author").
 		Preload("Media").
 		Preload("Tags").
+		Preload("LinkPreview").
 		Preload("SourcePost").
 		Preload("SourcePost.Author").
 		Preload("SourcePost.Media").
@@ -112,10 +122,11 @@ func (r *PostRepositoryImpl) ListByTag(ctx context.Context, tagName string, offs
 	// Debug logging
 	log.Printf("🔍 Repository searching for tag: '%s'", tagName)
 
-	query := r.db.WithContext(ctx).
+	query := dbFromContext(ctx, r.db).
 		Preload("Author").
 		Preload("Media").
 		Preload("Tags").
+		Preload("LinkPreview").
 		Preload("SourcePost").
 		Preload("SourcePost.Author").
 		Preload("SourcePost.Media").
@@ -141,10 +152,11 @@ func (r *PostRepositoryImpl) ListByTag(ctx context.Context, tagName string, offs
 
 func (r *PostRepositoryImpl) ListByTagID(ctx context.Context, tagID uuid.UUID, offset, limit int, sortBy repositories.PostSortBy) ([]*models.Post, error) {
 	var posts []*models.Post
-	query := r.db.WithContext(ctx).
+	query := dbFromContext(ctx, r.db).
 		Preload("Author").
 		Preload("Media").
 		Preload("Tags").
+		Preload("LinkPreview").
 		Preload("SourcePost").
 		Preload("SourcePost.Author").
 		Preload("SourcePost.Media").
@@ -171,10 +183,11 @@ func (r *PostRepositoryImpl) Search(ctx context.Context, query string, offset, l
 	var posts []*models.Post
 	searchQuery := "%" + query + "%"
 
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Preload("Author").
 		Preload("Media").
 		Preload("Tags").
+		Preload("LinkPreview").
 		Preload("SourcePost").
 		Preload("SourcePost.Author").
 		Preload("SourcePost.Media").
@@ -197,10 +210,11 @@ func (r *PostRepositoryImpl) Search(ctx context.Context, query string, offset, l
 
 func (r *PostRepositoryImpl) GetCrossposts(ctx context.Context, postID uuid.UUID, offset, limit int) ([]*models.Post, error) {
 	var posts []*models.Post
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Preload("Author").
 		Preload("Media").
 		Preload("Tags").
+		Preload("LinkPreview").
 		Where("source_post_id = ? AND is_deleted = ?", postID, false).
 		Order("created_at DESC").
 		Offset(offset).Limit(limit).
@@ -210,35 +224,44 @@ func (r *PostRepositoryImpl) GetCrossposts(ctx context.Context, postID uuid.UUID
 
 func (r *PostRepositoryImpl) Count(ctx context.Context) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&models.Post{}).Where("is_deleted = ?", false).Count(&count).Error
+	err := dbFromContext(ctx, r.db).Model(&models.Post{}).Where("is_deleted = ?", false).Count(&count).Error
 	return count, err
 }
 
 func (r *PostRepositoryImpl) CountByAuthor(ctx context.Context, authorID uuid.UUID) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Model(&models.Post{}).
 		Where("author_id = ? AND is_deleted = ?", authorID, false).
 		Count(&count).Error
 	return count, err
 }
 
+func (r *PostRepositoryImpl) CountByAuthorSince(ctx context.Context, authorID uuid.UUID, since time.Time) (int64, error) {
+	var count int64
+	err := dbFromContext(ctx, r.db).
+		Model(&models.Post{}).
+		Where("author_id = ? AND created_at >= ?", authorID, since).
+		Count(&count).Error
+	return count, err
+}
+
 func (r *PostRepositoryImpl) IncrementCommentCount(ctx context.Context, postID uuid.UUID) error {
-	return r.db.WithContext(ctx).
+	return dbFromContext(ctx, r.db).
 		Model(&models.Post{}).
 		Where("id = ?", postID).
 		UpdateColumn("comment_count", gorm.Expr("comment_count + ?", 1)).Error
 }
 
 func (r *PostRepositoryImpl) DecrementCommentCount(ctx context.Context, postID uuid.UUID) error {
-	return r.db.WithContext(ctx).
+	return dbFromContext(ctx, r.db).
 		Model(&models.Post{}).
 		Where("id = ?", postID).
 		UpdateColumn("comment_count", gorm.Expr("comment_count - ?", 1)).Error
 }
 
 func (r *PostRepositoryImpl) UpdateVoteCount(ctx context.Context, postID uuid.UUID, voteChange int) error {
-	return r.db.WithContext(ctx).
+	return dbFromContext(ctx, r.db).
 		Model(&models.Post{}).
 		Where("id = ?", postID).
 		UpdateColumn("votes", gorm.Expr("votes + ?", voteChange)).Error
@@ -250,7 +273,7 @@ func (r *PostRepositoryImpl) AttachMedia(ctx context.Context, postID uuid.UUID,
 	for _, mediaID := range mediaIDs {
 		mediaList = append(mediaList, models.Media{ID: mediaID})
 	}
-	return r.db.WithContext(ctx).Model(post).Association("Media").Append(mediaList)
+	return dbFromContext(ctx, r.db).Model(post).Association("Media").Append(mediaList)
 }
 
 func (r *PostRepositoryImpl) DetachMedia(ctx context.Context, postID uuid.UUID, mediaIDs []uuid.UUID) error {
@@ -259,7 +282,7 @@ func (r *PostRepositoryImpl) DetachMedia(ctx context.Context, postID uuid.UUID,
 	for _, mediaID := range mediaIDs {
 		mediaList = append(mediaList, models.Media{ID: mediaID})
 	}
-	return r.db.WithContext(ctx).Model(post).Association("Media").Delete(mediaList)
+	return dbFromContext(ctx, r.db).Model(post).Association("Media").Delete(mediaList)
 }
 
 func (r *PostRepositoryImpl) AttachTags(ctx context.Context, postID uuid.UUID, tagIDs []uuid.UUID) error {
@@ -268,7 +291,7 @@ func (r *PostRepositoryImpl) AttachTags(ctx context.Context, postID uuid.UUID, t
 	for _, tagID := range tagIDs {
 		tagList = append(tagList, models.Tag{ID: tagID})
 	}
-	return r.db.WithContext(ctx).Model(post).Association("Tags").Append(tagList)
+	return dbFromContext(ctx, r.db).Model(post).Association("Tags").Append(tagList)
 }
 
 func (r *PostRepositoryImpl) DetachTags(ctx context.Context, postID uuid.UUID, tagIDs []uuid.UUID) error {
@@ -277,7 +300,7 @@ func (r *PostRepositoryImpl) DetachTags(ctx context.Context, postID uuid.UUID, t
 	for _, tagID := range tagIDs {
 		tagList = append(tagList, models.Tag{ID: tagID})
 	}
-	return r.db.WithContext(ctx).Model(post).Association("Tags").Delete(tagList)
+	return dbFromContext(ctx, r.db).Model(post).Association("Tags").Delete(tagList)
 }
 
 func (r *PostRepositoryImpl) SyncTags(ctx context.Context, postID uuid.UUID, tagIDs []uuid.UUID) error {
@@ -286,7 +309,7 @@ func (r *PostRepositoryImpl) SyncTags(ctx context.Context, postID uuid.UUID, tag
 	for _, tagID := range tagIDs {
 		tagList = append(tagList, models.Tag{ID: tagID})
 	}
-	return r.db.WithContext(ctx).Model(post).Association("Tags").Replace(tagList)
+	return dbFromContext(ctx, r.db).Model(post).Association("Tags").Replace(tagList)
 }
 
 // hotScoreSQL generates SQL for hot score calculation: votes / (hours + 2)^1.5
@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gofiber-template/domain/models"
+	"gofiber-template/domain/repositories"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SessionRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) repositories.SessionRepository {
+	return &SessionRepositoryImpl{db: db}
+}
+
+func (r *SessionRepositoryImpl) Create(ctx context.Context, session *models.Session) error {
+	return dbFromContext(ctx, r.db).Create(session).Error
+}
+
+func (r *SessionRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*models.Session, error) {
+	var session models.Session
+	err := dbFromContext(ctx, r.db).
+		Where("id = ?", id).
+		First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *SessionRepositoryImpl) ListActiveByUser(ctx context.Context, userID uuid.UUID, now time.Time) ([]*models.Session, error) {
+	var sessions []*models.Session
+	err := dbFromContext(ctx, r.db).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_active_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *SessionRepositoryImpl) Touch(ctx context.Context, id uuid.UUID, userAgent string, ipAddress string, at time.Time, expiresAt time.Time) error {
+	return dbFromContext(ctx, r.db).
+		Model(&models.Session{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"user_agent":     userAgent,
+			"ip_address":     ipAddress,
+			"last_active_at": at,
+			"expires_at":     expiresAt,
+		}).Error
+}
+
+func (r *SessionRepositoryImpl) MarkMFAVerified(ctx context.Context, id uuid.UUID) error {
+	return dbFromContext(ctx, r.db).
+		Model(&models.Session{}).
+		Where("id = ?", id).
+		Update("mfa_verified", true).Error
+}
+
+func (r *SessionRepositoryImpl) MarkReauthenticated(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return dbFromContext(ctx, r.db).
+		Model(&models.Session{}).
+		Where("id = ?", id).
+		Update("reauthenticated_at", at).Error
+}
+
+func (r *SessionRepositoryImpl) Revoke(ctx context.Context, id uuid.UUID, reason string, at time.Time) (bool, error) {
+	result := dbFromContext(ctx, r.db).
+		Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"revoked_at":    at,
+			"revoke_reason": reason,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *SessionRepositoryImpl) RevokeAllByUser(ctx context.Context, userID uuid.UUID, exceptID uuid.UUID, reason string, at time.Time) ([]uuid.UUID, error) {
+	var revoked []models.Session
+	err := dbFromContext(ctx, r.db).
+		Model(&revoked).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL AND expires_at > ?", userID, exceptID, at).
+		Updates(map[string]interface{}{
+			"revoked_at":    at,
+			"revoke_reason": reason,
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(revoked))
+	for i, session := range revoked {
+		ids[i] = session.ID
+	}
+	return ids, nil
+}
+
+func (r *SessionRepositoryImpl) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := dbFromContext(ctx, r.db).
+		Where("expires_at < ?", before).
+		Delete(&models.Session{})
+	return result.RowsAffected, result.Error
+}
+
+var _ repositories.SessionRepository = (*SessionRepositoryImpl)(nil)
@@ -156,4 +156,30 @@ func (r *TagRepositoryImpl) Delete(ctx context.Context, id uuid.UUID) error {
 		Delete(&models.Tag{}).Error
 }
 
+func (r *TagRepositoryImpl) Merge(ctx context.Context, sourceID, targetID uuid.UUID) error {
+	return dbFromContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// Posts tagged with both keep a single row
+		moved := tx.Exec(`
+			INSERT INTO post_tags (post_id, tag_id)
+			SELECT post_id, ? FROM post_tags WHERE tag_id = ?
+			ON CONFLICT DO NOTHING`, targetID, sourceID)
+		if moved.Error != nil {
+			return moved.Error
+		}
+
+		if err := tx.Exec("DELETE FROM post_tags WHERE tag_id = ?", sourceID).Error; err != nil {
+			return err
+		}
+
+		err := tx.Model(&models.Tag{}).
+			Where("id = ?", targetID).
+			UpdateColumn("post_count", gorm.Expr("post_count + ?", moved.RowsAffected)).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("id = ?", sourceID).Delete(&models.Tag{}).Error
+	})
+}
+
 var _ repositories.TagRepository = (*TagRepositoryImpl)(nil)
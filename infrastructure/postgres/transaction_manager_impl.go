@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gofiber-template/domain/repositories"
+)
+
+type txContextKey struct{}
+
+// afterCommitKey holds the hooks registered with AfterCommit during a transaction
+type afterCommitKey struct{}
+
+type TransactionManagerImpl struct {
+	db *gorm.DB
+}
+
+func NewTransactionManager(db *gorm.DB) repositories.TransactionManager {
+	return &TransactionManagerImpl{db: db}
+}
+
+func (m *TransactionManagerImpl) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	// Nested calls reuse the outer transaction
+	if _, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+
+	var afterCommit []func()
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		txCtx := context.WithValue(ctx, txContextKey{}, tx)
+		return fn(context.WithValue(txCtx, afterCommitKey{}, &afterCommit))
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range afterCommit {
+		hook()
+	}
+	return nil
+}
+
+func (m *TransactionManagerImpl) AfterCommit(ctx context.Context, fn func()) {
+	if hooks, ok := ctx.Value(afterCommitKey{}).(*[]func()); ok {
+		*hooks = append(*hooks, fn)
+		return
+	}
+	fn()
+}
+
+func (m *TransactionManagerImpl) WithoutTransaction(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(txContextKey{}).(*gorm.DB); !ok {
+		return ctx
+	}
+	return context.WithValue(context.WithValue(ctx, txContextKey{}, nil), afterCommitKey{}, nil)
+}
+
+// dbFromContext returns the transaction carried by ctx, or db when there is none
+func dbFromContext(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+var _ repositories.TransactionManager = (*TransactionManagerImpl)(nil)
@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gofiber-template/domain/models"
+	"gofiber-template/domain/repositories"
+	"gorm.io/gorm"
+)
+
+type UserIdentityRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewUserIdentityRepository(db *gorm.DB) repositories.UserIdentityRepository {
+	return &UserIdentityRepositoryImpl{db: db}
+}
+
+func (r *UserIdentityRepositoryImpl) Create(ctx context.Context, identity *models.UserIdentity) error {
+	return dbFromContext(ctx, r.db).Create(identity).Error
+}
+
+func (r *UserIdentityRepositoryImpl) GetByProvider(ctx context.Context, provider string, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := dbFromContext(ctx, r.db).
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *UserIdentityRepositoryImpl) ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.UserIdentity, error) {
+	var identities []*models.UserIdentity
+	err := dbFromContext(ctx, r.db).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&identities).Error
+	return identities, err
+}
+
+func (r *UserIdentityRepositoryImpl) CountByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := dbFromContext(ctx, r.db).
+		Model(&models.UserIdentity{}).
+		Where("user_id = ?", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *UserIdentityRepositoryImpl) Touch(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return dbFromContext(ctx, r.db).
+		Model(&models.UserIdentity{}).
+		Where("id = ?", id).
+		Update("last_used_at", at).Error
+}
+
+func (r *UserIdentityRepositoryImpl) Delete(ctx context.Context, userID uuid.UUID, provider string) (bool, error) {
+	result := dbFromContext(ctx, r.db).
+		Where("user_id = ? AND provider = ?", userID, provider).
+		Delete(&models.UserIdentity{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+var _ repositories.UserIdentityRepository = (*UserIdentityRepositoryImpl)(nil)
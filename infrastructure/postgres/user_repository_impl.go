@@ -2,8 +2,10 @@ package postgres
 
 import (
 	"context"
+	"time"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gofiber-template/domain/models"
 	"gofiber-template/domain/repositories"
 )
@@ -17,7 +19,7 @@ func NewUserRepository(db *gorm.DB) repositories.UserRepository {
 }
 
 func (r *UserRepositoryImpl) Create(ctx context.Context, user *models.User) error {
-	return r.db.WithContext(ctx).Create(user).Error
+	return dbFromContext(ctx, r.db).Create(user).Error
 }
 
 func (r *UserRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
@@ -47,10 +49,11 @@ func (r *UserRepositoryImpl) GetByUsername(ctx context.Context, username string)
 	return &user, nil
 }
 
-func (r *UserRepositoryImpl) GetByOAuth(ctx context.Context, provider, oauthID string) (*models.User, error) {
+func (r *UserRepositoryImpl) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	var user models.User
-	err := r.db.WithContext(ctx).
-		Where("oauth_provider = ? AND oauth_id = ?", provider, oauthID).
+	err := dbFromContext(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
 		First(&user).Error
 	if err != nil {
 		return nil, err
@@ -62,6 +65,64 @@ func (r *UserRepositoryImpl) Update(ctx context.Context, id uuid.UUID, user *mod
 	return r.db.WithContext(ctx).Where("id = ?", id).Updates(user).Error
 }
 
+func (r *UserRepositoryImpl) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	// Map update so an empty hash isn't skipped as a zero value
+	return dbFromContext(ctx, r.db).
+		Model(&models.User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"password":   passwordHash,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+func (r *UserRepositoryImpl) UpdateTOTP(ctx context.Context, id uuid.UUID, secret string, enabledAt *time.Time) error {
+	// Map update so clearing the secret and disabling aren't skipped as zero values
+	return dbFromContext(ctx, r.db).
+		Model(&models.User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"totp_secret":     secret,
+			"totp_enabled":    enabledAt != nil,
+			"totp_enabled_at": enabledAt,
+			"updated_at":      time.Now(),
+		}).Error
+}
+
+func (r *UserRepositoryImpl) ConfirmEmail(ctx context.Context, id uuid.UUID, verifiedAt time.Time) error {
+	// Map update so clearing the grandfathered flag isn't skipped as a zero value
+	return dbFromContext(ctx, r.db).
+		Model(&models.User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"email_verified":      true,
+			"email_verified_at":   verifiedAt,
+			"email_grandfathered": false,
+			"updated_at":          verifiedAt,
+		}).Error
+}
+
+func (r *UserRepositoryImpl) UpdateRole(ctx context.Context, id uuid.UUID, role string) error {
+	return dbFromContext(ctx, r.db).
+		Model(&models.User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"role":       role,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+func (r *UserRepositoryImpl) SetActive(ctx context.Context, id uuid.UUID, active bool) error {
+	// Map update so deactivating isn't skipped as a zero value
+	return dbFromContext(ctx, r.db).
+		Model(&models.User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"is_active":  active,
+			"updated_at": time.Now(),
+		}).Error
+}
+
 func (r *UserRepositoryImpl) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.User{}).Error
 }
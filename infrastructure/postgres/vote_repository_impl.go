@@ -20,7 +20,7 @@ func NewVoteRepository(db *gorm.DB) repositories.VoteRepository {
 
 func (r *VoteRepositoryImpl) Vote(ctx context.Context, vote *models.Vote) error {
 	// Upsert: Insert or update if exists
-	return r.db.WithContext(ctx).
+	return dbFromContext(ctx, r.db).
 		Clauses(clause.OnConflict{
 			Columns:   []clause.Column{{Name: "user_id"}, {Name: "target_id"}, {Name: "target_type"}},
 			DoUpdates: clause.AssignmentColumns([]string{"vote_type"}),
@@ -29,14 +29,14 @@ func (r *VoteRepositoryImpl) Vote(ctx context.Context, vote *models.Vote) error
 }
 
 func (r *VoteRepositoryImpl) Unvote(ctx context.Context, userID uuid.UUID, targetID uuid.UUID, targetType string) error {
-	return r.db.WithContext(ctx).
+	return dbFromContext(ctx, r.db).
 		Where("user_id = ? AND target_id = ? AND target_type = ?", userID, targetID, targetType).
 		Delete(&models.Vote{}).Error
 }
 
 func (r *VoteRepositoryImpl) GetVote(ctx context.Context, userID uuid.UUID, targetID uuid.UUID, targetType string) (*models.Vote, error) {
 	var vote models.Vote
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Where("user_id = ? AND target_id = ? AND target_type = ?", userID, targetID, targetType).
 		First(&vote).Error
 	if err != nil {
@@ -50,7 +50,7 @@ func (r *VoteRepositoryImpl) GetVote(ctx context.Context, userID uuid.UUID, targ
 
 func (r *VoteRepositoryImpl) HasVoted(ctx context.Context, userID uuid.UUID, targetID uuid.UUID, targetType string) (bool, error) {
 	var count int64
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Model(&models.Vote{}).
 		Where("user_id = ? AND target_id = ? AND target_type = ?", userID, targetID, targetType).
 		Count(&count).Error
@@ -59,7 +59,7 @@ func (r *VoteRepositoryImpl) HasVoted(ctx context.Context, userID uuid.UUID, tar
 
 func (r *VoteRepositoryImpl) GetVoteCount(ctx context.Context, targetID uuid.UUID, targetType string) (upvotes int64, downvotes int64, err error) {
 	// Count upvotes
-	err = r.db.WithContext(ctx).
+	err = dbFromContext(ctx, r.db).
 		Model(&models.Vote{}).
 		Where("target_id = ? AND target_type = ? AND vote_type = ?", targetID, targetType, "up").
 		Count(&upvotes).Error
@@ -68,7 +68,7 @@ func (r *VoteRepositoryImpl) GetVoteCount(ctx context.Context, targetID uuid.UUI
 	}
 
 	// Count downvotes
-	err = r.db.WithContext(ctx).
+	err = dbFromContext(ctx, r.db).
 		Model(&models.Vote{}).
 		Where("target_id = ? AND target_type = ? AND vote_type = ?", targetID, targetType, "down").
 		Count(&downvotes).Error
@@ -81,7 +81,7 @@ func (r *VoteRepositoryImpl) GetVoteCount(ctx context.Context, targetID uuid.UUI
 
 func (r *VoteRepositoryImpl) ListByUser(ctx context.Context, userID uuid.UUID, targetType string, offset, limit int) ([]*models.Vote, error) {
 	var votes []*models.Vote
-	query := r.db.WithContext(ctx).
+	query := dbFromContext(ctx, r.db).
 		Preload("User").
 		Where("user_id = ?", userID)
 
@@ -98,7 +98,7 @@ func (r *VoteRepositoryImpl) ListByUser(ctx context.Context, userID uuid.UUID, t
 
 func (r *VoteRepositoryImpl) ListByTarget(ctx context.Context, targetID uuid.UUID, targetType string, offset, limit int) ([]*models.Vote, error) {
 	var votes []*models.Vote
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Preload("User").
 		Where("target_id = ? AND target_type = ?", targetID, targetType).
 		Order("created_at DESC").
@@ -109,7 +109,7 @@ func (r *VoteRepositoryImpl) ListByTarget(ctx context.Context, targetID uuid.UUI
 
 func (r *VoteRepositoryImpl) GetUserVotesForTargets(ctx context.Context, userID uuid.UUID, targetIDs []uuid.UUID, targetType string) (map[uuid.UUID]*models.Vote, error) {
 	var votes []*models.Vote
-	err := r.db.WithContext(ctx).
+	err := dbFromContext(ctx, r.db).
 		Where("user_id = ? AND target_id IN ? AND target_type = ?", userID, targetIDs, targetType).
 		Find(&votes).Error
 	if err != nil {
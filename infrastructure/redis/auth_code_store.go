@@ -0,0 +1,60 @@
+package redis
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gofiber-template/pkg/auth_code_store"
+)
+
+const authCodePrefix = "auth:oauth_code:"
+
+// AuthCodeStore keeps OAuth authorization codes in Redis, so the callback and the
+// exchange can be served by different instances
+type AuthCodeStore struct {
+	client *redis.Client
+}
+
+func NewAuthCodeStore(redisClient *RedisClient) auth_code_store.Store {
+	return &AuthCodeStore{client: redisClient.client}
+}
+
+func (s *AuthCodeStore) Save(ctx context.Context, code string, data *auth_code_store.AuthCodeData, ttl time.Duration) error {
+	saved := *data
+	saved.ExpiresAt = time.Now().Add(ttl)
+
+	payload, err := json.Marshal(&saved)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, authCodeKey(code), payload, ttl).Err()
+}
+
+func (s *AuthCodeStore) Take(ctx context.Context, code string) (*auth_code_store.AuthCodeData, error) {
+	// GETDEL reads and removes in one step, so two exchanges of the same code can't both succeed
+	payload, err := s.client.GetDel(ctx, authCodeKey(code)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var data auth_code_store.AuthCodeData
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// authCodeKey stores a hash of the code, so the keyspace holds nothing redeemable
+func authCodeKey(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return authCodePrefix + hex.EncodeToString(sum[:])
+}
+
+var _ auth_code_store.Store = (*AuthCodeStore)(nil)
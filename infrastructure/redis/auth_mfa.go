@@ -0,0 +1,17 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const totpUsedPrefix = "auth:totp_used:"
+
+// MarkTOTPStepUsed records that a user's code for a time step was accepted; false if it
+// already was, which means the code is being replayed
+func (r *RedisService) MarkTOTPStepUsed(ctx context.Context, userID uuid.UUID, step int64, ttl time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, fmt.Sprintf("%s%s:%d", totpUsedPrefix, userID, step), 1, ttl).Result()
+}
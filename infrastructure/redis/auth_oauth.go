@@ -0,0 +1,26 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const oauthStatePrefix = "auth:oauth_state:"
+
+// SaveOAuthState keeps what the callback needs to finish an OAuth login
+// (provider, PKCE verifier) under the login's state parameter
+func (r *RedisService) SaveOAuthState(ctx context.Context, state string, data []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, oauthStatePrefix+state, data, ttl).Err()
+}
+
+// ConsumeOAuthState returns and deletes the data saved for state, so each login
+// can only be completed once. Returns nil when the state is unknown or expired.
+func (r *RedisService) ConsumeOAuthState(ctx context.Context, state string) ([]byte, error) {
+	data, err := r.client.GetDel(ctx, oauthStatePrefix+state).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	return data, err
+}
@@ -0,0 +1,65 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	revokedSessionPrefix  = "auth:revoked:"
+	sessionRevokedChannel = "auth:session_revoked"
+)
+
+// SessionRevokedEvent tells every API instance to drop connections of a revoked session
+type SessionRevokedEvent struct {
+	UserID    uuid.UUID `json:"userId"`
+	SessionID uuid.UUID `json:"sessionId"`
+}
+
+// RevokeSession marks a session as revoked. The marker only has to outlive the session's
+// last access token, so ttl should be at least the access token lifetime.
+func (r *RedisService) RevokeSession(ctx context.Context, sessionID uuid.UUID, ttl time.Duration) error {
+	return r.client.Set(ctx, revokedSessionPrefix+sessionID.String(), 1, ttl).Err()
+}
+
+// IsSessionRevoked reports whether a session was revoked
+func (r *RedisService) IsSessionRevoked(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	count, err := r.client.Exists(ctx, revokedSessionPrefix+sessionID.String()).Result()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// PublishSessionRevoked announces a revoked session to every instance
+func (r *RedisService) PublishSessionRevoked(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error {
+	data, err := json.Marshal(SessionRevokedEvent{UserID: userID, SessionID: sessionID})
+	if err != nil {
+		return err
+	}
+	return r.client.Publish(ctx, sessionRevokedChannel, data).Err()
+}
+
+// SubscribeSessionRevoked streams revoked sessions until the returned close func is called
+func (r *RedisService) SubscribeSessionRevoked(ctx context.Context) (<-chan []byte, func() error) {
+	return r.Subscribe(ctx, sessionRevokedChannel)
+}
+
+const usedActionTokenPrefix = "auth:used_token:"
+
+// ConsumeActionToken marks an emailed token as used; false if it was used before.
+// ttl only has to cover the token's remaining lifetime.
+func (r *RedisService) ConsumeActionToken(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, usedActionTokenPrefix+nonce, 1, ttl).Result()
+}
+
+const mailCooldownPrefix = "auth:mail_cooldown:"
+
+// AcquireMailCooldown reports whether an account email may be sent now, and if so
+// blocks further sends of the same kind until the cooldown passes
+func (r *RedisService) AcquireMailCooldown(ctx context.Context, kind string, userID uuid.UUID, cooldown time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, mailCooldownPrefix+kind+":"+userID.String(), 1, cooldown).Result()
+}
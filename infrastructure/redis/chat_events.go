@@ -0,0 +1,101 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	chatSeqPrefix    = "chat:seq:"
+	chatEventsPrefix = "chat:events:"
+)
+
+// appendChatEventScript bumps the user's sequence and stores the event under stream ID <seq>-0,
+// so both keys always move together. ARGV: event, max stream length, TTL in seconds.
+var appendChatEventScript = redis.NewScript(`
+local seq = redis.call('INCR', KEYS[1])
+redis.call('XADD', KEYS[2], 'MAXLEN', '~', ARGV[2], seq .. '-0', 'event', ARGV[1])
+redis.call('EXPIRE', KEYS[1], ARGV[3])
+redis.call('EXPIRE', KEYS[2], ARGV[3])
+return seq
+`)
+
+// ChatEvent is a stored WebSocket event with its per-user sequence number
+type ChatEvent struct {
+	Seq  int64
+	Data []byte
+}
+
+// ChatEventLog is a snapshot of a user's event stream
+type ChatEventLog struct {
+	CurrentSeq int64       // Last sequence number assigned (0 if none)
+	OldestSeq  int64       // Oldest sequence still retained (0 if the stream is empty)
+	Events     []ChatEvent // Events after the requested sequence, oldest first
+}
+
+// AppendChatEvent assigns the next sequence number for a user and stores the encoded event.
+// The stream is trimmed to roughly maxLen entries and expires after ttl of inactivity.
+func (r *RedisService) AppendChatEvent(ctx context.Context, userID uuid.UUID, data []byte, maxLen int64, ttl time.Duration) (int64, error) {
+	keys := []string{chatSeqPrefix + userID.String(), chatEventsPrefix + userID.String()}
+	return appendChatEventScript.Run(ctx, r.client, keys, data, maxLen, int64(ttl.Seconds())).Int64()
+}
+
+// GetChatEventSeq returns the last sequence number assigned to a user
+func (r *RedisService) GetChatEventSeq(ctx context.Context, userID uuid.UUID) (int64, error) {
+	seq, err := r.client.Get(ctx, chatSeqPrefix+userID.String()).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return seq, err
+}
+
+// GetChatEventsSince returns up to count events with a sequence greater than afterSeq
+// together with the current and oldest retained sequence numbers
+func (r *RedisService) GetChatEventsSince(ctx context.Context, userID uuid.UUID, afterSeq int64, count int64) (*ChatEventLog, error) {
+	streamKey := chatEventsPrefix + userID.String()
+
+	pipe := r.client.TxPipeline()
+	seqCmd := pipe.Get(ctx, chatSeqPrefix+userID.String())
+	oldestCmd := pipe.XRangeN(ctx, streamKey, "-", "+", 1)
+	eventsCmd := pipe.XRangeN(ctx, streamKey, fmt.Sprintf("%d-0", afterSeq+1), "+", count)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	eventLog := &ChatEventLog{}
+
+	if seq, err := seqCmd.Int64(); err == nil {
+		eventLog.CurrentSeq = seq
+	} else if err != redis.Nil {
+		return nil, err
+	}
+
+	if oldest := oldestCmd.Val(); len(oldest) > 0 {
+		eventLog.OldestSeq = chatEventSeq(oldest[0].ID)
+	}
+
+	for _, entry := range eventsCmd.Val() {
+		data, _ := entry.Values["event"].(string)
+		eventLog.Events = append(eventLog.Events, ChatEvent{
+			Seq:  chatEventSeq(entry.ID),
+			Data: []byte(data),
+		})
+	}
+
+	return eventLog, nil
+}
+
+// chatEventSeq extracts the sequence number from a stream ID of the form <seq>-0
+func chatEventSeq(id string) int64 {
+	if i := strings.IndexByte(id, '-'); i >= 0 {
+		id = id[:i]
+	}
+	seq, _ := strconv.ParseInt(id, 10, 64)
+	return seq
+}
@@ -0,0 +1,32 @@
+package redis
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const linkPreviewPrefix = "linkpreview:"
+
+// linkPreviewKey hashes the URL to keep keys short and free of user-controlled characters
+func linkPreviewKey(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return linkPreviewPrefix + hex.EncodeToString(sum[:])
+}
+
+// GetLinkPreview returns the cached preview for a URL (nil on miss)
+func (r *RedisService) GetLinkPreview(ctx context.Context, url string) ([]byte, error) {
+	data, err := r.client.Get(ctx, linkPreviewKey(url)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	return data, err
+}
+
+// SetLinkPreview caches an encoded preview for a URL
+func (r *RedisService) SetLinkPreview(ctx context.Context, url string, data []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, linkPreviewKey(url), data, ttl).Err()
+}
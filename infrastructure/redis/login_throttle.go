@@ -0,0 +1,56 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	loginFailurePrefix = "auth:login_fail:"
+	loginBlockPrefix   = "auth:login_block:"
+)
+
+// RecordLoginFailure counts a failed login for a subject (an account or IP address) and
+// returns the failures so far. The count is forgotten after window without new failures.
+func (r *RedisService) RecordLoginFailure(ctx context.Context, subject string, window time.Duration) (int64, error) {
+	key := loginFailurePrefix + subject
+
+	pipe := r.client.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+// BlockLogin rejects logins for a subject for the given duration
+func (r *RedisService) BlockLogin(ctx context.Context, subject string, duration time.Duration) error {
+	return r.client.Set(ctx, loginBlockPrefix+subject, 1, duration).Err()
+}
+
+// LoginBlockRemaining returns how long the longest block among the subjects lasts (0 if none)
+func (r *RedisService) LoginBlockRemaining(ctx context.Context, subjects ...string) (time.Duration, error) {
+	pipe := r.client.Pipeline()
+	ttls := make([]*redis.DurationCmd, len(subjects))
+	for i, subject := range subjects {
+		ttls[i] = pipe.PTTL(ctx, loginBlockPrefix+subject)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	var remaining time.Duration
+	for _, ttl := range ttls {
+		// Missing keys report a negative TTL
+		remaining = max(remaining, ttl.Val())
+	}
+	return remaining, nil
+}
+
+// ClearLoginFailures forgets failures and any block of a subject after a successful login
+func (r *RedisService) ClearLoginFailures(ctx context.Context, subject string) error {
+	return r.client.Del(ctx, loginFailurePrefix+subject, loginBlockPrefix+subject).Err()
+}
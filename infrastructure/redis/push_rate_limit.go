@@ -0,0 +1,27 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const pushRatePrefix = "push:rate:"
+
+// IncrementPushCounter counts a push sent to a user in the current fixed window
+// and returns the number of pushes sent in that window so far
+func (r *RedisService) IncrementPushCounter(ctx context.Context, userID uuid.UUID, window time.Duration) (int64, error) {
+	bucket := time.Now().Unix() / int64(window.Seconds())
+	key := fmt.Sprintf("%s%s:%d", pushRatePrefix, userID.String(), bucket)
+
+	pipe := r.client.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	return incr.Val(), nil
+}
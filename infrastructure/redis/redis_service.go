@@ -129,6 +129,16 @@ func (r *RedisService) IncrementTotalUnread(ctx context.Context, userID uuid.UUI
 	return r.client.Incr(ctx, key).Err()
 }
 
+// AddTotalUnread increases total unread count by count (e.g. when a message request is accepted)
+func (r *RedisService) AddTotalUnread(ctx context.Context, userID uuid.UUID, count int) error {
+	if count <= 0 {
+		return nil
+	}
+
+	key := fmt.Sprintf("unread:total:%s", userID.String())
+	return r.client.IncrBy(ctx, key, int64(count)).Err()
+}
+
 // DecrementTotalUnread decrements total unread count (prevents negative values)
 func (r *RedisService) DecrementTotalUnread(ctx context.Context, userID uuid.UUID, count int) error {
 	if count <= 0 {
@@ -194,6 +204,21 @@ func (r *RedisService) ResetConversationUnread(ctx context.Context, userID uuid.
 	return count, nil
 }
 
+// SetConversationUnread overwrites unread count for a conversation and returns the previous count
+func (r *RedisService) SetConversationUnread(ctx context.Context, userID uuid.UUID, conversationID uuid.UUID, count int) (int, error) {
+	key := fmt.Sprintf("unread:conv:%s:%s", userID.String(), conversationID.String())
+
+	val, err := r.client.GetSet(ctx, key, count).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return strconv.Atoi(val)
+}
+
 // InvalidateUnreadCache invalidates all unread caches for a user (use when rebuilding)
 func (r *RedisService) InvalidateUnreadCache(ctx context.Context, userID uuid.UUID) error {
 	// Delete total unread
@@ -300,3 +325,24 @@ func (r *RedisService) SubscribeToUser(ctx context.Context, userID uuid.UUID) *r
 func (r *RedisService) UnsubscribeUser(ctx context.Context, pubsub *redis.PubSub) error {
 	return pubsub.Close()
 }
+
+// Publish publishes a raw payload to a channel
+func (r *RedisService) Publish(ctx context.Context, channel string, payload []byte) error {
+	return r.client.Publish(ctx, channel, payload).Err()
+}
+
+// Subscribe subscribes to a channel and streams raw payloads until the returned close func is called.
+// The underlying subscription reconnects automatically after connection loss.
+func (r *RedisService) Subscribe(ctx context.Context, channel string) (<-chan []byte, func() error) {
+	pubsub := r.client.Subscribe(ctx, channel)
+	out := make(chan []byte, 256)
+
+	go func() {
+		defer close(out)
+		for msg := range pubsub.Channel() {
+			out <- []byte(msg.Payload)
+		}
+	}()
+
+	return out, pubsub.Close
+}
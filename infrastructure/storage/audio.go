@@ -0,0 +1,487 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+// Audio formats accepted for voice messages (detected from content, not the extension)
+const (
+	AudioMimeOgg  = "audio/ogg"  // Opus/Vorbis (Firefox, Android)
+	AudioMimeWebM = "audio/webm" // Opus in WebM (Chrome MediaRecorder)
+	AudioMimeMP4  = "audio/mp4"  // AAC in MP4/M4A (Safari, iOS)
+	AudioMimeAAC  = "audio/aac"  // Raw ADTS AAC
+	AudioMimeMPEG = "audio/mpeg" // MP3
+	AudioMimeWAV  = "audio/wav"
+)
+
+const (
+	// MaxVoiceDuration caps voice message length in seconds
+	MaxVoiceDuration = 15 * 60
+
+	// waveformSamples is the number of bars in a voice message waveform (amplitude 0-100)
+	waveformSamples = 64
+)
+
+var audioExtensions = map[string]string{
+	AudioMimeOgg:  ".ogg",
+	AudioMimeWebM: ".webm",
+	AudioMimeMP4:  ".m4a",
+	AudioMimeAAC:  ".aac",
+	AudioMimeMPEG: ".mp3",
+	AudioMimeWAV:  ".wav",
+}
+
+// AudioMetadata is what can be read from an audio file without decoding it
+type AudioMetadata struct {
+	Duration float64 // Seconds (0 when the container doesn't say)
+	Waveform []int   // Only for uncompressed audio; clients may supply one otherwise
+}
+
+// DetectAudioMimeType sniffs the audio container from the first bytes of a file ("" if not audio)
+func DetectAudioMimeType(header []byte) string {
+	switch {
+	case bytes.HasPrefix(header, []byte("OggS")):
+		return AudioMimeOgg
+	case len(header) >= 12 && string(header[:4]) == "RIFF" && string(header[8:12]) == "WAVE":
+		return AudioMimeWAV
+	case bytes.HasPrefix(header, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return AudioMimeWebM
+	case len(header) >= 8 && string(header[4:8]) == "ftyp":
+		return AudioMimeMP4
+	case bytes.HasPrefix(header, []byte("ID3")):
+		return AudioMimeMPEG
+	case len(header) >= 2 && header[0] == 0xFF && header[1]&0xF6 == 0xF0:
+		// ADTS sync word with layer 00
+		return AudioMimeAAC
+	case len(header) >= 2 && header[0] == 0xFF && header[1]&0xE0 == 0xE0:
+		// MPEG audio frame sync
+		return AudioMimeMPEG
+	}
+	return ""
+}
+
+// AnalyzeAudio extracts duration (and a waveform for WAV) from an audio file
+func AnalyzeAudio(data []byte, mimeType string) *AudioMetadata {
+	switch mimeType {
+	case AudioMimeWAV:
+		return analyzeWAV(data)
+	case AudioMimeOgg:
+		return &AudioMetadata{Duration: oggDuration(data)}
+	case AudioMimeWebM:
+		return &AudioMetadata{Duration: webmDuration(data)}
+	case AudioMimeMP4:
+		return &AudioMetadata{Duration: mp4Duration(data)}
+	case AudioMimeAAC:
+		return &AudioMetadata{Duration: adtsDuration(data)}
+	case AudioMimeMPEG:
+		return &AudioMetadata{Duration: mp3Duration(data)}
+	}
+	return &AudioMetadata{}
+}
+
+// ==================== WAV ====================
+
+func analyzeWAV(data []byte) *AudioMetadata {
+	metadata := &AudioMetadata{}
+
+	var format, channels, bitsPerSample uint16
+	var byteRate uint32
+
+	for offset := 12; offset+8 <= len(data); {
+		id := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		body := offset + 8
+		end := body + size
+		if size < 0 || end > len(data) {
+			end = len(data)
+		}
+
+		switch id {
+		case "fmt ":
+			if end-body >= 16 {
+				format = binary.LittleEndian.Uint16(data[body:])
+				channels = binary.LittleEndian.Uint16(data[body+2:])
+				byteRate = binary.LittleEndian.Uint32(data[body+8:])
+				bitsPerSample = binary.LittleEndian.Uint16(data[body+14:])
+			}
+		case "data":
+			samples := data[body:end]
+			if byteRate > 0 {
+				metadata.Duration = float64(len(samples)) / float64(byteRate)
+			}
+			// Only PCM can be read directly
+			if format == 1 && channels > 0 && (bitsPerSample == 8 || bitsPerSample == 16) {
+				metadata.Waveform = pcmWaveform(samples, int(channels), int(bitsPerSample))
+			}
+			return metadata
+		}
+
+		// Chunks are padded to an even size
+		offset = body + size + size%2
+	}
+
+	return metadata
+}
+
+// pcmWaveform returns the peak amplitude of the first channel per bucket, scaled to 0-100
+func pcmWaveform(samples []byte, channels int, bitsPerSample int) []int {
+	frameSize := channels * bitsPerSample / 8
+	frames := len(samples) / frameSize
+	if frames == 0 {
+		return nil
+	}
+
+	buckets := waveformSamples
+	if frames < buckets {
+		buckets = frames
+	}
+
+	peaks := make([]float64, buckets)
+	maxPeak := 0.0
+	for i := 0; i < frames; i++ {
+		var amplitude float64
+		if bitsPerSample == 8 {
+			amplitude = math.Abs(float64(int(samples[i*frameSize])-128)) / 128
+		} else {
+			amplitude = math.Abs(float64(int16(binary.LittleEndian.Uint16(samples[i*frameSize:])))) / 32768
+		}
+
+		bucket := i * buckets / frames
+		if amplitude > peaks[bucket] {
+			peaks[bucket] = amplitude
+		}
+		if amplitude > maxPeak {
+			maxPeak = amplitude
+		}
+	}
+
+	// Normalize so the loudest bar is full height
+	waveform := make([]int, buckets)
+	if maxPeak == 0 {
+		return waveform
+	}
+	for i, peak := range peaks {
+		waveform[i] = int(math.Round(peak / maxPeak * 100))
+	}
+	return waveform
+}
+
+// ==================== Ogg (Opus / Vorbis) ====================
+
+func oggDuration(data []byte) float64 {
+	// Codec identification header is the payload of the first page
+	if len(data) < 27 {
+		return 0
+	}
+	payload := 27 + int(data[26])
+	if payload >= len(data) {
+		return 0
+	}
+	header := data[payload:]
+
+	var sampleRate, preSkip float64
+	switch {
+	case bytes.HasPrefix(header, []byte("OpusHead")) && len(header) >= 12:
+		// Opus granule positions always count 48 kHz samples
+		sampleRate = 48000
+		preSkip = float64(binary.LittleEndian.Uint16(header[10:12]))
+	case bytes.HasPrefix(header, []byte("\x01vorbis")) && len(header) >= 16:
+		sampleRate = float64(binary.LittleEndian.Uint32(header[12:16]))
+	default:
+		return 0
+	}
+	if sampleRate == 0 {
+		return 0
+	}
+
+	// The last page's granule position is the total sample count
+	last := bytes.LastIndex(data, []byte("OggS"))
+	if last < 0 || last+14 > len(data) {
+		return 0
+	}
+	granule := float64(binary.LittleEndian.Uint64(data[last+6 : last+14]))
+
+	return math.Max(0, (granule-preSkip)/sampleRate)
+}
+
+// ==================== WebM (EBML) ====================
+
+const (
+	ebmlSegment       = 0x18538067
+	ebmlInfo          = 0x1549A966
+	ebmlTimecodeScale = 0x2AD7B1
+	ebmlDuration      = 0x4489
+)
+
+func webmDuration(data []byte) float64 {
+	// Walk top-level elements down to Segment > Info
+	for offset := 0; offset < len(data); {
+		id, size, body, ok := readEBMLElement(data, offset)
+		if !ok {
+			return 0
+		}
+
+		switch id {
+		case ebmlSegment:
+			// Descend into the segment
+			offset = body
+			continue
+		case ebmlInfo:
+			return webmInfoDuration(data[body:min(body+size, len(data))])
+		}
+
+		if size < 0 {
+			return 0 // Unknown-size element other than the segment
+		}
+		offset = body + size
+	}
+	return 0
+}
+
+func webmInfoDuration(info []byte) float64 {
+	timecodeScale := 1000000.0 // Nanoseconds per tick (default)
+	duration := 0.0
+
+	for offset := 0; offset < len(info); {
+		id, size, body, ok := readEBMLElement(info, offset)
+		if !ok || size < 0 || body+size > len(info) {
+			break
+		}
+		value := info[body : body+size]
+
+		switch id {
+		case ebmlTimecodeScale:
+			var scale uint64
+			for _, b := range value {
+				scale = scale<<8 | uint64(b)
+			}
+			if scale > 0 {
+				timecodeScale = float64(scale)
+			}
+		case ebmlDuration:
+			switch size {
+			case 4:
+				duration = float64(math.Float32frombits(binary.BigEndian.Uint32(value)))
+			case 8:
+				duration = math.Float64frombits(binary.BigEndian.Uint64(value))
+			}
+		}
+		offset = body + size
+	}
+
+	// MediaRecorder output often has no duration at all
+	return duration * timecodeScale / 1e9
+}
+
+// readEBMLElement reads an element header; size is -1 for unknown-size elements
+func readEBMLElement(data []byte, offset int) (id uint64, size int, body int, ok bool) {
+	id, idLen := readEBMLVint(data, offset, true)
+	if idLen == 0 {
+		return 0, 0, 0, false
+	}
+
+	rawSize, sizeLen := readEBMLVint(data, offset+idLen, false)
+	if sizeLen == 0 {
+		return 0, 0, 0, false
+	}
+
+	size = int(rawSize)
+	if rawSize == (uint64(1)<<(7*sizeLen))-1 || rawSize > uint64(len(data)) {
+		size = -1
+	}
+
+	return id, size, offset + idLen + sizeLen, true
+}
+
+// readEBMLVint reads a variable-length integer; IDs keep their length marker bit
+func readEBMLVint(data []byte, offset int, keepMarker bool) (uint64, int) {
+	if offset >= len(data) || data[offset] == 0 {
+		return 0, 0
+	}
+
+	first := data[offset]
+	length := 1
+	for mask := byte(0x80); first&mask == 0; mask >>= 1 {
+		length++
+	}
+	if offset+length > len(data) {
+		return 0, 0
+	}
+
+	value := uint64(first)
+	if !keepMarker {
+		value &= uint64(0xFF >> length)
+	}
+	for i := 1; i < length; i++ {
+		value = value<<8 | uint64(data[offset+i])
+	}
+	return value, length
+}
+
+// ==================== MP4 / M4A ====================
+
+func mp4Duration(data []byte) float64 {
+	moov := findMP4Box(data, "moov")
+	if moov == nil {
+		return 0
+	}
+	mvhd := findMP4Box(moov, "mvhd")
+	if len(mvhd) < 4 {
+		return 0
+	}
+
+	var timescale, duration uint64
+	if mvhd[0] == 1 {
+		// Version 1: 64-bit creation/modification times and duration
+		if len(mvhd) < 32 {
+			return 0
+		}
+		timescale = uint64(binary.BigEndian.Uint32(mvhd[20:24]))
+		duration = binary.BigEndian.Uint64(mvhd[24:32])
+	} else {
+		if len(mvhd) < 20 {
+			return 0
+		}
+		timescale = uint64(binary.BigEndian.Uint32(mvhd[12:16]))
+		duration = uint64(binary.BigEndian.Uint32(mvhd[16:20]))
+	}
+
+	if timescale == 0 {
+		return 0
+	}
+	return float64(duration) / float64(timescale)
+}
+
+// findMP4Box returns the body of the first direct child box of the given type
+func findMP4Box(data []byte, boxType string) []byte {
+	for offset := 0; offset+8 <= len(data); {
+		size := uint64(binary.BigEndian.Uint32(data[offset : offset+4]))
+		header := uint64(8)
+
+		switch size {
+		case 0:
+			size = uint64(len(data) - offset) // Box extends to the end
+		case 1:
+			if offset+16 > len(data) {
+				return nil
+			}
+			size = binary.BigEndian.Uint64(data[offset+8 : offset+16])
+			header = 16
+		}
+		if size < header || uint64(offset)+size > uint64(len(data)) {
+			return nil
+		}
+
+		if string(data[offset+4:offset+8]) == boxType {
+			return data[uint64(offset)+header : uint64(offset)+size]
+		}
+		offset += int(size)
+	}
+	return nil
+}
+
+// ==================== AAC (ADTS) ====================
+
+var adtsSampleRates = []float64{96000, 88200, 64000, 48000, 44100, 32000, 24000, 22050, 16000, 12000, 11025, 8000, 7350}
+
+func adtsDuration(data []byte) float64 {
+	var samples, sampleRate float64
+
+	for offset := 0; offset+7 <= len(data); {
+		if data[offset] != 0xFF || data[offset+1]&0xF6 != 0xF0 {
+			break
+		}
+
+		rateIndex := int(data[offset+2]>>2) & 0x0F
+		if rateIndex >= len(adtsSampleRates) {
+			break
+		}
+		sampleRate = adtsSampleRates[rateIndex]
+
+		frameLength := int(data[offset+3]&0x03)<<11 | int(data[offset+4])<<3 | int(data[offset+5])>>5
+		if frameLength < 7 {
+			break
+		}
+
+		// Each raw data block holds 1024 samples
+		samples += float64(int(data[offset+6]&0x03)+1) * 1024
+		offset += frameLength
+	}
+
+	if sampleRate == 0 {
+		return 0
+	}
+	return samples / sampleRate
+}
+
+// ==================== MP3 ====================
+
+var (
+	mp3BitratesV1 = []int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320}
+	mp3BitratesV2 = []int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160}
+	mp3Rates      = map[byte][]int{
+		3: {44100, 48000, 32000}, // MPEG 1
+		2: {22050, 24000, 16000}, // MPEG 2
+		0: {11025, 12000, 8000},  // MPEG 2.5
+	}
+)
+
+func mp3Duration(data []byte) float64 {
+	offset := 0
+
+	// Skip an ID3v2 tag (syncsafe size)
+	if bytes.HasPrefix(data, []byte("ID3")) && len(data) >= 10 {
+		tagSize := int(data[6])<<21 | int(data[7])<<14 | int(data[8])<<7 | int(data[9])
+		offset = 10 + tagSize
+		if data[5]&0x10 != 0 {
+			offset += 10 // Footer
+		}
+	}
+	if offset+4 > len(data) || data[offset] != 0xFF || data[offset+1]&0xE0 != 0xE0 {
+		return 0
+	}
+
+	// Only Layer III is expected from voice recorders
+	version := (data[offset+1] >> 3) & 0x03
+	layer := (data[offset+1] >> 1) & 0x03
+	bitrateIndex := int(data[offset+2] >> 4)
+	rateIndex := int(data[offset+2]>>2) & 0x03
+	mono := data[offset+3]>>6 == 0x03
+
+	rates, ok := mp3Rates[version]
+	if !ok || layer != 0x01 || rateIndex > 2 || bitrateIndex == 0 || bitrateIndex > 14 {
+		return 0
+	}
+	sampleRate := float64(rates[rateIndex])
+
+	bitrates := mp3BitratesV2
+	samplesPerFrame := 576.0
+	sideInfo := 17
+	if mono {
+		sideInfo = 9
+	}
+	if version == 3 {
+		bitrates = mp3BitratesV1
+		samplesPerFrame = 1152
+		sideInfo = 32
+		if mono {
+			sideInfo = 17
+		}
+	}
+
+	// A Xing/Info header carries the exact frame count (VBR files)
+	xing := offset + 4 + sideInfo
+	if xing+12 <= len(data) {
+		tag := string(data[xing : xing+4])
+		if (tag == "Xing" || tag == "Info") && binary.BigEndian.Uint32(data[xing+4:xing+8])&0x01 != 0 {
+			frames := float64(binary.BigEndian.Uint32(data[xing+8 : xing+12]))
+			return frames * samplesPerFrame / sampleRate
+		}
+	}
+
+	// Otherwise assume constant bitrate
+	bitrate := float64(bitrates[bitrateIndex] * 1000)
+	return float64(len(data)-offset) * 8 / bitrate
+}
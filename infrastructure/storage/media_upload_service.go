@@ -12,6 +12,7 @@ import (
 	_ "image/jpeg"
 	_ "image/png"
 	"io"
+	"math"
 	"mime/multipart"
 	"path/filepath"
 
@@ -32,7 +33,8 @@ type UploadResult struct {
 	Size             int64
 	Width            int
 	Height           int
-	Duration         int // For videos
+	Duration         int // For videos and voice (seconds)
+	Waveform         []int // For voice (amplitude 0-100)
 	// Video streaming fields (for Bunny Stream)
 	VideoID          string
 	HLSURL           string
@@ -183,3 +185,46 @@ func (s *MediaUploadService) UploadFile(ctx context.Context, file multipart.File
 		Size:     fileSize,
 	}, nil
 }
+
+// UploadAudio uploads a voice clip to Bunny Storage after checking its content is audio
+func (s *MediaUploadService) UploadAudio(ctx context.Context, file multipart.File, filename string) (*UploadResult, error) {
+	// Read file
+	var buf bytes.Buffer
+	fileSize, err := io.Copy(&buf, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	// Trust the content, not the extension or client MIME type
+	mimeType := DetectAudioMimeType(buf.Bytes())
+	if mimeType == "" {
+		return nil, fmt.Errorf("unsupported audio format")
+	}
+
+	metadata := AnalyzeAudio(buf.Bytes(), mimeType)
+	if metadata.Duration > MaxVoiceDuration {
+		return nil, fmt.Errorf("voice message exceeds %d minutes", MaxVoiceDuration/60)
+	}
+
+	// Generate unique filename
+	ext := filepath.Ext(filename)
+	if ext == "" {
+		ext = audioExtensions[mimeType]
+	}
+	uniqueName := fmt.Sprintf("%s%s", uuid.New().String(), ext)
+	uploadPath := fmt.Sprintf("chat/voice/%s", uniqueName)
+
+	// Upload to Bunny
+	fileURL, err := s.bunnyStorage.UploadFile(bytes.NewReader(buf.Bytes()), uploadPath, mimeType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload audio: %w", err)
+	}
+
+	return &UploadResult{
+		URL:      fileURL,
+		MimeType: mimeType,
+		Size:     fileSize,
+		Duration: int(math.Ceil(metadata.Duration)),
+		Waveform: metadata.Waveform,
+	}, nil
+}
@@ -9,6 +9,8 @@ import (
 
 	"github.com/gofiber/websocket/v2"
 	"github.com/google/uuid"
+	"gofiber-template/domain/dto"
+	"gofiber-template/domain/models"
 	"gofiber-template/domain/repositories"
 	"gofiber-template/domain/services"
 	"gofiber-template/infrastructure/redis"
@@ -30,7 +32,7 @@ type ChatHub struct {
 	conversationService services.ConversationService
 	blockService        services.BlockService
 	redisService        *redis.RedisService
-	pushService         services.PushService
+	notificationService services.NotificationService
 
 	// Repositories
 	conversationRepo repositories.ConversationRepository
@@ -43,11 +45,12 @@ type ChatHub struct {
 
 // ChatClient represents a connected chat user
 type ChatClient struct {
-	UserID uuid.UUID
-	Conn   *websocket.Conn
-	Send   chan []byte
-	Hub    *ChatHub
-	Ready  chan bool // Signal when WritePump is ready
+	UserID    uuid.UUID
+	SessionID uuid.UUID // Auth session the connection was opened with
+	Conn      *websocket.Conn
+	Send      chan []byte
+	Hub       *ChatHub
+	Ready     chan bool // Signal when WritePump is ready
 }
 
 // ChatMessage represents a WebSocket message
@@ -55,6 +58,7 @@ type ChatMessage struct {
 	Type    string                 `json:"type"`
 	Payload map[string]interface{} `json:"payload,omitempty"`
 	Error   *ChatError             `json:"error,omitempty"`
+	Seq     int64                  `json:"seq,omitempty"` // Per-user event sequence (replayable events only, see recordEvent)
 }
 
 // ChatError represents an error message
@@ -71,7 +75,7 @@ func NewChatHub(
 	redisService *redis.RedisService,
 	conversationRepo repositories.ConversationRepository,
 	followRepo repositories.FollowRepository,
-	pushService services.PushService,
+	notificationService services.NotificationService,
 ) *ChatHub {
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -86,7 +90,7 @@ func NewChatHub(
 		redisService:        redisService,
 		conversationRepo:    conversationRepo,
 		followRepo:          followRepo,
-		pushService:         pushService,
+		notificationService: notificationService,
 		ctx:                 ctx,
 		cancel:              cancel,
 	}
@@ -127,6 +131,64 @@ func (h *ChatHub) SendToUser(userID uuid.UUID, message *ChatMessage) {
 	h.sendToUser(userID, message)
 }
 
+// SendToConversation sends message to every active participant except exceptUserID
+// and returns the recipients
+func (h *ChatHub) SendToConversation(conversationID uuid.UUID, exceptUserID uuid.UUID, message *ChatMessage) []uuid.UUID {
+	conversation, err := h.conversationRepo.GetByID(h.ctx, conversationID)
+	if err != nil {
+		log.Printf("Failed to get conversation %s for fan-out: %v", conversationID, err)
+		return nil
+	}
+
+	return h.sendToParticipants(conversation, exceptUserID, message)
+}
+
+// sendToParticipants fans message out to every participant except exceptUserID
+func (h *ChatHub) sendToParticipants(conversation *models.Conversation, exceptUserID uuid.UUID, message *ChatMessage) []uuid.UUID {
+	recipients := conversation.OtherParticipantIDs(exceptUserID)
+	for _, recipientID := range recipients {
+		h.sendToUser(recipientID, message)
+	}
+	return recipients
+}
+
+// BroadcastNewMessage delivers a new message to the other participants of its conversation
+// and returns those who got it in their inbox (see sendNewMessage)
+func (h *ChatHub) BroadcastNewMessage(message *dto.MessageResponse) []uuid.UUID {
+	conversation, err := h.conversationRepo.GetByID(h.ctx, message.ConversationID)
+	if err != nil {
+		log.Printf("Failed to get conversation %s for fan-out: %v", message.ConversationID, err)
+		return nil
+	}
+
+	return h.sendNewMessage(conversation, message)
+}
+
+// sendNewMessage sends message.new to inbox recipients and message.request to recipients of
+// a pending message request. Declined requests are not delivered. Returns inbox recipients.
+func (h *ChatHub) sendNewMessage(conversation *models.Conversation, message *dto.MessageResponse) []uuid.UUID {
+	var inbox []uuid.UUID
+	for _, recipientID := range conversation.OtherParticipantIDs(message.SenderId) {
+		eventType := "message.new"
+		if conversation.IsRequestFor(recipientID) {
+			if conversation.RequestStatus != models.ConversationRequestPending {
+				continue
+			}
+			eventType = "message.request"
+		} else {
+			inbox = append(inbox, recipientID)
+		}
+
+		h.sendToUser(recipientID, &ChatMessage{
+			Type: eventType,
+			Payload: map[string]interface{}{
+				"message": message,
+			},
+		})
+	}
+	return inbox
+}
+
 // registerClient is internal handler for client registration
 func (h *ChatHub) registerClient(client *ChatClient) {
 	h.clientsMutex.Lock()
@@ -146,6 +208,7 @@ func (h *ChatHub) registerClient(client *ChatClient) {
 		Payload: map[string]interface{}{
 			"userId":      client.UserID.String(),
 			"connectedAt": time.Now().Format(time.RFC3339),
+			"seq":         h.currentEventSeq(client.UserID),
 		},
 	})
 
@@ -159,10 +222,13 @@ func (h *ChatHub) registerClient(client *ChatClient) {
 // unregisterClient handles client disconnection
 func (h *ChatHub) unregisterClient(client *ChatClient) {
 	h.clientsMutex.Lock()
-	if _, ok := h.clients[client.UserID]; ok {
-		delete(h.clients, client.UserID)
-		close(client.Send)
+	// A closed connection may unregister again (ReadPump exit); don't touch a newer connection
+	if current, ok := h.clients[client.UserID]; !ok || current != client {
+		h.clientsMutex.Unlock()
+		return
 	}
+	delete(h.clients, client.UserID)
+	close(client.Send)
 	h.clientsMutex.Unlock()
 
 	// Set user offline in Redis
@@ -198,6 +264,9 @@ func (h *ChatHub) broadcastMessage(message *ChatMessage) {
 
 // sendToUser sends message to specific user
 func (h *ChatHub) sendToUser(userID uuid.UUID, message *ChatMessage) {
+	// Replayable events get the user's next sequence number so a reconnecting client can catch up
+	message = h.recordEvent(userID, message)
+
 	h.clientsMutex.RLock()
 	client, exists := h.clients[userID]
 	h.clientsMutex.RUnlock()
@@ -213,7 +282,9 @@ func (h *ChatHub) sendToUser(userID uuid.UUID, message *ChatMessage) {
 	}
 }
 
-// sendToClient sends message to client's send channel
+// sendToClient sends message to client's send channel without recording it. Use it only for
+// replies that are not replayed on sync: errors, pong, connection.success, initial.online.status,
+// sync.*, session notices and system broadcasts. State changes go through sendEventToClient.
 func (h *ChatHub) sendToClient(client *ChatClient, message *ChatMessage) {
 	data, err := json.Marshal(message)
 	if err != nil {
@@ -221,6 +292,17 @@ func (h *ChatHub) sendToClient(client *ChatClient, message *ChatMessage) {
 		return
 	}
 
+	h.sendRawToClient(client, data)
+}
+
+// sendEventToClient records a replayable event in the client user's event log and sends it
+// with its sequence number, so acks of the user's own actions survive a reconnect
+func (h *ChatHub) sendEventToClient(client *ChatClient, message *ChatMessage) {
+	h.sendToClient(client, h.recordEvent(client.UserID, message))
+}
+
+// sendRawToClient queues already encoded data on the client's send channel
+func (h *ChatHub) sendRawToClient(client *ChatClient, data []byte) {
 	select {
 	case client.Send <- data:
 		// Message sent successfully
@@ -239,6 +321,9 @@ func (h *ChatHub) listenRedisPubSub() {
 
 	// For now, we'll implement user-specific subscriptions when clients connect
 	// Full implementation will be in Phase 2
+
+	// Revoked sessions are announced to every instance
+	go h.listenSessionRevocations()
 }
 
 // Stop gracefully shuts down the hub
@@ -300,16 +385,14 @@ func (h *ChatHub) broadcastOnlineStatus(userID uuid.UUID, isOnline bool) {
 	}
 
 	// 3. Get conversation partners (people who have active conversations with this user)
-	conversations, err := h.conversationRepo.ListByUser(ctx, userID, nil, 1000)
+	conversations, err := h.conversationRepo.ListByUser(ctx, userID, models.ConversationFolderAll, nil, 1000)
 	if err != nil {
 		log.Printf("Failed to get conversations for online status broadcast: %v", err)
 	} else {
 		for _, conv := range conversations {
-			// Add the other participant in the conversation
-			if conv.User1ID == userID {
-				recipientMap[conv.User2ID] = true
-			} else {
-				recipientMap[conv.User1ID] = true
+			// Add the other participant of direct conversations (groups would fan out too widely)
+			if !conv.IsGroup() {
+				recipientMap[conv.OtherUserID(userID)] = true
 			}
 		}
 	}
@@ -372,15 +455,13 @@ func (h *ChatHub) sendInitialOnlineStatus(client *ChatClient) {
 	}
 
 	// 3. Get conversation partners
-	conversations, err := h.conversationRepo.ListByUser(ctx, client.UserID, nil, 1000)
+	conversations, err := h.conversationRepo.ListByUser(ctx, client.UserID, models.ConversationFolderAll, nil, 1000)
 	if err != nil {
 		log.Printf("Failed to get conversations for initial status: %v", err)
 	} else {
 		for _, conv := range conversations {
-			if conv.User1ID == client.UserID {
-				relevantUserMap[conv.User2ID] = true
-			} else {
-				relevantUserMap[conv.User1ID] = true
+			if !conv.IsGroup() {
+				relevantUserMap[conv.OtherUserID(client.UserID)] = true
 			}
 		}
 	}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 	"gofiber-template/domain/dto"
+	"gofiber-template/domain/models"
 )
 
 // routeMessage routes incoming messages to appropriate handlers
@@ -22,6 +23,22 @@ func (h *ChatHub) routeMessage(client *ChatClient, message *ChatMessage) {
 	case "message.read":
 		h.handleMessageRead(ctx, client, message)
 
+	case "message.delivered":
+		h.handleMessageDelivered(ctx, client, message)
+
+	case "message.edit":
+		h.handleMessageEdit(ctx, client, message)
+
+	case "message.delete":
+		h.handleMessageDelete(ctx, client, message)
+
+	// Reactions
+	case "message.react":
+		h.handleMessageReaction(ctx, client, message, true)
+
+	case "message.unreact":
+		h.handleMessageReaction(ctx, client, message, false)
+
 	// Typing indicators
 	case "typing.start":
 		h.handleTypingStart(ctx, client, message)
@@ -33,6 +50,10 @@ func (h *ChatHub) routeMessage(client *ChatClient, message *ChatMessage) {
 	case "ping":
 		h.handlePing(ctx, client, message)
 
+	// Missed-event resync after reconnect
+	case "sync":
+		h.handleSync(ctx, client, message)
+
 	// Block events
 	case "block.add":
 		h.handleBlockAdd(ctx, client, message)
@@ -100,6 +121,13 @@ func (h *ChatHub) handleMessageSend(ctx context.Context, client *ChatClient, mes
 					d := int(duration)
 					mediaItem.Duration = &d
 				}
+				if waveform, ok := mediaMap["waveform"].([]interface{}); ok {
+					for _, sample := range waveform {
+						if amplitude, ok := sample.(float64); ok {
+							mediaItem.Waveform = append(mediaItem.Waveform, int(amplitude))
+						}
+					}
+				}
 				media = append(media, mediaItem)
 			}
 		}
@@ -111,6 +139,17 @@ func (h *ChatHub) handleMessageSend(ctx context.Context, client *ChatClient, mes
 		tempID = &tempIDStr
 	}
 
+	// Parse replyToId (optional quoted message)
+	var replyToID *uuid.UUID
+	if replyToStr, ok := message.Payload["replyToId"].(string); ok && replyToStr != "" {
+		parsed, err := uuid.Parse(replyToStr)
+		if err != nil {
+			client.sendError("validation_error", "Invalid replyToId")
+			return
+		}
+		replyToID = &parsed
+	}
+
 	// Validate: content OR media must be provided
 	if (content == nil || *content == "") && len(media) == 0 {
 		client.sendError("validation_error", "Either content or media must be provided")
@@ -124,6 +163,7 @@ func (h *ChatHub) handleMessageSend(ctx context.Context, client *ChatClient, mes
 		Content:        content,
 		Media:          media,
 		TempID:         tempID,
+		ReplyToID:      replyToID,
 	}
 
 	// Send message via MessageService
@@ -135,7 +175,7 @@ func (h *ChatHub) handleMessageSend(ctx context.Context, client *ChatClient, mes
 	}
 
 	// Send acknowledgment to sender (message.sent)
-	h.sendToClient(client, &ChatMessage{
+	h.sendEventToClient(client, &ChatMessage{
 		Type: "message.sent",
 		Payload: map[string]interface{}{
 			"message": msgResponse,
@@ -143,18 +183,24 @@ func (h *ChatHub) handleMessageSend(ctx context.Context, client *ChatClient, mes
 		},
 	})
 
-	// Send new message notification to receiver (message.new)
-	receiverID := msgResponse.Receiver.ID
-	h.sendToUser(receiverID, &ChatMessage{
-		Type: "message.new",
-		Payload: map[string]interface{}{
-			"message": msgResponse,
-		},
-	})
+	conversation, err := h.conversationRepo.GetByID(ctx, conversationID)
+	if err != nil {
+		log.Printf("Failed to get conversation for fan-out: %v", err)
+		return
+	}
 
-	// If receiver is offline, send push notification
-	if !h.IsUserOnline(receiverID) {
-		go h.sendPushNotification(ctx, receiverID, client.UserID, msgResponse)
+	// Send new message notification to every other participant (message.new / message.request)
+	recipients := h.sendNewMessage(conversation, msgResponse)
+
+	// Send push notifications to offline recipients (never for message requests or muted conversations)
+	now := time.Now()
+	for _, recipientID := range recipients {
+		if participant := conversation.Participant(recipientID); participant != nil && participant.IsMuted(now) {
+			continue
+		}
+		if !h.IsUserOnline(recipientID) {
+			go h.sendPushNotification(ctx, recipientID, client.UserID, msgResponse, conversation)
+		}
 	}
 }
 
@@ -173,46 +219,301 @@ func (h *ChatHub) handleMessageRead(ctx context.Context, client *ChatClient, mes
 		return
 	}
 
-	// Mark all messages as read
-	if err := h.conversationService.MarkAsRead(ctx, conversationID, client.UserID); err != nil {
+	// Parse lastReadMessageId (optional, defaults to the latest message)
+	var lastReadMessageID *uuid.UUID
+	if lastReadStr, ok := message.Payload["lastReadMessageId"].(string); ok && lastReadStr != "" {
+		parsed, err := uuid.Parse(lastReadStr)
+		if err != nil {
+			client.sendError("validation_error", "Invalid lastReadMessageId")
+			return
+		}
+		lastReadMessageID = &parsed
+	}
+
+	update, err := h.conversationService.MarkAsRead(ctx, conversationID, client.UserID, lastReadMessageID)
+	if err != nil {
 		log.Printf("Failed to mark as read: %v", err)
 		client.sendError("mark_read_failed", err.Error())
 		return
 	}
 
 	// Send acknowledgment to reader (message.read_ack)
-	h.sendToClient(client, &ChatMessage{
-		Type: "message.read_ack",
+	ack := map[string]interface{}{
+		"conversationId": conversationID.String(),
+		"readAt":         update.ReadAt.Format(time.RFC3339),
+		"unreadCount":    update.UnreadCount,
+	}
+	if update.LastReadMessageID != nil {
+		ack["lastReadMessageId"] = update.LastReadMessageID.String()
+	}
+	h.sendEventToClient(client, &ChatMessage{
+		Type:    "message.read_ack",
+		Payload: ack,
+	})
+
+	h.BroadcastReadReceipt(update)
+}
+
+// BroadcastReadReceipt tells the senders of newly read messages (message.read_update).
+// Senders are already filtered by the read receipt privacy setting.
+func (h *ChatHub) BroadcastReadReceipt(update *dto.ReadReceiptUpdate) {
+	if len(update.NotifyUserIDs) == 0 {
+		return
+	}
+
+	payload := map[string]interface{}{
+		"conversationId": update.ConversationID.String(),
+		"readBy":         update.ReadBy.String(),
+		"readAt":         update.ReadAt.Format(time.RFC3339),
+	}
+	if update.LastReadMessageID != nil {
+		payload["lastReadMessageId"] = update.LastReadMessageID.String()
+	}
+
+	for _, userID := range update.NotifyUserIDs {
+		h.sendToUser(userID, &ChatMessage{
+			Type:    "message.read_update",
+			Payload: payload,
+		})
+	}
+}
+
+// handleMessageDelivered records that the client received messages (message.delivered)
+func (h *ChatHub) handleMessageDelivered(ctx context.Context, client *ChatClient, message *ChatMessage) {
+	// Accept a single messageId or a batch in messageIds
+	var messageIDs []uuid.UUID
+	if idStr, ok := message.Payload["messageId"].(string); ok && idStr != "" {
+		parsed, err := uuid.Parse(idStr)
+		if err != nil {
+			client.sendError("validation_error", "Invalid messageId")
+			return
+		}
+		messageIDs = append(messageIDs, parsed)
+	}
+	if ids, ok := message.Payload["messageIds"].([]interface{}); ok {
+		for _, id := range ids {
+			idStr, _ := id.(string)
+			parsed, err := uuid.Parse(idStr)
+			if err != nil {
+				client.sendError("validation_error", "Invalid messageIds")
+				return
+			}
+			messageIDs = append(messageIDs, parsed)
+		}
+	}
+
+	if len(messageIDs) == 0 {
+		client.sendError("validation_error", "messageId or messageIds is required")
+		return
+	}
+
+	updates, err := h.messageService.MarkDelivered(ctx, client.UserID, messageIDs)
+	if err != nil {
+		log.Printf("Failed to mark as delivered: %v", err)
+		client.sendError("mark_delivered_failed", err.Error())
+		return
+	}
+
+	h.BroadcastDeliveryReceipts(updates)
+}
+
+// BroadcastDeliveryReceipts tells each sender which of their messages reached a recipient
+// (message.delivered_update)
+func (h *ChatHub) BroadcastDeliveryReceipts(updates []*dto.DeliveryReceiptUpdate) {
+	for _, update := range updates {
+		messageIDs := make([]string, len(update.MessageIDs))
+		for i, id := range update.MessageIDs {
+			messageIDs[i] = id.String()
+		}
+
+		h.sendToUser(update.SenderID, &ChatMessage{
+			Type: "message.delivered_update",
+			Payload: map[string]interface{}{
+				"conversationId": update.ConversationID.String(),
+				"messageIds":     messageIDs,
+				"deliveredTo":    update.DeliveredTo.String(),
+				"deliveredAt":    update.DeliveredAt.Format(time.RFC3339),
+			},
+		})
+	}
+}
+
+// handleMessageEdit handles editing a sent message
+func (h *ChatHub) handleMessageEdit(ctx context.Context, client *ChatClient, message *ChatMessage) {
+	messageIDStr, ok := message.Payload["messageId"].(string)
+	if !ok {
+		client.sendError("validation_error", "messageId is required")
+		return
+	}
+
+	messageID, err := uuid.Parse(messageIDStr)
+	if err != nil {
+		client.sendError("validation_error", "Invalid messageId")
+		return
+	}
+
+	content, ok := message.Payload["content"].(string)
+	if !ok || content == "" || len(content) > 5000 {
+		client.sendError("validation_error", "content must be between 1 and 5000 characters")
+		return
+	}
+
+	msgResponse, err := h.messageService.EditMessage(ctx, messageID, client.UserID, &dto.EditMessageRequest{Content: content})
+	if err != nil {
+		log.Printf("Failed to edit message: %v", err)
+		client.sendError("edit_failed", err.Error())
+		return
+	}
+
+	edited := &ChatMessage{
+		Type: "message.edited",
 		Payload: map[string]interface{}{
-			"conversationId": conversationID.String(),
-			"readAt":         time.Now().Format(time.RFC3339),
+			"message": msgResponse,
 		},
-	})
+	}
 
-	// Get conversation to find other participant
-	conversation, err := h.conversationRepo.GetByID(ctx, conversationID)
+	// Acknowledge to the editor and update everyone else's copy
+	h.sendEventToClient(client, edited)
+	h.SendToConversation(msgResponse.ConversationID, client.UserID, edited)
+}
+
+// handleMessageDelete handles "delete for me" and "delete for everyone"
+func (h *ChatHub) handleMessageDelete(ctx context.Context, client *ChatClient, message *ChatMessage) {
+	messageIDStr, ok := message.Payload["messageId"].(string)
+	if !ok {
+		client.sendError("validation_error", "messageId is required")
+		return
+	}
+
+	messageID, err := uuid.Parse(messageIDStr)
+	if err != nil {
+		client.sendError("validation_error", "Invalid messageId")
+		return
+	}
+
+	// Parse scope (default: me)
+	scope := models.MessageDeleteScopeMe
+	if scopeStr, ok := message.Payload["scope"].(string); ok && scopeStr != "" {
+		scope = scopeStr
+	}
+
+	msgResponse, err := h.messageService.DeleteMessage(ctx, messageID, client.UserID, scope)
+	if err != nil {
+		log.Printf("Failed to delete message: %v", err)
+		client.sendError("delete_failed", err.Error())
+		return
+	}
+
+	h.BroadcastMessageDeleted(client.UserID, msgResponse, scope)
+}
+
+// BroadcastMessageDeleted tells the deleting user (and everyone else for "everyone" deletes)
+// that a message is gone
+func (h *ChatHub) BroadcastMessageDeleted(userID uuid.UUID, msgResponse *dto.MessageResponse, scope string) {
+	deleted := &ChatMessage{
+		Type: "message.deleted",
+		Payload: map[string]interface{}{
+			"messageId":      msgResponse.ID.String(),
+			"conversationId": msgResponse.ConversationID.String(),
+			"scope":          scope,
+		},
+	}
+
+	h.sendToUser(userID, deleted)
+	if scope == models.MessageDeleteScopeEveryone {
+		h.SendToConversation(msgResponse.ConversationID, userID, deleted)
+	}
+}
+
+// BroadcastLinkPreview sends a message's link preview, unfurled after it was sent, to every participant
+func (h *ChatHub) BroadcastLinkPreview(msgResponse *dto.MessageResponse) {
+	if msgResponse.LinkPreview == nil {
+		return
+	}
+
+	update := &ChatMessage{
+		Type: "message.link_preview",
+		Payload: map[string]interface{}{
+			"messageId":      msgResponse.ID.String(),
+			"conversationId": msgResponse.ConversationID.String(),
+			"linkPreview":    msgResponse.LinkPreview,
+		},
+	}
+
+	h.sendToUser(msgResponse.SenderId, update)
+	h.SendToConversation(msgResponse.ConversationID, msgResponse.SenderId, update)
+}
+
+// ==================== Reactions ====================
+
+// handleMessageReaction adds or removes an emoji reaction
+func (h *ChatHub) handleMessageReaction(ctx context.Context, client *ChatClient, message *ChatMessage, add bool) {
+	messageIDStr, ok := message.Payload["messageId"].(string)
+	if !ok {
+		client.sendError("validation_error", "messageId is required")
+		return
+	}
+
+	messageID, err := uuid.Parse(messageIDStr)
 	if err != nil {
-		log.Printf("Failed to get conversation: %v", err)
+		client.sendError("validation_error", "Invalid messageId")
 		return
 	}
 
-	// Determine other participant
-	var otherUserID uuid.UUID
-	if conversation.User1ID == client.UserID {
-		otherUserID = conversation.User2ID
+	emoji, ok := message.Payload["emoji"].(string)
+	if !ok || emoji == "" {
+		client.sendError("validation_error", "emoji is required")
+		return
+	}
+
+	var update *dto.MessageReactionUpdate
+	if add {
+		update, err = h.messageService.React(ctx, messageID, client.UserID, emoji)
 	} else {
-		otherUserID = conversation.User1ID
+		update, err = h.messageService.Unreact(ctx, messageID, client.UserID, emoji)
+	}
+	if err != nil {
+		log.Printf("Failed to update reaction: %v", err)
+		client.sendError("reaction_failed", err.Error())
+		return
 	}
 
-	// Send read update to sender (message.read_update)
-	h.sendToUser(otherUserID, &ChatMessage{
-		Type: "message.read_update",
+	// Reactor gets the full summary; others get the delta (reactedByMe differs per viewer)
+	h.sendEventToClient(client, &ChatMessage{
+		Type: "message.reaction",
 		Payload: map[string]interface{}{
-			"conversationId": conversationID.String(),
-			"readBy":         client.UserID.String(),
-			"readAt":         time.Now().Format(time.RFC3339),
+			"messageId":      update.MessageID.String(),
+			"conversationId": update.ConversationID.String(),
+			"userId":         update.UserID.String(),
+			"emoji":          update.Emoji,
+			"action":         update.Action,
+			"reactions":      update.Reactions,
 		},
 	})
+
+	conversation, err := h.conversationRepo.GetByID(ctx, update.ConversationID)
+	if err != nil {
+		log.Printf("Failed to get conversation for reaction fan-out: %v", err)
+		return
+	}
+
+	for _, recipientID := range conversation.OtherParticipantIDs(client.UserID) {
+		// Users who blocked each other don't see each other's reactions
+		if blocked, err := h.blockService.IsBlockedBetween(ctx, recipientID, client.UserID); err != nil || blocked {
+			continue
+		}
+
+		h.sendToUser(recipientID, &ChatMessage{
+			Type: "message.reaction",
+			Payload: map[string]interface{}{
+				"messageId":      update.MessageID.String(),
+				"conversationId": update.ConversationID.String(),
+				"userId":         update.UserID.String(),
+				"emoji":          update.Emoji,
+				"action":         update.Action,
+			},
+		})
+	}
 }
 
 // ==================== Typing Indicators ====================
@@ -229,22 +530,14 @@ func (h *ChatHub) handleTypingStart(ctx context.Context, client *ChatClient, mes
 		return
 	}
 
-	// Get conversation to find other participant
+	// Get conversation to find other participants
 	conversation, err := h.conversationRepo.GetByID(ctx, conversationID)
-	if err != nil {
+	if err != nil || !conversation.HasParticipant(client.UserID) {
 		return
 	}
 
-	// Determine other participant
-	var otherUserID uuid.UUID
-	if conversation.User1ID == client.UserID {
-		otherUserID = conversation.User2ID
-	} else {
-		otherUserID = conversation.User1ID
-	}
-
-	// Broadcast typing indicator to other user
-	h.sendToUser(otherUserID, &ChatMessage{
+	// Broadcast typing indicator to other participants
+	h.sendToParticipants(conversation, client.UserID, &ChatMessage{
 		Type: "typing.start",
 		Payload: map[string]interface{}{
 			"conversationId": conversationID.String(),
@@ -265,22 +558,14 @@ func (h *ChatHub) handleTypingStop(ctx context.Context, client *ChatClient, mess
 		return
 	}
 
-	// Get conversation to find other participant
+	// Get conversation to find other participants
 	conversation, err := h.conversationRepo.GetByID(ctx, conversationID)
-	if err != nil {
+	if err != nil || !conversation.HasParticipant(client.UserID) {
 		return
 	}
 
-	// Determine other participant
-	var otherUserID uuid.UUID
-	if conversation.User1ID == client.UserID {
-		otherUserID = conversation.User2ID
-	} else {
-		otherUserID = conversation.User1ID
-	}
-
-	// Broadcast stop typing to other user
-	h.sendToUser(otherUserID, &ChatMessage{
+	// Broadcast stop typing to other participants
+	h.sendToParticipants(conversation, client.UserID, &ChatMessage{
 		Type: "typing.stop",
 		Payload: map[string]interface{}{
 			"conversationId": conversationID.String(),
@@ -323,7 +608,7 @@ func (h *ChatHub) handleBlockAdd(ctx context.Context, client *ChatClient, messag
 	}
 
 	// Send acknowledgment
-	h.sendToClient(client, &ChatMessage{
+	h.sendEventToClient(client, &ChatMessage{
 		Type: "block.added",
 		Payload: map[string]interface{}{
 			"username":  usernameToBlock,
@@ -348,7 +633,7 @@ func (h *ChatHub) handleBlockRemove(ctx context.Context, client *ChatClient, mes
 	}
 
 	// Send acknowledgment
-	h.sendToClient(client, &ChatMessage{
+	h.sendEventToClient(client, &ChatMessage{
 		Type: "block.removed",
 		Payload: map[string]interface{}{
 			"username":    usernameToUnblock,
@@ -360,12 +645,15 @@ func (h *ChatHub) handleBlockRemove(ctx context.Context, client *ChatClient, mes
 // ==================== Push Notifications ====================
 
 // sendPushNotification sends push notification to offline user
-func (h *ChatHub) sendPushNotification(ctx context.Context, receiverID uuid.UUID, senderID uuid.UUID, message *dto.MessageResponse) {
+func (h *ChatHub) sendPushNotification(ctx context.Context, receiverID uuid.UUID, senderID uuid.UUID, message *dto.MessageResponse, conversation *models.Conversation) {
 	// Get sender info for notification
 	sender := message.Sender
 
 	// Prepare notification title and body
 	title := fmt.Sprintf("New message from %s", sender.Username)
+	if conversation.IsGroup() && conversation.Title != nil {
+		title = fmt.Sprintf("%s in %s", sender.Username, *conversation.Title)
+	}
 	body := ""
 
 	// Format body based on message type
@@ -386,6 +674,12 @@ func (h *ChatHub) sendPushNotification(ctx context.Context, receiverID uuid.UUID
 		body = "🎥 Sent a video"
 	case "file":
 		body = "📎 Sent a file"
+	case "voice":
+		body = "🎤 Sent a voice message"
+		if len(message.Media) > 0 && message.Media[0].Duration != nil {
+			duration := *message.Media[0].Duration
+			body = fmt.Sprintf("🎤 Sent a voice message (%d:%02d)", duration/60, duration%60)
+		}
 	default:
 		body = "Sent a message"
 	}
@@ -402,13 +696,20 @@ func (h *ChatHub) sendPushNotification(ctx context.Context, receiverID uuid.UUID
 			"messageId":      message.ID.String(),
 			"senderId":       senderID.String(),
 		},
+		// Collapse pending pushes per conversation; stale chat pushes are useless after an hour
+		Options: &dto.PushDeliveryOptions{
+			TTL:     3600,
+			Urgency: "high",
+			Topic:   "chat:" + message.ConversationID.String(),
+		},
 	}
 
-	err := h.pushService.SendToUser(ctx, receiverID, payload)
+	// Goes through the receiver's chat push preference and quiet hours
+	err := h.notificationService.DispatchPush(ctx, receiverID, models.NotificationTypeChatMessage, payload)
 
 	if err != nil {
 		log.Printf("Failed to send push notification to %s: %v", receiverID, err)
 	} else {
-		log.Printf("📬 Push notification sent to offline user %s", receiverID)
+		log.Printf("📬 Push notification queued for offline user %s", receiverID)
 	}
 }
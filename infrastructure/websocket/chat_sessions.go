@@ -0,0 +1,56 @@
+package websocket
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/google/uuid"
+	"gofiber-template/infrastructure/redis"
+)
+
+// listenSessionRevocations closes connections whose auth session was revoked on any instance
+func (h *ChatHub) listenSessionRevocations() {
+	events, closeSub := h.redisService.SubscribeSessionRevoked(h.ctx)
+	defer closeSub()
+
+	for {
+		select {
+		case payload, ok := <-events:
+			if !ok {
+				return
+			}
+
+			var event redis.SessionRevokedEvent
+			if err := json.Unmarshal(payload, &event); err != nil {
+				log.Printf("Failed to decode session revocation: %v", err)
+				continue
+			}
+
+			h.DisconnectSession(event.UserID, event.SessionID)
+
+		case <-h.ctx.Done():
+			return
+		}
+	}
+}
+
+// DisconnectSession closes the user's connection if it was opened with the given session
+func (h *ChatHub) DisconnectSession(userID uuid.UUID, sessionID uuid.UUID) {
+	h.clientsMutex.RLock()
+	client, exists := h.clients[userID]
+	h.clientsMutex.RUnlock()
+
+	if !exists || client.SessionID != sessionID {
+		return
+	}
+
+	h.sendToClient(client, &ChatMessage{
+		Type: "session.revoked",
+		Payload: map[string]interface{}{
+			"sessionId": sessionID.String(),
+		},
+	})
+
+	log.Printf("🔒 Closing chat connection of user %s, session %s revoked", userID, sessionID)
+	h.unregister <- client
+}
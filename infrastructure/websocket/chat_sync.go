@@ -0,0 +1,127 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	chatEventStreamMaxLen = 1000           // Events retained per user (approximate, trimmed by Redis)
+	chatEventTTL          = 72 * time.Hour // Event log expires after this long without new events
+	chatSyncMaxReplay     = 200            // Larger gaps require a full resync
+)
+
+// ephemeralChatEvents only make sense live; replaying them after a reconnect would show stale state.
+// Replies sent with sendToClient (errors, pong, connection.success, initial.online.status, sync.*,
+// session.revoked) are never recorded either and carry no seq; clients must not treat them as gaps.
+var ephemeralChatEvents = map[string]bool{
+	"typing.start": true,
+	"typing.stop":  true,
+	"user.online":  true,
+	"user.offline": true,
+}
+
+// recordEvent appends a replayable event to the user's event log and returns a copy
+// stamped with its sequence number. Ephemeral events are returned unchanged.
+func (h *ChatHub) recordEvent(userID uuid.UUID, message *ChatMessage) *ChatMessage {
+	if ephemeralChatEvents[message.Type] || message.Error != nil {
+		return message
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("Failed to marshal chat event: %v", err)
+		return message
+	}
+
+	seq, err := h.redisService.AppendChatEvent(h.ctx, userID, data, chatEventStreamMaxLen, chatEventTTL)
+	if err != nil {
+		log.Printf("Failed to record chat event for user %s: %v", userID, err)
+		return message
+	}
+
+	// Copy so recipients sharing the same message keep their own sequence numbers
+	stamped := *message
+	stamped.Seq = seq
+	return &stamped
+}
+
+// currentEventSeq returns the user's last event sequence number (0 if unknown)
+func (h *ChatHub) currentEventSeq(userID uuid.UUID) int64 {
+	seq, err := h.redisService.GetChatEventSeq(h.ctx, userID)
+	if err != nil {
+		log.Printf("Failed to get chat event sequence for user %s: %v", userID, err)
+	}
+	return seq
+}
+
+// ==================== Sync ====================
+
+// handleSync replays events the client missed since lastSeq, or tells it to refetch
+// everything when the gap can no longer be covered by the event log
+func (h *ChatHub) handleSync(ctx context.Context, client *ChatClient, message *ChatMessage) {
+	lastSeqValue, ok := message.Payload["lastSeq"].(float64)
+	if !ok || lastSeqValue < 0 {
+		client.sendError("validation_error", "lastSeq is required")
+		return
+	}
+	lastSeq := int64(lastSeqValue)
+
+	eventLog, err := h.redisService.GetChatEventsSince(ctx, client.UserID, lastSeq, chatSyncMaxReplay+1)
+	if err != nil {
+		log.Printf("Failed to load chat events for user %s: %v", client.UserID, err)
+		client.sendError("sync_failed", "Failed to load missed events")
+		return
+	}
+
+	reason := ""
+	switch {
+	case lastSeq > eventLog.CurrentSeq:
+		// Event log was reset (expired or flushed) since the client last synced
+		reason = "sequence_reset"
+	case lastSeq == eventLog.CurrentSeq:
+		// Nothing missed
+	case eventLog.OldestSeq == 0 || lastSeq+1 < eventLog.OldestSeq:
+		reason = "events_expired"
+	case len(eventLog.Events) > chatSyncMaxReplay:
+		reason = "too_many_events"
+	}
+
+	if reason != "" {
+		h.sendToClient(client, &ChatMessage{
+			Type: "sync.resync_required",
+			Payload: map[string]interface{}{
+				"seq":    eventLog.CurrentSeq,
+				"reason": reason,
+			},
+		})
+		return
+	}
+
+	events := make([]*ChatMessage, 0, len(eventLog.Events))
+	for _, event := range eventLog.Events {
+		var replayed ChatMessage
+		if err := json.Unmarshal(event.Data, &replayed); err != nil {
+			log.Printf("Failed to decode chat event %d for user %s: %v", event.Seq, client.UserID, err)
+			continue
+		}
+		replayed.Seq = event.Seq
+		events = append(events, &replayed)
+	}
+
+	// Sent as one frame so a large replay cannot overflow the client's send buffer.
+	// Live events sent meanwhile carry higher sequence numbers; clients drop duplicates by seq.
+	h.sendToClient(client, &ChatMessage{
+		Type: "sync.replay",
+		Payload: map[string]interface{}{
+			"events": events,
+			"seq":    eventLog.CurrentSeq,
+		},
+	})
+
+	log.Printf("🔄 Replayed %d chat event(s) to user %s after seq %d", len(events), client.UserID, lastSeq)
+}
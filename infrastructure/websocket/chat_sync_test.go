@@ -0,0 +1,69 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"gofiber-template/infrastructure/redis"
+)
+
+func newTestChatHub(t *testing.T) *ChatHub {
+	t.Helper()
+	server := miniredis.RunT(t)
+	hub := NewChatHub(nil, nil, nil, redis.NewRedisService(redis.NewRedisClient(redis.RedisConfig{
+		Host: server.Host(),
+		Port: server.Port(),
+	})), nil, nil, nil)
+	t.Cleanup(hub.cancel)
+	return hub
+}
+
+func nextFrame(t *testing.T, client *ChatClient) ChatMessage {
+	t.Helper()
+	select {
+	case data := <-client.Send:
+		var message ChatMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			t.Fatalf("decode frame: %v", err)
+		}
+		return message
+	default:
+		t.Fatal("no frame queued for client")
+		return ChatMessage{}
+	}
+}
+
+func TestOwnActionAcksAreReplayedOnSync(t *testing.T) {
+	hub := newTestChatHub(t)
+	client := &ChatClient{UserID: uuid.New(), Send: make(chan []byte, 8)}
+
+	hub.sendEventToClient(client, &ChatMessage{Type: "message.sent", Payload: map[string]interface{}{"tempId": "t1"}})
+	hub.sendToClient(client, &ChatMessage{Type: "pong"})
+
+	ack := nextFrame(t, client)
+	if ack.Type != "message.sent" || ack.Seq != 1 {
+		t.Fatalf("ack = %s seq %d, want message.sent seq 1", ack.Type, ack.Seq)
+	}
+	if pong := nextFrame(t, client); pong.Seq != 0 {
+		t.Fatalf("pong seq = %d, want none", pong.Seq)
+	}
+
+	// A second client of the same user that missed the ack catches up from seq 0
+	hub.handleSync(context.Background(), client, &ChatMessage{Type: "sync", Payload: map[string]interface{}{"lastSeq": float64(0)}})
+
+	replay := nextFrame(t, client)
+	if replay.Type != "sync.replay" {
+		t.Fatalf("type = %s, want sync.replay", replay.Type)
+	}
+	events, _ := replay.Payload["events"].([]interface{})
+	if len(events) != 1 {
+		t.Fatalf("replayed %d events, want 1", len(events))
+	}
+	event := events[0].(map[string]interface{})
+	if event["type"] != "message.sent" || event["seq"] != float64(1) {
+		t.Fatalf("replayed %v, want message.sent seq 1", event)
+	}
+}
@@ -1,14 +1,19 @@
 package websocket
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"sync"
+	"time"
 	"github.com/gofiber/websocket/v2"
 	"github.com/google/uuid"
 )
 
+// clusterChannel is the Pub/Sub channel shared by all API instances
+const clusterChannel = "ws:broadcast"
+
 type WebSocketManager struct {
 	clients    map[*websocket.Conn]Client
 	rooms      map[string]map[*websocket.Conn]bool
@@ -16,6 +21,26 @@ type WebSocketManager struct {
 	unregister chan *websocket.Conn
 	broadcast  chan BroadcastMessage
 	mutex      sync.RWMutex
+
+	// Cross-instance fan-out (nil broker = single instance)
+	nodeID       string
+	broker       Broker
+	publishQueue chan BroadcastMessage
+	brokerCancel context.CancelFunc
+}
+
+// Broker relays broadcasts between API instances (implemented by RedisService)
+type Broker interface {
+	Publish(ctx context.Context, channel string, payload []byte) error
+	Subscribe(ctx context.Context, channel string) (<-chan []byte, func() error)
+}
+
+// clusterEnvelope is a broadcast as it travels between instances
+type clusterEnvelope struct {
+	Origin  string     `json:"origin"`
+	Message Message    `json:"message"`
+	RoomID  string     `json:"roomId,omitempty"`
+	UserID  *uuid.UUID `json:"userId,omitempty"`
 }
 
 type Client struct {
@@ -46,10 +71,129 @@ func init() {
 		register:   make(chan Client),
 		unregister: make(chan *websocket.Conn),
 		broadcast:  make(chan BroadcastMessage),
+		nodeID:     uuid.New().String(),
 	}
 	go Manager.run()
 }
 
+// UseBroker enables cross-instance delivery: broadcasts are delivered locally and
+// published to the broker, and broadcasts from other instances are delivered here
+func (m *WebSocketManager) UseBroker(broker Broker) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.broker != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.broker = broker
+	m.brokerCancel = cancel
+	m.publishQueue = make(chan BroadcastMessage, 256)
+
+	// The loops get the broker and queue rather than reading the fields, which
+	// StopBroker clears while they may still be running
+	go m.publishLoop(ctx, broker, m.publishQueue)
+	go m.subscribeLoop(ctx, broker)
+
+	log.Printf("WebSocket cluster fan-out enabled (node %s)", m.nodeID)
+}
+
+// StopBroker stops cross-instance delivery
+func (m *WebSocketManager) StopBroker() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.brokerCancel != nil {
+		m.brokerCancel()
+		m.brokerCancel = nil
+	}
+	m.broker = nil
+}
+
+// dispatch delivers a broadcast to local clients and queues it for other instances
+func (m *WebSocketManager) dispatch(message BroadcastMessage) {
+	m.broadcast <- message
+
+	m.mutex.RLock()
+	queue := m.publishQueue
+	hasBroker := m.broker != nil
+	m.mutex.RUnlock()
+
+	if !hasBroker {
+		return
+	}
+
+	select {
+	case queue <- message:
+	default:
+		log.Printf("⚠️  WebSocket publish queue full, dropping cross-instance broadcast: %s", message.Message.Type)
+	}
+}
+
+// publishLoop publishes queued broadcasts in order
+func (m *WebSocketManager) publishLoop(ctx context.Context, broker Broker, queue <-chan BroadcastMessage) {
+	for {
+		select {
+		case message := <-queue:
+			payload, err := json.Marshal(clusterEnvelope{
+				Origin:  m.nodeID,
+				Message: message.Message,
+				RoomID:  message.RoomID,
+				UserID:  message.UserID,
+			})
+			if err != nil {
+				log.Printf("Error marshaling cluster broadcast: %v", err)
+				continue
+			}
+
+			publishCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+			if err := broker.Publish(publishCtx, clusterChannel, payload); err != nil {
+				log.Printf("⚠️  Failed to publish cluster broadcast: %v", err)
+			}
+			cancel()
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// subscribeLoop delivers broadcasts published by other instances
+func (m *WebSocketManager) subscribeLoop(ctx context.Context, broker Broker) {
+	messages, closeSub := broker.Subscribe(ctx, clusterChannel)
+	defer closeSub()
+
+	for {
+		select {
+		case payload, ok := <-messages:
+			if !ok {
+				return
+			}
+
+			var envelope clusterEnvelope
+			if err := json.Unmarshal(payload, &envelope); err != nil {
+				log.Printf("Error unmarshaling cluster broadcast: %v", err)
+				continue
+			}
+
+			// Our own broadcasts were already delivered locally
+			if envelope.Origin == m.nodeID {
+				continue
+			}
+
+			m.broadcast <- BroadcastMessage{
+				Message: envelope.Message,
+				RoomID:  envelope.RoomID,
+				UserID:  envelope.UserID,
+			}
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 func (m *WebSocketManager) run() {
 	for {
 		select {
@@ -139,7 +283,7 @@ func (m *WebSocketManager) BroadcastToRoom(roomID string, messageType string, da
 		RoomID:  roomID,
 	}
 
-	m.broadcast <- broadcast
+	m.dispatch(broadcast)
 }
 
 func (m *WebSocketManager) BroadcastToUser(userID uuid.UUID, messageType string, data interface{}) {
@@ -153,7 +297,7 @@ func (m *WebSocketManager) BroadcastToUser(userID uuid.UUID, messageType string,
 		UserID:  &userID,
 	}
 
-	m.broadcast <- broadcast
+	m.dispatch(broadcast)
 }
 
 func (m *WebSocketManager) BroadcastToAll(messageType string, data interface{}) {
@@ -166,7 +310,7 @@ func (m *WebSocketManager) BroadcastToAll(messageType string, data interface{})
 		Message: message,
 	}
 
-	m.broadcast <- broadcast
+	m.dispatch(broadcast)
 }
 
 func (m *WebSocketManager) GetRoomClients(roomID string) int {
@@ -0,0 +1,137 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"gofiber-template/infrastructure/redis"
+)
+
+// newTestNode returns a manager for one API instance without its run loop; broadcasts
+// it would deliver to local clients are left in its broadcast channel
+func newTestNode(t *testing.T, server *miniredis.Miniredis) *WebSocketManager {
+	t.Helper()
+
+	node := &WebSocketManager{
+		broadcast: make(chan BroadcastMessage, 16),
+		nodeID:    uuid.New().String(),
+	}
+	node.UseBroker(redis.NewRedisService(redis.NewRedisClient(redis.RedisConfig{
+		Host: server.Host(),
+		Port: server.Port(),
+	})))
+	t.Cleanup(node.StopBroker)
+	return node
+}
+
+// waitForSubscribers waits until n nodes listen on the cluster channel, as messages
+// published earlier are not delivered
+func waitForSubscribers(t *testing.T, server *miniredis.Miniredis, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for server.PubSubNumSub(clusterChannel)[clusterChannel] < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %d subscribers", n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func receive(t *testing.T, node *WebSocketManager) BroadcastMessage {
+	t.Helper()
+	select {
+	case message := <-node.broadcast:
+		return message
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a broadcast")
+		return BroadcastMessage{}
+	}
+}
+
+func TestBroadcastReachesOtherInstances(t *testing.T) {
+	server := miniredis.RunT(t)
+	sender := newTestNode(t, server)
+	receiver := newTestNode(t, server)
+	waitForSubscribers(t, server, 2)
+
+	userID := uuid.New()
+	sender.dispatch(BroadcastMessage{
+		Message: Message{Type: "notification", Data: map[string]interface{}{"id": "n1"}},
+		RoomID:  "room-1",
+		UserID:  &userID,
+	})
+
+	if local := receive(t, sender); local.Message.Type != "notification" {
+		t.Fatalf("expected local delivery, got %+v", local)
+	}
+
+	remote := receive(t, receiver)
+	if remote.Message.Type != "notification" || remote.RoomID != "room-1" || remote.UserID == nil || *remote.UserID != userID {
+		t.Fatalf("unexpected cross-instance broadcast %+v", remote)
+	}
+	if data, _ := remote.Message.Data.(map[string]interface{}); data["id"] != "n1" {
+		t.Fatalf("unexpected data %+v", remote.Message.Data)
+	}
+}
+
+func TestInstanceSkipsItsOwnBroadcasts(t *testing.T) {
+	server := miniredis.RunT(t)
+	first := newTestNode(t, server)
+	second := newTestNode(t, server)
+	waitForSubscribers(t, server, 2)
+
+	first.dispatch(BroadcastMessage{Message: Message{Type: "own"}})
+	if local := receive(t, first); local.Message.Type != "own" {
+		t.Fatalf("expected local delivery, got %+v", local)
+	}
+	receive(t, second)
+
+	// Pub/Sub keeps order, so if the echo of "own" weren't skipped it would arrive
+	// before "other"
+	second.dispatch(BroadcastMessage{Message: Message{Type: "other"}})
+	receive(t, second)
+
+	if next := receive(t, first); next.Message.Type != "other" {
+		t.Fatalf("instance redelivered its own broadcast: %+v", next)
+	}
+}
+
+// blockingBroker holds every Publish until release is closed
+type blockingBroker struct {
+	publishing chan struct{}
+	release    chan struct{}
+}
+
+func (b *blockingBroker) Publish(ctx context.Context, channel string, payload []byte) error {
+	b.publishing <- struct{}{}
+	<-b.release
+	return nil
+}
+
+func (b *blockingBroker) Subscribe(ctx context.Context, channel string) (<-chan []byte, func() error) {
+	return make(chan []byte), func() error { return nil }
+}
+
+func TestStopBrokerWithQueuedBroadcasts(t *testing.T) {
+	broker := &blockingBroker{publishing: make(chan struct{}, 16), release: make(chan struct{})}
+	node := &WebSocketManager{
+		broadcast: make(chan BroadcastMessage, 16),
+		nodeID:    uuid.New().String(),
+	}
+	node.UseBroker(broker)
+
+	for i := 0; i < 10; i++ {
+		node.dispatch(BroadcastMessage{Message: Message{Type: "queued"}})
+	}
+
+	// Stop while one broadcast is being published and the rest are still queued
+	<-broker.publishing
+	node.StopBroker()
+	close(broker.release)
+
+	// Give the publish loop time to finish the queue or notice the stop
+	time.Sleep(50 * time.Millisecond)
+}
@@ -0,0 +1,294 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gofiber-template/domain/dto"
+	"gofiber-template/domain/models"
+	"gofiber-template/domain/repositories"
+	"gofiber-template/domain/services"
+	"gofiber-template/infrastructure/websocket"
+	"gofiber-template/pkg/mailer"
+)
+
+const (
+	outboxPollInterval    = 2 * time.Second  // Fallback poll when no wake-up arrives
+	outboxBatchSize       = 50               // Entries claimed per iteration
+	outboxLease           = 1 * time.Minute  // How long a claimed entry stays locked
+	outboxLeaseMargin     = 10 * time.Second // No delivery is started this close to the end of the lease
+	outboxRetryBaseDelay  = 5 * time.Second  // First retry delay, doubled per attempt
+	outboxRetryMaxDelay   = 30 * time.Minute // Upper bound for the backoff
+	outboxCleanupInterval = 1 * time.Hour
+	outboxRetention       = 7 * 24 * time.Hour // Delivered entries are kept for a week
+)
+
+// errPermanentDelivery marks failures that retrying cannot fix
+var errPermanentDelivery = errors.New("permanent delivery failure")
+
+// NotificationOutboxWorker delivers queued notifications to WebSocket, Web Push and email
+type NotificationOutboxWorker struct {
+	outboxRepo  repositories.NotificationOutboxRepository
+	notifRepo   repositories.NotificationRepository
+	pushService services.PushService
+	mailer      mailer.Mailer
+	frontendURL string
+	running     bool
+	stopChan    chan struct{}
+	wakeChan    chan struct{}
+}
+
+func NewNotificationOutboxWorker(
+	outboxRepo repositories.NotificationOutboxRepository,
+	notifRepo repositories.NotificationRepository,
+	pushService services.PushService,
+	mailer mailer.Mailer,
+	frontendURL string,
+) *NotificationOutboxWorker {
+	return &NotificationOutboxWorker{
+		outboxRepo:  outboxRepo,
+		notifRepo:   notifRepo,
+		pushService: pushService,
+		mailer:      mailer,
+		frontendURL: frontendURL,
+		stopChan:    make(chan struct{}),
+		wakeChan:    make(chan struct{}, 1),
+	}
+}
+
+// Start begins the worker's processing loop
+func (w *NotificationOutboxWorker) Start() {
+	if w.running {
+		log.Println("⚠️  NotificationOutboxWorker is already running")
+		return
+	}
+
+	w.running = true
+	log.Println("📬 NotificationOutboxWorker started")
+
+	go w.processLoop()
+}
+
+// Stop gracefully stops the worker
+func (w *NotificationOutboxWorker) Stop() {
+	if !w.running {
+		return
+	}
+
+	log.Println("🛑 Stopping NotificationOutboxWorker...")
+	w.running = false
+	close(w.stopChan)
+}
+
+// Wake asks the worker to process the outbox now instead of waiting for the next tick
+func (w *NotificationOutboxWorker) Wake() {
+	select {
+	case w.wakeChan <- struct{}{}:
+	default:
+		// A wake-up is already pending
+	}
+}
+
+// processLoop is the main worker loop
+func (w *NotificationOutboxWorker) processLoop() {
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+
+	cleanupTicker := time.NewTicker(outboxCleanupInterval)
+	defer cleanupTicker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.processBatch()
+		case <-w.wakeChan:
+			w.processBatch()
+		case <-cleanupTicker.C:
+			w.cleanup()
+		case <-w.stopChan:
+			log.Println("✓ NotificationOutboxWorker stopped")
+			return
+		}
+	}
+}
+
+// processBatch claims due entries and delivers them
+func (w *NotificationOutboxWorker) processBatch() {
+	ctx := context.Background()
+
+	// Once the lease runs out another worker may claim the same entries, so
+	// deliveries must finish well before it does
+	deadline := time.Now().Add(outboxLease - outboxLeaseMargin)
+
+	entries, err := w.outboxRepo.ClaimDue(ctx, outboxBatchSize, outboxLease)
+	if err != nil {
+		log.Printf("❌ Failed to claim outbox entries: %v", err)
+		return
+	}
+
+	for i, entry := range entries {
+		if time.Now().After(deadline) {
+			// Left to be claimed again when their lease expires
+			log.Printf("⚠️  Outbox batch ran out of lease time, %d entries postponed", len(entries)-i)
+			return
+		}
+		w.processEntry(ctx, entry, deadline)
+	}
+}
+
+// processEntry delivers a single entry and records the result
+func (w *NotificationOutboxWorker) processEntry(ctx context.Context, entry *models.NotificationOutbox, deadline time.Time) {
+	if entry.LeaseID == nil {
+		log.Printf("❌ Outbox entry %s was claimed without a lease", entry.ID)
+		return
+	}
+	leaseID := *entry.LeaseID
+	attempts := entry.Attempts + 1
+
+	deliverCtx, cancel := context.WithDeadline(ctx, deadline)
+	err := w.deliver(deliverCtx, entry)
+	cancel()
+
+	if err == nil {
+		w.recordResult(entry, "mark delivered", w.outboxRepo.MarkDelivered(ctx, entry.ID, leaseID, attempts))
+		return
+	}
+
+	if errors.Is(err, errPermanentDelivery) || attempts >= entry.MaxAttempts {
+		log.Printf("❌ Notification %s delivery via %s failed after %d attempt(s): %v", entry.ID, entry.Channel, attempts, err)
+		w.recordResult(entry, "mark failed", w.outboxRepo.MarkFailed(ctx, entry.ID, leaseID, attempts, err.Error()))
+		return
+	}
+
+	nextAttemptAt := time.Now().Add(retryDelay(attempts))
+	log.Printf("⚠️  Notification %s delivery via %s failed (attempt %d), retrying at %s: %v",
+		entry.ID, entry.Channel, attempts, nextAttemptAt.Format(time.RFC3339), err)
+	w.recordResult(entry, "schedule retry for", w.outboxRepo.ScheduleRetry(ctx, entry.ID, leaseID, attempts, nextAttemptAt, err.Error()))
+}
+
+// recordResult logs a failure to store a delivery result
+func (w *NotificationOutboxWorker) recordResult(entry *models.NotificationOutbox, action string, err error) {
+	switch {
+	case err == nil:
+	case errors.Is(err, repositories.ErrOutboxLeaseLost):
+		log.Printf("⚠️  Lease on outbox entry %s expired during delivery, result discarded", entry.ID)
+	default:
+		log.Printf("❌ Failed to %s outbox entry %s: %v", action, entry.ID, err)
+	}
+}
+
+// deliver dispatches an entry to its channel
+func (w *NotificationOutboxWorker) deliver(ctx context.Context, entry *models.NotificationOutbox) error {
+	switch entry.Channel {
+	case models.NotificationChannelWebSocket:
+		return w.deliverWebSocket(ctx, entry)
+	case models.NotificationChannelPush:
+		return w.deliverPush(ctx, entry)
+	case models.NotificationChannelEmail:
+		return w.deliverEmail(ctx, entry)
+	default:
+		return fmt.Errorf("%w: unknown channel %q", errPermanentDelivery, entry.Channel)
+	}
+}
+
+func (w *NotificationOutboxWorker) deliverWebSocket(ctx context.Context, entry *models.NotificationOutbox) error {
+	notification, err := w.loadNotification(ctx, entry)
+	if err != nil {
+		return err
+	}
+
+	unreadCount, err := w.notifRepo.CountUnreadByUser(ctx, entry.UserID)
+	if err != nil {
+		return err
+	}
+
+	websocket.Manager.BroadcastToUser(entry.UserID, "notification", map[string]interface{}{
+		"notification": dto.NotificationToNotificationResponse(notification),
+		"unreadCount":  unreadCount,
+	})
+
+	log.Printf("📬 Real-time notification sent to user %s: %s", entry.UserID.String(), notification.Message)
+	return nil
+}
+
+func (w *NotificationOutboxWorker) deliverPush(ctx context.Context, entry *models.NotificationOutbox) error {
+	if w.pushService == nil {
+		return fmt.Errorf("%w: push service not configured", errPermanentDelivery)
+	}
+
+	var payload dto.PushNotificationPayload
+	if err := json.Unmarshal(entry.Payload, &payload); err != nil {
+		return fmt.Errorf("%w: invalid push payload: %v", errPermanentDelivery, err)
+	}
+
+	return w.pushService.SendToUser(ctx, entry.UserID, &payload)
+}
+
+func (w *NotificationOutboxWorker) deliverEmail(ctx context.Context, entry *models.NotificationOutbox) error {
+	if w.mailer == nil {
+		return fmt.Errorf("%w: mailer not configured", errPermanentDelivery)
+	}
+
+	var payload struct {
+		To      string `json:"to"`
+		Subject string `json:"subject"`
+		Body    string `json:"body"`
+		URL     string `json:"url"`
+	}
+	if err := json.Unmarshal(entry.Payload, &payload); err != nil || payload.To == "" {
+		return fmt.Errorf("%w: invalid email payload", errPermanentDelivery)
+	}
+
+	return w.mailer.Send(ctx, &mailer.Message{
+		To:      payload.To,
+		Subject: payload.Subject,
+		Body:    fmt.Sprintf("%s\n\n%s%s", payload.Body, w.frontendURL, payload.URL),
+	})
+}
+
+// loadNotification fetches the notification an entry refers to
+func (w *NotificationOutboxWorker) loadNotification(ctx context.Context, entry *models.NotificationOutbox) (*models.Notification, error) {
+	if entry.NotificationID == nil {
+		return nil, fmt.Errorf("%w: entry has no notification", errPermanentDelivery)
+	}
+
+	notification, err := w.notifRepo.GetByID(ctx, *entry.NotificationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Deleted by the user before delivery
+			return nil, fmt.Errorf("%w: notification deleted", errPermanentDelivery)
+		}
+		return nil, err
+	}
+
+	return notification, nil
+}
+
+// cleanup removes delivered entries past the retention window
+func (w *NotificationOutboxWorker) cleanup() {
+	deleted, err := w.outboxRepo.DeleteDeliveredBefore(context.Background(), time.Now().Add(-outboxRetention))
+	if err != nil {
+		log.Printf("❌ Failed to clean up notification outbox: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("🗑️  Removed %d delivered outbox entries", deleted)
+	}
+}
+
+// retryDelay returns an exponential backoff for the given attempt number
+func retryDelay(attempts int) time.Duration {
+	delay := outboxRetryBaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= outboxRetryMaxDelay {
+			return outboxRetryMaxDelay
+		}
+	}
+	return delay
+}
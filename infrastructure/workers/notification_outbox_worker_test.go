@@ -0,0 +1,63 @@
+package workers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gofiber-template/domain/models"
+	"gofiber-template/domain/repositories"
+)
+
+type failedCall struct {
+	id      uuid.UUID
+	leaseID uuid.UUID
+}
+
+// leasedOutboxRepo hands out a fixed batch and records failures; entries in lost
+// have been claimed again by another worker
+type leasedOutboxRepo struct {
+	repositories.NotificationOutboxRepository
+	batch  []*models.NotificationOutbox
+	lost   map[uuid.UUID]bool
+	failed []failedCall
+}
+
+func (r *leasedOutboxRepo) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*models.NotificationOutbox, error) {
+	batch := r.batch
+	r.batch = nil
+	return batch, nil
+}
+
+func (r *leasedOutboxRepo) MarkFailed(ctx context.Context, id uuid.UUID, leaseID uuid.UUID, attempts int, lastError string) error {
+	r.failed = append(r.failed, failedCall{id: id, leaseID: leaseID})
+	if r.lost[id] {
+		return repositories.ErrOutboxLeaseLost
+	}
+	return nil
+}
+
+func TestProcessBatchRecordsResultsUnderTheClaimLease(t *testing.T) {
+	leaseID := uuid.New()
+	kept := &models.NotificationOutbox{ID: uuid.New(), Channel: "pager", MaxAttempts: 5, LeaseID: &leaseID}
+	reclaimed := &models.NotificationOutbox{ID: uuid.New(), Channel: "pager", MaxAttempts: 5, LeaseID: &leaseID}
+	unleased := &models.NotificationOutbox{ID: uuid.New(), Channel: "pager", MaxAttempts: 5}
+
+	repo := &leasedOutboxRepo{
+		batch: []*models.NotificationOutbox{kept, reclaimed, unleased},
+		lost:  map[uuid.UUID]bool{reclaimed.ID: true},
+	}
+	worker := NewNotificationOutboxWorker(repo, nil, nil, nil, "")
+
+	worker.processBatch()
+
+	if len(repo.failed) != 2 {
+		t.Fatalf("expected results for the two leased entries, got %+v", repo.failed)
+	}
+	for i, entry := range []*models.NotificationOutbox{kept, reclaimed} {
+		if repo.failed[i].id != entry.ID || repo.failed[i].leaseID != leaseID {
+			t.Errorf("entry %d: expected %s under lease %s, got %+v", i, entry.ID, leaseID, repo.failed[i])
+		}
+	}
+}
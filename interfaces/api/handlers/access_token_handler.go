@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gofiber-template/domain/dto"
+	"gofiber-template/domain/services"
+	"gofiber-template/pkg/utils"
+)
+
+type AccessTokenHandler struct {
+	accessTokenService services.AccessTokenService
+}
+
+func NewAccessTokenHandler(accessTokenService services.AccessTokenService) *AccessTokenHandler {
+	return &AccessTokenHandler{
+		accessTokenService: accessTokenService,
+	}
+}
+
+// CreateToken issues a personal access token. The token is only returned in this response.
+// POST /users/tokens
+func (h *AccessTokenHandler) CreateToken(c *fiber.Ctx) error {
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	var req dto.CreateAccessTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid request body")
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		errors := utils.GetValidationErrors(err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Validation failed",
+			"errors":  errors,
+		})
+	}
+
+	token, err := h.accessTokenService.CreateToken(c.Context(), user.ID, user.SessionID, user.MFAVerified, &req)
+	if err != nil {
+		return accountChangeError(c, "Failed to create access token", err)
+	}
+
+	return utils.SuccessResponse(c, "Access token created successfully", token)
+}
+
+// ListTokens returns the current user's active personal access tokens
+// GET /users/tokens
+func (h *AccessTokenHandler) ListTokens(c *fiber.Ctx) error {
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	tokens, err := h.accessTokenService.ListTokens(c.Context(), user.ID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve access tokens", err)
+	}
+
+	return utils.SuccessResponse(c, "Access tokens retrieved successfully", tokens)
+}
+
+// RevokeToken stops one of the current user's personal access tokens from working
+// DELETE /users/tokens/:tokenId
+func (h *AccessTokenHandler) RevokeToken(c *fiber.Ctx) error {
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	tokenID, err := uuid.Parse(c.Params("tokenId"))
+	if err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid token ID")
+	}
+
+	if err := h.accessTokenService.RevokeToken(c.Context(), user.ID, tokenID); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, "Failed to revoke access token", err)
+	}
+
+	return utils.SuccessResponse(c, "Access token revoked successfully", nil)
+}
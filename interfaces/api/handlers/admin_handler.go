@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gofiber-template/domain/dto"
+	"gofiber-template/domain/models"
+	"gofiber-template/domain/services"
+	"gofiber-template/pkg/utils"
+)
+
+type AdminHandler struct {
+	userService services.UserService
+}
+
+func NewAdminHandler(userService services.UserService) *AdminHandler {
+	return &AdminHandler{
+		userService: userService,
+	}
+}
+
+// ListRoles returns every role with the permissions it grants
+// GET /admin/roles
+func (h *AdminHandler) ListRoles(c *fiber.Ctx) error {
+	roles := make([]dto.RoleResponse, len(models.Roles))
+	for i, role := range models.Roles {
+		roles[i] = dto.RoleResponse{
+			Name:        role,
+			Permissions: models.RolePermissions(role),
+		}
+	}
+
+	return utils.SuccessResponse(c, "Roles retrieved successfully", roles)
+}
+
+// AssignRole changes a user's role and signs them out everywhere
+// PUT /admin/users/:id/role
+func (h *AdminHandler) AssignRole(c *fiber.Ctx) error {
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid user ID")
+	}
+
+	var req dto.AssignRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid request body")
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		errors := utils.GetValidationErrors(err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Validation failed",
+			"errors":  errors,
+		})
+	}
+
+	updated, err := h.userService.AssignRole(c.Context(), requestActor(user), userID, req.Role)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to assign role", err)
+	}
+
+	return utils.SuccessResponse(c, "Role assigned successfully", dto.UserToUserResponse(updated))
+}
+
+// BanUser deactivates an account and signs it out everywhere
+// POST /admin/users/:id/ban
+func (h *AdminHandler) BanUser(c *fiber.Ctx) error {
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid user ID")
+	}
+
+	if err := h.userService.BanUser(c.Context(), requestActor(user), userID); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to ban user", err)
+	}
+
+	return utils.SuccessResponse(c, "User banned successfully", nil)
+}
+
+// UnbanUser lets a banned account sign in again
+// DELETE /admin/users/:id/ban
+func (h *AdminHandler) UnbanUser(c *fiber.Ctx) error {
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid user ID")
+	}
+
+	if err := h.userService.UnbanUser(c.Context(), requestActor(user), userID); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to unban user", err)
+	}
+
+	return utils.SuccessResponse(c, "User unbanned successfully", nil)
+}
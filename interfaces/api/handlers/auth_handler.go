@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gofiber-template/domain/dto"
+	"gofiber-template/domain/models"
+	"gofiber-template/domain/services"
+	"gofiber-template/pkg/utils"
+)
+
+type AuthHandler struct {
+	authService    services.AuthService
+	accountService services.AccountService
+}
+
+func NewAuthHandler(authService services.AuthService, accountService services.AccountService) *AuthHandler {
+	return &AuthHandler{
+		authService:    authService,
+		accountService: accountService,
+	}
+}
+
+// Refresh exchanges a refresh token for a new access token and a rotated refresh token
+// POST /auth/refresh
+func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+	var req dto.RefreshTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid request body")
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		errors := utils.GetValidationErrors(err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Validation failed",
+			"errors":  errors,
+		})
+	}
+
+	tokens, err := h.authService.Refresh(c.Context(), req.RefreshToken, clientInfo(c))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, "Token refresh failed", err)
+	}
+
+	return utils.SuccessResponse(c, "Token refreshed successfully", tokens)
+}
+
+// Logout revokes the session of the given refresh token, including its access tokens
+// POST /auth/logout
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	var req dto.LogoutRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid request body")
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		errors := utils.GetValidationErrors(err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Validation failed",
+			"errors":  errors,
+		})
+	}
+
+	if err := h.authService.Logout(c.Context(), req.RefreshToken); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, "Logout failed", err)
+	}
+
+	return utils.SuccessResponse(c, "Logged out successfully", nil)
+}
+
+// SendVerificationEmail emails the current user a new verification link
+// POST /auth/verify-email/resend
+func (h *AuthHandler) SendVerificationEmail(c *fiber.Ctx) error {
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	if err := h.accountService.SendVerificationEmail(c.Context(), user.ID); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to send verification email", err)
+	}
+
+	return utils.SuccessResponse(c, "Verification email sent successfully", nil)
+}
+
+// VerifyEmail confirms an email address. Clients should refresh their tokens
+// afterwards so the verified status reaches the access token.
+// POST /auth/verify-email
+func (h *AuthHandler) VerifyEmail(c *fiber.Ctx) error {
+	var req dto.VerifyEmailRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid request body")
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		errors := utils.GetValidationErrors(err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Validation failed",
+			"errors":  errors,
+		})
+	}
+
+	if err := h.accountService.VerifyEmail(c.Context(), req.Token); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Email verification failed", err)
+	}
+
+	return utils.SuccessResponse(c, "Email verified successfully", nil)
+}
+
+// ForgotPassword emails a password reset link if the address has an account
+// POST /auth/forgot-password
+func (h *AuthHandler) ForgotPassword(c *fiber.Ctx) error {
+	var req dto.ForgotPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid request body")
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		errors := utils.GetValidationErrors(err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Validation failed",
+			"errors":  errors,
+		})
+	}
+
+	if err := h.accountService.RequestPasswordReset(c.Context(), req.Email); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to request password reset", err)
+	}
+
+	// Same answer whether or not the address is registered
+	return utils.SuccessResponse(c, "If the email is registered, a reset link has been sent", nil)
+}
+
+// ResetPassword sets a new password from a reset link and signs out every session
+// POST /auth/reset-password
+func (h *AuthHandler) ResetPassword(c *fiber.Ctx) error {
+	var req dto.ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid request body")
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		errors := utils.GetValidationErrors(err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Validation failed",
+			"errors":  errors,
+		})
+	}
+
+	if err := h.accountService.ResetPassword(c.Context(), &req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Password reset failed", err)
+	}
+
+	return utils.SuccessResponse(c, "Password reset successfully", nil)
+}
+
+// ListSessions returns the devices the current user is signed in on
+// GET /users/sessions
+func (h *AuthHandler) ListSessions(c *fiber.Ctx) error {
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	sessions, err := h.authService.ListSessions(c.Context(), user.ID, user.SessionID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve sessions", err)
+	}
+
+	return utils.SuccessResponse(c, "Sessions retrieved successfully", sessions)
+}
+
+// RevokeSession signs out one of the current user's devices
+// DELETE /users/sessions/:sessionId
+func (h *AuthHandler) RevokeSession(c *fiber.Ctx) error {
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	sessionID, err := uuid.Parse(c.Params("sessionId"))
+	if err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid session ID")
+	}
+
+	if err := h.authService.RevokeSession(c.Context(), user.ID, sessionID); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, "Failed to revoke session", err)
+	}
+
+	return utils.SuccessResponse(c, "Session revoked successfully", nil)
+}
+
+// RevokeOtherSessions signs out every device except the one making the request
+// DELETE /users/sessions
+func (h *AuthHandler) RevokeOtherSessions(c *fiber.Ctx) error {
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	count, err := h.authService.RevokeOtherSessions(c.Context(), user.ID, user.SessionID, models.SessionRevokeReasonUser)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to revoke sessions", err)
+	}
+
+	return utils.SuccessResponse(c, "Sessions revoked successfully", dto.RevokeSessionsResponse{
+		RevokedCount: count,
+	})
+}
+
+// clientInfo describes the device making the request, recorded with new sessions
+func clientInfo(c *fiber.Ctx) *dto.ClientInfo {
+	return &dto.ClientInfo{
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		IPAddress: c.IP(),
+	}
+}
+
+// requestActor describes the authenticated user for service-level permission checks
+func requestActor(user *utils.UserContext) *dto.Actor {
+	return &dto.Actor{
+		UserID:      user.ID,
+		Permissions: user.Permissions(),
+	}
+}
+
+// accountChangeError responds to a failed sensitive account change, telling the client
+// to confirm the user's identity (POST /users/reauth) when that is what's missing
+func accountChangeError(c *fiber.Ctx, message string, err error) error {
+	if errors.Is(err, services.ErrReauthenticationRequired) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"message": "Please confirm your identity to continue",
+			"error":   "reauth_required",
+		})
+	}
+	return utils.ErrorResponse(c, fiber.StatusBadRequest, message, err)
+}
+
+// loginFailureStatus maps a failed sign-in to 429 with Retry-After while the account
+// or IP address is throttled, and 401 otherwise
+func loginFailureStatus(c *fiber.Ctx, err error) int {
+	var throttled *services.LoginThrottledError
+	if !errors.As(err, &throttled) {
+		return fiber.StatusUnauthorized
+	}
+
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+	return fiber.StatusTooManyRequests
+}
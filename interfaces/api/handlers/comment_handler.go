@@ -101,14 +101,17 @@ func (h *CommentHandler) UpdateComment(c *fiber.Ctx) error {
 
 // DeleteComment deletes a comment
 func (h *CommentHandler) DeleteComment(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uuid.UUID)
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "User not authenticated")
+	}
 
 	commentID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		return utils.ValidationErrorResponse(c, "Invalid comment ID")
 	}
 
-	err = h.commentService.DeleteComment(c.Context(), commentID, userID)
+	err = h.commentService.DeleteComment(c.Context(), commentID, requestActor(user))
 	if err != nil {
 		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to delete comment", err)
 	}
@@ -3,10 +3,10 @@ package handlers
 import (
 	"log"
 	"strconv"
-	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"gofiber-template/domain/dto"
 	"gofiber-template/domain/repositories"
 	"gofiber-template/domain/services"
 	chatWebsocket "gofiber-template/infrastructure/websocket"
@@ -89,7 +89,7 @@ func (h *ConversationHandler) GetUnreadCount(c *fiber.Ctx) error {
 	return utils.SuccessResponse(c, "Unread count retrieved successfully", unreadCount)
 }
 
-// MarkAsRead marks all messages in a conversation as read
+// MarkAsRead marks messages in a conversation as read, up to lastReadMessageId when given
 // POST /conversations/:conversationId/read
 func (h *ConversationHandler) MarkAsRead(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uuid.UUID)
@@ -99,50 +99,592 @@ func (h *ConversationHandler) MarkAsRead(c *fiber.Ctx) error {
 		return utils.ValidationErrorResponse(c, "Invalid conversation ID")
 	}
 
+	// Body is optional
+	var req dto.MarkConversationReadRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return utils.ValidationErrorResponse(c, "Invalid request body")
+		}
+	}
+
 	// Mark as read in database
-	if err := h.conversationService.MarkAsRead(c.Context(), conversationID, userID); err != nil {
+	update, err := h.conversationService.MarkAsRead(c.Context(), conversationID, userID, req.LastReadMessageID)
+	if err != nil {
 		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to mark as read", err)
 	}
 
-	// Send WebSocket notification to the other user (sender)
-	h.sendReadNotification(c, conversationID, userID)
+	// Send WebSocket notification to the senders
+	h.sendReadNotification(update)
 
-	return utils.SuccessResponse(c, "Conversation marked as read", nil)
+	return utils.SuccessResponse(c, "Conversation marked as read", update)
 }
 
-// sendReadNotification sends WebSocket notification to sender when receiver reads messages
-func (h *ConversationHandler) sendReadNotification(c *fiber.Ctx, conversationID uuid.UUID, readerID uuid.UUID) {
+// sendReadNotification sends WebSocket notification to senders when a reader reads their messages
+func (h *ConversationHandler) sendReadNotification(update *dto.ReadReceiptUpdate) {
 	if h.chatHub == nil {
 		log.Printf("⚠️ ChatHub is nil, skipping read notification")
 		return
 	}
 
-	// Get conversation to find the other participant (sender)
-	conversation, err := h.conversationRepo.GetByID(c.Context(), conversationID)
+	h.chatHub.BroadcastReadReceipt(update)
+
+	log.Printf("📤 Read notification sent to %d sender(s) (conversation: %s, read by: %s)", len(update.NotifyUserIDs), update.ConversationID, update.ReadBy)
+}
+
+// GetChatSettings retrieves the caller's chat privacy settings
+// GET /chat/settings
+func (h *ConversationHandler) GetChatSettings(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	settings, err := h.conversationService.GetChatSettings(c.Context(), userID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve chat settings", err)
+	}
+
+	return utils.SuccessResponse(c, "Chat settings retrieved successfully", settings)
+}
+
+// UpdateChatSettings updates the caller's chat privacy settings
+// PUT /chat/settings
+func (h *ConversationHandler) UpdateChatSettings(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	var req dto.UpdateChatSettingsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid request body")
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		errors := utils.GetValidationErrors(err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Validation failed",
+			"errors":  errors,
+		})
+	}
+
+	settings, err := h.conversationService.UpdateChatSettings(c.Context(), userID, &req)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to update chat settings", err)
+	}
+
+	return utils.SuccessResponse(c, "Chat settings updated successfully", settings)
+}
+
+// ==================== Conversation Management ====================
+
+// ListArchivedConversations retrieves the current user's archived conversations
+// GET /conversations/archived?cursor=xxx&limit=20
+func (h *ConversationHandler) ListArchivedConversations(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	cursor := c.Query("cursor")
+	var cursorPtr *string
+	if cursor != "" {
+		cursorPtr = &cursor
+	}
+
+	limit := 20
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil {
+			if parsedLimit > 0 && parsedLimit <= 50 {
+				limit = parsedLimit
+			}
+		}
+	}
+
+	conversations, err := h.conversationService.ListArchivedConversations(c.Context(), userID, cursorPtr, limit)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve archived conversations", err)
+	}
+
+	return utils.SuccessResponse(c, "Archived conversations retrieved successfully", conversations)
+}
+
+// PinConversation pins a conversation to the top of the list
+// PUT /conversations/:conversationId/pin
+func (h *ConversationHandler) PinConversation(c *fiber.Ctx) error {
+	return h.setPinned(c, true)
+}
+
+// UnpinConversation removes a conversation's pin
+// DELETE /conversations/:conversationId/pin
+func (h *ConversationHandler) UnpinConversation(c *fiber.Ctx) error {
+	return h.setPinned(c, false)
+}
+
+func (h *ConversationHandler) setPinned(c *fiber.Ctx, pinned bool) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	conversationID, err := uuid.Parse(c.Params("conversationId"))
+	if err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid conversation ID")
+	}
+
+	conversation, err := h.conversationService.PinConversation(c.Context(), conversationID, userID, pinned)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to update pin", err)
+	}
+
+	return utils.SuccessResponse(c, "Conversation updated successfully", conversation)
+}
+
+// ArchiveConversation hides a conversation from the list until a new message arrives
+// PUT /conversations/:conversationId/archive
+func (h *ConversationHandler) ArchiveConversation(c *fiber.Ctx) error {
+	return h.setArchived(c, true)
+}
+
+// UnarchiveConversation moves a conversation back to the list
+// DELETE /conversations/:conversationId/archive
+func (h *ConversationHandler) UnarchiveConversation(c *fiber.Ctx) error {
+	return h.setArchived(c, false)
+}
+
+func (h *ConversationHandler) setArchived(c *fiber.Ctx, archived bool) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	conversationID, err := uuid.Parse(c.Params("conversationId"))
+	if err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid conversation ID")
+	}
+
+	conversation, err := h.conversationService.ArchiveConversation(c.Context(), conversationID, userID, archived)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to update archive", err)
+	}
+
+	return utils.SuccessResponse(c, "Conversation updated successfully", conversation)
+}
+
+// MuteConversation suppresses push notifications, optionally for durationMinutes
+// PUT /conversations/:conversationId/mute
+func (h *ConversationHandler) MuteConversation(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	conversationID, err := uuid.Parse(c.Params("conversationId"))
+	if err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid conversation ID")
+	}
+
+	// Body is optional (no duration = until unmuted)
+	var req dto.MuteConversationRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return utils.ValidationErrorResponse(c, "Invalid request body")
+		}
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		errors := utils.GetValidationErrors(err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Validation failed",
+			"errors":  errors,
+		})
+	}
+
+	conversation, err := h.conversationService.MuteConversation(c.Context(), conversationID, userID, &req)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to mute conversation", err)
+	}
+
+	return utils.SuccessResponse(c, "Conversation muted successfully", conversation)
+}
+
+// UnmuteConversation re-enables push notifications
+// DELETE /conversations/:conversationId/mute
+func (h *ConversationHandler) UnmuteConversation(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	conversationID, err := uuid.Parse(c.Params("conversationId"))
+	if err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid conversation ID")
+	}
+
+	conversation, err := h.conversationService.UnmuteConversation(c.Context(), conversationID, userID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to unmute conversation", err)
+	}
+
+	return utils.SuccessResponse(c, "Conversation unmuted successfully", conversation)
+}
+
+// DeleteConversation deletes a conversation for the current user only (history up to now is hidden)
+// DELETE /conversations/:conversationId
+func (h *ConversationHandler) DeleteConversation(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	conversationID, err := uuid.Parse(c.Params("conversationId"))
+	if err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid conversation ID")
+	}
+
+	if err := h.conversationService.DeleteConversationForMe(c.Context(), conversationID, userID); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to delete conversation", err)
+	}
+
+	return utils.SuccessResponse(c, "Conversation deleted successfully", nil)
+}
+
+// ==================== Message Requests ====================
+
+// ListMessageRequests lists conversations started by users the caller doesn't follow
+// GET /chat/requests?cursor=xxx&limit=20
+func (h *ConversationHandler) ListMessageRequests(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	var cursorPtr *string
+	if cursor := c.Query("cursor"); cursor != "" {
+		cursorPtr = &cursor
+	}
+
+	limit := 20
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 && parsedLimit <= 50 {
+			limit = parsedLimit
+		}
+	}
+
+	requests, err := h.conversationService.ListMessageRequests(c.Context(), userID, cursorPtr, limit)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve message requests", err)
+	}
+
+	return utils.SuccessResponse(c, "Message requests retrieved successfully", requests)
+}
+
+// CountMessageRequests retrieves the number of pending message requests
+// GET /chat/requests/count
+func (h *ConversationHandler) CountMessageRequests(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	count, err := h.conversationService.CountMessageRequests(c.Context(), userID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve message request count", err)
+	}
+
+	return utils.SuccessResponse(c, "Message request count retrieved successfully", count)
+}
+
+// AcceptMessageRequest moves a message request into the inbox
+// POST /chat/requests/:conversationId/accept
+func (h *ConversationHandler) AcceptMessageRequest(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	conversationID, err := uuid.Parse(c.Params("conversationId"))
+	if err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid conversation ID")
+	}
+
+	conversation, err := h.conversationService.AcceptMessageRequest(c.Context(), conversationID, userID)
 	if err != nil {
-		log.Printf("⚠️ Failed to get conversation for read notification: %v", err)
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to accept message request", err)
+	}
+
+	// Let the requester know they can keep writing
+	h.sendGroupEvent(conversationID, userID, "conversation.request_accepted", map[string]interface{}{
+		"conversationId": conversationID.String(),
+		"acceptedBy":     userID.String(),
+	})
+
+	return utils.SuccessResponse(c, "Message request accepted", conversation)
+}
+
+// DeclineMessageRequest declines a message request (the requester is not notified)
+// POST /chat/requests/:conversationId/decline
+func (h *ConversationHandler) DeclineMessageRequest(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	conversationID, err := uuid.Parse(c.Params("conversationId"))
+	if err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid conversation ID")
+	}
+
+	if err := h.conversationService.DeclineMessageRequest(c.Context(), conversationID, userID); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to decline message request", err)
+	}
+
+	return utils.SuccessResponse(c, "Message request declined", nil)
+}
+
+// ReportMessageRequest reports and declines a message request, optionally blocking the sender
+// POST /chat/requests/:conversationId/report
+func (h *ConversationHandler) ReportMessageRequest(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	conversationID, err := uuid.Parse(c.Params("conversationId"))
+	if err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid conversation ID")
+	}
+
+	var req dto.ReportConversationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid request body")
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		errors := utils.GetValidationErrors(err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Validation failed",
+			"errors":  errors,
+		})
+	}
+
+	if err := h.conversationService.ReportMessageRequest(c.Context(), conversationID, userID, &req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to report message request", err)
+	}
+
+	return utils.SuccessResponse(c, "Message request reported", nil)
+}
+
+// ==================== Group Conversations ====================
+
+// CreateGroup creates a group conversation
+// POST /chat/groups
+func (h *ConversationHandler) CreateGroup(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	var req dto.CreateGroupRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid request body")
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		errors := utils.GetValidationErrors(err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Validation failed",
+			"errors":  errors,
+		})
+	}
+
+	conversation, err := h.conversationService.CreateGroup(c.Context(), userID, &req)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to create group", err)
+	}
+
+	h.sendGroupEvent(conversation.ID, userID, "group.created", map[string]interface{}{
+		"conversationId": conversation.ID.String(),
+	})
+
+	return utils.SuccessResponse(c, "Group created successfully", conversation)
+}
+
+// UpdateGroup updates group title/avatar
+// PATCH /chat/groups/:conversationId
+func (h *ConversationHandler) UpdateGroup(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	conversationID, err := uuid.Parse(c.Params("conversationId"))
+	if err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid conversation ID")
+	}
+
+	var req dto.UpdateGroupRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid request body")
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		errors := utils.GetValidationErrors(err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Validation failed",
+			"errors":  errors,
+		})
+	}
+
+	conversation, err := h.conversationService.UpdateGroup(c.Context(), conversationID, userID, &req)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to update group", err)
+	}
+
+	h.sendGroupEvent(conversationID, userID, "group.updated", map[string]interface{}{
+		"conversationId": conversationID.String(),
+		"title":          conversation.Title,
+		"avatar":         conversation.Avatar,
+		"updatedBy":      userID.String(),
+	})
+
+	return utils.SuccessResponse(c, "Group updated successfully", conversation)
+}
+
+// ListParticipants retrieves active participants of a conversation
+// GET /chat/conversations/:conversationId/participants
+func (h *ConversationHandler) ListParticipants(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	conversationID, err := uuid.Parse(c.Params("conversationId"))
+	if err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid conversation ID")
+	}
+
+	participants, err := h.conversationService.ListParticipants(c.Context(), conversationID, userID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to retrieve participants", err)
+	}
+
+	return utils.SuccessResponse(c, "Participants retrieved successfully", participants)
+}
+
+// AddParticipants adds members to a group
+// POST /chat/groups/:conversationId/participants
+func (h *ConversationHandler) AddParticipants(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	conversationID, err := uuid.Parse(c.Params("conversationId"))
+	if err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid conversation ID")
+	}
+
+	var req dto.AddParticipantsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid request body")
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		errors := utils.GetValidationErrors(err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Validation failed",
+			"errors":  errors,
+		})
+	}
+
+	conversation, err := h.conversationService.AddParticipants(c.Context(), conversationID, userID, &req)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to add participants", err)
+	}
+
+	h.sendGroupEvent(conversationID, userID, "group.participants_added", map[string]interface{}{
+		"conversationId": conversationID.String(),
+		"participants":   conversation.Participants,
+		"addedBy":        userID.String(),
+	})
+
+	return utils.SuccessResponse(c, "Participants added successfully", conversation)
+}
+
+// RemoveParticipant removes a member from a group
+// DELETE /chat/groups/:conversationId/participants/:userId
+func (h *ConversationHandler) RemoveParticipant(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	conversationID, err := uuid.Parse(c.Params("conversationId"))
+	if err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid conversation ID")
+	}
+
+	targetUserID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid user ID")
+	}
+
+	if err := h.conversationService.RemoveParticipant(c.Context(), conversationID, userID, targetUserID); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to remove participant", err)
+	}
+
+	h.sendParticipantRemoved(conversationID, targetUserID, userID)
+
+	return utils.SuccessResponse(c, "Participant removed successfully", nil)
+}
+
+// UpdateParticipantRole changes a member's role
+// PATCH /chat/groups/:conversationId/participants/:userId
+func (h *ConversationHandler) UpdateParticipantRole(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	conversationID, err := uuid.Parse(c.Params("conversationId"))
+	if err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid conversation ID")
+	}
+
+	targetUserID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid user ID")
+	}
+
+	var req dto.UpdateParticipantRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid request body")
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		errors := utils.GetValidationErrors(err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Validation failed",
+			"errors":  errors,
+		})
+	}
+
+	conversation, err := h.conversationService.UpdateParticipantRole(c.Context(), conversationID, userID, targetUserID, req.Role)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to update participant role", err)
+	}
+
+	h.sendGroupEvent(conversationID, userID, "group.role_updated", map[string]interface{}{
+		"conversationId": conversationID.String(),
+		"userId":         targetUserID.String(),
+		"role":           req.Role,
+		"updatedBy":      userID.String(),
+	})
+
+	return utils.SuccessResponse(c, "Participant role updated successfully", conversation)
+}
+
+// LeaveGroup removes the current user from a group
+// POST /chat/groups/:conversationId/leave
+func (h *ConversationHandler) LeaveGroup(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	conversationID, err := uuid.Parse(c.Params("conversationId"))
+	if err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid conversation ID")
+	}
+
+	if err := h.conversationService.LeaveGroup(c.Context(), conversationID, userID); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to leave group", err)
+	}
+
+	h.sendParticipantRemoved(conversationID, userID, userID)
+
+	return utils.SuccessResponse(c, "Left group successfully", nil)
+}
+
+// sendGroupEvent notifies the other participants of a group change
+func (h *ConversationHandler) sendGroupEvent(conversationID uuid.UUID, actorID uuid.UUID, eventType string, payload map[string]interface{}) {
+	if h.chatHub == nil {
 		return
 	}
 
-	// Determine the other user (sender who should receive the notification)
-	var senderID uuid.UUID
-	if conversation.User1ID == readerID {
-		senderID = conversation.User2ID
-	} else {
-		senderID = conversation.User1ID
+	h.chatHub.SendToConversation(conversationID, actorID, &chatWebsocket.ChatMessage{
+		Type:    eventType,
+		Payload: payload,
+	})
+}
+
+// sendParticipantRemoved notifies the remaining participants and the removed user
+func (h *ConversationHandler) sendParticipantRemoved(conversationID uuid.UUID, removedUserID uuid.UUID, actorID uuid.UUID) {
+	if h.chatHub == nil {
+		return
 	}
 
-	// Send read update notification to sender
-	h.chatHub.SendToUser(senderID, &chatWebsocket.ChatMessage{
-		Type: "message.read_update",
+	message := &chatWebsocket.ChatMessage{
+		Type: "group.participant_removed",
 		Payload: map[string]interface{}{
 			"conversationId": conversationID.String(),
-			"readBy":         readerID.String(),
-			"readAt":         time.Now().Format(time.RFC3339),
+			"userId":         removedUserID.String(),
+			"removedBy":      actorID.String(),
 		},
-	})
+	}
 
-	log.Printf("📤 Read notification sent to sender: %s (conversation: %s, read by: %s)", senderID, conversationID, readerID)
+	h.chatHub.SendToConversation(conversationID, actorID, message)
+	if removedUserID != actorID {
+		h.chatHub.SendToUser(removedUserID, message)
+	}
 }
 
 // SearchUsersForChat searches users for starting a new chat
@@ -12,6 +12,10 @@ import (
 // Services contains all the services needed for handlers
 type Services struct {
 	UserService         services.UserService
+	AuthService         services.AuthService
+	AccountService      services.AccountService
+	MFAService          services.MFAService
+	AccessTokenService  services.AccessTokenService
 	TaskService         services.TaskService
 	FileService         services.FileService
 	JobService          services.JobService
@@ -35,6 +39,10 @@ type Services struct {
 // Handlers contains all HTTP handlers
 type Handlers struct {
 	UserHandler         *UserHandler
+	AuthHandler         *AuthHandler
+	MFAHandler          *MFAHandler
+	AdminHandler        *AdminHandler
+	AccessTokenHandler  *AccessTokenHandler
 	ProfileHandler      *ProfileHandler
 	TaskHandler         *TaskHandler
 	FileHandler         *FileHandler
@@ -62,6 +70,10 @@ type Handlers struct {
 func NewHandlers(services *Services, cfg *config.Config, chatWSHandler *websocketHandler.ChatWebSocketHandler, chatHub *chatWebsocket.ChatHub, conversationRepo repositories.ConversationRepository, mediaUploadService *storage.MediaUploadService) *Handlers {
 	return &Handlers{
 		UserHandler:         NewUserHandler(services.UserService),
+		AuthHandler:         NewAuthHandler(services.AuthService, services.AccountService),
+		MFAHandler:          NewMFAHandler(services.MFAService),
+		AdminHandler:        NewAdminHandler(services.UserService),
+		AccessTokenHandler:  NewAccessTokenHandler(services.AccessTokenService),
 		ProfileHandler:      NewProfileHandler(services.UserService),
 		TaskHandler:         NewTaskHandler(services.TaskService),
 		FileHandler:         NewFileHandler(services.FileService),
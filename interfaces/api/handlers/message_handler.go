@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"mime/multipart"
@@ -12,6 +13,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"gofiber-template/domain/dto"
+	"gofiber-template/domain/models"
 	"gofiber-template/domain/services"
 	chatWebsocket "gofiber-template/infrastructure/websocket"
 	"gofiber-template/infrastructure/storage"
@@ -88,9 +90,19 @@ func (h *MessageHandler) sendTextMessage(c *fiber.Ctx, userID uuid.UUID, convers
 // sendMediaMessage handles multipart/form-data media messages
 func (h *MessageHandler) sendMediaMessage(c *fiber.Ctx, userID uuid.UUID, conversationID uuid.UUID) error {
 	// 1. Parse form data
-	messageType := c.FormValue("type") // "image", "video", "file"
+	messageType := c.FormValue("type") // "image", "video", "file", "voice"
 	content := c.FormValue("content")  // Optional caption
 
+	// Optional quoted message
+	var replyToID *uuid.UUID
+	if replyToStr := c.FormValue("replyToId"); replyToStr != "" {
+		parsed, err := uuid.Parse(replyToStr)
+		if err != nil {
+			return utils.ValidationErrorResponse(c, "Invalid replyToId")
+		}
+		replyToID = &parsed
+	}
+
 	// 2. Get uploaded files
 	form, err := c.MultipartForm()
 	if err != nil {
@@ -111,6 +123,7 @@ func (h *MessageHandler) sendMediaMessage(c *fiber.Ctx, userID uuid.UUID, conver
 			ConversationID: conversationID,
 			Type:           "text",
 			Content:        &content,
+			ReplyToID:      replyToID,
 		}
 
 		message, err := h.messageService.SendMessage(c.Context(), userID, req)
@@ -122,17 +135,25 @@ func (h *MessageHandler) sendMediaMessage(c *fiber.Ctx, userID uuid.UUID, conver
 	}
 
 	// 3. Validate message type (for media messages)
-	validTypes := map[string]bool{"image": true, "video": true, "file": true}
+	validTypes := map[string]bool{"image": true, "video": true, "file": true, "voice": true}
 	if messageType == "" || !validTypes[messageType] {
-		return utils.ValidationErrorResponse(c, "Invalid message type. Must be one of: image, video, file")
+		return utils.ValidationErrorResponse(c, "Invalid message type. Must be one of: image, video, file, voice")
 	}
 
 	// 4. Validate file count
-	maxFiles := map[string]int{"image": 10, "video": 1, "file": 5}
+	maxFiles := map[string]int{"image": 10, "video": 1, "file": 5, "voice": 1}
 	if len(files) > maxFiles[messageType] {
 		return utils.ValidationErrorResponse(c, fmt.Sprintf("Maximum %d files allowed for type %s", maxFiles[messageType], messageType))
 	}
 
+	// Optional client-computed waveform (JSON array of 0-100), used when the server can't compute one
+	var clientWaveform []int
+	if waveformStr := c.FormValue("waveform"); messageType == "voice" && waveformStr != "" {
+		if err := json.Unmarshal([]byte(waveformStr), &clientWaveform); err != nil {
+			return utils.ValidationErrorResponse(c, "Invalid waveform")
+		}
+	}
+
 	// 5. Process each file
 	mediaItems := make([]dto.MessageMedia, 0, len(files))
 
@@ -158,6 +179,10 @@ func (h *MessageHandler) sendMediaMessage(c *fiber.Ctx, userID uuid.UUID, conver
 			return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to upload file", err)
 		}
 
+		if messageType == "voice" && len(mediaItem.Waveform) == 0 {
+			mediaItem.Waveform = clientWaveform
+		}
+
 		mediaItems = append(mediaItems, mediaItem)
 	}
 
@@ -172,6 +197,7 @@ func (h *MessageHandler) sendMediaMessage(c *fiber.Ctx, userID uuid.UUID, conver
 		Type:           messageType,
 		Content:        contentPtr,
 		Media:          mediaItems,
+		ReplyToID:      replyToID,
 	}
 
 	message, err := h.messageService.SendMessage(c.Context(), userID, req)
@@ -257,6 +283,217 @@ func (h *MessageHandler) GetMessageContext(c *fiber.Ctx) error {
 	return utils.SuccessResponse(c, "Message context retrieved successfully", context)
 }
 
+// GetReplyContext retrieves the quoted message of a reply with surrounding context
+// GET /messages/:id/reply-context
+func (h *MessageHandler) GetReplyContext(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	messageID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid message ID")
+	}
+
+	context, err := h.messageService.GetReplyContext(c.Context(), messageID, userID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to retrieve reply context", err)
+	}
+
+	return utils.SuccessResponse(c, "Message context retrieved successfully", context)
+}
+
+// EditMessage edits a sent message within the edit window
+// PATCH /messages/:id
+func (h *MessageHandler) EditMessage(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	messageID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid message ID")
+	}
+
+	var req dto.EditMessageRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid request body")
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		errors := utils.GetValidationErrors(err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Validation failed",
+			"errors":  errors,
+		})
+	}
+
+	message, err := h.messageService.EditMessage(c.Context(), messageID, userID, &req)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to edit message", err)
+	}
+
+	// Update the other participants' copy
+	if h.chatHub != nil {
+		h.chatHub.SendToConversation(message.ConversationID, userID, &chatWebsocket.ChatMessage{
+			Type: "message.edited",
+			Payload: map[string]interface{}{
+				"message": message,
+			},
+		})
+	}
+
+	return utils.SuccessResponse(c, "Message edited successfully", message)
+}
+
+// DeleteMessage deletes a message for the current user or for everyone
+// DELETE /messages/:id?scope=me|everyone
+func (h *MessageHandler) DeleteMessage(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	messageID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid message ID")
+	}
+
+	scope := c.Query("scope", models.MessageDeleteScopeMe)
+
+	message, err := h.messageService.DeleteMessage(c.Context(), messageID, userID, scope)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to delete message", err)
+	}
+
+	if h.chatHub != nil {
+		h.chatHub.BroadcastMessageDeleted(userID, message, scope)
+	}
+
+	return utils.SuccessResponse(c, "Message deleted successfully", message)
+}
+
+// ListReactions lists who reacted to a message
+// GET /messages/:id/reactions?emoji=👍&offset=0&limit=50
+func (h *MessageHandler) ListReactions(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	messageID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid message ID")
+	}
+
+	var emoji *string
+	if emojiStr := c.Query("emoji"); emojiStr != "" {
+		emoji = &emojiStr
+	}
+
+	offset := 0
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if parsedOffset, err := strconv.Atoi(offsetStr); err == nil && parsedOffset >= 0 {
+			offset = parsedOffset
+		}
+	}
+
+	limit := 50
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 && parsedLimit <= 100 {
+			limit = parsedLimit
+		}
+	}
+
+	reactions, err := h.messageService.ListReactions(c.Context(), messageID, userID, emoji, offset, limit)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to retrieve reactions", err)
+	}
+
+	return utils.SuccessResponse(c, "Reactions retrieved successfully", reactions)
+}
+
+// MarkDelivered acknowledges messages received outside the WebSocket (e.g. fetched after a push)
+// POST /chat/messages/delivered
+func (h *MessageHandler) MarkDelivered(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	var req dto.MarkDeliveredRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid request body")
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		errors := utils.GetValidationErrors(err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Validation failed",
+			"errors":  errors,
+		})
+	}
+
+	updates, err := h.messageService.MarkDelivered(c.Context(), userID, req.MessageIDs)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to mark as delivered", err)
+	}
+
+	if h.chatHub != nil {
+		h.chatHub.BroadcastDeliveryReceipts(updates)
+	}
+
+	return utils.SuccessResponse(c, "Messages marked as delivered", nil)
+}
+
+// ListReceipts retrieves per-recipient delivery/read state of the caller's message
+// GET /chat/messages/:id/receipts
+func (h *MessageHandler) ListReceipts(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	messageID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid message ID")
+	}
+
+	receipts, err := h.messageService.ListReceipts(c.Context(), messageID, userID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to retrieve receipts", err)
+	}
+
+	return utils.SuccessResponse(c, "Receipts retrieved successfully", receipts)
+}
+
+// SearchMessages searches message text across the user's conversations or within one
+// GET /chat/search?q=xxx&conversationId=xxx&cursor=xxx&limit=20
+// GET /conversations/:conversationId/search?q=xxx&cursor=xxx&limit=20
+func (h *MessageHandler) SearchMessages(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	query := c.Query("q")
+	if query == "" {
+		return utils.ValidationErrorResponse(c, "Search query is required")
+	}
+
+	var conversationID *uuid.UUID
+	conversationIDStr := c.Params("conversationId", c.Query("conversationId"))
+	if conversationIDStr != "" {
+		parsed, err := uuid.Parse(conversationIDStr)
+		if err != nil {
+			return utils.ValidationErrorResponse(c, "Invalid conversation ID")
+		}
+		conversationID = &parsed
+	}
+
+	var cursor *string
+	if cursorStr := c.Query("cursor"); cursorStr != "" {
+		cursor = &cursorStr
+	}
+
+	limit := 20
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 && parsedLimit <= 50 {
+			limit = parsedLimit
+		}
+	}
+
+	results, err := h.messageService.SearchMessages(c.Context(), userID, query, conversationID, cursor, limit)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to search messages", err)
+	}
+
+	return utils.SuccessResponse(c, "Search results retrieved successfully", results)
+}
+
 // Note: MarkAsRead is handled by ConversationHandler
 // POST /conversations/:conversationId/read
 
@@ -382,6 +619,7 @@ func (h *MessageHandler) validateFile(file multipart.File, fileHeader *multipart
 		"image": 10 * 1024 * 1024,  // 10MB
 		"video": 100 * 1024 * 1024, // 100MB
 		"file":  50 * 1024 * 1024,  // 50MB
+		"voice": 10 * 1024 * 1024,  // 10MB
 	}
 
 	if fileHeader.Size > maxSizes[messageType] {
@@ -401,6 +639,14 @@ func (h *MessageHandler) validateFile(file multipart.File, fileHeader *multipart
 			"application/zip",
 			"text/plain",
 		},
+		"voice": {
+			storage.AudioMimeOgg,
+			storage.AudioMimeWebM,
+			storage.AudioMimeMP4,
+			storage.AudioMimeAAC,
+			storage.AudioMimeMPEG,
+			storage.AudioMimeWAV,
+		},
 	}
 
 	// Detect MIME type from file content
@@ -411,6 +657,12 @@ func (h *MessageHandler) validateFile(file multipart.File, fileHeader *multipart
 	}
 
 	mimeType := http.DetectContentType(buffer[:n])
+	if messageType == "voice" {
+		// DetectContentType doesn't know most recorder formats (WebM, AAC, M4A)
+		if audioType := storage.DetectAudioMimeType(buffer[:n]); audioType != "" {
+			mimeType = audioType
+		}
+	}
 
 	// Check if MIME type is allowed
 	allowed := false
@@ -503,27 +755,41 @@ func (h *MessageHandler) uploadFile(ctx context.Context, file multipart.File, fi
 			Size:     &result.Size,
 		}, nil
 
+	case "voice":
+		result, err := h.mediaUploadService.UploadAudio(ctx, file, fileHeader.Filename)
+		if err != nil {
+			return dto.MessageMedia{}, err
+		}
+
+		item := dto.MessageMedia{
+			URL:      result.URL,
+			Type:     "voice",
+			Filename: &fileHeader.Filename,
+			MimeType: &result.MimeType,
+			Size:     &result.Size,
+			Waveform: result.Waveform,
+		}
+
+		if result.Duration > 0 {
+			item.Duration = &result.Duration
+		}
+
+		return item, nil
+
 	default:
 		return dto.MessageMedia{}, fmt.Errorf("unsupported message type: %s", messageType)
 	}
 }
 
-// sendWebSocketNotification sends WebSocket notification to receiver
+// sendWebSocketNotification sends WebSocket notification to the other participants
 func (h *MessageHandler) sendWebSocketNotification(message *dto.MessageResponse) {
 	if h.chatHub == nil {
 		log.Printf("⚠️ ChatHub is nil, skipping WebSocket notification")
 		return
 	}
 
-	receiverID := message.Receiver.ID
-
-	// Send new message notification to receiver (message.new)
-	h.chatHub.SendToUser(receiverID, &chatWebsocket.ChatMessage{
-		Type: "message.new",
-		Payload: map[string]interface{}{
-			"message": message,
-		},
-	})
+	// Send new message notification to every other participant (message.new / message.request)
+	recipients := h.chatHub.BroadcastNewMessage(message)
 
-	log.Printf("📤 WebSocket notification sent to receiver: %s (message: %s)", receiverID, message.ID)
+	log.Printf("📤 WebSocket notification sent to %d participant(s) (message: %s)", len(recipients), message.ID)
 }
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"gofiber-template/domain/dto"
+	"gofiber-template/domain/services"
+	"gofiber-template/pkg/utils"
+)
+
+type MFAHandler struct {
+	mfaService services.MFAService
+}
+
+func NewMFAHandler(mfaService services.MFAService) *MFAHandler {
+	return &MFAHandler{
+		mfaService: mfaService,
+	}
+}
+
+// GetStatus returns the current user's two-factor authentication state
+// GET /users/mfa
+func (h *MFAHandler) GetStatus(c *fiber.Ctx) error {
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	status, err := h.mfaService.GetStatus(c.Context(), user.ID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve two-factor status", err)
+	}
+
+	return utils.SuccessResponse(c, "Two-factor status retrieved successfully", status)
+}
+
+// Setup generates a TOTP secret to add to an authenticator app
+// POST /users/mfa/setup
+func (h *MFAHandler) Setup(c *fiber.Ctx) error {
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	setup, err := h.mfaService.BeginEnrollment(c.Context(), user.ID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to start two-factor setup", err)
+	}
+
+	return utils.SuccessResponse(c, "Two-factor setup started successfully", setup)
+}
+
+// Enable confirms setup with a code from the app and returns recovery codes.
+// Refresh tokens afterwards to get an access token carrying the mfa claim.
+// POST /users/mfa/enable
+func (h *MFAHandler) Enable(c *fiber.Ctx) error {
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	var req dto.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid request body")
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		errors := utils.GetValidationErrors(err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Validation failed",
+			"errors":  errors,
+		})
+	}
+
+	codes, err := h.mfaService.ConfirmEnrollment(c.Context(), user.ID, user.SessionID, req.Code)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to enable two-factor authentication", err)
+	}
+
+	return utils.SuccessResponse(c, "Two-factor authentication enabled successfully", codes)
+}
+
+// Disable turns two-factor authentication off
+// POST /users/mfa/disable
+func (h *MFAHandler) Disable(c *fiber.Ctx) error {
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	var req dto.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid request body")
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		errors := utils.GetValidationErrors(err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Validation failed",
+			"errors":  errors,
+		})
+	}
+
+	if err := h.mfaService.Disable(c.Context(), user.ID, req.Code); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to disable two-factor authentication", err)
+	}
+
+	return utils.SuccessResponse(c, "Two-factor authentication disabled successfully", nil)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes
+// POST /users/mfa/recovery-codes
+func (h *MFAHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	var req dto.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid request body")
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		errors := utils.GetValidationErrors(err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Validation failed",
+			"errors":  errors,
+		})
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(c.Context(), user.ID, req.Code)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to regenerate recovery codes", err)
+	}
+
+	return utils.SuccessResponse(c, "Recovery codes regenerated successfully", codes)
+}
+
+// Verify completes a login that returned mfaRequired
+// POST /auth/mfa/verify
+func (h *MFAHandler) Verify(c *fiber.Ctx) error {
+	var req dto.MFAVerifyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid request body")
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		errors := utils.GetValidationErrors(err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Validation failed",
+			"errors":  errors,
+		})
+	}
+
+	tokens, user, err := h.mfaService.VerifyChallenge(c.Context(), req.MFAToken, req.Code, clientInfo(c))
+	if err != nil {
+		return utils.ErrorResponse(c, loginFailureStatus(c, err), "Two-factor verification failed", err)
+	}
+
+	return utils.SuccessResponse(c, "Login successful", &dto.LoginResponse{
+		Token:        tokens.Token,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		User:         *dto.UserToUserResponse(user),
+	})
+}
@@ -67,6 +67,23 @@ func (h *NotificationHandler) GetNotification(c *fiber.Ctx) error {
 	return utils.SuccessResponse(c, "Notification retrieved successfully", notification)
 }
 
+// GetDeliveryStatus retrieves per-channel delivery status of a notification
+func (h *NotificationHandler) GetDeliveryStatus(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uuid.UUID)
+
+	notificationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid notification ID")
+	}
+
+	deliveries, err := h.notificationService.GetDeliveryStatus(c.Context(), notificationID, userID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, "Notification not found", err)
+	}
+
+	return utils.SuccessResponse(c, "Delivery status retrieved successfully", deliveries)
+}
+
 // MarkAsRead marks a notification as read
 func (h *NotificationHandler) MarkAsRead(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uuid.UUID)
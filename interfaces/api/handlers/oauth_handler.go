@@ -1,12 +1,13 @@
 package handlers
 
 import (
-	"fmt"
+	"crypto/sha256"
+	"encoding/base64"
+	"slices"
 
 	"github.com/gofiber/fiber/v2"
 	"gofiber-template/domain/dto"
 	"gofiber-template/domain/services"
-	"gofiber-template/pkg/auth_code_store"
 	"gofiber-template/pkg/config"
 	"gofiber-template/pkg/utils"
 )
@@ -23,18 +24,58 @@ func NewOAuthHandler(oauthService services.OAuthService, cfg *config.Config) *OA
 	}
 }
 
-// GetGoogleAuthURL generates Google OAuth authorization URL
-// @Summary Get Google OAuth URL
-// @Description Get Google OAuth authorization URL to start OAuth flow
+// ListProviders lists the OAuth providers users can sign in with
+// @Summary List OAuth providers
+// @Tags OAuth
+// @Produce json
+// @Success 200 {object} dto.OAuthProvidersResponse
+// @Router /auth/oauth/providers [get]
+func (h *OAuthHandler) ListProviders(c *fiber.Ctx) error {
+	return utils.SuccessResponse(c, "OAuth providers retrieved successfully", dto.OAuthProvidersResponse{
+		Providers: h.oauthService.Providers(),
+	})
+}
+
+// GetAuthURL generates the provider's OAuth authorization URL
+// @Summary Get OAuth URL
+// @Description Get the authorization URL that starts an OAuth login with the provider
 // @Tags OAuth
 // @Accept json
 // @Produce json
+// @Param provider path string true "Provider (google, github, facebook, line, oidc)"
+// @Param code_challenge query string false "S256 PKCE challenge; /auth/exchange then needs its codeVerifier"
+// @Param code_challenge_method query string false "Must be S256 when code_challenge is set"
 // @Success 200 {object} dto.OAuthURLResponse
+// @Failure 404 {object} map[string]interface{}
+// @Router /auth/oauth/{provider} [get]
+func (h *OAuthHandler) GetAuthURL(c *fiber.Ctx) error {
+	return h.startLogin(c, c.Params("provider"))
+}
+
+// GetGoogleAuthURL is the original Google-only route, kept for existing clients
 // @Router /auth/google [get]
 func (h *OAuthHandler) GetGoogleAuthURL(c *fiber.Ctx) error {
+	return h.startLogin(c, "google")
+}
+
+func (h *OAuthHandler) startLogin(c *fiber.Ctx, provider string) error {
+	// Frontends can protect the code exchange with their own PKCE pair
+	codeChallenge := c.Query("code_challenge")
+	if codeChallenge != "" && (c.Query("code_challenge_method") != "S256" || !isS256Challenge(codeChallenge)) {
+		return utils.ValidationErrorResponse(c, "code_challenge must be an S256 PKCE challenge")
+	}
+
 	// Generate random state for CSRF protection
 	state := utils.GenerateRandomString(32)
 
+	url, err := h.oauthService.GetAuthURL(c.Context(), provider, state, codeChallenge)
+	if err != nil {
+		if !slices.Contains(h.oauthService.Providers(), provider) {
+			return utils.ErrorResponse(c, fiber.StatusNotFound, "OAuth provider not found", err)
+		}
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to generate OAuth URL", err)
+	}
+
 	// Store state in session or cookie for validation in callback
 	c.Cookie(&fiber.Cookie{
 		Name:     "oauth_state",
@@ -45,36 +86,37 @@ func (h *OAuthHandler) GetGoogleAuthURL(c *fiber.Ctx) error {
 		Path:     "/",
 	})
 
-	url := h.oauthService.GetGoogleAuthURL(state)
-
-	return utils.SuccessResponse(c, "Google OAuth URL generated", dto.OAuthURLResponse{
+	return utils.SuccessResponse(c, "OAuth URL generated", dto.OAuthURLResponse{
 		URL: url,
 	})
 }
 
-// GoogleCallback handles Google OAuth callback
-// @Summary Handle Google OAuth Callback
-// @Description Process Google OAuth callback and login/register user
+// Callback handles the provider's OAuth callback
+// @Summary Handle OAuth Callback
+// @Description Process the provider's OAuth callback and login/register user
 // @Tags OAuth
 // @Accept json
 // @Produce json
-// @Param code query string true "Authorization code from Google"
+// @Param provider path string true "Provider (google, github, facebook, line, oidc)"
+// @Param code query string true "Authorization code from the provider"
 // @Param state query string true "State parameter for CSRF protection"
 // @Success 200 {object} dto.OAuthLoginResponse
 // @Failure 400 {object} map[string]interface{}
+// @Router /auth/oauth/{provider}/callback [get]
+func (h *OAuthHandler) Callback(c *fiber.Ctx) error {
+	return h.finishLogin(c, c.Params("provider"))
+}
+
+// GoogleCallback is the original Google-only callback, kept so existing redirect URLs keep working
 // @Router /auth/google/callback [get]
 func (h *OAuthHandler) GoogleCallback(c *fiber.Ctx) error {
+	return h.finishLogin(c, "google")
+}
+
+func (h *OAuthHandler) finishLogin(c *fiber.Ctx, provider string) error {
 	code := c.Query("code")
 	state := c.Query("state")
 
-	// Debug logging
-	fmt.Printf("\n=== Google OAuth Callback Debug ===\n")
-	fmt.Printf("Received code: %s\n", code)
-	fmt.Printf("Code length: %d\n", len(code))
-	fmt.Printf("Received state: %s\n", state)
-	fmt.Printf("Redirect URL from config: %s\n", h.config.OAuth.Google.RedirectURL)
-	fmt.Printf("===================================\n\n")
-
 	if code == "" {
 		// Redirect to frontend with error if redirect URL is provided
 		redirectURL := c.Query("redirect_url")
@@ -87,15 +129,9 @@ func (h *OAuthHandler) GoogleCallback(c *fiber.Ctx) error {
 	// Validate state parameter for CSRF protection
 	storedState := c.Cookies("oauth_state")
 
-	// Debug logging
-	c.Locals("debug_received_state", state)
-	c.Locals("debug_stored_state", storedState)
-
-	// Skip state validation if cookie is not present (direct Google redirect)
-	// In production, this should be strict
+	// The state itself is checked against the login started on the server (which also
+	// holds the PKCE verifier); the cookie additionally ties it to this browser when present
 	if storedState == "" {
-		// No cookie found - user likely came directly from Google without calling /auth/google first
-		// For now, we'll allow this in development
 		c.Locals("debug_state_validation", "skipped_no_cookie")
 	} else if storedState != state {
 		// Cookie exists but doesn't match
@@ -111,7 +147,8 @@ func (h *OAuthHandler) GoogleCallback(c *fiber.Ctx) error {
 	}
 
 	// Handle OAuth callback
-	response, err := h.oauthService.HandleGoogleCallback(c.Context(), code)
+	browserBound := storedState != "" && storedState == state
+	result, err := h.oauthService.HandleCallback(c.Context(), provider, code, state, browserBound, clientInfo(c))
 	if err != nil {
 		// Redirect to frontend with error if redirect URL is provided
 		redirectURL := c.Query("redirect_url")
@@ -121,15 +158,13 @@ func (h *OAuthHandler) GoogleCallback(c *fiber.Ctx) error {
 		return utils.ErrorResponse(c, fiber.StatusBadRequest, "OAuth authentication failed", err)
 	}
 
-	// Generate authorization code
-	store := auth_code_store.GetInstance()
-	authCode, err := store.GenerateCode(response.Token, response.User, response.IsNewUser, state)
-	if err != nil {
+	// Linking signs nobody in; just tell the frontend it worked
+	if result.LinkedProvider != "" {
 		redirectURL := c.Query("redirect_url")
-		if redirectURL != "" {
-			return c.Redirect(redirectURL + "?error=code_generation_failed")
+		if redirectURL == "" {
+			redirectURL = h.config.App.FrontendURL + "/auth/callback"
 		}
-		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to generate authorization code", err)
+		return c.Redirect(redirectURL + "?linked=" + result.LinkedProvider + "&state=" + state)
 	}
 
 	// Get redirect URL from query or use default frontend URL
@@ -140,7 +175,66 @@ func (h *OAuthHandler) GoogleCallback(c *fiber.Ctx) error {
 	}
 
 	// Redirect to frontend with authorization code and state
-	return c.Redirect(redirectURL + "?code=" + authCode + "&state=" + state)
+	return c.Redirect(redirectURL + "?code=" + result.Code + "&state=" + state)
+}
+
+// LinkIdentity starts linking a provider account to the signed-in user
+// @Summary Link OAuth account
+// @Description Get the authorization URL that links the provider account; the callback redirects with ?linked={provider}
+// @Tags OAuth
+// @Produce json
+// @Param provider path string true "Provider (google, github, facebook, line, oidc)"
+// @Success 200 {object} dto.OAuthURLResponse
+// @Failure 403 {object} map[string]interface{} "reauth_required"
+// @Router /users/identities/{provider} [post]
+func (h *OAuthHandler) LinkIdentity(c *fiber.Ctx) error {
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	provider := c.Params("provider")
+	state := utils.GenerateRandomString(32)
+
+	url, err := h.oauthService.GetLinkURL(c.Context(), provider, user.ID, user.SessionID, state)
+	if err != nil {
+		return accountChangeError(c, "Failed to start linking", err)
+	}
+
+	// Linking requires the callback to present this cookie
+	c.Cookie(&fiber.Cookie{
+		Name:     "oauth_state",
+		Value:    state,
+		HTTPOnly: true,
+		Secure:   false, // Set to true in production with HTTPS
+		SameSite: "Lax",
+		Path:     "/",
+	})
+
+	return utils.SuccessResponse(c, "OAuth URL generated", dto.OAuthURLResponse{
+		URL: url,
+	})
+}
+
+// UnlinkIdentity removes a linked provider account
+// @Summary Unlink OAuth account
+// @Tags OAuth
+// @Produce json
+// @Param provider path string true "Provider"
+// @Failure 403 {object} map[string]interface{} "reauth_required"
+// @Router /users/identities/{provider} [delete]
+func (h *OAuthHandler) UnlinkIdentity(c *fiber.Ctx) error {
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	err = h.oauthService.UnlinkIdentity(c.Context(), user.ID, user.SessionID, c.Params("provider"))
+	if err != nil {
+		return accountChangeError(c, "Failed to unlink account", err)
+	}
+
+	return utils.SuccessResponse(c, "Account unlinked successfully", nil)
 }
 
 // ExchangeCodeForToken exchanges authorization code for JWT token
@@ -169,18 +263,20 @@ func (h *OAuthHandler) ExchangeCodeForToken(c *fiber.Ctx) error {
 	}
 
 	// Exchange code for token
-	store := auth_code_store.GetInstance()
-	data, ok := store.ExchangeCode(req.Code, req.State)
-	if !ok {
-		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid or expired authorization code", nil)
+	response, err := h.oauthService.ExchangeCode(c.Context(), &req)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid or expired authorization code", err)
 	}
 
 	// Return token and user info
-	return utils.SuccessResponse(c, "Token exchanged successfully", dto.ExchangeCodeResponse{
-		Token:     data.Token,
-		IsNewUser: data.IsNewUser,
-		User:      data.User,
-	})
+	return utils.SuccessResponse(c, "Token exchanged successfully", response)
+}
+
+// isS256Challenge reports whether s looks like an S256 PKCE challenge: base64url
+// (no padding) of a SHA-256 hash
+func isS256Challenge(s string) bool {
+	decoded, err := base64.RawURLEncoding.DecodeString(s)
+	return err == nil && len(decoded) == sha256.Size
 }
 
 func boolToString(b bool) string {
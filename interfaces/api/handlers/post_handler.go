@@ -71,7 +71,10 @@ func (h *PostHandler) GetPost(c *fiber.Ctx) error {
 
 // UpdatePost updates an existing post
 func (h *PostHandler) UpdatePost(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uuid.UUID)
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "User not authenticated")
+	}
 
 	postID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
@@ -92,7 +95,7 @@ func (h *PostHandler) UpdatePost(c *fiber.Ctx) error {
 		})
 	}
 
-	post, err := h.postService.UpdatePost(c.Context(), postID, userID, &req)
+	post, err := h.postService.UpdatePost(c.Context(), postID, requestActor(user), &req)
 	if err != nil {
 		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to update post", err)
 	}
@@ -102,14 +105,17 @@ func (h *PostHandler) UpdatePost(c *fiber.Ctx) error {
 
 // DeletePost deletes a post
 func (h *PostHandler) DeletePost(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uuid.UUID)
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "User not authenticated")
+	}
 
 	postID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		return utils.ValidationErrorResponse(c, "Invalid post ID")
 	}
 
-	err = h.postService.DeletePost(c.Context(), postID, userID)
+	err = h.postService.DeletePost(c.Context(), postID, requestActor(user))
 	if err != nil {
 		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to delete post", err)
 	}
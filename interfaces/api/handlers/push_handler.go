@@ -69,3 +69,8 @@ func (h *PushHandler) GetPublicKey(c *fiber.Ctx) error {
 		"publicKey": publicKey,
 	})
 }
+
+// GetMetrics returns Web Push delivery metrics per push service domain (admin only)
+func (h *PushHandler) GetMetrics(c *fiber.Ctx) error {
+	return utils.SuccessResponse(c, "Push metrics retrieved successfully", h.pushService.GetMetrics())
+}
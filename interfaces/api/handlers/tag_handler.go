@@ -5,6 +5,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"gofiber-template/domain/dto"
 	"gofiber-template/domain/services"
 	"gofiber-template/pkg/utils"
 )
@@ -90,3 +91,38 @@ func (h *TagHandler) SearchTags(c *fiber.Ctx) error {
 
 	return utils.SuccessResponse(c, "Tags search results retrieved successfully", tags)
 }
+
+// MergeTags folds the tag into the target tag, moving its posts and deleting it
+// POST /tags/:id/merge
+func (h *TagHandler) MergeTags(c *fiber.Ctx) error {
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	tagID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid tag ID")
+	}
+
+	var req dto.MergeTagRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid request body")
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		errors := utils.GetValidationErrors(err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Validation failed",
+			"errors":  errors,
+		})
+	}
+
+	tag, err := h.tagService.MergeTags(c.Context(), requestActor(user), tagID, req.TargetTagID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Failed to merge tags", err)
+	}
+
+	return utils.SuccessResponse(c, "Tags merged successfully", tag)
+}
@@ -57,14 +57,13 @@ func (h *UserHandler) Login(c *fiber.Ctx) error {
 		})
 	}
 
-	token, user, err := h.userService.Login(c.Context(), &req)
+	loginResponse, err := h.userService.Login(c.Context(), &req, clientInfo(c))
 	if err != nil {
-		return utils.ErrorResponse(c, fiber.StatusUnauthorized, "Login failed", err)
+		return utils.ErrorResponse(c, loginFailureStatus(c, err), "Login failed", err)
 	}
 
-	loginResponse := &dto.LoginResponse{
-		Token: token,
-		User:  *dto.UserToUserResponse(user),
+	if loginResponse.MFARequired {
+		return utils.SuccessResponse(c, "Two-factor authentication required", loginResponse)
 	}
 	return utils.SuccessResponse(c, "Login successful", loginResponse)
 }
@@ -104,6 +103,129 @@ func (h *UserHandler) UpdateProfile(c *fiber.Ctx) error {
 	return utils.SuccessResponse(c, "Profile updated successfully", userResponse)
 }
 
+// ChangePassword sets a new password and signs out the user's other sessions
+// PUT /users/password
+func (h *UserHandler) ChangePassword(c *fiber.Ctx) error {
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	var req dto.ChangePasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid request body")
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		errors := utils.GetValidationErrors(err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Validation failed",
+			"errors":  errors,
+		})
+	}
+
+	err = h.userService.ChangePassword(c.Context(), user.ID, user.SessionID, &req)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Password change failed", err)
+	}
+
+	return utils.SuccessResponse(c, "Password changed successfully", nil)
+}
+
+// Reauthenticate confirms the password or second factor again, allowing sensitive
+// changes (linking accounts, setting or removing the password) for a few minutes
+// POST /users/reauth
+func (h *UserHandler) Reauthenticate(c *fiber.Ctx) error {
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	var req dto.ReauthenticateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid request body")
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		errors := utils.GetValidationErrors(err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Validation failed",
+			"errors":  errors,
+		})
+	}
+
+	err = h.userService.Reauthenticate(c.Context(), user.ID, user.SessionID, &req, clientInfo(c))
+	if err != nil {
+		return utils.ErrorResponse(c, loginFailureStatus(c, err), "Re-authentication failed", err)
+	}
+
+	return utils.SuccessResponse(c, "Identity confirmed successfully", nil)
+}
+
+// GetLoginMethods lists the password and linked OAuth accounts of the user
+// GET /users/login-methods
+func (h *UserHandler) GetLoginMethods(c *fiber.Ctx) error {
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	methods, err := h.userService.GetLoginMethods(c.Context(), user.ID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve login methods", err)
+	}
+
+	return utils.SuccessResponse(c, "Login methods retrieved successfully", methods)
+}
+
+// SetPassword adds a password to an account that signs in through OAuth only
+// POST /users/password
+func (h *UserHandler) SetPassword(c *fiber.Ctx) error {
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	var req dto.SetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ValidationErrorResponse(c, "Invalid request body")
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		errors := utils.GetValidationErrors(err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Validation failed",
+			"errors":  errors,
+		})
+	}
+
+	err = h.userService.SetPassword(c.Context(), user.ID, user.SessionID, &req)
+	if err != nil {
+		return accountChangeError(c, "Failed to set password", err)
+	}
+
+	return utils.SuccessResponse(c, "Password set successfully", nil)
+}
+
+// RemovePassword removes the password, leaving the linked OAuth accounts
+// DELETE /users/password
+func (h *UserHandler) RemovePassword(c *fiber.Ctx) error {
+	user, err := utils.GetUserFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	err = h.userService.RemovePassword(c.Context(), user.ID, user.SessionID)
+	if err != nil {
+		return accountChangeError(c, "Failed to remove password", err)
+	}
+
+	return utils.SuccessResponse(c, "Password removed successfully", nil)
+}
+
 func (h *UserHandler) DeleteUser(c *fiber.Ctx) error {
 	user, err := utils.GetUserFromContext(c)
 	if err != nil {
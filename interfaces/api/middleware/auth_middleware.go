@@ -1,14 +1,84 @@
 package middleware
 
 import (
+	"context"
+	"gofiber-template/domain/dto"
+	"gofiber-template/domain/models"
 	"gofiber-template/pkg/utils"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
-// Protected middleware validates JWT tokens and sets user context
+// SessionValidator reports whether the session an access token was issued for is still active
+type SessionValidator func(ctx context.Context, sessionID uuid.UUID) (bool, error)
+
+var sessionValidator SessionValidator
+
+// SetSessionValidator installs the revocation check used by the auth middlewares
+func SetSessionValidator(validator SessionValidator) {
+	sessionValidator = validator
+}
+
+// AccessTokenValidator resolves a personal access token to the user and scopes it grants
+type AccessTokenValidator func(ctx context.Context, token string, ip string) (*dto.AccessTokenPrincipal, error)
+
+var accessTokenValidator AccessTokenValidator
+
+// SetAccessTokenValidator enables personal access tokens in Protected and Optional
+func SetAccessTokenValidator(validator AccessTokenValidator) {
+	accessTokenValidator = validator
+}
+
+// authenticate validates a bearer token, either a personal access token or a session JWT
+func authenticate(c *fiber.Ctx, token string, jwtSecret string) (*utils.UserContext, error) {
+	if !strings.HasPrefix(token, models.PersonalAccessTokenPrefix) {
+		return utils.ValidateTokenStringToUUID(token, jwtSecret)
+	}
+
+	if accessTokenValidator == nil {
+		return nil, utils.ErrInvalidToken
+	}
+
+	principal, err := accessTokenValidator(c.Context(), token, c.IP())
+	if err != nil {
+		return nil, err
+	}
+
+	return &utils.UserContext{
+		ID:       principal.UserID,
+		Username: principal.Username,
+		Email:    principal.Email,
+		Role:     principal.Role,
+
+		EmailVerified: principal.EmailVerified,
+		MFAVerified:   principal.MFAVerified,
+
+		TokenID: principal.TokenID,
+		Scopes:  principal.Scopes,
+	}, nil
+}
+
+// sessionActive reports whether the token's session was not revoked (logout, refresh token reuse)
+func sessionActive(c *fiber.Ctx, userCtx *utils.UserContext) bool {
+	// Personal access tokens have no session; revocation is checked when they are validated
+	if sessionValidator == nil || userCtx.IsAccessToken() {
+		return true
+	}
+
+	active, err := sessionValidator(c.Context(), userCtx.SessionID)
+	if err != nil {
+		// Access tokens are short-lived; don't lock everyone out while Redis is unavailable
+		log.Printf("⚠️ Session check failed for session %s: %v", userCtx.SessionID, err)
+		return true
+	}
+	return active
+}
+
+// Protected middleware validates JWT tokens or personal access tokens and sets user context
 func Protected() fiber.Handler {
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
@@ -28,7 +98,7 @@ func Protected() fiber.Handler {
 		}
 
 		// Validate token and get user context
-		userCtx, err := utils.ValidateTokenStringToUUID(token, jwtSecret)
+		userCtx, err := authenticate(c, token, jwtSecret)
 		if err != nil {
 			log.Printf("❌ Token validation failed: %v", err)
 			switch err {
@@ -43,11 +113,17 @@ func Protected() fiber.Handler {
 			}
 		}
 
+		if !sessionActive(c, userCtx) {
+			log.Printf("❌ Token rejected, session %s revoked", userCtx.SessionID)
+			return utils.UnauthorizedResponse(c, "Token has been revoked")
+		}
+
 		log.Printf("✅ Token validated for user: %s (%s)", userCtx.Email, userCtx.ID)
 
 		// Set user context in fiber locals
 		c.Locals("user", userCtx)
 		c.Locals("userID", userCtx.ID)
+		c.Locals("sessionID", userCtx.SessionID)
 
 		return c.Next()
 	}
@@ -69,16 +145,124 @@ func RequireRole(role string) fiber.Handler {
 			})
 		}
 
-		return c.Next()
+		return privilegedNext(c, user)
+	}
+}
+
+// RequirePermission middleware checks that the user's role grants permission
+func RequirePermission(permission string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, err := utils.GetUserFromContext(c)
+		if err != nil {
+			return utils.UnauthorizedResponse(c, "User not authenticated")
+		}
+
+		if !models.RoleHasPermission(user.Role, permission) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"success": false,
+				"message": "Insufficient permissions",
+				"error":   "Access denied",
+			})
+		}
+
+		return privilegedNext(c, user)
 	}
 }
 
+// privilegedNext continues a request that uses a role's privileges, unless it comes from a
+// personal access token without the admin scope or a session that skipped the second factor
+func privilegedNext(c *fiber.Ctx, user *utils.UserContext) error {
+	if !user.HasScope(models.TokenScopeAdmin) {
+		return insufficientScope(c, models.TokenScopeAdmin)
+	}
+
+	// Privileged roles only act from sessions that passed a second factor
+	if models.RoleRequiresMFA(user.Role) && !user.MFAVerified {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"message": "Two-factor authentication required",
+			"error":   "mfa_required",
+		})
+	}
+
+	return c.Next()
+}
+
 // AdminOnly middleware ensures only admin users can access
 func AdminOnly() fiber.Handler {
-	return RequireRole("admin")
+	return RequireRole(models.RoleAdmin)
 }
 
-// OwnerOnly middleware checks if user is the owner of the resource
+// RequireScope lets personal access tokens through only when they were granted scope.
+// Sessions have every scope. Must run after Protected.
+func RequireScope(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, err := utils.GetUserFromContext(c)
+		if err != nil {
+			return utils.UnauthorizedResponse(c, "User not authenticated")
+		}
+
+		if !user.HasScope(scope) {
+			return insufficientScope(c, scope)
+		}
+
+		return c.Next()
+	}
+}
+
+// SessionOnly rejects personal access tokens, for account management that needs a
+// signed-in user. Must run after Protected.
+func SessionOnly() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, err := utils.GetUserFromContext(c)
+		if err != nil {
+			return utils.UnauthorizedResponse(c, "User not authenticated")
+		}
+
+		if user.IsAccessToken() {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"success": false,
+				"message": "Personal access tokens cannot be used here",
+				"error":   "session_required",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+func insufficientScope(c *fiber.Ctx, scope string) error {
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"success": false,
+		"message": "Token is missing the " + scope + " scope",
+		"error":   "insufficient_scope",
+	})
+}
+
+// RequireVerifiedEmail blocks accounts that haven't confirmed their email address.
+// Must run after Protected or WebSocketProtected.
+func RequireVerifiedEmail() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, err := utils.GetUserFromContext(c)
+		if err != nil {
+			return utils.UnauthorizedResponse(c, "User not authenticated")
+		}
+
+		if !user.EmailVerified {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"success": false,
+				"message": "Email address not verified",
+				"error":   "email_not_verified",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// OwnerOnly middleware marks the route as limited to the resource owner. It only records
+// the requirement in locals; services check ownership (or a permission such as
+// post.remove.any) themselves.
 func OwnerOnly() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		user, err := utils.GetUserFromContext(c)
@@ -107,8 +291,8 @@ func Optional() fiber.Handler {
 		}
 
 		jwtSecret := os.Getenv("JWT_SECRET")
-		userCtx, err := utils.ValidateTokenStringToUUID(token, jwtSecret)
-		if err != nil {
+		userCtx, err := authenticate(c, token, jwtSecret)
+		if err != nil || !sessionActive(c, userCtx) || !userCtx.HasScope(models.TokenScopeRead) {
 			return c.Next()
 		}
 
@@ -117,6 +301,7 @@ func Optional() fiber.Handler {
 		// Set user context in fiber locals
 		c.Locals("user", userCtx)
 		c.Locals("userID", userCtx.ID)
+		c.Locals("sessionID", userCtx.SessionID)
 		return c.Next()
 	}
 }
@@ -165,11 +350,17 @@ func WebSocketProtected() fiber.Handler {
 			}
 		}
 
+		if !sessionActive(c, userCtx) {
+			log.Printf("❌ WebSocket token rejected, session %s revoked", userCtx.SessionID)
+			return utils.UnauthorizedResponse(c, "Token has been revoked")
+		}
+
 		log.Printf("✅ WebSocket: Token validated from query param for user: %s (%s)", userCtx.Email, userCtx.ID)
 
 		// Set user context in fiber locals
 		c.Locals("user", userCtx)
 		c.Locals("userID", userCtx.ID)
+		c.Locals("sessionID", userCtx.SessionID)
 
 		return c.Next()
 	}
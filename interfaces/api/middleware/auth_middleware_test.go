@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gofiber-template/domain/models"
+	"gofiber-template/pkg/utils"
+)
+
+// permissionApp serves a route guarded by RequirePermission for the given caller
+func permissionApp(user *utils.UserContext, permission string) *fiber.App {
+	app := fiber.New()
+	app.Get("/guarded", func(c *fiber.Ctx) error {
+		c.Locals("user", user)
+		return c.Next()
+	}, RequirePermission(permission), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	return app
+}
+
+func TestRequirePermission(t *testing.T) {
+	tokenID := uuid.New()
+
+	cases := []struct {
+		name       string
+		user       *utils.UserContext
+		permission string
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name:       "role lacks the permission",
+			user:       &utils.UserContext{Role: models.RoleUser, MFAVerified: true},
+			permission: models.PermissionUserBan,
+			wantStatus: fiber.StatusForbidden,
+		},
+		{
+			name:       "moderator cannot assign roles",
+			user:       &utils.UserContext{Role: models.RoleModerator, MFAVerified: true},
+			permission: models.PermissionUserAssignRole,
+			wantStatus: fiber.StatusForbidden,
+		},
+		{
+			name:       "moderator with 2FA bans",
+			user:       &utils.UserContext{Role: models.RoleModerator, MFAVerified: true},
+			permission: models.PermissionUserBan,
+			wantStatus: fiber.StatusNoContent,
+		},
+		{
+			name:       "privileged role without 2FA",
+			user:       &utils.UserContext{Role: models.RoleAdmin},
+			permission: models.PermissionUserBan,
+			wantStatus: fiber.StatusForbidden,
+			wantError:  "mfa_required",
+		},
+		{
+			name:       "token without the admin scope",
+			user:       &utils.UserContext{Role: models.RoleAdmin, MFAVerified: true, TokenID: tokenID, Scopes: []string{models.TokenScopeRead}},
+			permission: models.PermissionUserBan,
+			wantStatus: fiber.StatusForbidden,
+			wantError:  "insufficient_scope",
+		},
+		{
+			name:       "token with the admin scope",
+			user:       &utils.UserContext{Role: models.RoleAdmin, MFAVerified: true, TokenID: tokenID, Scopes: []string{models.TokenScopeAdmin}},
+			permission: models.PermissionUserBan,
+			wantStatus: fiber.StatusNoContent,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := permissionApp(tc.user, tc.permission).Test(httptest.NewRequest("GET", "/guarded", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tc.wantStatus)
+			}
+			if tc.wantError == "" {
+				return
+			}
+
+			var body struct {
+				Error string `json:"error"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Error != tc.wantError {
+				t.Fatalf("error = %q, want %q", body.Error, tc.wantError)
+			}
+		})
+	}
+}
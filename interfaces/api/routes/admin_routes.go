@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"gofiber-template/domain/models"
+	"gofiber-template/interfaces/api/handlers"
+	"gofiber-template/interfaces/api/middleware"
+)
+
+func SetupAdminRoutes(api fiber.Router, h *handlers.Handlers) {
+	admin := api.Group("/admin")
+	admin.Use(middleware.Protected())
+
+	// Roles and the permissions they grant
+	admin.Get("/roles", middleware.RequirePermission(models.PermissionUserAssignRole), h.AdminHandler.ListRoles)
+
+	// User management
+	admin.Get("/users", middleware.RequirePermission(models.PermissionUserList), h.UserHandler.ListUsers)
+	admin.Put("/users/:id/role", middleware.RequirePermission(models.PermissionUserAssignRole), h.AdminHandler.AssignRole)
+	admin.Post("/users/:id/ban", middleware.RequirePermission(models.PermissionUserBan), h.AdminHandler.BanUser)
+	admin.Delete("/users/:id/ban", middleware.RequirePermission(models.PermissionUserBan), h.AdminHandler.UnbanUser)
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/gofiber/fiber/v2"
 	"gofiber-template/interfaces/api/handlers"
+	"gofiber-template/interfaces/api/middleware"
 )
 
 func SetupAuthRoutes(api fiber.Router, h *handlers.Handlers) {
@@ -12,7 +13,23 @@ func SetupAuthRoutes(api fiber.Router, h *handlers.Handlers) {
 	auth.Post("/register", h.UserHandler.Register)
 	auth.Post("/login", h.UserHandler.Login)
 
+	// Session tokens
+	auth.Post("/refresh", h.AuthHandler.Refresh)
+	auth.Post("/logout", h.AuthHandler.Logout)
+
+	// Second step of a login with two-factor authentication
+	auth.Post("/mfa/verify", h.MFAHandler.Verify)
+
+	// Email verification and password reset
+	auth.Post("/verify-email", h.AuthHandler.VerifyEmail)
+	auth.Post("/verify-email/resend", middleware.Protected(), middleware.SessionOnly(), h.AuthHandler.SendVerificationEmail)
+	auth.Post("/forgot-password", h.AuthHandler.ForgotPassword)
+	auth.Post("/reset-password", h.AuthHandler.ResetPassword)
+
 	// OAuth authentication
+	auth.Get("/oauth/providers", h.OAuthHandler.ListProviders)
+	auth.Get("/oauth/:provider", h.OAuthHandler.GetAuthURL)
+	auth.Get("/oauth/:provider/callback", h.OAuthHandler.Callback)
 	auth.Get("/google", h.OAuthHandler.GetGoogleAuthURL)
 	auth.Get("/google/callback", h.OAuthHandler.GoogleCallback)
 	auth.Post("/exchange", h.OAuthHandler.ExchangeCodeForToken)
@@ -2,37 +2,80 @@ package routes
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"gofiber-template/domain/models"
 	"gofiber-template/interfaces/api/handlers"
 	"gofiber-template/interfaces/api/middleware"
 )
 
 func SetupChatRoutes(api fiber.Router, h *handlers.Handlers) {
-	// All chat routes require authentication
-	chat := api.Group("/chat", middleware.Protected())
+	// All chat routes require authentication, a verified email address and (for personal access tokens) the chat scope
+	chat := api.Group("/chat", middleware.Protected(), middleware.RequireVerifiedEmail(), middleware.RequireScope(models.TokenScopeChat))
 
 	// Search users for chat
 	chat.Get("/search-users", h.ConversationHandler.SearchUsersForChat)
 
+	// Search messages across conversations
+	chat.Get("/search", h.MessageHandler.SearchMessages)
+
+	// Chat privacy settings
+	chat.Get("/settings", h.ConversationHandler.GetChatSettings)
+	chat.Put("/settings", h.ConversationHandler.UpdateChatSettings)
+
 	// Conversation routes (Nested URLs)
 	conversations := chat.Group("/conversations")
 	conversations.Get("/with/:username", h.ConversationHandler.GetOrCreateConversation)
 	conversations.Get("/", h.ConversationHandler.ListConversations)
 	conversations.Get("/unread-count", h.ConversationHandler.GetUnreadCount)
+	conversations.Get("/archived", h.ConversationHandler.ListArchivedConversations)
+
+	// Per-user conversation management
+	conversations.Put("/:conversationId/pin", h.ConversationHandler.PinConversation)
+	conversations.Delete("/:conversationId/pin", h.ConversationHandler.UnpinConversation)
+	conversations.Put("/:conversationId/archive", h.ConversationHandler.ArchiveConversation)
+	conversations.Delete("/:conversationId/archive", h.ConversationHandler.UnarchiveConversation)
+	conversations.Put("/:conversationId/mute", h.ConversationHandler.MuteConversation)
+	conversations.Delete("/:conversationId/mute", h.ConversationHandler.UnmuteConversation)
+	conversations.Delete("/:conversationId", h.ConversationHandler.DeleteConversation)
 
 	// Nested message routes under conversations
 	conversations.Get("/:conversationId/messages", h.MessageHandler.ListMessages)
 	conversations.Post("/:conversationId/messages", h.MessageHandler.SendMessage)
 	conversations.Post("/:conversationId/read", h.ConversationHandler.MarkAsRead)
+	conversations.Get("/:conversationId/participants", h.ConversationHandler.ListParticipants)
 
 	// Phase 2: Media/Links/Files filtering
 	conversations.Get("/:conversationId/media", h.MessageHandler.GetConversationMedia)
 	conversations.Get("/:conversationId/links", h.MessageHandler.GetConversationLinks)
 	conversations.Get("/:conversationId/files", h.MessageHandler.GetConversationFiles)
+	conversations.Get("/:conversationId/search", h.MessageHandler.SearchMessages)
+
+	// Message requests (from users the caller doesn't follow)
+	requests := chat.Group("/requests")
+	requests.Get("/", h.ConversationHandler.ListMessageRequests)
+	requests.Get("/count", h.ConversationHandler.CountMessageRequests)
+	requests.Post("/:conversationId/accept", h.ConversationHandler.AcceptMessageRequest)
+	requests.Post("/:conversationId/decline", h.ConversationHandler.DeclineMessageRequest)
+	requests.Post("/:conversationId/report", h.ConversationHandler.ReportMessageRequest)
+
+	// Group conversation routes
+	groups := chat.Group("/groups")
+	groups.Post("/", h.ConversationHandler.CreateGroup)
+	groups.Patch("/:conversationId", h.ConversationHandler.UpdateGroup)
+	groups.Post("/:conversationId/participants", h.ConversationHandler.AddParticipants)
+	groups.Patch("/:conversationId/participants/:userId", h.ConversationHandler.UpdateParticipantRole)
+	groups.Delete("/:conversationId/participants/:userId", h.ConversationHandler.RemoveParticipant)
+	groups.Post("/:conversationId/leave", h.ConversationHandler.LeaveGroup)
 
 	// Message routes (for message-specific operations)
 	messages := chat.Group("/messages")
+	messages.Post("/delivered", h.MessageHandler.MarkDelivered)
 	messages.Get("/:id/context", h.MessageHandler.GetMessageContext)
+	messages.Get("/:id/reply-context", h.MessageHandler.GetReplyContext)
+	messages.Get("/:id/reactions", h.MessageHandler.ListReactions)
+	messages.Get("/:id/receipts", h.MessageHandler.ListReceipts)
 	messages.Get("/:id", h.MessageHandler.GetMessage)
+	messages.Patch("/:id", h.MessageHandler.EditMessage)
+	messages.Delete("/:id", h.MessageHandler.DeleteMessage)
 
 	// Block routes
 	blocks := chat.Group("/blocks")
@@ -10,6 +10,7 @@ import (
 func SetupChatWebSocketRoutes(app *fiber.App, h *handlers.Handlers) {
 	// Chat WebSocket endpoint with JWT authentication from query parameter
 	app.Use("/chat/ws", middleware.WebSocketProtected())
+	app.Use("/chat/ws", middleware.RequireVerifiedEmail())
 	app.Use("/chat/ws", h.ChatWSHandler.WebSocketUpgrade)
 	app.Get("/chat/ws", websocket.New(h.ChatWSHandler.HandleChatWebSocket))
 }
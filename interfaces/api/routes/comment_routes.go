@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"gofiber-template/domain/models"
 	"gofiber-template/interfaces/api/handlers"
 	"gofiber-template/interfaces/api/middleware"
 )
@@ -19,7 +20,7 @@ func SetupCommentRoutes(api fiber.Router, h *handlers.Handlers) {
 
 	// Protected routes (require authentication)
 	comments.Use(middleware.Protected())
-	comments.Post("/", h.CommentHandler.CreateComment)
-	comments.Put("/:id", h.CommentHandler.UpdateComment)
-	comments.Delete("/:id", h.CommentHandler.DeleteComment)
+	comments.Post("/", middleware.RequireScope(models.TokenScopePost), h.CommentHandler.CreateComment)
+	comments.Put("/:id", middleware.RequireScope(models.TokenScopePost), h.CommentHandler.UpdateComment)
+	comments.Delete("/:id", middleware.RequireScope(models.TokenScopePost), h.CommentHandler.DeleteComment)
 }
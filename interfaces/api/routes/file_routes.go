@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"gofiber-template/domain/models"
 	"gofiber-template/interfaces/api/handlers"
 	"gofiber-template/interfaces/api/middleware"
 )
@@ -9,9 +10,9 @@ import (
 func SetupFileRoutes(api fiber.Router, h *handlers.Handlers) {
 	files := api.Group("/files")
 	files.Use(middleware.Protected())
-	files.Post("/upload", h.FileHandler.UploadFile)
-	files.Get("/", middleware.AdminOnly(), h.FileHandler.ListFiles)
-	files.Get("/my", h.FileHandler.GetUserFiles)
-	files.Get("/:id", h.FileHandler.GetFile)
-	files.Delete("/:id", middleware.OwnerOnly(), h.FileHandler.DeleteFile)
+	files.Post("/upload", middleware.RequireScope(models.TokenScopePost), h.FileHandler.UploadFile)
+	files.Get("/", middleware.RequirePermission(models.PermissionSystemManage), h.FileHandler.ListFiles)
+	files.Get("/my", middleware.RequireScope(models.TokenScopeRead), h.FileHandler.GetUserFiles)
+	files.Get("/:id", middleware.RequireScope(models.TokenScopeRead), h.FileHandler.GetFile)
+	files.Delete("/:id", middleware.RequireScope(models.TokenScopePost), middleware.OwnerOnly(), h.FileHandler.DeleteFile)
 }
\ No newline at end of file
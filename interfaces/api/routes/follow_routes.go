@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"gofiber-template/domain/models"
 	"gofiber-template/interfaces/api/handlers"
 	"gofiber-template/interfaces/api/middleware"
 )
@@ -16,7 +17,7 @@ func SetupFollowRoutes(api fiber.Router, h *handlers.Handlers) {
 
 	// Protected routes (require authentication)
 	follows.Use(middleware.Protected())
-	follows.Post("/user/:userId", h.FollowHandler.Follow)
-	follows.Delete("/user/:userId", h.FollowHandler.Unfollow)
-	follows.Get("/mutuals", h.FollowHandler.GetMutualFollows)
+	follows.Post("/user/:userId", middleware.RequireScope(models.TokenScopePost), h.FollowHandler.Follow)
+	follows.Delete("/user/:userId", middleware.RequireScope(models.TokenScopePost), h.FollowHandler.Unfollow)
+	follows.Get("/mutuals", middleware.RequireScope(models.TokenScopeRead), h.FollowHandler.GetMutualFollows)
 }
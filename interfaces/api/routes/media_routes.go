@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"gofiber-template/domain/models"
 	"gofiber-template/interfaces/api/handlers"
 	"gofiber-template/interfaces/api/middleware"
 )
@@ -15,7 +16,7 @@ func SetupMediaRoutes(api fiber.Router, h *handlers.Handlers) {
 
 	// Protected routes (require authentication)
 	media.Use(middleware.Protected())
-	media.Post("/upload/image", h.MediaHandler.UploadImage)
-	media.Post("/upload/video", h.MediaHandler.UploadVideo)
-	media.Delete("/:id", h.MediaHandler.DeleteMedia)
+	media.Post("/upload/image", middleware.RequireScope(models.TokenScopePost), h.MediaHandler.UploadImage)
+	media.Post("/upload/video", middleware.RequireScope(models.TokenScopePost), h.MediaHandler.UploadVideo)
+	media.Delete("/:id", middleware.RequireScope(models.TokenScopePost), h.MediaHandler.DeleteMedia)
 }
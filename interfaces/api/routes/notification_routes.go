@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"gofiber-template/domain/models"
 	"gofiber-template/interfaces/api/handlers"
 	"gofiber-template/interfaces/api/middleware"
 )
@@ -11,20 +12,21 @@ func SetupNotificationRoutes(api fiber.Router, h *handlers.Handlers) {
 	notifications.Use(middleware.Protected())
 
 	// Settings (must be before /:id to avoid route conflict)
-	notifications.Get("/settings", h.NotificationHandler.GetSettings)
-	notifications.Put("/settings", h.NotificationHandler.UpdateSettings)
+	notifications.Get("/settings", middleware.RequireScope(models.TokenScopeRead), h.NotificationHandler.GetSettings)
+	notifications.Put("/settings", middleware.SessionOnly(), h.NotificationHandler.UpdateSettings)
 
 	// Get notifications
-	notifications.Get("/", h.NotificationHandler.GetNotifications)
-	notifications.Get("/unread", h.NotificationHandler.GetUnreadNotifications)
-	notifications.Get("/unread/count", h.NotificationHandler.GetUnreadCount)
-	notifications.Get("/:id", h.NotificationHandler.GetNotification)
+	notifications.Get("/", middleware.RequireScope(models.TokenScopeRead), h.NotificationHandler.GetNotifications)
+	notifications.Get("/unread", middleware.RequireScope(models.TokenScopeRead), h.NotificationHandler.GetUnreadNotifications)
+	notifications.Get("/unread/count", middleware.RequireScope(models.TokenScopeRead), h.NotificationHandler.GetUnreadCount)
+	notifications.Get("/:id", middleware.RequireScope(models.TokenScopeRead), h.NotificationHandler.GetNotification)
+	notifications.Get("/:id/deliveries", middleware.RequireScope(models.TokenScopeRead), h.NotificationHandler.GetDeliveryStatus)
 
-	// Mark as read
-	notifications.Put("/:id/read", h.NotificationHandler.MarkAsRead)
-	notifications.Put("/read-all", h.NotificationHandler.MarkAllAsRead)
+	// Mark as read (no token scope covers changing the inbox)
+	notifications.Put("/:id/read", middleware.SessionOnly(), h.NotificationHandler.MarkAsRead)
+	notifications.Put("/read-all", middleware.SessionOnly(), h.NotificationHandler.MarkAllAsRead)
 
 	// Delete notifications
-	notifications.Delete("/:id", h.NotificationHandler.DeleteNotification)
-	notifications.Delete("/", h.NotificationHandler.DeleteAllNotifications)
+	notifications.Delete("/:id", middleware.SessionOnly(), h.NotificationHandler.DeleteNotification)
+	notifications.Delete("/", middleware.SessionOnly(), h.NotificationHandler.DeleteAllNotifications)
 }
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"gofiber-template/domain/models"
 	"gofiber-template/interfaces/api/handlers"
 	"gofiber-template/interfaces/api/middleware"
 )
@@ -20,9 +21,9 @@ func SetupPostRoutes(api fiber.Router, h *handlers.Handlers) {
 
 	// Protected routes (require authentication)
 	posts.Use(middleware.Protected())
-	posts.Post("/", h.PostHandler.CreatePost)
-	posts.Put("/:id", h.PostHandler.UpdatePost)
-	posts.Delete("/:id", h.PostHandler.DeletePost)
-	posts.Post("/:id/crosspost", h.PostHandler.CreateCrosspost)
-	posts.Get("/feed", h.PostHandler.GetFeed)
+	posts.Post("/", middleware.RequireScope(models.TokenScopePost), h.PostHandler.CreatePost)
+	posts.Put("/:id", middleware.RequireScope(models.TokenScopePost), h.PostHandler.UpdatePost)
+	posts.Delete("/:id", middleware.RequireScope(models.TokenScopePost), h.PostHandler.DeletePost)
+	posts.Post("/:id/crosspost", middleware.RequireScope(models.TokenScopePost), h.PostHandler.CreateCrosspost)
+	posts.Get("/feed", middleware.RequireScope(models.TokenScopeRead), h.PostHandler.GetFeed)
 }
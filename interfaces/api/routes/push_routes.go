@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"gofiber-template/domain/models"
 	"gofiber-template/interfaces/api/handlers"
 	"gofiber-template/interfaces/api/middleware"
 )
@@ -14,6 +15,7 @@ func SetupPushRoutes(api fiber.Router, h *handlers.Handlers) {
 
 	// Protected routes (require authentication)
 	push.Use(middleware.Protected())
-	push.Post("/subscribe", h.PushHandler.Subscribe)
-	push.Post("/unsubscribe", h.PushHandler.Unsubscribe)
+	push.Post("/subscribe", middleware.SessionOnly(), h.PushHandler.Subscribe)
+	push.Post("/unsubscribe", middleware.SessionOnly(), h.PushHandler.Unsubscribe)
+	push.Get("/metrics", middleware.RequirePermission(models.PermissionSystemManage), h.PushHandler.GetMetrics)
 }
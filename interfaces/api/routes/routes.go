@@ -19,6 +19,7 @@ func SetupRoutes(app *fiber.App, h *handlers.Handlers) {
 	SetupAuthRoutes(api, h)
 	SetupUserRoutes(api, h)
 	SetupProfileRoutes(api, h)
+	SetupAdminRoutes(api, h)
 
 	// Setup social media routes
 	SetupPostRoutes(api, h)
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"gofiber-template/domain/models"
 	"gofiber-template/interfaces/api/handlers"
 	"gofiber-template/interfaces/api/middleware"
 )
@@ -10,8 +11,8 @@ func SetupSavedPostRoutes(api fiber.Router, h *handlers.Handlers) {
 	saved := api.Group("/saved")
 	saved.Use(middleware.Protected())
 
-	saved.Post("/posts/:postId", h.SavedPostHandler.SavePost)
-	saved.Delete("/posts/:postId", h.SavedPostHandler.UnsavePost)
-	saved.Get("/posts/:postId/status", h.SavedPostHandler.IsSaved)
-	saved.Get("/posts", h.SavedPostHandler.GetSavedPosts)
+	saved.Post("/posts/:postId", middleware.RequireScope(models.TokenScopePost), h.SavedPostHandler.SavePost)
+	saved.Delete("/posts/:postId", middleware.RequireScope(models.TokenScopePost), h.SavedPostHandler.UnsavePost)
+	saved.Get("/posts/:postId/status", middleware.RequireScope(models.TokenScopeRead), h.SavedPostHandler.IsSaved)
+	saved.Get("/posts", middleware.RequireScope(models.TokenScopeRead), h.SavedPostHandler.GetSavedPosts)
 }
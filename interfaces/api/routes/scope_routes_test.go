@@ -0,0 +1,94 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/google/uuid"
+	"gofiber-template/domain/dto"
+	"gofiber-template/domain/models"
+	"gofiber-template/interfaces/api/handlers"
+	"gofiber-template/interfaces/api/middleware"
+)
+
+// newScopeTestApp mounts the routes without handlers: every request in these tests must
+// be turned away by the middleware, one that gets through fails with a 500
+func newScopeTestApp(t *testing.T, scopes ...string) *fiber.App {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	middleware.SetAccessTokenValidator(func(ctx context.Context, token string, ip string) (*dto.AccessTokenPrincipal, error) {
+		return &dto.AccessTokenPrincipal{
+			TokenID:       uuid.New(),
+			UserID:        uuid.New(),
+			Role:          "user",
+			Scopes:        scopes,
+			EmailVerified: true,
+		}, nil
+	})
+	t.Cleanup(func() { middleware.SetAccessTokenValidator(nil) })
+
+	app := fiber.New()
+	app.Use(recover.New())
+	api := app.Group("/api/v1")
+	h := &handlers.Handlers{}
+	SetupNotificationRoutes(api, h)
+	SetupSearchRoutes(api, h)
+	return app
+}
+
+func TestReadScopedTokenCannotChangeNotificationsOrSearchHistory(t *testing.T) {
+	app := newScopeTestApp(t, models.TokenScopeRead)
+
+	writes := []struct{ method, path string }{
+		{fiber.MethodPut, "/api/v1/notifications/settings"},
+		{fiber.MethodPut, "/api/v1/notifications/" + uuid.NewString() + "/read"},
+		{fiber.MethodPut, "/api/v1/notifications/read-all"},
+		{fiber.MethodDelete, "/api/v1/notifications/" + uuid.NewString()},
+		{fiber.MethodDelete, "/api/v1/notifications/"},
+		{fiber.MethodDelete, "/api/v1/search/history"},
+		{fiber.MethodDelete, "/api/v1/search/history/" + uuid.NewString()},
+	}
+
+	for _, route := range writes {
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			code, body := doWithToken(t, app, route.method, route.path)
+			if code != fiber.StatusForbidden || body["error"] != "session_required" {
+				t.Fatalf("expected 403 session_required, got %d %v", code, body)
+			}
+		})
+	}
+}
+
+func TestNotificationReadsStillRequireReadScope(t *testing.T) {
+	app := newScopeTestApp(t, models.TokenScopeVote)
+
+	for _, path := range []string{"/api/v1/notifications/", "/api/v1/notifications/unread/count", "/api/v1/search/history"} {
+		code, body := doWithToken(t, app, fiber.MethodGet, path)
+		if code != fiber.StatusForbidden || body["error"] != "insufficient_scope" {
+			t.Fatalf("GET %s: expected 403 insufficient_scope, got %d %v", path, code, body)
+		}
+	}
+}
+
+func doWithToken(t *testing.T, app *fiber.App, method string, path string) (int, map[string]any) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer "+models.PersonalAccessTokenPrefix+"test")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var body map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, body
+}
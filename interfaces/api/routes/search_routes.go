@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"gofiber-template/domain/models"
 	"gofiber-template/interfaces/api/handlers"
 	"gofiber-template/interfaces/api/middleware"
 )
@@ -15,7 +16,7 @@ func SetupSearchRoutes(api fiber.Router, h *handlers.Handlers) {
 
 	// Protected routes (require authentication)
 	search.Use(middleware.Protected())
-	search.Get("/history", h.SearchHandler.GetSearchHistory)
-	search.Delete("/history", h.SearchHandler.ClearSearchHistory)
-	search.Delete("/history/:id", h.SearchHandler.DeleteSearchHistoryItem)
+	search.Get("/history", middleware.RequireScope(models.TokenScopeRead), h.SearchHandler.GetSearchHistory)
+	search.Delete("/history", middleware.SessionOnly(), h.SearchHandler.ClearSearchHistory)
+	search.Delete("/history/:id", middleware.SessionOnly(), h.SearchHandler.DeleteSearchHistoryItem)
 }
@@ -2,16 +2,21 @@ package routes
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"gofiber-template/domain/models"
 	"gofiber-template/interfaces/api/handlers"
+	"gofiber-template/interfaces/api/middleware"
 )
 
 func SetupTagRoutes(api fiber.Router, h *handlers.Handlers) {
 	tags := api.Group("/tags")
 
-	// All tag routes are public
+	// Public routes
 	tags.Get("/", h.TagHandler.ListTags)
 	tags.Get("/popular", h.TagHandler.GetPopularTags)
 	tags.Get("/search", h.TagHandler.SearchTags)
 	tags.Get("/:id", h.TagHandler.GetTag)
 	tags.Get("/name/:name", h.TagHandler.GetTagByName)
+
+	// Moderation
+	tags.Post("/:id/merge", middleware.Protected(), middleware.RequirePermission(models.PermissionTagMerge), h.TagHandler.MergeTags)
 }
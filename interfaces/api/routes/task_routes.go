@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"gofiber-template/domain/models"
 	"gofiber-template/interfaces/api/handlers"
 	"gofiber-template/interfaces/api/middleware"
 )
@@ -9,10 +10,10 @@ import (
 func SetupTaskRoutes(api fiber.Router, h *handlers.Handlers) {
 	tasks := api.Group("/tasks")
 	tasks.Use(middleware.Protected())
-	tasks.Post("/", h.TaskHandler.CreateTask)
-	tasks.Get("/", middleware.AdminOnly(), h.TaskHandler.ListTasks)
-	tasks.Get("/my", h.TaskHandler.GetUserTasks)
-	tasks.Get("/:id", h.TaskHandler.GetTask)
-	tasks.Put("/:id", middleware.OwnerOnly(), h.TaskHandler.UpdateTask)
-	tasks.Delete("/:id", middleware.OwnerOnly(), h.TaskHandler.DeleteTask)
+	tasks.Post("/", middleware.RequireScope(models.TokenScopePost), h.TaskHandler.CreateTask)
+	tasks.Get("/", middleware.RequirePermission(models.PermissionSystemManage), h.TaskHandler.ListTasks)
+	tasks.Get("/my", middleware.RequireScope(models.TokenScopeRead), h.TaskHandler.GetUserTasks)
+	tasks.Get("/:id", middleware.RequireScope(models.TokenScopeRead), h.TaskHandler.GetTask)
+	tasks.Put("/:id", middleware.RequireScope(models.TokenScopePost), middleware.OwnerOnly(), h.TaskHandler.UpdateTask)
+	tasks.Delete("/:id", middleware.RequireScope(models.TokenScopePost), middleware.OwnerOnly(), h.TaskHandler.DeleteTask)
 }
\ No newline at end of file
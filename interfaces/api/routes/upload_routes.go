@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"gofiber-template/domain/models"
 	"gofiber-template/interfaces/api/handlers"
 	"gofiber-template/interfaces/api/middleware"
 )
@@ -11,5 +12,5 @@ func SetupUploadRoutes(api fiber.Router, h *handlers.Handlers) {
 
 	// Protected routes (require authentication)
 	upload.Use(middleware.Protected())
-	upload.Post("/file", h.FileUploadHandler.UploadFile)
+	upload.Post("/file", middleware.RequireScope(models.TokenScopePost), h.FileUploadHandler.UploadFile)
 }
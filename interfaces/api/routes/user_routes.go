@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"gofiber-template/domain/models"
 	"gofiber-template/interfaces/api/handlers"
 	"gofiber-template/interfaces/api/middleware"
 )
@@ -9,8 +10,37 @@ import (
 func SetupUserRoutes(api fiber.Router, h *handlers.Handlers) {
 	users := api.Group("/users")
 	users.Use(middleware.Protected())
-	users.Get("/profile", h.UserHandler.GetProfile)
+	users.Get("/profile", middleware.RequireScope(models.TokenScopeRead), h.UserHandler.GetProfile)
+	users.Get("/", middleware.RequirePermission(models.PermissionUserList), h.UserHandler.ListUsers)
+
+	// Account management needs a signed-in session, not a personal access token
+	users.Use(middleware.SessionOnly())
 	users.Put("/profile", h.UserHandler.UpdateProfile)
 	users.Delete("/profile", h.UserHandler.DeleteUser)
-	users.Get("/", middleware.AdminOnly(), h.UserHandler.ListUsers)
+	users.Put("/password", h.UserHandler.ChangePassword)
+
+	// Login methods (sensitive changes need a recent sign-in or POST /reauth)
+	users.Post("/reauth", h.UserHandler.Reauthenticate)
+	users.Get("/login-methods", h.UserHandler.GetLoginMethods)
+	users.Post("/password", h.UserHandler.SetPassword)
+	users.Delete("/password", h.UserHandler.RemovePassword)
+	users.Post("/identities/:provider", h.OAuthHandler.LinkIdentity)
+	users.Delete("/identities/:provider", h.OAuthHandler.UnlinkIdentity)
+
+	// Two-factor authentication
+	users.Get("/mfa", h.MFAHandler.GetStatus)
+	users.Post("/mfa/setup", h.MFAHandler.Setup)
+	users.Post("/mfa/enable", h.MFAHandler.Enable)
+	users.Post("/mfa/disable", h.MFAHandler.Disable)
+	users.Post("/mfa/recovery-codes", h.MFAHandler.RegenerateRecoveryCodes)
+
+	// Signed-in devices
+	users.Get("/sessions", h.AuthHandler.ListSessions)
+	users.Delete("/sessions", h.AuthHandler.RevokeOtherSessions)
+	users.Delete("/sessions/:sessionId", h.AuthHandler.RevokeSession)
+
+	// Personal access tokens for bots and integrations (creating one needs a recent sign-in)
+	users.Get("/tokens", h.AccessTokenHandler.ListTokens)
+	users.Post("/tokens", h.AccessTokenHandler.CreateToken)
+	users.Delete("/tokens/:tokenId", h.AccessTokenHandler.RevokeToken)
 }
\ No newline at end of file
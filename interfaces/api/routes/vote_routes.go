@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"gofiber-template/domain/models"
 	"gofiber-template/interfaces/api/handlers"
 	"gofiber-template/interfaces/api/middleware"
 )
@@ -15,7 +16,7 @@ func SetupVoteRoutes(api fiber.Router, h *handlers.Handlers) {
 
 	// Protected routes (require authentication)
 	votes.Use(middleware.Protected())
-	votes.Post("/", h.VoteHandler.Vote)
-	votes.Delete("/:targetType/:targetId", h.VoteHandler.Unvote)
-	votes.Get("/user", h.VoteHandler.GetUserVotes)
+	votes.Post("/", middleware.RequireScope(models.TokenScopeVote), h.VoteHandler.Vote)
+	votes.Delete("/:targetType/:targetId", middleware.RequireScope(models.TokenScopeVote), h.VoteHandler.Unvote)
+	votes.Get("/user", middleware.RequireScope(models.TokenScopeRead), h.VoteHandler.GetUserVotes)
 }
@@ -55,13 +55,17 @@ func (h *ChatWebSocketHandler) HandleChatWebSocket(c *websocket.Conn) {
 
 	log.Printf("✅ Chat WebSocket: User connected: %s", userID)
 
+	// Session is used to close the connection when the user logs out
+	sessionID, _ := c.Locals("sessionID").(uuid.UUID)
+
 	// Create client with ready channel for synchronization
 	client := &chatWebsocket.ChatClient{
-		UserID: userID,
-		Conn:   c,
-		Send:   make(chan []byte, 256),
-		Hub:    h.chatHub,
-		Ready:  make(chan bool),
+		UserID:    userID,
+		SessionID: sessionID,
+		Conn:      c,
+		Send:      make(chan []byte, 256),
+		Hub:       h.chatHub,
+		Ready:     make(chan bool),
 	}
 
 	// Start WritePump first (it will signal when ready)
@@ -0,0 +1,70 @@
+package auth_code_store
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps codes in process memory. Only for tests and single-instance
+// development: a code issued by one replica can't be exchanged on another.
+type MemoryStore struct {
+	mu    sync.Mutex
+	codes map[string]*AuthCodeData
+}
+
+func NewMemoryStore() *MemoryStore {
+	store := &MemoryStore{
+		codes: make(map[string]*AuthCodeData),
+	}
+	// Start cleanup goroutine
+	go store.cleanupExpiredCodes()
+	return store
+}
+
+func (s *MemoryStore) Save(ctx context.Context, code string, data *AuthCodeData, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := *data
+	saved.ExpiresAt = time.Now().Add(ttl)
+	s.codes[code] = &saved
+	return nil
+}
+
+func (s *MemoryStore) Take(ctx context.Context, code string) (*AuthCodeData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, exists := s.codes[code]
+	if !exists {
+		return nil, nil
+	}
+
+	// Delete code after use (one-time use)
+	delete(s.codes, code)
+
+	if time.Now().After(data.ExpiresAt) {
+		return nil, nil
+	}
+	return data, nil
+}
+
+// cleanupExpiredCodes removes expired codes every minute
+func (s *MemoryStore) cleanupExpiredCodes() {
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.mu.Lock()
+		now := time.Now()
+		for code, data := range s.codes {
+			if now.After(data.ExpiresAt) {
+				delete(s.codes, code)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+var _ Store = (*MemoryStore)(nil)
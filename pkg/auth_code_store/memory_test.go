@@ -0,0 +1,63 @@
+package auth_code_store
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreCodesAreSingleUse(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	if err := store.Save(ctx, "code-1", &AuthCodeData{State: "state-1"}, CodeTTL); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := store.Take(ctx, "code-1")
+	if err != nil || data == nil || data.State != "state-1" {
+		t.Fatalf("expected the saved data, got %+v %v", data, err)
+	}
+
+	if data, err := store.Take(ctx, "code-1"); err != nil || data != nil {
+		t.Fatalf("expected the second take to find nothing, got %+v %v", data, err)
+	}
+}
+
+func TestMemoryStoreUnknownCode(t *testing.T) {
+	if data, err := NewMemoryStore().Take(context.Background(), "missing"); err != nil || data != nil {
+		t.Fatalf("expected nothing for an unknown code, got %+v %v", data, err)
+	}
+}
+
+func TestMemoryStoreExpiredCode(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	if err := store.Save(ctx, "code-1", &AuthCodeData{State: "state-1"}, -time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, err := store.Take(ctx, "code-1"); err != nil || data != nil {
+		t.Fatalf("expected an expired code to be rejected, got %+v %v", data, err)
+	}
+}
+
+func TestMemoryStoreKeepsACopy(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	data := &AuthCodeData{State: "state-1"}
+	if err := store.Save(ctx, "code-1", data, CodeTTL); err != nil {
+		t.Fatal(err)
+	}
+	data.State = "changed"
+
+	taken, err := store.Take(ctx, "code-1")
+	if err != nil || taken == nil || taken.State != "state-1" {
+		t.Fatalf("expected the data as saved, got %+v %v", taken, err)
+	}
+	if !taken.ExpiresAt.After(time.Now()) {
+		t.Fatalf("expected the expiry to be set from the ttl, got %s", taken.ExpiresAt)
+	}
+}
@@ -1,110 +1,60 @@
 package auth_code_store
 
 import (
+	"context"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/base64"
-	"sync"
 	"time"
 
 	"gofiber-template/domain/dto"
 )
 
+// CodeTTL is how long the frontend has to exchange a code
+const CodeTTL = 5 * time.Minute
+
 // AuthCodeData stores the data associated with an authorization code
 type AuthCodeData struct {
-	Token     string
-	User      dto.UserResponse
-	IsNewUser bool
-	State     string
-	ExpiresAt time.Time
-}
-
-// Store manages authorization codes
-type Store struct {
-	mu    sync.RWMutex
-	codes map[string]*AuthCodeData
-}
-
-var (
-	instance *Store
-	once     sync.Once
-)
-
-// GetInstance returns singleton instance of the store
-func GetInstance() *Store {
-	once.Do(func() {
-		instance = &Store{
-			codes: make(map[string]*AuthCodeData),
-		}
-		// Start cleanup goroutine
-		go instance.cleanupExpiredCodes()
-	})
-	return instance
+	Tokens        dto.TokenPair
+	MFAToken      string // Set instead of Tokens when the user still has to pass 2FA
+	User          dto.UserResponse
+	IsNewUser     bool
+	State         string
+	CodeChallenge string // S256 PKCE challenge sent by the frontend when starting the login, if any
+	ExpiresAt     time.Time
 }
 
-// GenerateCode creates a new authorization code and stores the data
-func (s *Store) GenerateCode(token string, user dto.UserResponse, isNewUser bool, state string) (string, error) {
-	// Generate random code
-	b := make([]byte, 32)
-	if _, err := rand.Read(b); err != nil {
-		return "", err
+// Matches reports whether an exchange presented the state the code was issued for
+// and, when the login used PKCE, the verifier for its challenge
+func (d *AuthCodeData) Matches(state string, codeVerifier string) bool {
+	if subtle.ConstantTimeCompare([]byte(d.State), []byte(state)) != 1 {
+		return false
 	}
-	code := base64.URLEncoding.EncodeToString(b)
-
-	s.mu.Lock()
-	defer s.mu.Unlock()
 
-	// Store code with 5 minute expiration
-	s.codes[code] = &AuthCodeData{
-		Token:     token,
-		User:      user,
-		IsNewUser: isNewUser,
-		State:     state,
-		ExpiresAt: time.Now().Add(5 * time.Minute),
+	if d.CodeChallenge == "" {
+		return true
 	}
-
-	return code, nil
+	sum := sha256.Sum256([]byte(codeVerifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(d.CodeChallenge), []byte(challenge)) == 1
 }
 
-// ExchangeCode retrieves and deletes the data for a given code
-func (s *Store) ExchangeCode(code string, state string) (*AuthCodeData, bool) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// Store keeps authorization codes between the OAuth callback and the exchange.
+// Codes are single use: Take removes the code in the same step that reads it.
+type Store interface {
+	// Save stores data under code until ttl passes
+	Save(ctx context.Context, code string, data *AuthCodeData, ttl time.Duration) error
 
-	data, exists := s.codes[code]
-	if !exists {
-		return nil, false
-	}
-
-	// Check if code is expired
-	if time.Now().After(data.ExpiresAt) {
-		delete(s.codes, code)
-		return nil, false
-	}
-
-	// Validate state if provided
-	if state != "" && data.State != state {
-		return nil, false
-	}
-
-	// Delete code after use (one-time use)
-	delete(s.codes, code)
-
-	return data, true
+	// Take returns and deletes the data for code; nil when unknown or expired
+	Take(ctx context.Context, code string) (*AuthCodeData, error)
 }
 
-// cleanupExpiredCodes removes expired codes every minute
-func (s *Store) cleanupExpiredCodes() {
-	ticker := time.NewTicker(1 * time.Minute)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		s.mu.Lock()
-		now := time.Now()
-		for code, data := range s.codes {
-			if now.After(data.ExpiresAt) {
-				delete(s.codes, code)
-			}
-		}
-		s.mu.Unlock()
+// NewCode returns a new random authorization code
+func NewCode() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
 	}
+	return base64.URLEncoding.EncodeToString(b), nil
 }
@@ -0,0 +1,57 @@
+package auth_code_store
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+)
+
+func s256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func TestAuthCodeDataMatches(t *testing.T) {
+	const verifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+
+	withPKCE := &AuthCodeData{State: "state-1", CodeChallenge: s256(verifier)}
+	withoutPKCE := &AuthCodeData{State: "state-1"}
+
+	tests := []struct {
+		name     string
+		data     *AuthCodeData
+		state    string
+		verifier string
+		want     bool
+	}{
+		{"state and verifier", withPKCE, "state-1", verifier, true},
+		{"wrong state", withPKCE, "state-2", verifier, false},
+		{"empty state", withPKCE, "", verifier, false},
+		{"wrong verifier", withPKCE, "state-1", verifier + "x", false},
+		{"missing verifier", withPKCE, "state-1", "", false},
+		{"challenge as verifier", withPKCE, "state-1", s256(verifier), false},
+		{"no PKCE", withoutPKCE, "state-1", "", true},
+		{"no PKCE wrong state", withoutPKCE, "other", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.data.Matches(tt.state, tt.verifier); got != tt.want {
+			t.Errorf("%s: Matches = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNewCode(t *testing.T) {
+	first, err := NewCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first == second || len(first) != 44 {
+		t.Fatalf("expected distinct 32-byte codes, got %q and %q", first, second)
+	}
+}
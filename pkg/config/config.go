@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"github.com/joho/godotenv"
 )
 
@@ -14,6 +15,10 @@ type Config struct {
 	Bunny    BunnyConfig
 	OAuth    OAuthConfig
 	VAPID    VAPIDConfig
+	Push     PushConfig
+	Mail     MailConfig
+	Account  AccountConfig
+	MFA      MFAConfig
 }
 
 type AppConfig struct {
@@ -40,7 +45,29 @@ type RedisConfig struct {
 }
 
 type JWTConfig struct {
-	Secret string
+	Secret          string
+	AccessTokenTTL  int // Minutes an access token is valid
+	RefreshTokenTTL int // Days a refresh token is valid (renewed on every rotation)
+}
+
+type MailConfig struct {
+	Driver       string // log, file or smtp
+	From         string
+	SMTPHost     string
+	SMTPPort     string // 465 uses implicit TLS, anything else STARTTLS when offered
+	SMTPUsername string
+	SMTPPassword string
+	FileDir      string // Where the file driver writes .eml files
+}
+
+type AccountConfig struct {
+	EmailVerificationTTL int // Hours an email verification link is valid
+	PasswordResetTTL     int // Minutes a password reset link is valid
+}
+
+type MFAConfig struct {
+	Issuer        string // Name authenticator apps show next to the code
+	EncryptionKey string // Encrypts stored TOTP secrets; falls back to the JWT secret
 }
 
 type BunnyConfig struct {
@@ -56,28 +83,52 @@ type BunnyConfig struct {
 	StreamCDNURL    string
 }
 
+// OAuthConfig holds the sign-in providers; a provider is enabled once its client ID and secret are set
 type OAuthConfig struct {
-	Google GoogleOAuthConfig
+	Google   OAuthProviderConfig
+	GitHub   OAuthProviderConfig
+	Facebook OAuthProviderConfig
+	LINE     OAuthProviderConfig
+	OIDC     OIDCConfig
 }
 
-type GoogleOAuthConfig struct {
+type OAuthProviderConfig struct {
 	ClientID     string
 	ClientSecret string
 	RedirectURL  string
 }
 
+// OIDCConfig is a generic OpenID Connect provider, configured from its issuer's discovery document
+type OIDCConfig struct {
+	OAuthProviderConfig
+	Name      string // Provider name in routes, e.g. /auth/oauth/{name}
+	IssuerURL string
+	Scopes    []string
+}
+
 type VAPIDConfig struct {
 	PublicKey  string
 	PrivateKey string
 	Subject    string
 }
 
+type PushConfig struct {
+	DefaultTTL       int // Seconds a push service keeps an undelivered message
+	MaxPerUserMinute int // Per-user cap on pushes per minute (0 = unlimited)
+}
+
 func LoadConfig() (*Config, error) {
 	// Load .env file if it exists (for local development)
 	// In production/Docker, environment variables are set by the container
 	_ = godotenv.Load()
 
 	redisDB, _ := strconv.Atoi(getEnv("REDIS_DB", "0"))
+	pushDefaultTTL, _ := strconv.Atoi(getEnv("PUSH_DEFAULT_TTL", "86400"))
+	pushMaxPerUserMinute, _ := strconv.Atoi(getEnv("PUSH_MAX_PER_USER_MINUTE", "20"))
+	accessTokenTTL, _ := strconv.Atoi(getEnv("JWT_ACCESS_TOKEN_TTL_MINUTES", "15"))
+	refreshTokenTTL, _ := strconv.Atoi(getEnv("JWT_REFRESH_TOKEN_TTL_DAYS", "30"))
+	emailVerificationTTL, _ := strconv.Atoi(getEnv("EMAIL_VERIFICATION_TTL_HOURS", "24"))
+	passwordResetTTL, _ := strconv.Atoi(getEnv("PASSWORD_RESET_TTL_MINUTES", "60"))
 
 	config := &Config{
 		App: AppConfig{
@@ -101,7 +152,9 @@ func LoadConfig() (*Config, error) {
 			DB:       redisDB,
 		},
 		JWT: JWTConfig{
-			Secret: getEnv("JWT_SECRET", "your-secret-key"),
+			Secret:          getEnv("JWT_SECRET", "your-secret-key"),
+			AccessTokenTTL:  accessTokenTTL,
+			RefreshTokenTTL: refreshTokenTTL,
 		},
 		Bunny: BunnyConfig{
 			// Storage config
@@ -116,17 +169,63 @@ func LoadConfig() (*Config, error) {
 			StreamCDNURL:    getEnv("BUNNY_STREAM_CDN_URL", "https://vz-b1631ae0-4c8.b-cdn.net"),
 		},
 		OAuth: OAuthConfig{
-			Google: GoogleOAuthConfig{
+			Google: OAuthProviderConfig{
 				ClientID:     getEnv("GOOGLE_CLIENT_ID", ""),
 				ClientSecret: getEnv("GOOGLE_CLIENT_SECRET", ""),
 				RedirectURL:  getEnv("GOOGLE_REDIRECT_URL", "http://localhost:8080/api/v1/auth/google/callback"),
 			},
+			GitHub: OAuthProviderConfig{
+				ClientID:     getEnv("GITHUB_CLIENT_ID", ""),
+				ClientSecret: getEnv("GITHUB_CLIENT_SECRET", ""),
+				RedirectURL:  getEnv("GITHUB_REDIRECT_URL", "http://localhost:8080/api/v1/auth/oauth/github/callback"),
+			},
+			Facebook: OAuthProviderConfig{
+				ClientID:     getEnv("FACEBOOK_CLIENT_ID", ""),
+				ClientSecret: getEnv("FACEBOOK_CLIENT_SECRET", ""),
+				RedirectURL:  getEnv("FACEBOOK_REDIRECT_URL", "http://localhost:8080/api/v1/auth/oauth/facebook/callback"),
+			},
+			LINE: OAuthProviderConfig{
+				ClientID:     getEnv("LINE_CLIENT_ID", ""),
+				ClientSecret: getEnv("LINE_CLIENT_SECRET", ""),
+				RedirectURL:  getEnv("LINE_REDIRECT_URL", "http://localhost:8080/api/v1/auth/oauth/line/callback"),
+			},
+			OIDC: OIDCConfig{
+				OAuthProviderConfig: OAuthProviderConfig{
+					ClientID:     getEnv("OIDC_CLIENT_ID", ""),
+					ClientSecret: getEnv("OIDC_CLIENT_SECRET", ""),
+					RedirectURL:  getEnv("OIDC_REDIRECT_URL", "http://localhost:8080/api/v1/auth/oauth/oidc/callback"),
+				},
+				Name:      getEnv("OIDC_PROVIDER_NAME", "oidc"),
+				IssuerURL: getEnv("OIDC_ISSUER_URL", ""),
+				Scopes:    strings.Fields(getEnv("OIDC_SCOPES", "openid email profile")),
+			},
 		},
 		VAPID: VAPIDConfig{
 			PublicKey:  getEnv("VAPID_PUBLIC_KEY", ""),
 			PrivateKey: getEnv("VAPID_PRIVATE_KEY", ""),
 			Subject:    getEnv("VAPID_SUBJECT", "mailto:admin@voobize.com"),
 		},
+		Push: PushConfig{
+			DefaultTTL:       pushDefaultTTL,
+			MaxPerUserMinute: pushMaxPerUserMinute,
+		},
+		Mail: MailConfig{
+			Driver:       getEnv("MAIL_DRIVER", "log"),
+			From:         getEnv("MAIL_FROM", "no-reply@localhost"),
+			SMTPHost:     getEnv("SMTP_HOST", ""),
+			SMTPPort:     getEnv("SMTP_PORT", "587"),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+			FileDir:      getEnv("MAIL_FILE_DIR", "./storage/mail"),
+		},
+		Account: AccountConfig{
+			EmailVerificationTTL: emailVerificationTTL,
+			PasswordResetTTL:     passwordResetTTL,
+		},
+		MFA: MFAConfig{
+			Issuer:        getEnv("MFA_ISSUER", getEnv("APP_NAME", "GoFiber Template")),
+			EncryptionKey: getEnv("MFA_ENCRYPTION_KEY", ""),
+		},
 	}
 
 	return config, nil
@@ -12,7 +12,12 @@ import (
 	"gofiber-template/infrastructure/websocket"
 	"gofiber-template/infrastructure/workers"
 	"gofiber-template/interfaces/api/handlers"
+	"gofiber-template/interfaces/api/middleware"
+	"gofiber-template/pkg/auth_code_store"
 	"gofiber-template/pkg/config"
+	"gofiber-template/pkg/linkpreview"
+	"gofiber-template/pkg/mailer"
+	"gofiber-template/pkg/oauth"
 	"gofiber-template/pkg/scheduler"
 	"gorm.io/gorm"
 )
@@ -29,11 +34,23 @@ type Container struct {
 	BunnyStreamService *storage.BunnyStreamService
 	MediaUploadService *storage.MediaUploadService
 	EventScheduler     scheduler.EventScheduler
+	Mailer             mailer.Mailer
+	OAuthProviders     []oauth.Provider
+	AuthCodeStore      auth_code_store.Store
 	ChatHub            *websocket.ChatHub
 	VideoEncoderWorker *workers.VideoEncoderWorker
+	OutboxWorker       *workers.NotificationOutboxWorker
+
+	// Transactions
+	TransactionManager repositories.TransactionManager
 
 	// Repositories - Legacy
 	UserRepository repositories.UserRepository
+	SessionRepository      repositories.SessionRepository
+	RefreshTokenRepository repositories.RefreshTokenRepository
+	MFARecoveryCodeRepository repositories.MFARecoveryCodeRepository
+	UserIdentityRepository    repositories.UserIdentityRepository
+	PersonalAccessTokenRepository repositories.PersonalAccessTokenRepository
 	TaskRepository repositories.TaskRepository
 	FileRepository repositories.FileRepository
 	JobRepository  repositories.JobRepository
@@ -46,21 +63,31 @@ type Container struct {
 	SavedPostRepository            repositories.SavedPostRepository
 	NotificationRepository         repositories.NotificationRepository
 	NotificationSettingsRepository repositories.NotificationSettingsRepository
+	NotificationOutboxRepository   repositories.NotificationOutboxRepository
 	PushSubscriptionRepository     repositories.PushSubscriptionRepository
 	TagRepository                  repositories.TagRepository
 	SearchHistoryRepository        repositories.SearchHistoryRepository
 	MediaRepository                repositories.MediaRepository
+	LinkPreviewRepository          repositories.LinkPreviewRepository
 
 	// Repositories - Chat System
 	ConversationRepository repositories.ConversationRepository
 	MessageRepository      repositories.MessageRepository
+	MessageReactionRepository repositories.MessageReactionRepository
+	MessageReceiptRepository  repositories.MessageReceiptRepository
+	ChatSettingsRepository    repositories.ChatSettingsRepository
+	ConversationReportRepository repositories.ConversationReportRepository
 	BlockRepository        repositories.BlockRepository
 
 	// Services - Legacy
-	UserService services.UserService
-	TaskService services.TaskService
-	FileService services.FileService
-	JobService  services.JobService
+	UserService    services.UserService
+	AuthService    services.AuthService
+	AccountService services.AccountService
+	MFAService     services.MFAService
+	AccessTokenService services.AccessTokenService
+	TaskService    services.TaskService
+	FileService    services.FileService
+	JobService     services.JobService
 
 	// Services - Social Media
 	PostService         services.PostService
@@ -74,6 +101,7 @@ type Container struct {
 	SearchService       services.SearchService
 	MediaService        services.MediaService
 	OAuthService        services.OAuthService
+	LinkPreviewService  services.LinkPreviewService
 
 	// Services - Chat System
 	ConversationService services.ConversationService
@@ -117,6 +145,10 @@ func (c *Container) Initialize() error {
 		return err
 	}
 
+	if err := c.initNotificationOutboxWorker(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -174,6 +206,13 @@ func (c *Container) initInfrastructure() error {
 	c.RedisService = redis.NewRedisService(c.RedisClient)
 	log.Println("✓ RedisService initialized")
 
+	// OAuth authorization codes are shared by all instances through Redis
+	c.AuthCodeStore = redis.NewAuthCodeStore(c.RedisClient)
+
+	// Fan out /ws broadcasts to every API instance
+	websocket.Manager.UseBroker(c.RedisService)
+	log.Println("✓ WebSocket Redis fan-out enabled")
+
 	// Initialize Bunny Storage
 	bunnyConfig := storage.BunnyConfig{
 		StorageZone: c.Config.Bunny.StorageZone,
@@ -196,12 +235,59 @@ func (c *Container) initInfrastructure() error {
 	c.MediaUploadService = storage.NewMediaUploadService(c.BunnyStorage, c.BunnyStreamService)
 	log.Println("✓ MediaUploadService initialized")
 
+	// Initialize Mailer
+	switch c.Config.Mail.Driver {
+	case "smtp":
+		c.Mailer = mailer.NewSMTPMailer(mailer.SMTPConfig{
+			Host:     c.Config.Mail.SMTPHost,
+			Port:     c.Config.Mail.SMTPPort,
+			Username: c.Config.Mail.SMTPUsername,
+			Password: c.Config.Mail.SMTPPassword,
+			From:     c.Config.Mail.From,
+		})
+	case "file":
+		c.Mailer = mailer.NewFileMailer(c.Config.Mail.FileDir, c.Config.Mail.From)
+	default:
+		c.Mailer = mailer.NewLogMailer()
+	}
+	log.Printf("✓ Mailer initialized (%s transport)", c.Config.Mail.Driver)
+
+	// Initialize OAuth providers (each is enabled once its credentials are configured)
+	oauthCfg := c.Config.OAuth
+	providerConfig := func(p config.OAuthProviderConfig) oauth.Config {
+		return oauth.Config{ClientID: p.ClientID, ClientSecret: p.ClientSecret, RedirectURL: p.RedirectURL}
+	}
+	if cfg := providerConfig(oauthCfg.Google); cfg.Enabled() {
+		c.OAuthProviders = append(c.OAuthProviders, oauth.NewGoogle(cfg))
+	}
+	if cfg := providerConfig(oauthCfg.GitHub); cfg.Enabled() {
+		c.OAuthProviders = append(c.OAuthProviders, oauth.NewGitHub(cfg))
+	}
+	if cfg := providerConfig(oauthCfg.Facebook); cfg.Enabled() {
+		c.OAuthProviders = append(c.OAuthProviders, oauth.NewFacebook(cfg))
+	}
+	if cfg := providerConfig(oauthCfg.LINE); cfg.Enabled() {
+		c.OAuthProviders = append(c.OAuthProviders, oauth.NewLINE(cfg))
+	}
+	if cfg := providerConfig(oauthCfg.OIDC.OAuthProviderConfig); cfg.Enabled() && oauthCfg.OIDC.IssuerURL != "" {
+		cfg.Scopes = oauthCfg.OIDC.Scopes
+		c.OAuthProviders = append(c.OAuthProviders, oauth.NewOIDC(oauthCfg.OIDC.Name, oauthCfg.OIDC.IssuerURL, cfg))
+	}
+	log.Printf("✓ OAuth providers initialized (%d enabled)", len(c.OAuthProviders))
+
 	return nil
 }
 
 func (c *Container) initRepositories() error {
+	c.TransactionManager = postgres.NewTransactionManager(c.DB)
+
 	// Legacy repositories
 	c.UserRepository = postgres.NewUserRepository(c.DB)
+	c.SessionRepository = postgres.NewSessionRepository(c.DB)
+	c.RefreshTokenRepository = postgres.NewRefreshTokenRepository(c.DB)
+	c.MFARecoveryCodeRepository = postgres.NewMFARecoveryCodeRepository(c.DB)
+	c.UserIdentityRepository = postgres.NewUserIdentityRepository(c.DB)
+	c.PersonalAccessTokenRepository = postgres.NewPersonalAccessTokenRepository(c.DB)
 	c.TaskRepository = postgres.NewTaskRepository(c.DB)
 	c.FileRepository = postgres.NewFileRepository(c.DB)
 	c.JobRepository = postgres.NewJobRepository(c.DB)
@@ -214,6 +300,7 @@ func (c *Container) initRepositories() error {
 	c.SavedPostRepository = postgres.NewSavedPostRepository(c.DB)
 	c.NotificationRepository = postgres.NewNotificationRepository(c.DB)
 	c.NotificationSettingsRepository = postgres.NewNotificationSettingsRepository(c.DB)
+	c.NotificationOutboxRepository = postgres.NewNotificationOutboxRepository(c.DB)
 	c.PushSubscriptionRepository = postgres.NewPushSubscriptionRepository(c.DB)
 	c.TagRepository = postgres.NewTagRepository(c.DB)
 	c.SearchHistoryRepository = postgres.NewSearchHistoryRepository(c.DB)
@@ -222,31 +309,95 @@ func (c *Container) initRepositories() error {
 	// Chat system repositories
 	c.ConversationRepository = postgres.NewConversationRepository(c.DB)
 	c.MessageRepository = postgres.NewMessageRepository(c.DB)
+	c.MessageReactionRepository = postgres.NewMessageReactionRepository(c.DB)
+	c.MessageReceiptRepository = postgres.NewMessageReceiptRepository(c.DB)
+	c.ChatSettingsRepository = postgres.NewChatSettingsRepository(c.DB)
+	c.ConversationReportRepository = postgres.NewConversationReportRepository(c.DB)
 	c.BlockRepository = postgres.NewBlockRepository(c.DB)
 
-	log.Println("✓ Repositories initialized (18 repositories)")
+	// Link previews (shared by posts and messages)
+	c.LinkPreviewRepository = postgres.NewLinkPreviewRepository(c.DB)
+
+	log.Println("✓ Repositories initialized (29 repositories)")
 	return nil
 }
 
 func (c *Container) initServices() error {
+	// Auth service (sessions and tokens)
+	c.AuthService = serviceimpl.NewAuthService(
+		c.SessionRepository,
+		c.RefreshTokenRepository,
+		c.UserRepository,
+		c.RedisService,
+		c.TransactionManager,
+		c.Config,
+	)
+	middleware.SetSessionValidator(c.AuthService.IsSessionActive)
+
+	// Account emails (verification, password reset)
+	c.AccountService = serviceimpl.NewAccountService(
+		c.UserRepository,
+		c.AuthService,
+		c.RedisService,
+		c.Mailer,
+		c.Config,
+	)
+
+	// Two-factor authentication
+	c.MFAService = serviceimpl.NewMFAService(
+		c.UserRepository,
+		c.MFARecoveryCodeRepository,
+		c.AuthService,
+		c.AccountService,
+		c.RedisService,
+		c.TransactionManager,
+		c.Config,
+	)
+
+	// Personal access tokens for bots and integrations
+	c.AccessTokenService = serviceimpl.NewAccessTokenService(
+		c.PersonalAccessTokenRepository,
+		c.UserRepository,
+		c.AuthService,
+	)
+	middleware.SetAccessTokenValidator(c.AccessTokenService.Authenticate)
+
 	// Legacy services
-	c.UserService = serviceimpl.NewUserService(c.UserRepository, c.FollowRepository, c.Config.JWT.Secret)
+	c.UserService = serviceimpl.NewUserService(c.UserRepository, c.FollowRepository, c.UserIdentityRepository, c.TransactionManager, c.AuthService, c.AccountService, c.MFAService, c.RedisService)
 	c.TaskService = serviceimpl.NewTaskService(c.TaskRepository, c.UserRepository)
 	c.FileService = serviceimpl.NewFileService(c.FileRepository, c.UserRepository, c.BunnyStorage)
 
 	// OAuth service
-	c.OAuthService = serviceimpl.NewOAuthService(c.UserRepository, c.Config)
+	c.OAuthService = serviceimpl.NewOAuthService(
+		c.UserRepository,
+		c.UserIdentityRepository,
+		c.TransactionManager,
+		c.AuthService,
+		c.MFAService,
+		c.RedisService,
+		c.AuthCodeStore,
+		c.OAuthProviders,
+		c.Config,
+	)
 
 	// Social media services (order matters due to dependencies)
 	// 1. No service dependencies
 	c.TagService = serviceimpl.NewTagService(c.TagRepository)
+	c.LinkPreviewService = serviceimpl.NewLinkPreviewService(
+		c.LinkPreviewRepository,
+		c.RedisService,
+		linkpreview.NewFetcher(linkpreview.DefaultConfig()),
+	)
 	c.NotificationService = serviceimpl.NewNotificationService(
 		c.NotificationRepository,
 		c.NotificationSettingsRepository,
+		c.NotificationOutboxRepository,
 		c.UserRepository,
+		c.TransactionManager,
 	)
 	c.PushService = serviceimpl.NewPushService(
 		c.PushSubscriptionRepository,
+		c.RedisService,
 		c.Config,
 	)
 
@@ -258,6 +409,7 @@ func (c *Container) initServices() error {
 		c.SavedPostRepository,
 		c.TagService,
 		c.MediaRepository,
+		c.LinkPreviewService,
 	)
 
 	// 3. Depends on NotificationService
@@ -266,6 +418,7 @@ func (c *Container) initServices() error {
 		c.PostRepository,
 		c.VoteRepository,
 		c.NotificationService,
+		c.TransactionManager,
 	)
 	c.VoteService = serviceimpl.NewVoteService(
 		c.VoteRepository,
@@ -273,11 +426,13 @@ func (c *Container) initServices() error {
 		c.CommentRepository,
 		c.UserRepository,
 		c.NotificationService,
+		c.TransactionManager,
 	)
 	c.FollowService = serviceimpl.NewFollowService(
 		c.FollowRepository,
 		c.UserRepository,
 		c.NotificationService,
+		c.TransactionManager,
 	)
 
 	// 4. Independent services
@@ -309,6 +464,10 @@ func (c *Container) initServices() error {
 		c.UserRepository,
 		c.FollowRepository,
 		c.RedisService,
+		c.TransactionManager,
+		c.MessageReceiptRepository,
+		c.ChatSettingsRepository,
+		c.ConversationReportRepository,
 	)
 	c.MessageService = serviceimpl.NewMessageService(
 		c.MessageRepository,
@@ -316,6 +475,10 @@ func (c *Container) initServices() error {
 		c.BlockRepository,
 		c.UserRepository,
 		c.RedisService,
+		c.MessageReactionRepository,
+		c.MessageReceiptRepository,
+		c.ChatSettingsRepository,
+		c.LinkPreviewService,
 	)
 	c.BlockService = serviceimpl.NewBlockService(
 		c.BlockRepository,
@@ -333,7 +496,7 @@ func (c *Container) initServices() error {
 		notifService.SetPushService(c.PushService)
 	}
 
-	log.Println("✓ Services initialized (19 services)")
+	log.Println("✓ Services initialized (24 services)")
 	return nil
 }
 
@@ -345,6 +508,32 @@ func (c *Container) initScheduler() error {
 	c.EventScheduler.Start()
 	log.Println("✓ Event scheduler started")
 
+	// Purge expired sessions and refresh tokens hourly
+	err := c.EventScheduler.AddJob("auth-session-cleanup", "0 * * * *", func() {
+		deleted, err := c.AuthService.PurgeExpiredSessions(context.Background())
+		if err != nil {
+			log.Printf("Warning: Failed to purge expired sessions: %v", err)
+		} else if deleted > 0 {
+			log.Printf("✓ Purged %d expired sessions and refresh tokens", deleted)
+		}
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to schedule session cleanup: %v", err)
+	}
+
+	// Purge long-expired and revoked personal access tokens daily
+	err = c.EventScheduler.AddJob("access-token-cleanup", "30 3 * * *", func() {
+		deleted, err := c.AccessTokenService.PurgeInactiveTokens(context.Background())
+		if err != nil {
+			log.Printf("Warning: Failed to purge inactive access tokens: %v", err)
+		} else if deleted > 0 {
+			log.Printf("✓ Purged %d inactive access tokens", deleted)
+		}
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to schedule access token cleanup: %v", err)
+	}
+
 	// Load and schedule existing active jobs
 	ctx := context.Background()
 	jobs, _, err := c.JobService.ListJobs(ctx, 0, 1000)
@@ -382,9 +571,14 @@ func (c *Container) initChatHub() error {
 		c.RedisService,
 		c.ConversationRepository,
 		c.FollowRepository,
-		c.PushService,
+		c.NotificationService,
 	)
 
+	// Push link previews unfurled after a message was sent
+	if msgService, ok := c.MessageService.(*serviceimpl.MessageServiceImpl); ok {
+		msgService.SetLinkPreviewListener(c.ChatHub.BroadcastLinkPreview)
+	}
+
 	// Start ChatHub in background
 	go c.ChatHub.Run()
 	log.Println("✓ ChatHub started")
@@ -406,15 +600,44 @@ func (c *Container) initVideoEncoderWorker() error {
 	return nil
 }
 
+func (c *Container) initNotificationOutboxWorker() error {
+	c.OutboxWorker = workers.NewNotificationOutboxWorker(
+		c.NotificationOutboxRepository,
+		c.NotificationRepository,
+		c.PushService,
+		c.Mailer,
+		c.Config.App.FrontendURL,
+	)
+
+	// Wake the worker as soon as notifications are queued
+	if notifService, ok := c.NotificationService.(*serviceimpl.NotificationServiceImpl); ok {
+		notifService.SetDeliveryTrigger(c.OutboxWorker.Wake)
+	}
+
+	// Start worker in background
+	c.OutboxWorker.Start()
+
+	return nil
+}
+
 func (c *Container) Cleanup() error {
 	log.Println("Starting cleanup...")
 
+	// Stop NotificationOutboxWorker
+	if c.OutboxWorker != nil {
+		c.OutboxWorker.Stop()
+		log.Println("✓ NotificationOutboxWorker stopped")
+	}
+
 	// Stop VideoEncoderWorker
 	if c.VideoEncoderWorker != nil {
 		c.VideoEncoderWorker.Stop()
 		log.Println("✓ VideoEncoderWorker stopped")
 	}
 
+	// Stop WebSocket cross-instance fan-out
+	websocket.Manager.StopBroker()
+
 	// Stop ChatHub
 	if c.ChatHub != nil {
 		c.ChatHub.Stop()
@@ -467,10 +690,14 @@ func (c *Container) GetConfig() *config.Config {
 func (c *Container) GetHandlerServices() *handlers.Services {
 	return &handlers.Services{
 		// Legacy services
-		UserService: c.UserService,
-		TaskService: c.TaskService,
-		FileService: c.FileService,
-		JobService:  c.JobService,
+		UserService:    c.UserService,
+		AuthService:    c.AuthService,
+		AccountService: c.AccountService,
+		MFAService:     c.MFAService,
+		AccessTokenService: c.AccessTokenService,
+		TaskService:    c.TaskService,
+		FileService:    c.FileService,
+		JobService:     c.JobService,
 
 		// Social media services
 		PostService:         c.PostService,
@@ -0,0 +1,236 @@
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+var (
+	ErrInvalidURL         = errors.New("invalid url")
+	ErrBlockedAddress     = errors.New("address not allowed")
+	ErrUnsupportedContent = errors.New("unsupported content type")
+	ErrNoMetadata         = errors.New("no preview metadata found")
+)
+
+// Config controls how pages are fetched
+type Config struct {
+	Timeout      time.Duration // Whole request, including redirects
+	MaxBodyBytes int64         // Bytes of HTML read before giving up on finding <head> metadata
+	MaxRedirects int
+	UserAgent    string
+
+	// AllowPrivateNetworks disables the SSRF guard. Only for tests and local development.
+	AllowPrivateNetworks bool
+}
+
+// DefaultConfig returns limits suitable for unfurling untrusted user links
+func DefaultConfig() Config {
+	return Config{
+		Timeout:      5 * time.Second,
+		MaxBodyBytes: 512 * 1024,
+		MaxRedirects: 3,
+		UserAgent:    "Mozilla/5.0 (compatible; LinkPreviewBot/1.0)",
+	}
+}
+
+// Metadata is the preview information extracted from a page
+type Metadata struct {
+	URL         string // Final URL after redirects
+	Title       string
+	Description string
+	SiteName    string
+	ImageURL    string
+}
+
+// Fetcher downloads pages and extracts Open Graph / Twitter card metadata
+type Fetcher struct {
+	client *http.Client
+	config Config
+}
+
+func NewFetcher(config Config) *Fetcher {
+	var guard dialGuard
+	if !config.AllowPrivateNetworks {
+		// Checked on the resolved address of every connection, so DNS rebinding
+		// and redirects to internal hosts are caught as well
+		guard = guardAddress
+	}
+	return newFetcher(config, guard)
+}
+
+// dialGuard vets the address of a connection before it is made
+type dialGuard func(network, address string, c syscall.RawConn) error
+
+// newFetcher builds a Fetcher whose connections go through guard (nil allows all)
+func newFetcher(config Config, guard dialGuard) *Fetcher {
+	dialer := &net.Dialer{
+		Timeout: config.Timeout,
+		Control: guard,
+	}
+
+	transport := &http.Transport{
+		Proxy:                 nil, // A proxy would bypass the address guard
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   config.Timeout,
+		ResponseHeaderTimeout: config.Timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   config.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > config.MaxRedirects {
+				return errors.New("too many redirects")
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return ErrInvalidURL
+			}
+			return nil
+		},
+	}
+
+	return &Fetcher{client: client, config: config}
+}
+
+// Fetch downloads rawURL and returns its preview metadata
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Metadata, error) {
+	normalized, err := NormalizeURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, normalized, nil)
+	if err != nil {
+		return nil, ErrInvalidURL
+	}
+	req.Header.Set("User-Agent", f.config.UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.1")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, ErrUnsupportedContent
+	}
+
+	body, err := charset.NewReader(io.LimitReader(resp.Body, f.config.MaxBodyBytes), contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := parseMetadata(body, resp.Request.URL)
+	if metadata.Title == "" && metadata.Description == "" && metadata.ImageURL == "" {
+		return nil, ErrNoMetadata
+	}
+
+	return metadata, nil
+}
+
+// NormalizeURL validates an http(s) URL and returns it without its fragment
+func NormalizeURL(rawURL string) (string, error) {
+	if len(rawURL) > 2048 {
+		return "", ErrInvalidURL
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" || parsed.User != nil {
+		return "", ErrInvalidURL
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", ErrInvalidURL
+	}
+
+	parsed.Host = strings.ToLower(parsed.Host)
+	parsed.Fragment = ""
+	parsed.RawFragment = ""
+
+	return parsed.String(), nil
+}
+
+// guardAddress rejects connections to non-public addresses and non-web ports
+func guardAddress(network, address string, _ syscall.RawConn) error {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return ErrBlockedAddress
+	}
+
+	if port != "80" && port != "443" {
+		return ErrBlockedAddress
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return ErrBlockedAddress
+	}
+
+	return nil
+}
+
+// reservedNetworks are ranges not covered by the net.IP helpers
+var reservedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",       // "This" network
+	"100.64.0.0/10",   // Carrier-grade NAT
+	"192.0.0.0/24",    // IETF protocol assignments
+	"192.0.2.0/24",    // Documentation
+	"198.18.0.0/15",   // Benchmarking
+	"198.51.100.0/24", // Documentation
+	"203.0.113.0/24",  // Documentation
+	"240.0.0.0/4",     // Reserved
+	"64:ff9b::/96",    // NAT64 (can map to internal IPv4)
+	"2001:db8::/32",   // Documentation
+)
+
+func isPublicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+
+	for _, network := range reservedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks[i] = network
+	}
+	return networks
+}
@@ -0,0 +1,164 @@
+package linkpreview
+
+import (
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	maxTitleLength       = 300 // Runes
+	maxDescriptionLength = 500 // Runes
+)
+
+// urlPattern matches the same links ListMessagesWithLinks detects (http://, https://, www.)
+var urlPattern = regexp.MustCompile(`(?i)(https?://|www\.)[^\s<>"']+`)
+
+// FirstURL returns the first link in text, or "" when there is none
+func FirstURL(text string) string {
+	match := urlPattern.FindString(text)
+	if match == "" {
+		return ""
+	}
+
+	// Trailing punctuation usually belongs to the sentence, not the link
+	match = strings.TrimRight(match, ".,;:!?'\"")
+	if strings.HasSuffix(match, ")") && !strings.Contains(match, "(") {
+		match = strings.TrimRight(match, ")")
+	}
+
+	if strings.HasPrefix(strings.ToLower(match), "www.") {
+		match = "https://" + match
+	}
+
+	return match
+}
+
+// parseMetadata reads <head> metadata, preferring Open Graph, then Twitter cards, then plain HTML
+func parseMetadata(body io.Reader, pageURL *url.URL) *Metadata {
+	meta := make(map[string]string)
+	var title strings.Builder
+	inTitle := false
+
+	tokenizer := html.NewTokenizer(body)
+loop:
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			break loop
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.DataAtom {
+			case atom.Meta:
+				key, content := metaAttributes(token)
+				if key != "" && content != "" {
+					if _, exists := meta[key]; !exists {
+						meta[key] = content
+					}
+				}
+			case atom.Title:
+				inTitle = true
+			case atom.Body:
+				// Metadata lives in <head>; don't scan the whole page
+				break loop
+			}
+
+		case html.TextToken:
+			if inTitle {
+				title.Write(tokenizer.Text())
+			}
+
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			switch atom.Lookup(name) {
+			case atom.Title:
+				inTitle = false
+			case atom.Head:
+				break loop
+			}
+		}
+	}
+
+	metadata := &Metadata{
+		URL:         pageURL.String(),
+		Title:       firstNonEmpty(meta["og:title"], meta["twitter:title"], title.String()),
+		Description: firstNonEmpty(meta["og:description"], meta["twitter:description"], meta["description"]),
+		SiteName:    firstNonEmpty(meta["og:site_name"], pageURL.Hostname()),
+	}
+
+	if canonical := resolveURL(pageURL, meta["og:url"]); canonical != "" {
+		metadata.URL = canonical
+	}
+
+	metadata.ImageURL = resolveURL(pageURL, firstNonEmpty(
+		meta["og:image:secure_url"], meta["og:image"], meta["og:image:url"],
+		meta["twitter:image"], meta["twitter:image:src"],
+	))
+
+	metadata.Title = truncateRunes(collapseSpace(metadata.Title), maxTitleLength)
+	metadata.Description = truncateRunes(collapseSpace(metadata.Description), maxDescriptionLength)
+	metadata.SiteName = truncateRunes(collapseSpace(metadata.SiteName), maxTitleLength)
+
+	return metadata
+}
+
+// metaAttributes returns the lower-cased property/name and content of a <meta> tag
+func metaAttributes(token html.Token) (string, string) {
+	var key, content string
+	for _, attr := range token.Attr {
+		switch strings.ToLower(attr.Key) {
+		case "property", "name":
+			if key == "" {
+				key = strings.ToLower(strings.TrimSpace(attr.Val))
+			}
+		case "content":
+			content = strings.TrimSpace(attr.Val)
+		}
+	}
+	return key, content
+}
+
+// resolveURL resolves ref against the page URL, keeping only http(s) results
+func resolveURL(base *url.URL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+
+	parsed, err := base.Parse(ref)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return ""
+	}
+
+	resolved := parsed.String()
+	if len(resolved) > 2048 {
+		return ""
+	}
+	return resolved
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func truncateRunes(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:max-1]) + "…"
+}
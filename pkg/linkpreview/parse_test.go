@@ -0,0 +1,48 @@
+package linkpreview
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestFirstURL(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"no links here", ""},
+		{"see https://example.com/a.", "https://example.com/a"},
+		{"(www.example.com)", "https://www.example.com"},
+		{"wiki https://en.wikipedia.org/wiki/Go_(programming_language) rocks", "https://en.wikipedia.org/wiki/Go_(programming_language)"},
+		{"first http://one.example then https://two.example", "http://one.example"},
+	}
+
+	for _, tt := range tests {
+		if got := FirstURL(tt.text); got != tt.want {
+			t.Errorf("FirstURL(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseMetadataTruncatesAndDropsUnsafeImages(t *testing.T) {
+	pageURL, _ := url.Parse("https://example.com/post")
+	longTitle := strings.Repeat("é", maxTitleLength+50)
+
+	metadata := parseMetadata(strings.NewReader(`<head>
+		<meta property="og:title" content="`+longTitle+`">
+		<meta property="og:image" content="javascript:alert(1)">
+		<meta property="og:url" content="/canonical">
+	</head>`), pageURL)
+
+	if n := utf8.RuneCountInString(metadata.Title); n != maxTitleLength || !strings.HasSuffix(metadata.Title, "…") {
+		t.Errorf("expected the title cut to %d runes, got %d", maxTitleLength, n)
+	}
+	if metadata.ImageURL != "" {
+		t.Errorf("expected a non-http image to be dropped, got %q", metadata.ImageURL)
+	}
+	if metadata.URL != "https://example.com/canonical" {
+		t.Errorf("expected the canonical URL, got %q", metadata.URL)
+	}
+}
@@ -0,0 +1,73 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"mime"
+	"strings"
+	"time"
+)
+
+// Message is a single outgoing email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// encode renders msg as a plain-text RFC 5322 message ready for an SMTP DATA command
+func (msg *Message) encode(from string) []byte {
+	var buf bytes.Buffer
+
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = strings.Trim(from[at+1:], "<> ")
+	}
+
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	// Header values come from our own templates, but strip line breaks so a
+	// crafted subject or address can never inject extra headers
+	fmt.Fprintf(&buf, "From: %s\r\n", stripLineBreaks(from))
+	fmt.Fprintf(&buf, "To: %s\r\n", stripLineBreaks(msg.To))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", stripLineBreaks(msg.Subject)))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@%s>\r\n", hex.EncodeToString(id), domain)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+
+	body := strings.ReplaceAll(msg.Body, "\r\n", "\n")
+	buf.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	buf.WriteString("\r\n")
+
+	return buf.Bytes()
+}
+
+func stripLineBreaks(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+// Mailer sends emails through a configured transport
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// LogMailer writes emails to the application log instead of sending them.
+// Used in development and whenever no real transport is configured.
+type LogMailer struct{}
+
+func NewLogMailer() Mailer {
+	return &LogMailer{}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg *Message) error {
+	log.Printf("📧 [mail] to=%s subject=%q\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
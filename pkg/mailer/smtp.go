@@ -0,0 +1,112 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+)
+
+const smtpTimeout = 30 * time.Second
+
+// SMTPConfig describes an SMTP relay
+type SMTPConfig struct {
+	Host     string
+	Port     string // 465 uses implicit TLS, anything else upgrades with STARTTLS when offered
+	Username string // Empty disables authentication
+	Password string
+	From     string
+}
+
+// SMTPMailer delivers emails through an SMTP relay
+type SMTPMailer struct {
+	config SMTPConfig
+}
+
+func NewSMTPMailer(config SMTPConfig) Mailer {
+	return &SMTPMailer{config: config}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg *Message) error {
+	from, err := mail.ParseAddress(m.config.From)
+	if err != nil {
+		return errors.New("invalid sender address")
+	}
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return errors.New("invalid recipient address")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+
+	client, err := m.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	// Abort a stalled conversation when the context ends
+	stop := context.AfterFunc(ctx, func() { client.Close() })
+	defer stop()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && m.config.Port != "465" {
+		if err := client.StartTLS(&tls.Config{ServerName: m.config.Host}); err != nil {
+			return err
+		}
+	}
+
+	if m.config.Username != "" {
+		auth := smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg.encode(m.config.From)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+func (m *SMTPMailer) dial(ctx context.Context) (*smtp.Client, error) {
+	address := net.JoinHostPort(m.config.Host, m.config.Port)
+
+	var conn net.Conn
+	var err error
+	if m.config.Port == "465" {
+		dialer := &tls.Dialer{Config: &tls.Config{ServerName: m.config.Host}}
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := smtp.NewClient(conn, m.config.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return client, nil
+}
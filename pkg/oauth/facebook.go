@@ -0,0 +1,60 @@
+package oauth
+
+import (
+	"context"
+
+	"gofiber-template/domain/dto"
+	"golang.org/x/oauth2/facebook"
+)
+
+const facebookAPIURL = "https://graph.facebook.com/v19.0"
+
+type facebookProvider struct {
+	client
+}
+
+func NewFacebook(cfg Config) Provider {
+	return &facebookProvider{
+		client: newClient("facebook", cfg, facebook.Endpoint, facebookAPIURL, []string{"public_profile", "email"}),
+	}
+}
+
+func (p *facebookProvider) Authenticate(ctx context.Context, code string, verifier string) (*dto.OAuthUserInfo, error) {
+	token, err := p.exchange(ctx, code, verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	var facebookUser struct {
+		ID        string `json:"id"`
+		Email     string `json:"email"`
+		Name      string `json:"name"`
+		FirstName string `json:"first_name"`
+		LastName  string `json:"last_name"`
+		Picture   struct {
+			Data struct {
+				URL string `json:"url"`
+			} `json:"data"`
+		} `json:"picture"`
+	}
+	url := p.apiURL + "/me?fields=id,name,email,first_name,last_name,picture.type(large)"
+	if err := p.getJSON(ctx, token, url, &facebookUser); err != nil {
+		return nil, err
+	}
+
+	// Accounts registered with a phone number have no email
+	if facebookUser.Email == "" {
+		return nil, ErrEmailNotProvided
+	}
+
+	return &dto.OAuthUserInfo{
+		Provider:   p.name,
+		OAuthID:    facebookUser.ID,
+		Email:      facebookUser.Email,
+		Name:       facebookUser.Name,
+		Picture:    facebookUser.Picture.Data.URL,
+		GivenName:  facebookUser.FirstName,
+		FamilyName: facebookUser.LastName,
+		Verified:   false, // Facebook doesn't say whether the address was confirmed
+	}, nil
+}
@@ -0,0 +1,60 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestFacebookAuthenticate(t *testing.T) {
+	fake := newFakeProvider(t)
+	profile := map[string]interface{}{
+		"id":         "10158",
+		"email":      "zuck@example.com",
+		"name":       "Mark Z",
+		"first_name": "Mark",
+		"last_name":  "Z",
+		"picture":    map[string]interface{}{"data": map[string]interface{}{"url": "https://graph.example.com/picture.jpg"}},
+	}
+	fake.mux.HandleFunc("GET /me", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testAccessToken {
+			writeJSON(w, http.StatusUnauthorized, nil)
+			return
+		}
+		if !strings.Contains(r.URL.Query().Get("fields"), "email") {
+			writeJSON(w, http.StatusOK, map[string]interface{}{"id": "10158", "name": "Mark Z"})
+			return
+		}
+		writeJSON(w, http.StatusOK, profile)
+	})
+
+	info, err := NewFacebook(fake.config()).Authenticate(context.Background(), testCode, testVerifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Provider != "facebook" || info.OAuthID != "10158" || info.Email != "zuck@example.com" {
+		t.Errorf("unexpected identity %s/%s %s", info.Provider, info.OAuthID, info.Email)
+	}
+	if info.Name != "Mark Z" || info.GivenName != "Mark" || info.FamilyName != "Z" {
+		t.Errorf("unexpected names %q %q %q", info.Name, info.GivenName, info.FamilyName)
+	}
+	if info.Picture != "https://graph.example.com/picture.jpg" {
+		t.Errorf("unexpected picture %q", info.Picture)
+	}
+	if info.Verified {
+		t.Error("Facebook emails must not be treated as verified")
+	}
+}
+
+func TestFacebookRequiresEmail(t *testing.T) {
+	fake := newFakeProvider(t)
+	fake.handleAPI("GET /me", map[string]interface{}{"id": "10158", "name": "Phone Only"})
+
+	_, err := NewFacebook(fake.config()).Authenticate(context.Background(), testCode, testVerifier)
+	if !errors.Is(err, ErrEmailNotProvided) {
+		t.Fatalf("expected ErrEmailNotProvided, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package oauth
+
+import (
+	"context"
+	"strconv"
+
+	"gofiber-template/domain/dto"
+	"golang.org/x/oauth2/github"
+)
+
+const githubAPIURL = "https://api.github.com"
+
+type githubProvider struct {
+	client
+}
+
+func NewGitHub(cfg Config) Provider {
+	return &githubProvider{
+		client: newClient("github", cfg, github.Endpoint, githubAPIURL, []string{"read:user", "user:email"}),
+	}
+}
+
+func (p *githubProvider) Authenticate(ctx context.Context, code string, verifier string) (*dto.OAuthUserInfo, error) {
+	token, err := p.exchange(ctx, code, verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	var githubUser struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := p.getJSON(ctx, token, p.apiURL+"/user", &githubUser); err != nil {
+		return nil, err
+	}
+
+	// The profile only shows a public email, which may be unverified or missing
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := p.getJSON(ctx, token, p.apiURL+"/user/emails", &emails); err != nil {
+		return nil, err
+	}
+
+	email := ""
+	for _, candidate := range emails {
+		if candidate.Verified && (candidate.Primary || email == "") {
+			email = candidate.Email
+		}
+	}
+	if email == "" {
+		return nil, ErrEmailNotProvided
+	}
+
+	name := githubUser.Name
+	if name == "" {
+		name = githubUser.Login
+	}
+
+	return &dto.OAuthUserInfo{
+		Provider: p.name,
+		OAuthID:  strconv.FormatInt(githubUser.ID, 10),
+		Email:    email,
+		Name:     name,
+		Picture:  githubUser.AvatarURL,
+		Verified: true,
+	}, nil
+}
@@ -0,0 +1,70 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestGitHubAuthenticate(t *testing.T) {
+	fake := newFakeProvider(t)
+	fake.handleAPI("GET /user", map[string]interface{}{
+		"id":         583231,
+		"login":      "octocat",
+		"name":       "",
+		"avatar_url": "https://avatars.example.com/u/583231",
+	})
+	fake.handleAPI("GET /user/emails", []map[string]interface{}{
+		{"email": "unverified@example.com", "primary": true, "verified": false},
+		{"email": "first@example.com", "primary": false, "verified": true},
+		{"email": "second@example.com", "primary": false, "verified": true},
+	})
+
+	info, err := NewGitHub(fake.config()).Authenticate(context.Background(), testCode, testVerifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Provider != "github" || info.OAuthID != "583231" {
+		t.Errorf("unexpected identity %s/%s", info.Provider, info.OAuthID)
+	}
+	if info.Email != "first@example.com" || !info.Verified {
+		t.Errorf("expected the first verified email, got %s (verified %v)", info.Email, info.Verified)
+	}
+	if info.Name != "octocat" {
+		t.Errorf("expected the login when the name is empty, got %q", info.Name)
+	}
+	if info.Picture != "https://avatars.example.com/u/583231" {
+		t.Errorf("unexpected picture %q", info.Picture)
+	}
+}
+
+func TestGitHubPrefersVerifiedPrimaryEmail(t *testing.T) {
+	fake := newFakeProvider(t)
+	fake.handleAPI("GET /user", map[string]interface{}{"id": 1, "login": "octocat", "name": "The Octocat"})
+	fake.handleAPI("GET /user/emails", []map[string]interface{}{
+		{"email": "other@example.com", "primary": false, "verified": true},
+		{"email": "primary@example.com", "primary": true, "verified": true},
+	})
+
+	info, err := NewGitHub(fake.config()).Authenticate(context.Background(), testCode, testVerifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Email != "primary@example.com" || info.Name != "The Octocat" {
+		t.Errorf("unexpected profile %s %q", info.Email, info.Name)
+	}
+}
+
+func TestGitHubRequiresVerifiedEmail(t *testing.T) {
+	fake := newFakeProvider(t)
+	fake.handleAPI("GET /user", map[string]interface{}{"id": 1, "login": "octocat"})
+	fake.handleAPI("GET /user/emails", []map[string]interface{}{
+		{"email": "unverified@example.com", "primary": true, "verified": false},
+	})
+
+	_, err := NewGitHub(fake.config()).Authenticate(context.Background(), testCode, testVerifier)
+	if !errors.Is(err, ErrEmailNotProvided) {
+		t.Fatalf("expected ErrEmailNotProvided, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package oauth
+
+import (
+	"context"
+
+	"gofiber-template/domain/dto"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const googleAPIURL = "https://www.googleapis.com"
+
+type googleProvider struct {
+	client
+}
+
+func NewGoogle(cfg Config) Provider {
+	return &googleProvider{
+		client: newClient("google", cfg, google.Endpoint, googleAPIURL, []string{
+			"https://www.googleapis.com/auth/userinfo.email",
+			"https://www.googleapis.com/auth/userinfo.profile",
+		}),
+	}
+}
+
+func (p *googleProvider) AuthCodeURL(ctx context.Context, state string, verifier string) (string, error) {
+	return p.config.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.S256ChallengeOption(verifier)), nil
+}
+
+func (p *googleProvider) Authenticate(ctx context.Context, code string, verifier string) (*dto.OAuthUserInfo, error) {
+	token, err := p.exchange(ctx, code, verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	var googleUser struct {
+		ID            string `json:"id"`
+		Email         string `json:"email"`
+		VerifiedEmail bool   `json:"verified_email"`
+		Name          string `json:"name"`
+		GivenName     string `json:"given_name"`
+		FamilyName    string `json:"family_name"`
+		Picture       string `json:"picture"`
+	}
+	if err := p.getJSON(ctx, token, p.apiURL+"/oauth2/v2/userinfo", &googleUser); err != nil {
+		return nil, err
+	}
+
+	if googleUser.Email == "" {
+		return nil, ErrEmailNotProvided
+	}
+
+	return &dto.OAuthUserInfo{
+		Provider:   p.name,
+		OAuthID:    googleUser.ID,
+		Email:      googleUser.Email,
+		Name:       googleUser.Name,
+		Picture:    googleUser.Picture,
+		GivenName:  googleUser.GivenName,
+		FamilyName: googleUser.FamilyName,
+		Verified:   googleUser.VerifiedEmail,
+	}, nil
+}
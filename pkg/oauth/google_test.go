@@ -0,0 +1,61 @@
+package oauth
+
+import (
+	"context"
+	"net/url"
+	"testing"
+)
+
+func TestGoogleAuthenticate(t *testing.T) {
+	fake := newFakeProvider(t)
+	fake.handleAPI("GET /oauth2/v2/userinfo", map[string]interface{}{
+		"id":             "1098",
+		"email":          "user@example.com",
+		"verified_email": true,
+		"name":           "Jane Doe",
+		"given_name":     "Jane",
+		"family_name":    "Doe",
+		"picture":        "https://lh3.example.com/photo.jpg",
+	})
+
+	info, err := NewGoogle(fake.config()).Authenticate(context.Background(), testCode, testVerifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Provider != "google" || info.OAuthID != "1098" || info.Email != "user@example.com" || !info.Verified {
+		t.Errorf("unexpected identity %+v", info)
+	}
+	if info.Name != "Jane Doe" || info.GivenName != "Jane" || info.FamilyName != "Doe" || info.Picture != "https://lh3.example.com/photo.jpg" {
+		t.Errorf("unexpected profile %+v", info)
+	}
+}
+
+func TestGoogleUnverifiedEmail(t *testing.T) {
+	fake := newFakeProvider(t)
+	fake.handleAPI("GET /oauth2/v2/userinfo", map[string]interface{}{"id": "1098", "email": "user@example.com", "verified_email": false})
+
+	info, err := NewGoogle(fake.config()).Authenticate(context.Background(), testCode, testVerifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Verified {
+		t.Error("expected an unverified email")
+	}
+}
+
+func TestGoogleAuthCodeURLRequestsOfflineAccess(t *testing.T) {
+	fake := newFakeProvider(t)
+
+	authURL, err := NewGoogle(fake.config()).AuthCodeURL(context.Background(), "state", testVerifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Query().Get("access_type") != "offline" || parsed.Query().Get("code_challenge") == "" {
+		t.Fatalf("unexpected auth URL %s", authURL)
+	}
+}
@@ -0,0 +1,68 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// handleLINEVerify serves LINE's ID token verification for idToken
+func handleLINEVerify(fake *fakeProvider, idToken string, claims map[string]interface{}) {
+	fake.mux.HandleFunc("POST /oauth2/v2.1/verify", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if r.PostForm.Get("id_token") != idToken || r.PostForm.Get("client_id") != testClientID {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+			return
+		}
+		writeJSON(w, http.StatusOK, claims)
+	})
+}
+
+func TestLINEAuthenticate(t *testing.T) {
+	fake := newFakeProvider(t)
+	fake.idToken = "line-id-token"
+	handleLINEVerify(fake, fake.idToken, map[string]interface{}{
+		"sub":     "U4af4980629",
+		"name":    "Taro",
+		"picture": "https://profile.line-scdn.example/abc",
+		"email":   "taro@example.com",
+	})
+
+	info, err := NewLINE(fake.config()).Authenticate(context.Background(), testCode, testVerifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Provider != "line" || info.OAuthID != "U4af4980629" || info.Email != "taro@example.com" {
+		t.Errorf("unexpected identity %+v", info)
+	}
+	if info.Name != "Taro" || info.Picture != "https://profile.line-scdn.example/abc" {
+		t.Errorf("unexpected profile %+v", info)
+	}
+	if info.Verified {
+		t.Error("LINE emails must not be treated as verified")
+	}
+}
+
+func TestLINERequiresIDToken(t *testing.T) {
+	fake := newFakeProvider(t)
+
+	if _, err := NewLINE(fake.config()).Authenticate(context.Background(), testCode, testVerifier); err == nil {
+		t.Fatal("expected an error without an id token")
+	}
+}
+
+func TestLINERequiresEmail(t *testing.T) {
+	fake := newFakeProvider(t)
+	fake.idToken = "line-id-token"
+	handleLINEVerify(fake, fake.idToken, map[string]interface{}{"sub": "U4af4980629", "name": "Taro"})
+
+	_, err := NewLINE(fake.config()).Authenticate(context.Background(), testCode, testVerifier)
+	if !errors.Is(err, ErrEmailNotProvided) {
+		t.Fatalf("expected ErrEmailNotProvided, got %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package oauth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// newFakeOIDCProvider adds a discovery document and a UserInfo endpoint to a fake provider
+func newFakeOIDCProvider(t *testing.T, userinfo map[string]interface{}) *fakeProvider {
+	t.Helper()
+	fake := newFakeProvider(t)
+	fake.mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, oidcDiscovery{
+			Issuer:                fake.URL,
+			AuthorizationEndpoint: fake.URL + "/authorize",
+			TokenEndpoint:         fake.URL + "/token",
+			UserinfoEndpoint:      fake.URL + "/userinfo",
+		})
+	})
+	fake.handleAPI("GET /userinfo", userinfo)
+	return fake
+}
+
+// unsignedIDToken builds an ID token with the given subject; its signature isn't checked
+func unsignedIDToken(t *testing.T, subject string) string {
+	t.Helper()
+	payload, err := json.Marshal(map[string]string{"sub": subject})
+	if err != nil {
+		t.Fatal(err)
+	}
+	encode := base64.RawURLEncoding.EncodeToString
+	return encode([]byte(`{"alg":"RS256"}`)) + "." + encode(payload) + "." + encode([]byte("signature"))
+}
+
+func oidcConfig() Config {
+	return Config{ClientID: testClientID, ClientSecret: testClientSecret, RedirectURL: "https://app.example.com/callback"}
+}
+
+func TestOIDCAuthenticate(t *testing.T) {
+	fake := newFakeOIDCProvider(t, map[string]interface{}{
+		"sub":            "user-1",
+		"email":          "user@example.com",
+		"email_verified": "true",
+		"given_name":     "Ada",
+		"family_name":    "Lovelace",
+	})
+	fake.idToken = unsignedIDToken(t, "user-1")
+	provider := NewOIDC("keycloak", fake.URL+"/", oidcConfig())
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state", testVerifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(authURL, fake.URL+"/authorize?") {
+		t.Fatalf("expected the discovered authorization endpoint, got %s", authURL)
+	}
+
+	info, err := provider.Authenticate(context.Background(), testCode, testVerifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Provider != "keycloak" || info.OAuthID != "user-1" || info.Email != "user@example.com" || !info.Verified {
+		t.Errorf("unexpected identity %+v", info)
+	}
+	if info.Name != "Ada Lovelace" {
+		t.Errorf("expected the name from given and family names, got %q", info.Name)
+	}
+}
+
+func TestOIDCRejectsUserInfoForAnotherSubject(t *testing.T) {
+	fake := newFakeOIDCProvider(t, map[string]interface{}{"sub": "attacker", "email": "attacker@example.com", "email_verified": true})
+	fake.idToken = unsignedIDToken(t, "user-1")
+
+	_, err := NewOIDC("oidc", fake.URL, oidcConfig()).Authenticate(context.Background(), testCode, testVerifier)
+	if err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("expected a subject mismatch error, got %v", err)
+	}
+}
+
+func TestOIDCRejectsMalformedIDToken(t *testing.T) {
+	fake := newFakeOIDCProvider(t, map[string]interface{}{"sub": "user-1", "email": "user@example.com"})
+	fake.idToken = "not-a-jwt"
+
+	if _, err := NewOIDC("oidc", fake.URL, oidcConfig()).Authenticate(context.Background(), testCode, testVerifier); err == nil {
+		t.Fatal("expected a malformed id token to be rejected")
+	}
+}
+
+func TestOIDCRejectsDiscoveryForAnotherIssuer(t *testing.T) {
+	fake := newFakeOIDCProvider(t, map[string]interface{}{"sub": "user-1"})
+
+	// The discovery document names fake.URL, not the configured issuer
+	provider := NewOIDC("oidc", fake.URL+"/realms/other", oidcConfig())
+	fake.mux.HandleFunc("GET /realms/other/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, oidcDiscovery{
+			Issuer:                fake.URL,
+			AuthorizationEndpoint: fake.URL + "/authorize",
+			TokenEndpoint:         fake.URL + "/token",
+			UserinfoEndpoint:      fake.URL + "/userinfo",
+		})
+	})
+
+	if _, err := provider.AuthCodeURL(context.Background(), "state", testVerifier); err == nil {
+		t.Fatal("expected an issuer mismatch to be rejected")
+	}
+}
@@ -0,0 +1,155 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"gofiber-template/domain/dto"
+	"golang.org/x/oauth2"
+)
+
+// ErrEmailNotProvided is returned when the provider account has no usable email address
+var ErrEmailNotProvided = errors.New("email not provided by the provider")
+
+// Provider signs users in through one OAuth 2.0 / OpenID Connect identity provider.
+// Every provider uses the authorization code flow with PKCE.
+type Provider interface {
+	// Name is the provider's identifier in routes and stored accounts (e.g. "github")
+	Name() string
+
+	// AuthCodeURL returns the URL that starts a login. verifier is the PKCE code
+	// verifier; only its S256 challenge is sent.
+	AuthCodeURL(ctx context.Context, state string, verifier string) (string, error)
+
+	// Authenticate exchanges the code from the callback and returns the signed-in account
+	Authenticate(ctx context.Context, code string, verifier string) (*dto.OAuthUserInfo, error)
+}
+
+// Config holds an application's credentials with a provider
+type Config struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string // Replaces the provider's default scopes when set
+
+	// Replace the provider's endpoints when set, e.g. for GitHub Enterprise. APIURL is
+	// the base the profile requests go to. OpenID Connect providers ignore them and
+	// use their discovery document.
+	AuthURL  string
+	TokenURL string
+	APIURL   string
+}
+
+// Enabled reports whether the provider has been configured
+func (c Config) Enabled() bool {
+	return c.ClientID != "" && c.ClientSecret != ""
+}
+
+// GenerateVerifier returns a new random PKCE code verifier
+func GenerateVerifier() string {
+	return oauth2.GenerateVerifier()
+}
+
+const (
+	requestTimeout  = 10 * time.Second
+	maxResponseSize = 1 << 20
+)
+
+// client talks to a provider's endpoints with the standard oauth2 flow
+type client struct {
+	name       string
+	config     *oauth2.Config
+	apiURL     string
+	httpClient *http.Client
+}
+
+func newClient(name string, cfg Config, endpoint oauth2.Endpoint, apiURL string, defaultScopes []string) client {
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = defaultScopes
+	}
+
+	if cfg.AuthURL != "" {
+		endpoint.AuthURL = cfg.AuthURL
+	}
+	if cfg.TokenURL != "" {
+		endpoint.TokenURL = cfg.TokenURL
+	}
+	if cfg.APIURL != "" {
+		apiURL = strings.TrimSuffix(cfg.APIURL, "/")
+	}
+
+	return client{
+		name:   name,
+		apiURL: apiURL,
+		config: &oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Scopes:       scopes,
+			Endpoint:     endpoint,
+		},
+		httpClient: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+func (c *client) Name() string {
+	return c.name
+}
+
+func (c *client) AuthCodeURL(ctx context.Context, state string, verifier string) (string, error) {
+	return c.config.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier)), nil
+}
+
+// exchange trades the authorization code and PKCE verifier for tokens
+func (c *client) exchange(ctx context.Context, code string, verifier string) (*oauth2.Token, error) {
+	token, err := c.config.Exchange(c.context(ctx), code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+	return token, nil
+}
+
+// getJSON fetches url with the access token and decodes the response into out
+func (c *client) getJSON(ctx context.Context, token *oauth2.Token, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.config.Client(c.context(ctx), token).Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to get user info: %w", err)
+	}
+	return decodeResponse(resp, out)
+}
+
+// context makes the oauth2 package use the client's HTTP client (with its timeout)
+func (c *client) context(ctx context.Context) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, c.httpClient)
+}
+
+func decodeResponse(resp *http.Response, out interface{}) error {
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, resp.Request.URL.Host)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
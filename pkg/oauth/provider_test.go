@@ -0,0 +1,181 @@
+package oauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"golang.org/x/oauth2/facebook"
+	"golang.org/x/oauth2/github"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	testClientID     = "client-id"
+	testClientSecret = "client-secret"
+	testCode         = "auth-code"
+	testAccessToken  = "access-token"
+)
+
+var testVerifier = GenerateVerifier()
+
+// fakeProvider is an authorization server whose token endpoint only accepts testCode
+// with testVerifier, plus whatever API routes a test adds
+type fakeProvider struct {
+	*httptest.Server
+	mux     *http.ServeMux
+	idToken string // Returned with the access token when set
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	t.Helper()
+	p := &fakeProvider{mux: http.NewServeMux()}
+
+	p.mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok {
+			clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+		}
+		if clientID != testClientID || clientSecret != testClientSecret {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+			return
+		}
+		if r.PostForm.Get("code") != testCode || r.PostForm.Get("code_verifier") != testVerifier {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		token := map[string]interface{}{
+			"access_token": testAccessToken,
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		}
+		if p.idToken != "" {
+			token["id_token"] = p.idToken
+		}
+		writeJSON(w, http.StatusOK, token)
+	})
+
+	p.Server = httptest.NewServer(p.mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+// handleAPI serves body at pattern to requests carrying the access token
+func (p *fakeProvider) handleAPI(pattern string, body interface{}) {
+	p.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testAccessToken {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_token"})
+			return
+		}
+		writeJSON(w, http.StatusOK, body)
+	})
+}
+
+// config points a provider at the fake server
+func (p *fakeProvider) config() Config {
+	return Config{
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  "https://app.example.com/callback",
+		AuthURL:      p.URL + "/authorize",
+		TokenURL:     p.URL + "/token",
+		APIURL:       p.URL,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func TestAuthCodeURLSendsOnlyTheVerifierChallenge(t *testing.T) {
+	fake := newFakeProvider(t)
+	provider := NewGitHub(fake.config())
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state-123", testVerifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := parsed.Scheme + "://" + parsed.Host + parsed.Path; got != fake.URL+"/authorize" {
+		t.Fatalf("expected the configured auth URL, got %s", got)
+	}
+
+	query := parsed.Query()
+	sum := sha256.Sum256([]byte(testVerifier))
+	if query.Get("code_challenge") != base64.RawURLEncoding.EncodeToString(sum[:]) || query.Get("code_challenge_method") != "S256" {
+		t.Fatalf("expected an S256 challenge of the verifier, got %s", parsed.RawQuery)
+	}
+	if query.Get("state") != "state-123" || query.Get("client_id") != testClientID {
+		t.Fatalf("unexpected query %s", parsed.RawQuery)
+	}
+	for key, values := range query {
+		for _, value := range values {
+			if value == testVerifier {
+				t.Fatalf("verifier leaked in %s", key)
+			}
+		}
+	}
+}
+
+func TestAuthenticateForwardsTheVerifier(t *testing.T) {
+	fake := newFakeProvider(t)
+	fake.handleAPI("GET /user", map[string]interface{}{"id": 1, "login": "octocat"})
+	fake.handleAPI("GET /user/emails", []map[string]interface{}{{"email": "octocat@example.com", "primary": true, "verified": true}})
+	provider := NewGitHub(fake.config())
+
+	if _, err := provider.Authenticate(context.Background(), testCode, GenerateVerifier()); err == nil {
+		t.Fatal("expected a different verifier to be rejected by the token endpoint")
+	}
+	if _, err := provider.Authenticate(context.Background(), testCode, testVerifier); err != nil {
+		t.Fatalf("expected the matching verifier to be accepted, got %v", err)
+	}
+}
+
+func TestDefaultEndpoints(t *testing.T) {
+	cfg := Config{ClientID: testClientID, ClientSecret: testClientSecret}
+
+	tests := []struct {
+		provider Provider
+		tokenURL string
+		apiURL   string
+	}{
+		{NewGitHub(cfg), github.Endpoint.TokenURL, githubAPIURL},
+		{NewGoogle(cfg), google.Endpoint.TokenURL, googleAPIURL},
+		{NewFacebook(cfg), facebook.Endpoint.TokenURL, facebookAPIURL},
+		{NewLINE(cfg), lineEndpoint.TokenURL, lineAPIURL},
+	}
+
+	for _, tt := range tests {
+		var c *client
+		switch p := tt.provider.(type) {
+		case *githubProvider:
+			c = &p.client
+		case *googleProvider:
+			c = &p.client
+		case *facebookProvider:
+			c = &p.client
+		case *lineProvider:
+			c = &p.client
+		}
+		if c.config.Endpoint.TokenURL != tt.tokenURL || c.apiURL != tt.apiURL {
+			t.Errorf("%s: got token URL %s and API URL %s", c.name, c.config.Endpoint.TokenURL, c.apiURL)
+		}
+	}
+}
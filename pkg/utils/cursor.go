@@ -44,3 +44,41 @@ func DecodeCursor(cursorStr string) (*time.Time, error) {
 
 	return &cursor.Timestamp, nil
 }
+
+// OffsetCursor paginates ranked results, where ordering by timestamp doesn't apply
+type OffsetCursor struct {
+	Offset int    `json:"offset"`
+	Mode   string `json:"mode,omitempty"` // Lets later pages keep the strategy chosen for the first
+}
+
+// EncodeOffsetCursor encodes an offset (and optional mode) into a base64 cursor string
+func EncodeOffsetCursor(offset int, mode string) (string, error) {
+	jsonBytes, err := json.Marshal(OffsetCursor{Offset: offset, Mode: mode})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(jsonBytes), nil
+}
+
+// DecodeOffsetCursor decodes a base64 cursor string into an offset cursor
+func DecodeOffsetCursor(cursorStr string) (*OffsetCursor, error) {
+	if cursorStr == "" {
+		return &OffsetCursor{}, nil
+	}
+
+	jsonBytes, err := base64.StdEncoding.DecodeString(cursorStr)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor OffsetCursor
+	if err := json.Unmarshal(jsonBytes, &cursor); err != nil {
+		return nil, err
+	}
+	if cursor.Offset < 0 {
+		cursor.Offset = 0
+	}
+
+	return &cursor, nil
+}
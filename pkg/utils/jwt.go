@@ -2,6 +2,7 @@ package utils
 
 import (
 	"errors"
+	"gofiber-template/domain/models"
 	"log"
 	"strings"
 	"time"
@@ -18,18 +19,56 @@ var (
 )
 
 type JWTClaims struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Role     string `json:"role,omitempty"`
+	UserID    string `json:"user_id"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	Role      string `json:"role,omitempty"`
+	SessionID string `json:"sid"` // Session the access token was issued for
+	Verified  bool   `json:"ev"`  // Email address verified when the token was issued
+	MFA       bool   `json:"mfa"` // Session signed in with a second factor
 	jwt.RegisteredClaims
 }
 
 type UserContext struct {
-	ID       uuid.UUID
-	Username string
-	Email    string
-	Role     string
+	ID        uuid.UUID
+	Username  string
+	Email     string
+	Role      string
+	SessionID uuid.UUID
+
+	EmailVerified bool
+	MFAVerified   bool
+
+	// Set when the request authenticated with a personal access token instead of a session
+	TokenID uuid.UUID
+	Scopes  []string
+}
+
+// IsAccessToken reports whether the request authenticated with a personal access token
+func (u *UserContext) IsAccessToken() bool {
+	return u.TokenID != uuid.Nil
+}
+
+// HasScope reports whether the request may act with scope. Sessions have every scope.
+func (u *UserContext) HasScope(scope string) bool {
+	if !u.IsAccessToken() {
+		return true
+	}
+	for _, granted := range u.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions returns what the request may do through the user's role. Personal access
+// tokens need the admin scope for them, and privileged roles a session signed in with 2FA.
+func (u *UserContext) Permissions() []string {
+	if !u.HasScope(models.TokenScopeAdmin) || (models.RoleRequiresMFA(u.Role) && !u.MFAVerified) {
+		return nil
+	}
+	return models.RolePermissions(u.Role)
 }
 
 func ValidateTokenStringToUUID(tokenString, jwtSecret string) (*UserContext, error) {
@@ -66,11 +105,21 @@ func ValidateTokenStringToUUID(tokenString, jwtSecret string) (*UserContext, err
 		return nil, ErrInvalidToken
 	}
 
+	// Tokens without a session can't be revoked, so they are not accepted
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil || sessionID == uuid.Nil {
+		return nil, ErrInvalidToken
+	}
+
 	return &UserContext{
-		ID:       userID,
-		Username: claims.Username,
-		Email:    claims.Email,
-		Role:     claims.Role,
+		ID:        userID,
+		Username:  claims.Username,
+		Email:     claims.Email,
+		Role:      claims.Role,
+		SessionID: sessionID,
+
+		EmailVerified: claims.Verified,
+		MFAVerified:   claims.MFA,
 	}, nil
 }
 
@@ -109,12 +158,21 @@ func GetUserFromContext(c *fiber.Ctx) (*UserContext, error) {
 	return userCtx, nil
 }
 
-// GenerateToken generates a JWT token for the given user ID
-func GenerateToken(userID uuid.UUID, jwtSecret string) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id": userID.String(),
-		"exp":     time.Now().Add(time.Hour * 24 * 7).Unix(), // 7 days
-		"iat":     time.Now().Unix(),
+// GenerateAccessToken generates a short-lived JWT for the user's session
+func GenerateAccessToken(user *UserContext, ttl time.Duration, jwtSecret string) (string, error) {
+	now := time.Now()
+	claims := JWTClaims{
+		UserID:    user.ID.String(),
+		Username:  user.Username,
+		Email:     user.Email,
+		Role:      user.Role,
+		SessionID: user.SessionID.String(),
+		Verified:  user.EmailVerified,
+		MFA:       user.MFAVerified,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	"gofiber-template/domain/models"
+)
+
+func TestUserContextPermissions(t *testing.T) {
+	tokenID := uuid.New()
+
+	cases := []struct {
+		name string
+		user UserContext
+		want []string // Permissions that must be granted; nil means none at all
+	}{
+		{
+			name: "admin session with 2FA",
+			user: UserContext{Role: models.RoleAdmin, MFAVerified: true},
+			want: []string{models.PermissionUserAssignRole, models.PermissionSystemManage},
+		},
+		{
+			name: "admin session without 2FA",
+			user: UserContext{Role: models.RoleAdmin},
+		},
+		{
+			name: "moderator session without 2FA",
+			user: UserContext{Role: models.RoleModerator},
+		},
+		{
+			name: "moderator session with 2FA",
+			user: UserContext{Role: models.RoleModerator, MFAVerified: true},
+			want: []string{models.PermissionUserBan},
+		},
+		{
+			name: "admin token without the admin scope",
+			user: UserContext{Role: models.RoleAdmin, MFAVerified: true, TokenID: tokenID, Scopes: []string{models.TokenScopeRead, models.TokenScopePost}},
+		},
+		{
+			name: "admin token with the admin scope",
+			user: UserContext{Role: models.RoleAdmin, MFAVerified: true, TokenID: tokenID, Scopes: []string{models.TokenScopeAdmin}},
+			want: []string{models.PermissionSystemManage},
+		},
+		{
+			name: "admin token with the admin scope, created without 2FA",
+			user: UserContext{Role: models.RoleAdmin, TokenID: tokenID, Scopes: []string{models.TokenScopeAdmin}},
+		},
+		{
+			name: "regular user",
+			user: UserContext{Role: models.RoleUser, MFAVerified: true},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.user.Permissions()
+			if tc.want == nil && len(got) != 0 {
+				t.Fatalf("got permissions %v, want none", got)
+			}
+			for _, permission := range tc.want {
+				if !slices.Contains(got, permission) {
+					t.Fatalf("permissions %v lack %s", got, permission)
+				}
+			}
+		})
+	}
+}